@@ -0,0 +1,510 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ANSI Component Type definitions.
+const (
+	ANSIInvokeLast int = iota + 9
+	ANSIReturnResultLast
+	ANSIReturnError
+	ANSIReject
+	ANSIInvokeNotLast
+	ANSIReturnResultNotLast
+)
+
+// ANSI National Operation Family definitions.
+const (
+	ANSIFamilyParameter uint8 = iota + 1
+	ANSIFamilyCharging
+	ANSIFamilyProvideInstructions
+	ANSIFamilyConnectionControl
+	ANSIFamilyCallerInteraction
+	ANSIFamilySendNotification
+	ANSIFamilyNetworkManagement
+	ANSIFamilyProcedural
+	ANSIFamilyOperationControl
+	ANSIFamilyReportEvent
+	ANSIFamilyMiscellaneous uint8 = 0x7e
+)
+
+// ANSIReplyRequired is the bit in Operation Family indicating that the reply is required.
+const ANSIReplyRequired uint8 = 0x80
+
+// ANSI Problem Type definitions.
+const (
+	ANSIGeneralProblem uint8 = iota + 1
+	ANSIInvokeProblem
+	ANSIReturnResultProblem
+	ANSIReturnErrorProblem
+	ANSITransactionPortionProblem
+)
+
+// ANSI General Problem Specifier definitions.
+const (
+	ANSIUnrecognizedComponentType uint8 = iota + 1
+	ANSIIncorrectComponentPortion
+	ANSIBadlyStructuredComponentPortion
+	ANSIIncorrectComponentCoding
+)
+
+// ANSI Invoke Problem Specifier definitions.
+const (
+	ANSIInvokeProblemDuplicateInvokeID uint8 = iota + 1
+	ANSIInvokeProblemUnrecognizedOperationCode
+	ANSIInvokeProblemIncorrectParameter
+	ANSIInvokeProblemUnrecognizedCorrelationID
+)
+
+// ANSI Return Result Problem Specifier definitions.
+const (
+	ANSIResultProblemUnrecognizedCorrelationID uint8 = iota + 1
+	ANSIResultProblemUnexpectedReturnResult
+	ANSIResultProblemIncorrectParameter
+)
+
+// ANSI Return Error Problem Specifier definitions.
+const (
+	ANSIErrorProblemUnrecognizedCorrelationID uint8 = iota + 1
+	ANSIErrorProblemUnexpectedReturnError
+	ANSIErrorProblemUnrecognizedError
+	ANSIErrorProblemUnexpectedError
+	ANSIErrorProblemIncorrectParameter
+)
+
+// tag numbers of the elements in ANSI Component Portion, all in private class.
+const (
+	ansiTagComponentID           = 15
+	ansiTagNationalOperationCode = 16
+	ansiTagPrivateOperationCode  = 17
+	ansiTagParameterSet          = 18
+	ansiTagNationalErrorCode     = 19
+	ansiTagPrivateErrorCode      = 20
+	ansiTagProblemCode           = 21
+)
+
+// ANSIComponents represents the Component Sequence of ANSI TCAP.
+type ANSIComponents struct {
+	Tag       Tag
+	Length    uint8
+	Component []*ANSIComponent
+}
+
+// ANSIComponent represents a Component of ANSI TCAP.
+//
+// ComponentID holds the Invoke ID and/or the Correlation ID in one field.
+// Parameter is the Parameter Set or Parameter Sequence.
+type ANSIComponent struct {
+	Type          Tag
+	Length        uint8
+	ComponentID   *IE
+	OperationCode *IE
+	ErrorCode     *IE
+	ProblemCode   *IE
+	Parameter     *IE
+}
+
+// NewANSIComponents creates a new ANSIComponents.
+func NewANSIComponents(comps ...*ANSIComponent) *ANSIComponents {
+	c := &ANSIComponents{
+		Tag:       NewPrivateConstructorTag(ansiTagComponentSequence),
+		Component: comps,
+	}
+	c.SetLength()
+
+	return c
+}
+
+// newANSIComponent creates a new ANSIComponent with the Component IDs given,
+// ignoring the negative ones. The param is put in the Parameter Set.
+func newANSIComponent(ctype int, ids []int, param []byte) *ANSIComponent {
+	c := &ANSIComponent{
+		Type:        NewPrivateConstructorTag(ctype),
+		ComponentID: NewIE(NewPrivatePrimitiveTag(ansiTagComponentID), []byte{}),
+		Parameter:   NewIE(NewPrivateConstructorTag(ansiTagParameterSet), append([]byte{}, param...)),
+	}
+	for _, id := range ids {
+		if id >= 0 {
+			c.ComponentID.Value = append(c.ComponentID.Value, uint8(id))
+		}
+	}
+	return c
+}
+
+// NewANSIOperationCode returns a National or Private Operation Code.
+func NewANSIOperationCode(family, specifier uint8, isNational bool) *IE {
+	tag := ansiTagPrivateOperationCode
+	if isNational {
+		tag = ansiTagNationalOperationCode
+	}
+	return NewIE(NewPrivatePrimitiveTag(tag), []byte{family, specifier})
+}
+
+// NewANSIErrorCode returns a National or Private Error Code.
+func NewANSIErrorCode(code uint8, isNational bool) *IE {
+	tag := ansiTagPrivateErrorCode
+	if isNational {
+		tag = ansiTagNationalErrorCode
+	}
+	return NewIE(NewPrivatePrimitiveTag(tag), []byte{code})
+}
+
+// NewANSIInvoke returns a new Invoke (Last) or Invoke (Not Last) Component.
+//
+// corrID is the Correlation ID of the Invoke linked to, which is omitted if negative.
+// param is the contents of the Parameter Set.
+func NewANSIInvoke(invID, corrID int, family, specifier uint8, isNational, isLast bool, param []byte) *ANSIComponent {
+	ctype := ANSIInvokeNotLast
+	if isLast {
+		ctype = ANSIInvokeLast
+	}
+	c := newANSIComponent(ctype, []int{invID, corrID}, param)
+	c.OperationCode = NewANSIOperationCode(family, specifier, isNational)
+	c.SetLength()
+
+	return c
+}
+
+// NewANSIReturnResult returns a new Return Result (Last) or Return Result (Not Last) Component.
+//
+// corrID is the Invoke ID of the Invoke responded to.
+func NewANSIReturnResult(corrID int, isLast bool, param []byte) *ANSIComponent {
+	ctype := ANSIReturnResultNotLast
+	if isLast {
+		ctype = ANSIReturnResultLast
+	}
+	c := newANSIComponent(ctype, []int{corrID}, param)
+	c.SetLength()
+
+	return c
+}
+
+// NewANSIReturnError returns a new Return Error Component.
+func NewANSIReturnError(corrID int, errCode uint8, isNational bool, param []byte) *ANSIComponent {
+	c := newANSIComponent(ANSIReturnError, []int{corrID}, param)
+	c.ErrorCode = NewANSIErrorCode(errCode, isNational)
+	c.SetLength()
+
+	return c
+}
+
+// NewANSIReject returns a new Reject Component.
+//
+// corrID is the Invoke ID of the Component rejected, which is omitted if negative.
+func NewANSIReject(corrID int, problemType, problemSpecifier uint8, param []byte) *ANSIComponent {
+	c := newANSIComponent(ANSIReject, []int{corrID}, param)
+	c.ProblemCode = NewIE(NewPrivatePrimitiveTag(ansiTagProblemCode), []byte{problemType, problemSpecifier})
+	c.SetLength()
+
+	return c
+}
+
+// MarshalBinary returns the byte sequence generated from an ANSIComponents instance.
+func (c *ANSIComponents) MarshalBinary() ([]byte, error) {
+	return marshalBinary(c)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (c *ANSIComponents) MarshalTo(b []byte) error {
+	return marshalTo(c, b)
+}
+
+// AppendBinary appends the byte sequence generated from an ANSIComponents instance to b.
+func (c *ANSIComponents) AppendBinary(b []byte) ([]byte, error) {
+	return c.appendTo(b, MarshalOptions{})
+}
+
+func (c *ANSIComponents) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, c.Tag)
+	for _, comp := range c.Component {
+		var err error
+		if b, err = comp.appendTo(b, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return endTLV(b, off, c.Tag, c.Length, o)
+}
+
+// ParseANSIComponents parses given byte sequence as an ANSIComponents.
+func ParseANSIComponents(b []byte) (*ANSIComponents, error) {
+	c := &ANSIComponents{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ANSIComponents.
+func (c *ANSIComponents) UnmarshalBinary(b []byte) error {
+	ies, err := parseANSIIEs(b)
+	if err != nil {
+		return err
+	}
+	if len(ies) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	c.Tag = ies[0].Tag
+	c.Length = ies[0].Length
+	return c.parseComponents(ies[0].Value)
+}
+
+func (c *ANSIComponents) parseComponents(b []byte) error {
+	ies, err := parseANSIIEs(b)
+	if err != nil {
+		return err
+	}
+
+	c.Component = nil
+	for _, ie := range ies {
+		comp := &ANSIComponent{Type: ie.Tag, Length: ie.Length}
+		if err := comp.parseFields(ie.Value); err != nil {
+			return err
+		}
+		c.Component = append(c.Component, comp)
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of ANSIComponents.
+func (c *ANSIComponents) MarshalLen() int {
+	return tlvLen(c.valueLen())
+}
+
+// valueLen returns the serial length of the Components.
+func (c *ANSIComponents) valueLen() int {
+	l := 0
+	for _, comp := range c.Component {
+		l += comp.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (c *ANSIComponents) SetLength() {
+	for _, comp := range c.Component {
+		comp.SetLength()
+	}
+	c.Length = lengthOctet(c.valueLen())
+}
+
+// String returns ANSIComponents in human readable string.
+func (c *ANSIComponents) String() string {
+	return fmt.Sprintf("{Tag: %#x, Length: %d, Component: %v}",
+		c.Tag,
+		c.Length,
+		c.Component,
+	)
+}
+
+func (c *ANSIComponent) fields() []*IE {
+	return []*IE{c.ComponentID, c.OperationCode, c.ErrorCode, c.ProblemCode, c.Parameter}
+}
+
+// MarshalBinary returns the byte sequence generated from an ANSIComponent instance.
+func (c *ANSIComponent) MarshalBinary() ([]byte, error) {
+	return marshalBinary(c)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (c *ANSIComponent) MarshalTo(b []byte) error {
+	return marshalTo(c, b)
+}
+
+// AppendBinary appends the byte sequence generated from an ANSIComponent instance to b.
+func (c *ANSIComponent) AppendBinary(b []byte) ([]byte, error) {
+	return c.appendTo(b, MarshalOptions{})
+}
+
+func (c *ANSIComponent) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, c.Type)
+	b, err := appendIEs(b, o, c.ComponentID, c.OperationCode, c.ErrorCode, c.ProblemCode, c.Parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	return endTLV(b, off, c.Type, c.Length, o)
+}
+
+// ParseANSIComponent parses given byte sequence as an ANSIComponent.
+func ParseANSIComponent(b []byte) (*ANSIComponent, error) {
+	c := &ANSIComponent{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ANSIComponent.
+func (c *ANSIComponent) UnmarshalBinary(b []byte) error {
+	ies, err := parseANSIIEs(b)
+	if err != nil {
+		return err
+	}
+	if len(ies) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	c.Type = ies[0].Tag
+	c.Length = ies[0].Length
+	return c.parseFields(ies[0].Value)
+}
+
+func (c *ANSIComponent) parseFields(b []byte) error {
+	ies, err := parseANSIIEs(b)
+	if err != nil {
+		return err
+	}
+
+	for _, ie := range ies {
+		if ie.Tag == 0x30 {
+			// Parameter Sequence.
+			c.Parameter = ie
+			continue
+		}
+		if ie.Tag.Class() != Private {
+			continue
+		}
+		switch ie.Tag.Code() {
+		case ansiTagComponentID:
+			c.ComponentID = ie
+		case ansiTagNationalOperationCode, ansiTagPrivateOperationCode:
+			c.OperationCode = ie
+		case ansiTagNationalErrorCode, ansiTagPrivateErrorCode:
+			c.ErrorCode = ie
+		case ansiTagProblemCode:
+			c.ProblemCode = ie
+		case ansiTagParameterSet:
+			c.Parameter = ie
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of ANSIComponent.
+func (c *ANSIComponent) MarshalLen() int {
+	return tlvLen(c.valueLen())
+}
+
+// valueLen returns the serial length of the fields of ANSIComponent.
+func (c *ANSIComponent) valueLen() int {
+	l := 0
+	for _, field := range c.fields() {
+		if field != nil {
+			l += field.MarshalLen()
+		}
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (c *ANSIComponent) SetLength() {
+	for _, field := range c.fields() {
+		if field != nil {
+			field.SetLength()
+		}
+	}
+	c.Length = lengthOctet(c.valueLen())
+}
+
+// ComponentTypeString returns the Component Type in string.
+func (c *ANSIComponent) ComponentTypeString() string {
+	switch c.Type.Code() {
+	case ANSIInvokeLast:
+		return "invokeLast"
+	case ANSIReturnResultLast:
+		return "returnResultLast"
+	case ANSIReturnError:
+		return "returnError"
+	case ANSIReject:
+		return "reject"
+	case ANSIInvokeNotLast:
+		return "invokeNotLast"
+	case ANSIReturnResultNotLast:
+		return "returnResultNotLast"
+	}
+	return ""
+}
+
+// IsLast reports whether the Component is the last one of the operation,
+// i.e., not Invoke (Not Last) nor Return Result (Not Last).
+func (c *ANSIComponent) IsLast() bool {
+	code := c.Type.Code()
+	return code != ANSIInvokeNotLast && code != ANSIReturnResultNotLast
+}
+
+// InvID returns the Invoke ID of Invoke, or the Correlation ID of the other Components.
+func (c *ANSIComponent) InvID() uint8 {
+	if c.ComponentID != nil && len(c.ComponentID.Value) > 0 {
+		return c.ComponentID.Value[0]
+	}
+	return 0
+}
+
+// CorrelationID returns the Correlation ID of Invoke, which is the Invoke ID of
+// the Invoke linked to. ok is false if it is not present.
+func (c *ANSIComponent) CorrelationID() (id uint8, ok bool) {
+	if c.ComponentID != nil && len(c.ComponentID.Value) > 1 {
+		return c.ComponentID.Value[1], true
+	}
+	return 0, false
+}
+
+// IsNational reports whether the Operation Code or Error Code is in National family.
+func (c *ANSIComponent) IsNational() bool {
+	for _, field := range []*IE{c.OperationCode, c.ErrorCode} {
+		if field != nil {
+			code := field.Tag.Code()
+			return code == ansiTagNationalOperationCode || code == ansiTagNationalErrorCode
+		}
+	}
+	return false
+}
+
+// OpCode returns the Operation Code in uint16, of which the first octet is the
+// Operation Family and the second one is the Operation Specifier.
+//
+// The Reply Required bit is included in the Operation Family.
+func (c *ANSIComponent) OpCode() uint16 {
+	if c.OperationCode == nil || len(c.OperationCode.Value) != 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(c.OperationCode.Value)
+}
+
+// ErrCode returns the Error Code.
+func (c *ANSIComponent) ErrCode() uint8 {
+	if c.ErrorCode == nil || len(c.ErrorCode.Value) == 0 {
+		return 0
+	}
+	return c.ErrorCode.Value[0]
+}
+
+// Problem returns the Problem Type and the Problem Specifier.
+func (c *ANSIComponent) Problem() (problemType, specifier uint8) {
+	if c.ProblemCode == nil || len(c.ProblemCode.Value) != 2 {
+		return 0, 0
+	}
+	return c.ProblemCode.Value[0], c.ProblemCode.Value[1]
+}
+
+// String returns ANSIComponent in human readable string.
+func (c *ANSIComponent) String() string {
+	return fmt.Sprintf("{Type: %#x, Length: %d, ComponentID: %v, OperationCode: %v, ErrorCode: %v, ProblemCode: %v, Parameter: %v}",
+		c.Type,
+		c.Length,
+		c.ComponentID,
+		c.OperationCode,
+		c.ErrorCode,
+		c.ProblemCode,
+		c.Parameter,
+	)
+}
@@ -0,0 +1,428 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Variant definitions.
+const (
+	VariantAuto int = iota
+	VariantITU
+	VariantANSI
+)
+
+// ANSI Package Type definitions, as in ANSI T1.114.
+const (
+	ANSIUnidirectional int = iota + 1
+	ANSIQueryWithPermission
+	ANSIQueryWithoutPermission
+	ANSIResponse
+	ANSIConversationWithPermission
+	ANSIConversationWithoutPermission
+	ANSIAbort = 22
+)
+
+// ANSI P-Abort Cause definitions.
+const (
+	ANSIUnrecognizedPackageType uint8 = iota + 1
+	ANSIIncorrectTransactionPortion
+	ANSIBadlyStructuredTransactionPortion
+	ANSIUnassignedRespondingTransactionID
+	ANSIPermissionToReleaseProblem
+	ANSIResourceUnavailable
+	ANSIUnrecognizedDialoguePortionID
+	ANSIBadlyStructuredDialoguePortion
+	ANSIMissingDialoguePortion
+	ANSIInconsistentDialoguePortion
+)
+
+// tag numbers of the elements in ANSI TCAP, all in private class.
+const (
+	ansiTagTransactionID        = 7
+	ansiTagComponentSequence    = 8
+	ansiTagPAbortCause          = 23
+	ansiTagUserAbortInformation = 24
+	ansiTagDialoguePortion      = 25
+	ansiTagApplicationContextID = 28
+)
+
+// Message is a TCAP message of either ITU-T or ANSI variant, i.e., *TCAP or *ANSITCAP.
+type Message interface {
+	MarshalBinary() ([]byte, error)
+	MarshalTo(b []byte) error
+	MarshalLen() int
+	String() string
+}
+
+// ANSITCAP represents a TCAP message of ANSI variant (ANSI T1.114).
+//
+// TransactionID holds the Originating and Responding Transaction IDs in
+// one field, and Dialogue is the Dialogue Portion as it is.
+type ANSITCAP struct {
+	PackageType          Tag
+	Length               uint8
+	TransactionID        *IE
+	PAbortCause          *IE
+	UserAbortInformation *IE
+	Dialogue             *IE
+	Components           *ANSIComponents
+}
+
+// NewANSITCAP creates a new ANSITCAP with the Package Type and the Transaction IDs given.
+//
+// The Transaction IDs are put in the Transaction ID field in order, e.g.,
+// the Originating TID and the Responding TID for Conversation.
+func NewANSITCAP(pkgType int, tids []uint32, comps ...*ANSIComponent) *ANSITCAP {
+	t := &ANSITCAP{
+		PackageType:   NewPrivateConstructorTag(pkgType),
+		TransactionID: NewIE(NewPrivatePrimitiveTag(ansiTagTransactionID), make([]byte, 4*len(tids))),
+	}
+	for i, tid := range tids {
+		binary.BigEndian.PutUint32(t.TransactionID.Value[4*i:], tid)
+	}
+	if len(comps) > 0 {
+		t.Components = NewANSIComponents(comps...)
+	}
+	t.SetLength()
+
+	return t
+}
+
+// NewANSIUnidirectional creates a new ANSITCAP of type Unidirectional.
+func NewANSIUnidirectional(comps ...*ANSIComponent) *ANSITCAP {
+	return NewANSITCAP(ANSIUnidirectional, nil, comps...)
+}
+
+// NewANSIQuery creates a new ANSITCAP of type Query With/Without Permission.
+func NewANSIQuery(otid uint32, withPermission bool, comps ...*ANSIComponent) *ANSITCAP {
+	pkgType := ANSIQueryWithoutPermission
+	if withPermission {
+		pkgType = ANSIQueryWithPermission
+	}
+	return NewANSITCAP(pkgType, []uint32{otid}, comps...)
+}
+
+// NewANSIConversation creates a new ANSITCAP of type Conversation With/Without Permission.
+func NewANSIConversation(otid, rtid uint32, withPermission bool, comps ...*ANSIComponent) *ANSITCAP {
+	pkgType := ANSIConversationWithoutPermission
+	if withPermission {
+		pkgType = ANSIConversationWithPermission
+	}
+	return NewANSITCAP(pkgType, []uint32{otid, rtid}, comps...)
+}
+
+// NewANSIResponse creates a new ANSITCAP of type Response.
+func NewANSIResponse(rtid uint32, comps ...*ANSIComponent) *ANSITCAP {
+	return NewANSITCAP(ANSIResponse, []uint32{rtid}, comps...)
+}
+
+// NewANSIAbort creates a new ANSITCAP of type Abort with the P-Abort Cause.
+func NewANSIAbort(rtid uint32, cause uint8) *ANSITCAP {
+	t := NewANSITCAP(ANSIAbort, []uint32{rtid})
+	t.PAbortCause = NewIE(NewPrivatePrimitiveTag(ansiTagPAbortCause), []byte{cause})
+	t.SetLength()
+
+	return t
+}
+
+// NewANSIUserAbort creates a new ANSITCAP of type Abort with the User Abort Information.
+func NewANSIUserAbort(rtid uint32, info []byte) *ANSITCAP {
+	t := NewANSITCAP(ANSIAbort, []uint32{rtid})
+	t.UserAbortInformation = NewIE(NewPrivateConstructorTag(ansiTagUserAbortInformation), info)
+	t.SetLength()
+
+	return t
+}
+
+// NewANSIDialogue creates a new Dialogue Portion with the Application Context Name
+// in the form of OID.
+//
+// It returns nil if the OID is invalid.
+func NewANSIDialogue(acn asn1.ObjectIdentifier) *IE {
+	v, err := ber.EncodeObjectIdentifier(acn)
+	if err != nil {
+		DefaultLogger().Warn("failed to encode Application Context Name", "acn", acn.String(), "error", err)
+		return nil
+	}
+
+	acnIE := NewIE(NewPrivatePrimitiveTag(ansiTagApplicationContextID), v)
+	b, err := acnIE.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	return NewIE(NewPrivateConstructorTag(ansiTagDialoguePortion), b)
+}
+
+// DetectVariant returns the variant of TCAP message by the first octet.
+//
+// It returns VariantAuto if the variant cannot be determined.
+func DetectVariant(b []byte) int {
+	if len(b) == 0 {
+		return VariantAuto
+	}
+
+	tag := Tag(b[0])
+	switch {
+	case tag.Class() == Private && tag.Form() == Constructor:
+		switch tag.Code() {
+		case ANSIUnidirectional, ANSIQueryWithPermission, ANSIQueryWithoutPermission, ANSIResponse,
+			ANSIConversationWithPermission, ANSIConversationWithoutPermission, ANSIAbort:
+			return VariantANSI
+		}
+	case tag.Class() == ApplicationWide && tag.Form() == Constructor:
+		switch tag.Code() {
+		case Unidirectional, Begin, End, Continue, Abort:
+			return VariantITU
+		}
+	}
+	return VariantAuto
+}
+
+// ParseWithVariant parses given byte sequence as a TCAP message of the variant.
+//
+// If variant is VariantAuto, it is determined by the first octet with DetectVariant.
+// The returned Message is *TCAP for ITU-T and *ANSITCAP for ANSI.
+func ParseWithVariant(b []byte, variant int) (Message, error) {
+	if variant == VariantAuto {
+		variant = DetectVariant(b)
+	}
+
+	switch variant {
+	case VariantITU:
+		return Parse(b)
+	case VariantANSI:
+		return ParseANSI(b)
+	}
+	if len(b) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return nil, &InvalidCodeError{Code: int(b[0])}
+}
+
+// MarshalBinary returns the byte sequence generated from an ANSITCAP instance.
+func (t *ANSITCAP) MarshalBinary() ([]byte, error) {
+	return marshalBinary(t)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (t *ANSITCAP) MarshalTo(b []byte) error {
+	return marshalTo(t, b)
+}
+
+// AppendBinary appends the byte sequence generated from an ANSITCAP instance to b.
+func (t *ANSITCAP) AppendBinary(b []byte) ([]byte, error) {
+	return t.appendTo(b, MarshalOptions{})
+}
+
+func (t *ANSITCAP) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, t.PackageType)
+	b, err := appendIEs(b, o, t.TransactionID, t.Dialogue, t.PAbortCause, t.UserAbortInformation)
+	if err != nil {
+		return nil, err
+	}
+	if portion := t.Components; portion != nil {
+		if b, err = portion.appendTo(b, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return endTLV(b, off, t.PackageType, t.Length, o)
+}
+
+// ParseANSI parses given byte sequence as an ANSITCAP.
+func ParseANSI(b []byte) (*ANSITCAP, error) {
+	t := &ANSITCAP{}
+	if err := t.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ANSITCAP.
+func (t *ANSITCAP) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	t.PackageType = Tag(b[0])
+	t.Length = b[1]
+	n, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
+	if len(b) < offset+n {
+		return io.ErrUnexpectedEOF
+	}
+
+	ies, err := parseANSIIEs(b[offset : offset+n])
+	if err != nil {
+		return err
+	}
+	for _, ie := range ies {
+		if ie.Tag.Class() != Private {
+			continue
+		}
+		switch ie.Tag.Code() {
+		case ansiTagTransactionID:
+			t.TransactionID = ie
+		case ansiTagDialoguePortion:
+			t.Dialogue = ie
+		case ansiTagPAbortCause:
+			t.PAbortCause = ie
+		case ansiTagUserAbortInformation:
+			t.UserAbortInformation = ie
+		case ansiTagComponentSequence:
+			t.Components = &ANSIComponents{Tag: ie.Tag, Length: ie.Length}
+			if err := t.Components.parseComponents(ie.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseANSIIEs parses the elements in b, allowing the ones without contents at
+// the end of b.
+func parseANSIIEs(b []byte) ([]*IE, error) {
+	var ies []*IE
+	for len(b) > 0 {
+		i := &IE{}
+		n, err := i.unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		ies = append(ies, i)
+		b = b[n:]
+	}
+	return ies, nil
+}
+
+// MarshalLen returns the serial length of ANSITCAP.
+func (t *ANSITCAP) MarshalLen() int {
+	return tlvLen(t.valueLen())
+}
+
+// valueLen returns the serial length of the contents of ANSITCAP.
+func (t *ANSITCAP) valueLen() int {
+	l := 0
+	for _, field := range []*IE{t.TransactionID, t.Dialogue, t.PAbortCause, t.UserAbortInformation} {
+		if field != nil {
+			l += field.MarshalLen()
+		}
+	}
+	if portion := t.Components; portion != nil {
+		l += portion.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (t *ANSITCAP) SetLength() {
+	for _, field := range []*IE{t.TransactionID, t.Dialogue, t.PAbortCause, t.UserAbortInformation} {
+		if field != nil {
+			field.SetLength()
+		}
+	}
+	if portion := t.Components; portion != nil {
+		portion.SetLength()
+	}
+	t.Length = lengthOctet(t.valueLen())
+}
+
+// PackageTypeString returns the Package Type in string.
+func (t *ANSITCAP) PackageTypeString() string {
+	switch t.PackageType.Code() {
+	case ANSIUnidirectional:
+		return "unidirectional"
+	case ANSIQueryWithPermission:
+		return "queryWithPermission"
+	case ANSIQueryWithoutPermission:
+		return "queryWithoutPermission"
+	case ANSIResponse:
+		return "response"
+	case ANSIConversationWithPermission:
+		return "conversationWithPermission"
+	case ANSIConversationWithoutPermission:
+		return "conversationWithoutPermission"
+	case ANSIAbort:
+		return "abort"
+	}
+	return ""
+}
+
+// tid returns the n-th Transaction ID in the Transaction ID field.
+func (t *ANSITCAP) tid(n int) uint32 {
+	if t.TransactionID == nil || len(t.TransactionID.Value) < 4*(n+1) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(t.TransactionID.Value[4*n:])
+}
+
+// OTID returns the Originating Transaction ID in uint32.
+//
+// It returns 0 for the Package Types without Originating TID, i.e., Unidirectional,
+// Response and Abort.
+func (t *ANSITCAP) OTID() uint32 {
+	switch t.PackageType.Code() {
+	case ANSIQueryWithPermission, ANSIQueryWithoutPermission,
+		ANSIConversationWithPermission, ANSIConversationWithoutPermission:
+		return t.tid(0)
+	}
+	return 0
+}
+
+// RTID returns the Responding Transaction ID in uint32.
+//
+// It returns 0 for the Package Types without Responding TID, i.e., Unidirectional and Query.
+func (t *ANSITCAP) RTID() uint32 {
+	switch t.PackageType.Code() {
+	case ANSIResponse, ANSIAbort:
+		return t.tid(0)
+	case ANSIConversationWithPermission, ANSIConversationWithoutPermission:
+		return t.tid(1)
+	}
+	return 0
+}
+
+// ApplicationContextOID returns the Application Context Name in the Dialogue Portion
+// in the form of OID, or nil if not present.
+func (t *ANSITCAP) ApplicationContextOID() asn1.ObjectIdentifier {
+	if t.Dialogue == nil {
+		return nil
+	}
+	ies, err := parseANSIIEs(t.Dialogue.Value)
+	if err != nil {
+		return nil
+	}
+	for _, ie := range ies {
+		if ie.Tag.Class() == Private && ie.Tag.Code() == ansiTagApplicationContextID {
+			oid, err := ber.DecodeObjectIdentifier(ie.Value)
+			if err != nil {
+				return nil
+			}
+			return oid
+		}
+	}
+	return nil
+}
+
+// String returns ANSITCAP in human readable string.
+func (t *ANSITCAP) String() string {
+	return fmt.Sprintf("{PackageType: %#x, Length: %d, TransactionID: %v, Dialogue: %v, PAbortCause: %v, UserAbortInformation: %v, Components: %v}",
+		t.PackageType,
+		t.Length,
+		t.TransactionID,
+		t.Dialogue,
+		t.PAbortCause,
+		t.UserAbortInformation,
+		t.Components,
+	)
+}
@@ -0,0 +1,255 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package ansi41 provides the typed parameters of IS-41 (ANSI-41) operations
+carried on ANSI TCAP, which can be set into and retrieved from the Parameter Set
+of tcap.ANSIComponent.
+
+IS-41 operations are Private TCAP operations in the Operation Family 9. The
+Operation Codes defined in this package are the Operation Specifiers in the family.
+*/
+package ansi41
+
+import (
+	"fmt"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// OperationFamily is the Operation Family of IS-41 operations.
+const OperationFamily uint8 = 9
+
+// Operation Specifier definitions.
+const (
+	QualificationRequest     uint8 = 6
+	RegistrationNotification uint8 = 13
+	LocationRequest          uint8 = 15
+	RoutingRequest           uint8 = 16
+	SMSDeliveryPointToPoint  uint8 = 53
+)
+
+// Error Code definitions.
+const (
+	UnrecognizedMIN            uint8 = 0x81
+	UnrecognizedESN            uint8 = 0x82
+	MINHLRMismatch             uint8 = 0x83
+	OperationSequenceProblem   uint8 = 0x84
+	ResourceShortage           uint8 = 0x85
+	OperationNotSupported      uint8 = 0x86
+	TrunkUnavailable           uint8 = 0x87
+	ParameterError             uint8 = 0x88
+	SystemFailure              uint8 = 0x89
+	UnrecognizedParameterValue uint8 = 0x8a
+	FeatureInactive            uint8 = 0x8b
+	MissingParameter           uint8 = 0x8c
+)
+
+// Parameter is the argument or result of IS-41 operation.
+//
+// MarshalBinary returns the contents of the Parameter Set, and UnmarshalBinary
+// accepts the same.
+type Parameter = param.Parameter
+
+var operations = map[uint8]param.Operation{
+	QualificationRequest: {
+		Name: "QualificationRequest",
+		Arg:  func() Parameter { return &QualificationRequestArg{} },
+		Res:  func() Parameter { return &QualificationRequestRes{} },
+	},
+	RegistrationNotification: {
+		Name: "RegistrationNotification",
+		Arg:  func() Parameter { return &RegistrationNotificationArg{} },
+		Res:  func() Parameter { return &RegistrationNotificationRes{} },
+	},
+	LocationRequest: {
+		Name: "LocationRequest",
+		Arg:  func() Parameter { return &LocationRequestArg{} },
+		Res:  func() Parameter { return &LocationRequestRes{} },
+	},
+	RoutingRequest: {
+		Name: "RoutingRequest",
+		Arg:  func() Parameter { return &RoutingRequestArg{} },
+		Res:  func() Parameter { return &RoutingRequestRes{} },
+	},
+	SMSDeliveryPointToPoint: {
+		Name: "SMSDeliveryPointToPoint",
+		Arg:  func() Parameter { return &SMSDeliveryPointToPointArg{} },
+		Res:  func() Parameter { return &SMSDeliveryPointToPointRes{} },
+	},
+}
+
+var errorNames = map[uint8]string{
+	UnrecognizedMIN:            "UnrecognizedMIN",
+	UnrecognizedESN:            "UnrecognizedESN",
+	MINHLRMismatch:             "MIN/HLRMismatch",
+	OperationSequenceProblem:   "OperationSequenceProblem",
+	ResourceShortage:           "ResourceShortage",
+	OperationNotSupported:      "OperationNotSupported",
+	TrunkUnavailable:           "TrunkUnavailable",
+	ParameterError:             "ParameterError",
+	SystemFailure:              "SystemFailure",
+	UnrecognizedParameterValue: "UnrecognizedParameterValue",
+	FeatureInactive:            "FeatureInactive",
+	MissingParameter:           "MissingParameter",
+}
+
+var table = &param.Table{
+	Protocol:   protocol,
+	Operations: operations,
+	Errors:     errorNames,
+	Contents:   true,
+}
+
+// Registry holds the IS-41 operations supported by this package, which is
+// included in tcap.DefaultOperationRegistry on init.
+//
+// As IS-41 has no application context, the operations are found in
+// tcap.DefaultOperationRegistry only by the codes that the other protocols
+// included do not use.
+var Registry = table.Registry()
+
+func init() {
+	tcap.DefaultOperationRegistry.Include(Registry)
+}
+
+// OperationName returns the name of operation in string.
+func OperationName(opCode uint8) string {
+	return table.OperationName(opCode)
+}
+
+// ErrorName returns the name of error in string.
+func ErrorName(errCode uint8) string {
+	return table.ErrorName(errCode)
+}
+
+// NewArgument returns an empty argument of the operation.
+func NewArgument(opCode uint8) (Parameter, error) {
+	return table.NewArgument(opCode)
+}
+
+// NewResult returns an empty result of the operation.
+func NewResult(opCode uint8) (Parameter, error) {
+	return table.NewResult(opCode)
+}
+
+// MarshalParameter returns the contents of the Parameter Set generated from p.
+func MarshalParameter(p Parameter) ([]byte, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UnmarshalParameter sets the values retrieved from the Parameter Set in tcap.ANSIComponent in p.
+func UnmarshalParameter(c *tcap.ANSIComponent, p Parameter) error {
+	if c.Parameter == nil {
+		return protocol.Missing("ParameterSet")
+	}
+	return p.UnmarshalBinary(c.Parameter.Value)
+}
+
+// NewInvoke returns a new Invoke (Last) Component with the argument of the operation.
+//
+// The Reply Required bit is set in the Operation Family.
+func NewInvoke(invID int, opCode uint8, arg Parameter) (*tcap.ANSIComponent, error) {
+	if _, ok := operations[opCode]; !ok {
+		return nil, protocol.Unsupported("", opCode)
+	}
+
+	b, err := MarshalParameter(arg)
+	if err != nil {
+		return nil, err
+	}
+	return tcap.NewANSIInvoke(invID, -1, OperationFamily|tcap.ANSIReplyRequired, opCode, false, true, b), nil
+}
+
+// NewReturnResult returns a new Return Result (Last) Component with the result of the operation.
+func NewReturnResult(corrID int, res Parameter) (*tcap.ANSIComponent, error) {
+	b, err := MarshalParameter(res)
+	if err != nil {
+		return nil, err
+	}
+	return tcap.NewANSIReturnResult(corrID, true, b), nil
+}
+
+// NewReturnError returns a new Return Error Component with the Private Error Code.
+func NewReturnError(corrID int, errCode uint8) *tcap.ANSIComponent {
+	return tcap.NewANSIReturnError(corrID, errCode, false, nil)
+}
+
+// OpCode returns the Operation Specifier of IS-41 operation in the Invoke
+// Component. ok is false if the Component is not an IS-41 Invoke.
+func OpCode(c *tcap.ANSIComponent) (opCode uint8, ok bool) {
+	if c.OperationCode == nil || c.IsNational() {
+		return 0, false
+	}
+	code := c.OpCode()
+	if uint8(code>>8)&^tcap.ANSIReplyRequired != OperationFamily {
+		return 0, false
+	}
+	return uint8(code), true
+}
+
+// ParseArgument parses the Parameter Set of Invoke Component as the argument of the operation.
+func ParseArgument(c *tcap.ANSIComponent) (Parameter, error) {
+	opCode, ok := OpCode(c)
+	if !ok {
+		return nil, protocol.Unsupported("", uint8(c.OpCode()))
+	}
+
+	p, err := NewArgument(opCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := UnmarshalParameter(c, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseResult parses the Parameter Set of Return Result Component as the result
+// of the operation.
+//
+// As Return Result does not have Operation Code, opCode should be the one of
+// the Invoke responded to.
+func ParseResult(opCode uint8, c *tcap.ANSIComponent) (Parameter, error) {
+	p, err := NewResult(opCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := UnmarshalParameter(c, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// marshalSet returns the contents of the Parameter Set encoded from the struct pointed by v.
+func marshalSet(v interface{}) ([]byte, error) {
+	b, err := ber.Marshal(v)
+	if err != nil {
+		return nil, protocol.Invalid(fmt.Sprintf("%T: %v", v, err))
+	}
+	return b, nil
+}
+
+// unmarshalSet decodes the contents of the Parameter Set into the struct pointed
+// by v, regardless of the order of the parameters.
+func unmarshalSet(b []byte, v interface{}) error {
+	elems, err := ber.ParseValues(b)
+	if err != nil {
+		return protocol.Invalid(fmt.Sprintf("%T: %v", v, err))
+	}
+	if err := ber.UnmarshalValueWithParams(ber.NewSet(elems...), v, "set"); err != nil {
+		return protocol.Invalid(fmt.Sprintf("%T: %v", v, err))
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi41_test
+
+import (
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ansi41"
+)
+
+func mustMIN(t *testing.T, min string) []byte {
+	t.Helper()
+	b, err := ansi41.EncodeMIN(min)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func mustDigits(t *testing.T, typ uint8, digits string) []byte {
+	t.Helper()
+	b, err := ansi41.NewDigits(typ, digits).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestOperations(t *testing.T) {
+	esn := ansi41.EncodeESN(0x8012abcd)
+	mscid := ansi41.EncodeMSCID(0x0102, 3)
+	billingID := []byte{0x01, 0x02, 0x03, 0x00, 0x00, 0x01, 0x00}
+
+	cases := []struct {
+		description string
+		opCode      uint8
+		arg         ansi41.Parameter
+		res         ansi41.Parameter
+	}{
+		{
+			description: "QualificationRequest",
+			opCode:      ansi41.QualificationRequest,
+			arg: &ansi41.QualificationRequestArg{
+				ElectronicSerialNumber:       esn,
+				MobileIdentificationNumber:   mustMIN(t, "2015550123"),
+				QualificationInformationCode: int(ansi41.QualificationValidationAndProfile),
+				SystemMyTypeCode:             1,
+			},
+			res: &ansi41.QualificationRequestRes{
+				SystemMyTypeCode:    1,
+				AuthorizationPeriod: []byte{0x01, 0x18},
+			},
+		}, {
+			description: "RegistrationNotification",
+			opCode:      ansi41.RegistrationNotification,
+			arg: &ansi41.RegistrationNotificationArg{
+				ElectronicSerialNumber:       esn,
+				MobileIdentificationNumber:   mustMIN(t, "2015550123"),
+				MSCID:                        mscid,
+				QualificationInformationCode: int(ansi41.QualificationValidationOnly),
+				SystemMyTypeCode:             1,
+				PCSSN:                        []byte{0x01, 0x00, 0x11, 0x22, 0x06},
+				TransactionCapability:        []byte{0x06},
+			},
+			res: &ansi41.RegistrationNotificationRes{
+				SystemMyTypeCode:    1,
+				AuthorizationDenied: 1,
+			},
+		}, {
+			description: "LocationRequest",
+			opCode:      ansi41.LocationRequest,
+			arg: &ansi41.LocationRequestArg{
+				BillingID:        billingID,
+				Digits:           mustDigits(t, ansi41.DigitsDialed, "12015550123"),
+				MSCID:            mscid,
+				SystemMyTypeCode: 1,
+			},
+			res: &ansi41.LocationRequestRes{
+				ElectronicSerialNumber:     esn,
+				MobileIdentificationNumber: mustMIN(t, "2015550123"),
+				MSCID:                      mscid,
+				Digits:                     mustDigits(t, ansi41.DigitsDestination, "12015559999"),
+			},
+		}, {
+			description: "RoutingRequest",
+			opCode:      ansi41.RoutingRequest,
+			arg: &ansi41.RoutingRequestArg{
+				BillingID:                  billingID,
+				ElectronicSerialNumber:     esn,
+				MobileIdentificationNumber: mustMIN(t, "2015550123"),
+				MSCID:                      mscid,
+				SystemMyTypeCode:           1,
+			},
+			res: &ansi41.RoutingRequestRes{
+				MSCID:              mscid,
+				AccessDeniedReason: ansi41.AccessDeniedBusy,
+			},
+		}, {
+			description: "SMSDeliveryPointToPoint",
+			opCode:      ansi41.SMSDeliveryPointToPoint,
+			arg: &ansi41.SMSDeliveryPointToPointArg{
+				SMSBearerData:                 []byte{0x00, 0x03, 0x10, 0x00, 0x10},
+				SMSTeleserviceIdentifier:      ansi41.TeleserviceCMT95,
+				MobileIdentificationNumber:    mustMIN(t, "2015550123"),
+				SMSOriginalOriginatingAddress: mustDigits(t, ansi41.DigitsCalling, "12015550000"),
+			},
+			res: &ansi41.SMSDeliveryPointToPointRes{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			inv, err := ansi41.NewInvoke(1, c.opCode, c.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := tcap.NewANSIQuery(0x11223344, true, inv).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := tcap.ParseANSI(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := msg.Components.Component[0]
+			if opCode, ok := ansi41.OpCode(got); !ok || opCode != c.opCode {
+				t.Errorf("unexpected OpCode: %d, %v", opCode, ok)
+			}
+			arg, err := ansi41.ParseArgument(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, c.description, arg, c.arg)
+
+			rr, err := ansi41.NewReturnResult(int(got.InvID()), c.res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err = tcap.NewANSIResponse(0x11223344, rr).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, err = tcap.ParseANSI(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := ansi41.ParseResult(c.opCode, msg.Components.Component[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, c.description, res, c.res)
+		})
+	}
+}
+
+func TestParameterEncoding(t *testing.T) {
+	arg := &ansi41.QualificationRequestArg{
+		ElectronicSerialNumber:       ansi41.EncodeESN(0x01020304),
+		MobileIdentificationNumber:   []byte{0x21, 0x43, 0x65, 0x87, 0x09},
+		QualificationInformationCode: 3,
+		SystemMyTypeCode:             1,
+	}
+	b, err := arg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x9f, 0x81, 0x09, 0x04, 0x01, 0x02, 0x03, 0x04,
+		0x9f, 0x81, 0x08, 0x05, 0x21, 0x43, 0x65, 0x87, 0x09,
+		0x91, 0x01, 0x03,
+		0x96, 0x01, 0x01,
+	}
+	verify.Values(t, "QualificationRequestArg", b, want)
+
+	// the order of parameters in the set does not matter.
+	reordered := append(append([]byte{}, want[17:]...), want[:17]...)
+	got := &ansi41.QualificationRequestArg{}
+	if err := got.UnmarshalBinary(reordered); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "QualificationRequestArg", got, arg)
+
+	min, err := ansi41.DecodeMIN(got.MobileIdentificationNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "MIN", min, "1234567890")
+
+	if err := got.UnmarshalBinary(want[8:]); err == nil {
+		t.Error("expected error for missing ElectronicSerialNumber")
+	}
+}
+
+func TestDigits(t *testing.T) {
+	d := ansi41.NewDigits(ansi41.DigitsDialed, "12345")
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Digits", b, []byte{0x01, 0x01, 0x11, 0x05, 0x21, 0x43, 0xf5})
+
+	got, err := ansi41.ParseDigits(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Digits", got, d)
+}
+
+func TestNames(t *testing.T) {
+	verify.Values(t, "OperationName", ansi41.OperationName(ansi41.LocationRequest), "LocationRequest")
+	verify.Values(t, "ErrorName", ansi41.ErrorName(ansi41.UnrecognizedMIN), "UnrecognizedMIN")
+
+	if _, err := ansi41.NewArgument(0xff); err == nil {
+		t.Error("expected error for unsupported operation")
+	}
+}
+
+func TestLongParameter(t *testing.T) {
+	arg := &ansi41.SMSDeliveryPointToPointArg{SMSBearerData: make([]byte, 200), SMSTeleserviceIdentifier: 4098}
+	c, err := ansi41.NewInvoke(1, ansi41.SMSDeliveryPointToPoint, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := tcap.NewANSIQuery(0x11111111, true, c).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := tcap.ParseANSI(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ansi41.ParseArgument(msg.Components.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
@@ -0,0 +1,98 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi41
+
+// Access Denied Reason definitions.
+const (
+	AccessDeniedUnassignedDirectoryNumber int = 1
+	AccessDeniedInactive                  int = 2
+	AccessDeniedBusy                      int = 3
+	AccessDeniedTerminationDenied         int = 4
+	AccessDeniedNoPageResponse            int = 5
+	AccessDeniedUnavailable               int = 6
+)
+
+// LocationRequestArg represents the argument of LocationRequest.
+//
+// Digits is the Digits (Dialed) parameter, which can be generated with
+// MarshalBinary of Digits.
+type LocationRequestArg struct {
+	BillingID             []byte `ber:"tag:1"`
+	Digits                []byte `ber:"tag:4"`
+	MSCID                 []byte `ber:"tag:21"`
+	SystemMyTypeCode      int    `ber:"tag:22"`
+	TransactionCapability []byte `ber:"tag:123,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a LocationRequestArg.
+func (l *LocationRequestArg) MarshalBinary() ([]byte, error) {
+	return marshalSet(l)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a LocationRequestArg.
+func (l *LocationRequestArg) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, l)
+}
+
+// LocationRequestRes represents the result of LocationRequest.
+//
+// Digits is the Digits (Destination) parameter.
+type LocationRequestRes struct {
+	ElectronicSerialNumber     []byte `ber:"tag:137"`
+	MobileIdentificationNumber []byte `ber:"tag:136"`
+	MSCID                      []byte `ber:"tag:21"`
+	AccessDeniedReason         int    `ber:"tag:20,optional"`
+	Digits                     []byte `ber:"tag:4,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a LocationRequestRes.
+func (l *LocationRequestRes) MarshalBinary() ([]byte, error) {
+	return marshalSet(l)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a LocationRequestRes.
+func (l *LocationRequestRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, l)
+}
+
+// RoutingRequestArg represents the argument of RoutingRequest.
+type RoutingRequestArg struct {
+	BillingID                  []byte `ber:"tag:1"`
+	ElectronicSerialNumber     []byte `ber:"tag:137"`
+	MobileIdentificationNumber []byte `ber:"tag:136"`
+	MSCID                      []byte `ber:"tag:21"`
+	SystemMyTypeCode           int    `ber:"tag:22"`
+	MSCIdentificationNumber    []byte `ber:"tag:94,optional"`
+	PCSSN                      []byte `ber:"tag:32,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a RoutingRequestArg.
+func (r *RoutingRequestArg) MarshalBinary() ([]byte, error) {
+	return marshalSet(r)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a RoutingRequestArg.
+func (r *RoutingRequestArg) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, r)
+}
+
+// RoutingRequestRes represents the result of RoutingRequest.
+//
+// Digits is the Digits (Destination) parameter, which is the TLDN allocated.
+type RoutingRequestRes struct {
+	MSCID              []byte `ber:"tag:21"`
+	AccessDeniedReason int    `ber:"tag:20,optional"`
+	Digits             []byte `ber:"tag:4,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a RoutingRequestRes.
+func (r *RoutingRequestRes) MarshalBinary() ([]byte, error) {
+	return marshalSet(r)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a RoutingRequestRes.
+func (r *RoutingRequestRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, r)
+}
@@ -0,0 +1,82 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi41
+
+// QualificationRequestArg represents the argument of QualificationRequest.
+type QualificationRequestArg struct {
+	ElectronicSerialNumber       []byte `ber:"tag:137"`
+	MobileIdentificationNumber   []byte `ber:"tag:136"`
+	QualificationInformationCode int    `ber:"tag:17"`
+	SystemMyTypeCode             int    `ber:"tag:22"`
+	MSCID                        []byte `ber:"tag:21,optional"`
+	TransactionCapability        []byte `ber:"tag:123,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a QualificationRequestArg.
+func (q *QualificationRequestArg) MarshalBinary() ([]byte, error) {
+	return marshalSet(q)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a QualificationRequestArg.
+func (q *QualificationRequestArg) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, q)
+}
+
+// QualificationRequestRes represents the result of QualificationRequest.
+type QualificationRequestRes struct {
+	SystemMyTypeCode    int    `ber:"tag:22"`
+	AuthorizationDenied int    `ber:"tag:13,optional"`
+	AuthorizationPeriod []byte `ber:"tag:14,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a QualificationRequestRes.
+func (q *QualificationRequestRes) MarshalBinary() ([]byte, error) {
+	return marshalSet(q)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a QualificationRequestRes.
+func (q *QualificationRequestRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, q)
+}
+
+// RegistrationNotificationArg represents the argument of RegistrationNotification.
+type RegistrationNotificationArg struct {
+	ElectronicSerialNumber       []byte `ber:"tag:137"`
+	MobileIdentificationNumber   []byte `ber:"tag:136"`
+	MSCID                        []byte `ber:"tag:21"`
+	QualificationInformationCode int    `ber:"tag:17"`
+	SystemMyTypeCode             int    `ber:"tag:22"`
+	PCSSN                        []byte `ber:"tag:32,optional"`
+	LocationAreaID               []byte `ber:"tag:33,optional"`
+	SystemAccessType             int    `ber:"tag:34,optional"`
+	TransactionCapability        []byte `ber:"tag:123,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a RegistrationNotificationArg.
+func (r *RegistrationNotificationArg) MarshalBinary() ([]byte, error) {
+	return marshalSet(r)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a RegistrationNotificationArg.
+func (r *RegistrationNotificationArg) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, r)
+}
+
+// RegistrationNotificationRes represents the result of RegistrationNotification.
+type RegistrationNotificationRes struct {
+	SystemMyTypeCode    int    `ber:"tag:22"`
+	AuthorizationDenied int    `ber:"tag:13,optional"`
+	AuthorizationPeriod []byte `ber:"tag:14,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a RegistrationNotificationRes.
+func (r *RegistrationNotificationRes) MarshalBinary() ([]byte, error) {
+	return marshalSet(r)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a RegistrationNotificationRes.
+func (r *RegistrationNotificationRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, r)
+}
@@ -0,0 +1,56 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi41
+
+// SMS Teleservice Identifier definitions.
+const (
+	TeleserviceCMT91 int = 4096
+	TeleserviceCPT95 int = 4097
+	TeleserviceCMT95 int = 4098
+	TeleserviceVMN95 int = 4099
+	TeleserviceWAP   int = 4100
+	TeleserviceWEMT  int = 4101
+	TeleserviceSCPT  int = 4102
+	TeleserviceCATPT int = 4103
+)
+
+// SMSDeliveryPointToPointArg represents the argument of SMSDeliveryPointToPoint.
+//
+// The addresses are in the format of Digits parameter.
+type SMSDeliveryPointToPointArg struct {
+	SMSBearerData                 []byte `ber:"tag:105"`
+	SMSTeleserviceIdentifier      int    `ber:"tag:116"`
+	ElectronicSerialNumber        []byte `ber:"tag:137,optional"`
+	MobileIdentificationNumber    []byte `ber:"tag:136,optional"`
+	SMSChargeIndicator            int    `ber:"tag:106,optional"`
+	SMSOriginalDestinationAddress []byte `ber:"tag:110,optional"`
+	SMSOriginalOriginatingAddress []byte `ber:"tag:112,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a SMSDeliveryPointToPointArg.
+func (s *SMSDeliveryPointToPointArg) MarshalBinary() ([]byte, error) {
+	return marshalSet(s)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a SMSDeliveryPointToPointArg.
+func (s *SMSDeliveryPointToPointArg) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, s)
+}
+
+// SMSDeliveryPointToPointRes represents the result of SMSDeliveryPointToPoint.
+type SMSDeliveryPointToPointRes struct {
+	SMSBearerData []byte `ber:"tag:105,optional"`
+	SMSCauseCode  int    `ber:"tag:153,optional"`
+}
+
+// MarshalBinary returns the contents of Parameter Set generated from a SMSDeliveryPointToPointRes.
+func (s *SMSDeliveryPointToPointRes) MarshalBinary() ([]byte, error) {
+	return marshalSet(s)
+}
+
+// UnmarshalBinary sets the values retrieved from the contents of Parameter Set in a SMSDeliveryPointToPointRes.
+func (s *SMSDeliveryPointToPointRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSet(b, s)
+}
@@ -0,0 +1,201 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi41
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Type of Digits definitions.
+const (
+	DigitsDialed      uint8 = 1
+	DigitsCalling     uint8 = 2
+	DigitsRouting     uint8 = 4
+	DigitsBilling     uint8 = 5
+	DigitsDestination uint8 = 6
+	DigitsCarrier     uint8 = 8
+)
+
+// Nature of Number definitions, which are bit flags.
+const (
+	NatureNational               uint8 = 0
+	NatureInternational          uint8 = 1
+	NaturePresentationRestricted uint8 = 1 << 1
+)
+
+// Numbering Plan definitions.
+const (
+	NumberingPlanUnknown   uint8 = 0
+	NumberingPlanTelephony uint8 = 1
+	NumberingPlanData      uint8 = 3
+	NumberingPlanTelex     uint8 = 4
+	NumberingPlanPrivate   uint8 = 14
+)
+
+// Encoding of Digits definitions.
+const (
+	EncodingBCD uint8 = 1
+	EncodingIA5 uint8 = 2
+)
+
+// Qualification Information Code definitions.
+const (
+	QualificationNoInformation        uint8 = 1
+	QualificationValidationOnly       uint8 = 2
+	QualificationValidationAndProfile uint8 = 3
+	QualificationProfileOnly          uint8 = 4
+)
+
+// Digits represents the Digits parameter.
+type Digits struct {
+	Type           uint8
+	NatureOfNumber uint8
+	NumberingPlan  uint8
+	Encoding       uint8
+	Digits         string
+}
+
+// NewDigits creates a new Digits of the type given, in BCD encoding with the
+// telephony numbering plan.
+func NewDigits(typ uint8, digits string) *Digits {
+	return &Digits{
+		Type:           typ,
+		NatureOfNumber: NatureInternational,
+		NumberingPlan:  NumberingPlanTelephony,
+		Encoding:       EncodingBCD,
+		Digits:         digits,
+	}
+}
+
+// MarshalBinary returns the contents of Digits parameter.
+func (d *Digits) MarshalBinary() ([]byte, error) {
+	if len(d.Digits) > 0xff {
+		return nil, protocol.Invalid("Digits")
+	}
+
+	b := []byte{d.Type, d.NatureOfNumber, d.NumberingPlan<<4 | d.Encoding&0x0f, uint8(len(d.Digits))}
+	if d.Encoding == EncodingIA5 {
+		return append(b, d.Digits...), nil
+	}
+
+	v, err := encodeBCD(d.Digits)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, v...), nil
+}
+
+// ParseDigits decodes the contents of Digits parameter.
+func ParseDigits(b []byte) (*Digits, error) {
+	if len(b) < 4 {
+		return nil, protocol.Invalid("Digits")
+	}
+
+	d := &Digits{
+		Type:           b[0],
+		NatureOfNumber: b[1],
+		NumberingPlan:  b[2] >> 4,
+		Encoding:       b[2] & 0x0f,
+	}
+	n := int(b[3])
+	if d.Encoding == EncodingIA5 {
+		if len(b[4:]) < n {
+			return nil, protocol.Invalid("Digits")
+		}
+		d.Digits = string(b[4 : 4+n])
+		return d, nil
+	}
+
+	digits := decodeBCD(b[4:])
+	if len(digits) < n {
+		return nil, protocol.Invalid("Digits")
+	}
+	d.Digits = digits[:n]
+	return d, nil
+}
+
+// String returns Digits in human readable string.
+func (d *Digits) String() string {
+	return fmt.Sprintf("{Type: %d, NatureOfNumber: %d, NumberingPlan: %d, Encoding: %d, Digits: %s}",
+		d.Type, d.NatureOfNumber, d.NumberingPlan, d.Encoding, d.Digits,
+	)
+}
+
+// EncodeMIN returns the contents of MobileIdentificationNumber parameter from
+// the 10-digit MIN.
+func EncodeMIN(min string) ([]byte, error) {
+	if len(min) != 10 {
+		return nil, protocol.Invalid("MobileIdentificationNumber")
+	}
+	return encodeBCD(min)
+}
+
+// DecodeMIN returns the MIN from the contents of MobileIdentificationNumber parameter.
+func DecodeMIN(b []byte) (string, error) {
+	if len(b) != 5 {
+		return "", protocol.Invalid("MobileIdentificationNumber")
+	}
+	return decodeBCD(b), nil
+}
+
+// EncodeESN returns the contents of ElectronicSerialNumber parameter.
+func EncodeESN(esn uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, esn)
+	return b
+}
+
+// DecodeESN returns the ESN from the contents of ElectronicSerialNumber parameter.
+func DecodeESN(b []byte) (uint32, error) {
+	if len(b) != 4 {
+		return 0, protocol.Invalid("ElectronicSerialNumber")
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// EncodeMSCID returns the contents of MSCID parameter.
+func EncodeMSCID(marketID uint16, switchNumber uint8) []byte {
+	return []byte{uint8(marketID >> 8), uint8(marketID), switchNumber}
+}
+
+// DecodeMSCID returns the Market ID and Switch Number from the contents of MSCID parameter.
+func DecodeMSCID(b []byte) (marketID uint16, switchNumber uint8, err error) {
+	if len(b) != 3 {
+		return 0, 0, protocol.Invalid("MSCID")
+	}
+	return binary.BigEndian.Uint16(b), b[2], nil
+}
+
+// encodeBCD encodes the digits into BCD, the first digit in the lower nibble.
+// The filler 0xf is put in the last octet if the number of digits is odd.
+func encodeBCD(digits string) ([]byte, error) {
+	b := make([]byte, (len(digits)+1)/2)
+	for i, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, protocol.Invalid(fmt.Sprintf("digits %q", digits))
+		}
+		if i%2 == 0 {
+			b[i/2] = 0xf0 | uint8(c-'0')
+		} else {
+			b[i/2] = b[i/2]&0x0f | uint8(c-'0')<<4
+		}
+	}
+	return b, nil
+}
+
+func decodeBCD(b []byte) string {
+	var s strings.Builder
+	for _, x := range b {
+		for _, n := range []uint8{x & 0x0f, x >> 4} {
+			if n > 9 {
+				return s.String()
+			}
+			s.WriteByte('0' + n)
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,159 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"encoding/asn1"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+var ansiTestcases = []struct {
+	description string
+	structured  serializable
+	serialized  []byte
+	parseFunc   func(b []byte) (serializable, error)
+}{
+	{
+		description: "ANSI/QueryWithPermission - InvokeLast",
+		structured: tcap.NewANSIQuery(0x11111111, true,
+			tcap.NewANSIInvoke(1, -1, 0x09|tcap.ANSIReplyRequired, 0x0d, false, true, []byte{0x84, 0x01, 0x05}),
+		),
+		serialized: []byte{
+			0xe2, 0x16, 0xc7, 0x04, 0x11, 0x11, 0x11, 0x11, 0xe8, 0x0e, 0xe9, 0x0c, 0xcf, 0x01, 0x01, 0xd1,
+			0x02, 0x89, 0x0d, 0xf2, 0x03, 0x84, 0x01, 0x05,
+		},
+		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseANSI(b) },
+	}, {
+		description: "ANSI/Response - ReturnResultLast",
+		structured: tcap.NewANSIResponse(0x22222222,
+			tcap.NewANSIReturnResult(1, true, []byte{0x84, 0x01, 0x05}),
+		),
+		serialized: []byte{
+			0xe4, 0x12, 0xc7, 0x04, 0x22, 0x22, 0x22, 0x22, 0xe8, 0x0a, 0xea, 0x08, 0xcf, 0x01, 0x01, 0xf2,
+			0x03, 0x84, 0x01, 0x05,
+		},
+		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseANSI(b) },
+	}, {
+		description: "ANSI/ConversationWithPermission - ReturnError",
+		structured: tcap.NewANSIConversation(0x11111111, 0x22222222, true,
+			tcap.NewANSIReturnError(1, 0x81, false, nil),
+		),
+		serialized: []byte{
+			0xe5, 0x16, 0xc7, 0x08, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0xe8, 0x0a, 0xeb, 0x08,
+			0xcf, 0x01, 0x01, 0xd4, 0x01, 0x81, 0xf2, 0x00,
+		},
+		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseANSI(b) },
+	}, {
+		description: "ANSI/Unidirectional - Reject",
+		structured: tcap.NewANSIUnidirectional(
+			tcap.NewANSIReject(1, tcap.ANSIInvokeProblem, tcap.ANSIInvokeProblemUnrecognizedOperationCode, nil),
+		),
+		serialized: []byte{
+			0xe1, 0x0f, 0xc7, 0x00, 0xe8, 0x0b, 0xec, 0x09, 0xcf, 0x01, 0x01, 0xd5, 0x02, 0x02, 0x02, 0xf2,
+			0x00,
+		},
+		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseANSI(b) },
+	}, {
+		description: "ANSI/Abort",
+		structured:  tcap.NewANSIAbort(0x22222222, tcap.ANSIResourceUnavailable),
+		serialized:  []byte{0xf6, 0x09, 0xc7, 0x04, 0x22, 0x22, 0x22, 0x22, 0xd7, 0x01, 0x06},
+		parseFunc:   func(b []byte) (serializable, error) { return tcap.ParseANSI(b) },
+	}, {
+		description: "ANSIComponent/InvokeNotLast with Correlation ID",
+		structured:  tcap.NewANSIInvoke(2, 1, tcap.ANSIFamilyCallerInteraction, 0x01, true, false, []byte{0x84, 0x01, 0x05}),
+		serialized: []byte{
+			0xed, 0x0d, 0xcf, 0x02, 0x02, 0x01, 0xd0, 0x02, 0x05, 0x01, 0xf2, 0x03, 0x84, 0x01, 0x05,
+		},
+		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseANSIComponent(b) },
+	},
+}
+
+func TestANSICodec(t *testing.T) {
+	for _, c := range ansiTestcases {
+		t.Run("Parse / "+c.description, func(t *testing.T) {
+			msg, err := c.parseFunc(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", msg, c.structured)
+		})
+
+		t.Run("Marshal / "+c.description, func(t *testing.T) {
+			b, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", b, c.serialized)
+		})
+
+		t.Run("Len / "+c.description, func(t *testing.T) {
+			if got, want := c.structured.MarshalLen(), len(c.serialized); got != want {
+				t.Fatalf("got %v want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestANSIAccessors(t *testing.T) {
+	acn := asn1.ObjectIdentifier{1, 2, 840, 10013, 1, 1}
+	q := tcap.NewANSIConversation(0x11111111, 0x22222222, false,
+		tcap.NewANSIInvoke(2, 1, tcap.ANSIFamilyCallerInteraction, 0x01, true, false, nil),
+	)
+	q.Dialogue = tcap.NewANSIDialogue(acn)
+	q.SetLength()
+
+	b, err := q.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := tcap.ParseWithVariant(b, tcap.VariantAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, ok := m.(*tcap.ANSITCAP)
+	if !ok {
+		t.Fatalf("unexpected type: %T", m)
+	}
+
+	comp := parsed.Components.Component[0]
+	corrID, _ := comp.CorrelationID()
+	verify.Values(t, "accessors", []interface{}{
+		parsed.PackageTypeString(), parsed.OTID(), parsed.RTID(), parsed.ApplicationContextOID().String(),
+		comp.ComponentTypeString(), comp.IsLast(), comp.IsNational(), comp.InvID(), corrID, comp.OpCode(),
+	}, []interface{}{
+		"conversationWithoutPermission", uint32(0x11111111), uint32(0x22222222), acn.String(),
+		"invokeNotLast", false, true, uint8(2), uint8(1), uint16(0x0501),
+	})
+}
+
+func TestDetectVariant(t *testing.T) {
+	cases := []struct {
+		description string
+		b           []byte
+		variant     int
+	}{
+		{"ITU Begin", []byte{0x62, 0x00}, tcap.VariantITU},
+		{"ITU Abort", []byte{0x67, 0x00}, tcap.VariantITU},
+		{"ANSI Query", []byte{0xe2, 0x00}, tcap.VariantANSI},
+		{"ANSI Abort", []byte{0xf6, 0x00}, tcap.VariantANSI},
+		{"Unknown", []byte{0x30, 0x00}, tcap.VariantAuto},
+		{"Empty", nil, tcap.VariantAuto},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got := tcap.DetectVariant(c.b); got != c.variant {
+				t.Errorf("got %d, want %d", got, c.variant)
+			}
+		})
+	}
+
+	if _, err := tcap.ParseWithVariant([]byte{0x30, 0x00}, tcap.VariantAuto); err == nil {
+		t.Error("expected error for unknown variant")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"sync"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// mapContextPrefix is the first part of ApplicationContextName of MAP, which is
+// {itu-t(0) identified-organization(4) etsi(0) mobileDomain(0) gsm-Network(1) ac-Id(0)}.
+var mapContextPrefix = []byte{0x06, 0x07, 0x04, 0x00, 0x00, 0x01, 0x00}
+
+var (
+	appContextMu    sync.RWMutex
+	appContextNames = map[string]string{}
+	appContextOIDs  = map[string]asn1.ObjectIdentifier{}
+)
+
+// RegisterApplicationContext registers the name of application context identified by the OID.
+//
+// The registered name is returned by Context() of Dialogue and DialoguePDU, which
+// is useful for the application contexts other than MAP, such as CAP or INAP.
+// The packages for such protocols register their application contexts on init.
+func RegisterApplicationContext(oid asn1.ObjectIdentifier, name string) {
+	appContextMu.Lock()
+	defer appContextMu.Unlock()
+
+	appContextNames[oid.String()] = name
+	appContextOIDs[name] = append(asn1.ObjectIdentifier(nil), oid...)
+}
+
+// LookupApplicationContext returns the name of application context registered
+// with RegisterApplicationContext. It returns empty string if not registered.
+func LookupApplicationContext(oid asn1.ObjectIdentifier) string {
+	appContextMu.RLock()
+	defer appContextMu.RUnlock()
+
+	return appContextNames[oid.String()]
+}
+
+// LookupApplicationContextByName returns the OID of application context registered
+// with RegisterApplicationContext by the name. It returns nil if not registered.
+func LookupApplicationContextByName(name string) asn1.ObjectIdentifier {
+	appContextMu.RLock()
+	defer appContextMu.RUnlock()
+
+	return appContextOIDs[name]
+}
+
+// NewApplicationContextNameOID creates a new ApplicationContextName as an IE from arbitrary OID.
+//
+// It returns nil if the OID is invalid.
+func NewApplicationContextNameOID(oid asn1.ObjectIdentifier) *IE {
+	v, err := ber.EncodeObjectIdentifier(oid)
+	if err != nil {
+		DefaultLogger().Warn("failed to encode ApplicationContextName", "acn", oid.String(), "error", err)
+		return nil
+	}
+
+	i := &IE{
+		Tag:   NewContextSpecificConstructorTag(1),
+		Value: append([]byte{0x06, uint8(len(v))}, v...),
+	}
+	i.SetLength()
+	return i
+}
+
+// NewAARQWithOID returns a new AARQ(Dialogue Request) with arbitrary ApplicationContextName.
+func NewAARQWithOID(protover int, acn asn1.ObjectIdentifier, userinfo ...*IE) *DialoguePDU {
+	d := NewAARQ(protover, 0, 0, userinfo...)
+	d.ApplicationContextName = NewApplicationContextNameOID(acn)
+	d.SetLength()
+	return d
+}
+
+// NewAAREWithOID returns a new AARE(Dialogue Response) with arbitrary ApplicationContextName.
+func NewAAREWithOID(protover int, acn asn1.ObjectIdentifier, result uint8, diagsrc int, reason uint8, userinfo ...*IE) *DialoguePDU {
+	d := NewAARE(protover, 0, 0, result, diagsrc, reason, userinfo...)
+	d.ApplicationContextName = NewApplicationContextNameOID(acn)
+	d.SetLength()
+	return d
+}
+
+// ApplicationContextOID returns the ApplicationContextName as an OID.
+//
+// It returns nil if the DialoguePDU does not have a valid ApplicationContextName.
+func (d *DialoguePDU) ApplicationContextOID() asn1.ObjectIdentifier {
+	appCtx := d.ApplicationContextName
+	if appCtx == nil {
+		return nil
+	}
+
+	e, err := ber.ParseElement(appCtx.Value)
+	if err != nil || !e.Is(ber.Universal, ber.TagObjectIdentifier) {
+		return nil
+	}
+	oid, err := ber.DecodeObjectIdentifier(e.Value)
+	if err != nil {
+		return nil
+	}
+	return oid
+}
+
+// ApplicationContextOID returns the ApplicationContextName in DialoguePDU as an OID.
+func (d *Dialogue) ApplicationContextOID() asn1.ObjectIdentifier {
+	if d.DialoguePDU == nil {
+		return nil
+	}
+
+	return d.DialoguePDU.ApplicationContextOID()
+}
+
+// isMAPContext reports whether the ApplicationContextName is in the form of MAP.
+func isMAPContext(appCtx *IE) bool {
+	return len(appCtx.Value) == 9 && bytes.HasPrefix(appCtx.Value, mapContextPrefix)
+}
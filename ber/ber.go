@@ -0,0 +1,284 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package ber provides the minimal set of BER (Basic Encoding Rules) codec used by
+TCAP user protocols such as MAP, CAP and INAP.
+
+Unlike the IE in the tcap package, it supports the tags with the number larger
+than 30 and the lengths in indefinite form.
+*/
+package ber
+
+import (
+	"fmt"
+	"io"
+)
+
+// Class definitions.
+const (
+	Universal int = iota
+	Application
+	ContextSpecific
+	Private
+)
+
+// Universal Tag Number definitions.
+const (
+	TagBoolean          = 1
+	TagInteger          = 2
+	TagBitString        = 3
+	TagOctetString      = 4
+	TagNull             = 5
+	TagObjectIdentifier = 6
+	TagEnumerated       = 10
+	TagSequence         = 16
+	TagSet              = 17
+)
+
+// Element is a single BER encoded TLV (Tag, Length and Value).
+type Element struct {
+	Class       int
+	Constructed bool
+	Tag         int
+	Value       []byte
+}
+
+// NewElement creates a new Element.
+func NewElement(class int, constructed bool, tag int, value []byte) *Element {
+	return &Element{
+		Class:       class,
+		Constructed: constructed,
+		Tag:         tag,
+		Value:       value,
+	}
+}
+
+// Is reports whether the Element has the given class and tag number.
+func (e *Element) Is(class, tag int) bool {
+	return e.Class == class && e.Tag == tag
+}
+
+// MarshalBinary returns the byte sequence generated from an Element.
+func (e *Element) MarshalBinary() ([]byte, error) {
+	return e.AppendTo(make([]byte, 0, e.MarshalLen())), nil
+}
+
+// AppendTo appends the byte sequence of the Element to b.
+func (e *Element) AppendTo(b []byte) []byte {
+	return Append(b, e.Class, e.Constructed, e.Tag, e.Value)
+}
+
+// MarshalLen returns the serial length of Element.
+func (e *Element) MarshalLen() int {
+	return HeaderLen(e.Tag, len(e.Value)) + len(e.Value)
+}
+
+// Children parses the Value of constructed Element as a list of Elements.
+func (e *Element) Children() ([]*Element, error) {
+	if !e.Constructed {
+		return nil, fmt.Errorf("ber: element with tag %d is not constructed", e.Tag)
+	}
+	return ParseElements(e.Value)
+}
+
+// String returns Element in human readable string.
+func (e *Element) String() string {
+	return fmt.Sprintf("{Class: %d, Constructed: %v, Tag: %d, Value: %x}",
+		e.Class,
+		e.Constructed,
+		e.Tag,
+		e.Value,
+	)
+}
+
+// Append appends a TLV with the given class, form, tag and value to b.
+func Append(b []byte, class int, constructed bool, tag int, value []byte) []byte {
+	b = AppendHeader(b, class, constructed, tag, len(value))
+	return append(b, value...)
+}
+
+// AppendHeader appends the identifier and length octets to b.
+//
+// The length is always encoded in the definite form, using the short form
+// when it is possible.
+func AppendHeader(b []byte, class int, constructed bool, tag, length int) []byte {
+	first := uint8(class << 6)
+	if constructed {
+		first |= 0x20
+	}
+
+	if tag < 31 {
+		b = append(b, first|uint8(tag))
+	} else {
+		b = append(b, first|0x1f)
+		for i := base128Len(tag) - 1; i >= 0; i-- {
+			o := uint8(tag>>(7*i)) & 0x7f
+			if i != 0 {
+				o |= 0x80
+			}
+			b = append(b, o)
+		}
+	}
+
+	if length < 0x80 {
+		return append(b, uint8(length))
+	}
+
+	n := 0
+	for l := length; l > 0; l >>= 8 {
+		n++
+	}
+	b = append(b, 0x80|uint8(n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, uint8(length>>(8*i)))
+	}
+	return b
+}
+
+// HeaderLen returns the length of identifier and length octets for the given tag number and length.
+func HeaderLen(tag, length int) int {
+	l := 2
+	if tag >= 31 {
+		l += base128Len(tag)
+	}
+	if length >= 0x80 {
+		for ; length > 0; length >>= 8 {
+			l++
+		}
+	}
+	return l
+}
+
+// Parse parses the first TLV in b and returns it with the number of bytes consumed.
+//
+// The Value of the returned Element refers to the same underlying array as b.
+// For the Element in indefinite form, the Value excludes the end-of-contents octets.
+func Parse(b []byte) (*Element, int, error) {
+	e, n, err := Next(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &e, n, nil
+}
+
+// Next is the same as Parse, but returns the Element by value so that walking
+// through the TLVs does not allocate.
+func Next(b []byte) (Element, int, error) {
+	if len(b) < 2 {
+		return Element{}, 0, io.ErrUnexpectedEOF
+	}
+
+	e := Element{
+		Class:       int(b[0] >> 6),
+		Constructed: b[0]&0x20 != 0,
+		Tag:         int(b[0] & 0x1f),
+	}
+
+	offset := 1
+	if e.Tag == 0x1f {
+		e.Tag = 0
+		for {
+			if offset >= len(b) {
+				return Element{}, 0, io.ErrUnexpectedEOF
+			}
+			if offset > 4 {
+				return Element{}, 0, fmt.Errorf("ber: tag number too large")
+			}
+			o := b[offset]
+			offset++
+			e.Tag = e.Tag<<7 | int(o&0x7f)
+			if o&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	if offset >= len(b) {
+		return Element{}, 0, io.ErrUnexpectedEOF
+	}
+	l := int(b[offset])
+	offset++
+
+	switch {
+	case l == 0x80:
+		if !e.Constructed {
+			return Element{}, 0, fmt.Errorf("ber: indefinite length in primitive element with tag %d", e.Tag)
+		}
+		end, err := findEOC(b[offset:])
+		if err != nil {
+			return Element{}, 0, err
+		}
+		e.Value = b[offset : offset+end]
+		return e, offset + end + 2, nil
+	case l > 0x80:
+		n := l & 0x7f
+		if n > 4 {
+			return Element{}, 0, fmt.Errorf("ber: length too large")
+		}
+		if offset+n > len(b) {
+			return Element{}, 0, io.ErrUnexpectedEOF
+		}
+		l = 0
+		for _, o := range b[offset : offset+n] {
+			l = l<<8 | int(o)
+		}
+		offset += n
+	}
+
+	if offset+l > len(b) || l < 0 {
+		return Element{}, 0, io.ErrUnexpectedEOF
+	}
+	e.Value = b[offset : offset+l]
+	return e, offset + l, nil
+}
+
+// ParseElement parses the first TLV in b as an Element.
+func ParseElement(b []byte) (*Element, error) {
+	e, _, err := Parse(b)
+	return e, err
+}
+
+// ParseElements parses all the TLVs in b.
+func ParseElements(b []byte) ([]*Element, error) {
+	var elems []*Element
+	for len(b) > 0 {
+		e, n, err := Parse(b)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+		b = b[n:]
+	}
+	return elems, nil
+}
+
+// findEOC returns the offset of end-of-contents octets that terminates the contents in b.
+func findEOC(b []byte) (int, error) {
+	offset := 0
+	for {
+		if len(b[offset:]) < 2 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if b[offset] == 0 && b[offset+1] == 0 {
+			return offset, nil
+		}
+		_, n, err := Parse(b[offset:])
+		if err != nil {
+			return 0, err
+		}
+		offset += n
+	}
+}
+
+func base128Len(v int) int {
+	if v == 0 {
+		return 1
+	}
+	l := 0
+	for ; v > 0; v >>= 7 {
+		l++
+	}
+	return l
+}
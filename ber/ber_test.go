@@ -0,0 +1,212 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber_test
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/json"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+var testcases = []struct {
+	description string
+	structured  *ber.Element
+	serialized  []byte
+}{
+	{
+		description: "Universal/Primitive",
+		structured:  ber.NewElement(ber.Universal, false, ber.TagOctetString, []byte{0xde, 0xad}),
+		serialized:  []byte{0x04, 0x02, 0xde, 0xad},
+	}, {
+		description: "ContextSpecific/Constructed/LongTag",
+		structured:  ber.NewElement(ber.ContextSpecific, true, 50, []byte{0x05, 0x00}),
+		serialized:  []byte{0xbf, 0x32, 0x02, 0x05, 0x00},
+	}, {
+		description: "ContextSpecific/Primitive/LongerTag",
+		structured:  ber.NewElement(ber.ContextSpecific, false, 200, []byte{0x01}),
+		serialized:  []byte{0x9f, 0x81, 0x48, 0x01, 0x01},
+	}, {
+		description: "Universal/Primitive/LongLength",
+		structured:  ber.NewElement(ber.Universal, false, ber.TagOctetString, bytes.Repeat([]byte{0x01}, 200)),
+		serialized:  append([]byte{0x04, 0x81, 0xc8}, bytes.Repeat([]byte{0x01}, 200)...),
+	},
+}
+
+func TestElement(t *testing.T) {
+	for _, c := range testcases {
+		t.Run("Parse / "+c.description, func(t *testing.T) {
+			got, n, err := ber.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(c.serialized) {
+				t.Errorf("unexpected length consumed: %d", n)
+			}
+			verify.Values(t, "", got, c.structured)
+		})
+
+		t.Run("Marshal / "+c.description, func(t *testing.T) {
+			got, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", got, c.serialized)
+		})
+
+		t.Run("Len / "+c.description, func(t *testing.T) {
+			if got := c.structured.MarshalLen(); got != len(c.serialized) {
+				t.Errorf("unexpected length: got %d, want %d", got, len(c.serialized))
+			}
+		})
+	}
+}
+
+func TestParseIndefinite(t *testing.T) {
+	got, n, err := ber.Parse([]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x30, 0x80, 0x00, 0x00, 0x00, 0x00, 0xff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 11 {
+		t.Errorf("unexpected length consumed: %d", n)
+	}
+	verify.Values(t, "", got.Value, []byte{0x02, 0x01, 0x05, 0x30, 0x80, 0x00, 0x00})
+}
+
+func TestPrimitives(t *testing.T) {
+	for _, v := range []int64{0, 127, 128, -1, -128, -129, 256, 1 << 40} {
+		got, err := ber.DecodeInteger(ber.EncodeInteger(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v {
+			t.Errorf("integer: got %d, want %d", got, v)
+		}
+	}
+
+	oid := asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 4}
+	b, err := ber.EncodeObjectIdentifier(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", b, []byte{0x04, 0x00, 0x00, 0x01, 0x15, 0x03, 0x04})
+
+	got, err := ber.DecodeObjectIdentifier([]byte{0x00, 0x11, 0x86, 0x05, 0x01, 0x01, 0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, asn1.ObjectIdentifier{0, 0, 17, 773, 1, 1, 1})
+}
+
+func TestValue(t *testing.T) {
+	bits, err := ber.NewBitString([]byte{0xa0}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := ber.NewSequence(
+		ber.NewInteger(-129),
+		ber.NewBoolean(true),
+		ber.Implicit(2, ber.NewEnumerated(3)),
+		ber.Explicit(3, ber.NewOctetString([]byte{0xde, 0xad})),
+		ber.NewSet(bits, ber.NewNull()),
+	)
+	serialized := []byte{
+		0x30, 0x18, 0x02, 0x02, 0xff, 0x7f, 0x01, 0x01, 0xff, 0x82, 0x01, 0x03, 0xa3, 0x04, 0x04, 0x02,
+		0xde, 0xad, 0x31, 0x06, 0x03, 0x02, 0x05, 0xa0, 0x05, 0x00,
+	}
+
+	b, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", b, serialized)
+
+	got, err := ber.ParseValue(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, v)
+
+	i, err := got.Child(ber.Universal, ber.TagInteger).Int()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", i, int64(-129))
+
+	e, err := got.Child(ber.ContextSpecific, 2).Int()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", e, int64(3))
+
+	inner, err := got.Child(ber.ContextSpecific, 3).Unwrap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := inner.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", o, []byte{0xde, 0xad})
+
+	bs, unused, err := got.Elements[4].Elements[0].BitString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", bs, []byte{0xa0})
+	verify.Values(t, "", unused, 5)
+
+	if !got.Elements[4].Elements[1].IsNull() {
+		t.Error("expected NULL")
+	}
+	if _, err := got.Int(); err == nil {
+		t.Error("expected error for constructed value")
+	}
+
+	got.Elements[0].SetInt(5)
+	verify.Values(t, "", got.Elements[0].Contents, []byte{0x05})
+}
+
+func TestValueJSON(t *testing.T) {
+	v := ber.NewSequence(
+		ber.NewInteger(1),
+		ber.Implicit(31, ber.NewOctetString([]byte{0xde, 0xad})),
+		ber.NewConstructed(ber.Private, 18),
+	)
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "JSON", string(b), `{"class":"universal","tag":16,"constructed":true,"elements":[`+
+		`{"class":"universal","tag":2,"value":"01"},`+
+		`{"class":"context","tag":31,"value":"dead"},`+
+		`{"class":"private","tag":18,"constructed":true}]}`)
+
+	got := &ber.Value{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Value", got, v)
+
+	// constructed value with its contents in hex.
+	if err := json.Unmarshal([]byte(`{"class":"application","tag":1,"constructed":true,"value":"020101"}`), got); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Value from hex", got, ber.NewConstructed(ber.Application, 1, ber.NewInteger(1)))
+
+	for _, s := range []string{
+		`{"class":"public","tag":1}`,
+		`{"class":"universal","tag":4,"value":"zz"}`,
+		`{"class":"universal","tag":4,"elements":[{"class":"universal","tag":5}]}`,
+	} {
+		if err := json.Unmarshal([]byte(s), got); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
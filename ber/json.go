@@ -0,0 +1,119 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+var classNames = [...]string{"universal", "application", "context", "private"}
+
+// JSONValue is the JSON representation of a Value.
+//
+// Primitive Value has its contents octets in hex in Value, and constructed
+// Value has its components in Elements.
+type JSONValue struct {
+	Class       string       `json:"class"`
+	Tag         int          `json:"tag"`
+	Constructed bool         `json:"constructed,omitempty"`
+	Value       string       `json:"value,omitempty"`
+	Elements    []*JSONValue `json:"elements,omitempty"`
+}
+
+// ClassName returns the name of class used in JSON, such as "universal" or "context".
+func ClassName(class int) string {
+	return classNames[class&0x3]
+}
+
+// ParseClassName returns the class from the name returned by ClassName.
+func ParseClassName(name string) (int, error) {
+	for i, n := range classNames {
+		if n == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("ber: unknown class: %q", name)
+}
+
+// JSON returns the JSON representation of Value.
+func (v *Value) JSON() *JSONValue {
+	j := &JSONValue{Class: ClassName(v.Class), Tag: v.Tag, Constructed: v.Constructed}
+	if !v.Constructed {
+		j.Value = hex.EncodeToString(v.Contents)
+		return j
+	}
+
+	j.Elements = make([]*JSONValue, len(v.Elements))
+	for i, e := range v.Elements {
+		j.Elements[i] = e.JSON()
+	}
+	return j
+}
+
+// BERValue returns the Value represented by JSONValue.
+//
+// Constructed JSONValue may have the contents octets in hex in Value instead
+// of Elements, which are parsed as the components.
+func (j *JSONValue) BERValue() (*Value, error) {
+	class, err := ParseClassName(j.Class)
+	if err != nil {
+		return nil, err
+	}
+	if j.Tag < 0 {
+		return nil, fmt.Errorf("ber: invalid tag number: %d", j.Tag)
+	}
+
+	contents, err := hex.DecodeString(j.Value)
+	if err != nil {
+		return nil, fmt.Errorf("ber: invalid contents: %w", err)
+	}
+	if !j.Constructed {
+		if len(j.Elements) > 0 {
+			return nil, fmt.Errorf("ber: primitive value with elements: %s", NewPrimitive(class, j.Tag, nil).TagString())
+		}
+		return NewPrimitive(class, j.Tag, contents), nil
+	}
+
+	if len(contents) > 0 {
+		if len(j.Elements) > 0 {
+			return nil, fmt.Errorf("ber: constructed value with both value and elements: %s", NewPrimitive(class, j.Tag, nil).TagString())
+		}
+		elems, err := ParseValues(contents)
+		if err != nil {
+			return nil, err
+		}
+		return NewConstructed(class, j.Tag, elems...), nil
+	}
+
+	elems := make([]*Value, len(j.Elements))
+	for i, e := range j.Elements {
+		if elems[i], err = e.BERValue(); err != nil {
+			return nil, err
+		}
+	}
+	return NewConstructed(class, j.Tag, elems...), nil
+}
+
+// MarshalJSON returns the JSON representation of Value.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.JSON())
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in Value.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	j := &JSONValue{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	val, err := j.BERValue()
+	if err != nil {
+		return err
+	}
+	*v = *val
+	return nil
+}
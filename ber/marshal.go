@@ -0,0 +1,671 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Marshal returns the contents octets of SEQUENCE encoded from the struct v.
+//
+// The result can be passed to tcap.NewInvoke, tcap.NewReturnResult and tcap.NewReturnError
+// as param, which wrap it with the SEQUENCE tag.
+//
+// The fields of the struct are encoded in order with the following types:
+//
+//	bool                          BOOLEAN, or NULL with "null" option
+//	int, uint and their variants  INTEGER, or ENUMERATED with "enum" option
+//	[]byte, [N]byte, string       OCTET STRING
+//	asn1.ObjectIdentifier         OBJECT IDENTIFIER
+//	asn1.BitString                BIT STRING
+//	Null                          NULL
+//	struct                        SEQUENCE, or SET with "set" option
+//	slice                         SEQUENCE OF, or SET OF with "set" option
+//	interface                     CHOICE registered with RegisterChoice
+//	*Value                        any value as it is
+//
+// The encoding of each field can be controlled with the struct tag with the key "ber",
+// which is a comma-separated list of the following options:
+//
+//	tag:N        tag the field with the context-specific tag number N
+//	application  use the application class for the tag instead
+//	private      use the private class for the tag instead
+//	universal    use the universal class for the tag instead
+//	implicit     tag in IMPLICIT mode (default)
+//	explicit     tag in EXPLICIT mode
+//	optional     omit the field when it has zero value (or nil)
+//	set          encode the struct or slice as SET or SET OF
+//	enum         encode the integer as ENUMERATED
+//	null         encode the bool as NULL, which is present only when true
+//
+// The fields with the tag "-" and the unexported fields are ignored. An unsigned
+// integer above math.MaxInt64 is an error, as INTEGER is decoded into int64.
+func Marshal(v interface{}) ([]byte, error) {
+	if !isStruct(reflect.TypeOf(v)) {
+		return nil, fmt.Errorf("ber: Marshal requires struct, got %T", v)
+	}
+
+	val, err := MarshalValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return val.ContentsBytes(), nil
+}
+
+// Unmarshal decodes the contents octets of SEQUENCE into the struct pointed by v.
+//
+// b is typically the Parameter of tcap.Component without its tag and length, such as
+// the one returned by LayerPayload of tcap.TCAP. See Marshal for the rules of decoding.
+//
+// The []byte fields in v refer to the same underlying array as b.
+func Unmarshal(b []byte, v interface{}) error {
+	elems, err := ParseValues(b)
+	if err != nil {
+		return err
+	}
+	return UnmarshalValue(NewSequence(elems...), v)
+}
+
+// MarshalValue returns the Value encoded from v.
+//
+// Unlike Marshal, v can be any type supported and the result has its tag.
+// To encode a CHOICE, give a pointer to the interface.
+func MarshalValue(v interface{}) (*Value, error) {
+	return MarshalValueWithParams(v, "")
+}
+
+// MarshalValueWithParams is the same as MarshalValue, but the options in the
+// same format as the struct tag are applied to the top-level value.
+func MarshalValueWithParams(v interface{}, params string) (*Value, error) {
+	p, err := parseFieldParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return marshalValue(reflect.ValueOf(v), p)
+}
+
+// UnmarshalValue decodes val into the value pointed by v.
+func UnmarshalValue(val *Value, v interface{}) error {
+	return UnmarshalValueWithParams(val, v, "")
+}
+
+// UnmarshalValueWithParams is the same as UnmarshalValue, but the options in the
+// same format as the struct tag are applied to the top-level value.
+func UnmarshalValueWithParams(val *Value, v interface{}, params string) error {
+	p, err := parseFieldParams(params)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("ber: UnmarshalValue requires non-nil pointer, got %T", v)
+	}
+	return unmarshalValue(val, rv.Elem(), p)
+}
+
+// Null represents NULL.
+//
+// Only Null and the types defined on it are encoded as NULL, so that the struct
+// without any field is encoded as an empty SEQUENCE.
+type Null struct {
+	_ [0]nullMarker
+}
+
+type nullMarker struct{}
+
+// Alternative is an alternative of CHOICE registered with RegisterChoice.
+//
+// Type is a value of the concrete type which implements the interface of CHOICE.
+// The alternative is tagged with Class and Tag in IMPLICIT mode, or EXPLICIT
+// mode if Explicit is true.
+type Alternative struct {
+	Class    int
+	Tag      int
+	Explicit bool
+	Type     interface{}
+}
+
+var (
+	choiceMu sync.RWMutex
+	choices  = map[reflect.Type][]Alternative{}
+)
+
+// RegisterChoice registers the alternatives of CHOICE represented by an interface.
+//
+// iface must be a nil pointer to the interface, e.g., (*LegID)(nil).
+func RegisterChoice(iface interface{}, alts ...Alternative) {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("ber: RegisterChoice requires pointer to interface, got %T", iface))
+	}
+
+	choiceMu.Lock()
+	defer choiceMu.Unlock()
+	choices[t.Elem()] = alts
+}
+
+func lookupChoice(t reflect.Type) []Alternative {
+	choiceMu.RLock()
+	defer choiceMu.RUnlock()
+	return choices[t]
+}
+
+type fieldParams struct {
+	class    int
+	tag      int
+	tagged   bool
+	explicit bool
+	optional bool
+	set      bool
+	enum     bool
+	null     bool
+}
+
+func parseFieldParams(s string) (fieldParams, error) {
+	p := fieldParams{class: ContextSpecific}
+	for _, opt := range strings.Split(s, ",") {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "":
+		case strings.HasPrefix(opt, "tag:"):
+			n, err := strconv.Atoi(opt[4:])
+			if err != nil || n < 0 {
+				return p, fmt.Errorf("ber: invalid tag in struct tag: %q", opt)
+			}
+			p.tag, p.tagged = n, true
+		case opt == "application":
+			p.class = Application
+		case opt == "private":
+			p.class = Private
+		case opt == "universal":
+			p.class = Universal
+		case opt == "implicit":
+			p.explicit = false
+		case opt == "explicit":
+			p.explicit = true
+		case opt == "optional":
+			p.optional = true
+		case opt == "set":
+			p.set = true
+		case opt == "enum":
+			p.enum = true
+		case opt == "null":
+			p.null, p.optional = true, true
+		default:
+			return p, fmt.Errorf("ber: unknown option in struct tag: %q", opt)
+		}
+	}
+	return p, nil
+}
+
+var (
+	valueType     = reflect.TypeOf(&Value{})
+	oidType       = reflect.TypeOf(asn1.ObjectIdentifier{})
+	bitStringType = reflect.TypeOf(asn1.BitString{})
+	nullType      = reflect.TypeOf(Null{})
+)
+
+// builtinType returns oidType, bitStringType or nullType if t is defined on
+// them, so that the concrete types of CHOICE can be declared as such.
+func builtinType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Name() != "" && t.ConvertibleTo(oidType) {
+			return oidType
+		}
+	case reflect.Struct:
+		if t.ConvertibleTo(nullType) {
+			return nullType
+		}
+		if t.ConvertibleTo(bitStringType) {
+			return bitStringType
+		}
+	}
+	return nil
+}
+
+func isStruct(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+func applyTag(v *Value, p fieldParams) *Value {
+	if !p.tagged {
+		return v
+	}
+	if p.explicit {
+		return NewConstructed(p.class, p.tag, v)
+	}
+
+	x := *v
+	x.Class, x.Tag = p.class, p.tag
+	return &x
+}
+
+func marshalValue(rv reflect.Value, p fieldParams) (*Value, error) {
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("ber: cannot marshal nil")
+	}
+
+	if rv.Type() == valueType {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("ber: cannot marshal nil *Value")
+		}
+		return applyTag(rv.Interface().(*Value), p), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, fmt.Errorf("ber: cannot marshal nil %s", rv.Type())
+		}
+		return marshalValue(rv.Elem(), p)
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil, fmt.Errorf("ber: cannot marshal nil %s", rv.Type())
+		}
+		v, err := marshalChoice(rv, rv.Type())
+		if err != nil {
+			return nil, err
+		}
+		// tagged CHOICE is always in EXPLICIT mode.
+		p.explicit = true
+		return applyTag(v, p), nil
+	}
+
+	v, err := marshalUntagged(rv, p)
+	if err != nil {
+		return nil, err
+	}
+	return applyTag(v, p), nil
+}
+
+func marshalChoice(rv reflect.Value, iface reflect.Type) (*Value, error) {
+	elem := rv.Elem()
+	for _, alt := range lookupChoice(iface) {
+		if reflect.TypeOf(alt.Type) != elem.Type() {
+			continue
+		}
+		return marshalValue(elem, fieldParams{class: alt.Class, tag: alt.Tag, tagged: true, explicit: alt.Explicit})
+	}
+	return nil, fmt.Errorf("ber: %s is not registered as an alternative of %s", elem.Type(), iface)
+}
+
+func marshalUntagged(rv reflect.Value, p fieldParams) (*Value, error) {
+	switch t := builtinType(rv.Type()); t {
+	case oidType:
+		return NewObjectIdentifier(rv.Convert(t).Interface().(asn1.ObjectIdentifier))
+	case bitStringType:
+		bs := rv.Convert(t).Interface().(asn1.BitString)
+		return NewBitString(bs.Bytes, len(bs.Bytes)*8-bs.BitLength)
+	case nullType:
+		return NewNull(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if p.null {
+			return NewNull(), nil
+		}
+		return NewBoolean(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return newInteger(rv.Int(), p), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return nil, fmt.Errorf("ber: integer %d overflows int64", u)
+		}
+		return newInteger(int64(u), p), nil
+	case reflect.String:
+		return NewOctetString([]byte(rv.String())), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return NewOctetString(b), nil
+		}
+
+		v := newCollection(p)
+		for i := 0; i < rv.Len(); i++ {
+			e, err := marshalValue(rv.Index(i), fieldParams{})
+			if err != nil {
+				return nil, err
+			}
+			v.Add(e)
+		}
+		return v, nil
+	case reflect.Struct:
+		return marshalStruct(rv, p)
+	}
+	return nil, fmt.Errorf("ber: unsupported type: %s", rv.Type())
+}
+
+func newInteger(i int64, p fieldParams) *Value {
+	if p.enum {
+		return NewEnumerated(i)
+	}
+	return NewInteger(i)
+}
+
+func newCollection(p fieldParams) *Value {
+	if p.set {
+		return NewSet()
+	}
+	return NewSequence()
+}
+
+func marshalStruct(rv reflect.Value, p fieldParams) (*Value, error) {
+	v := newCollection(p)
+
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("ber") == "-" {
+			continue
+		}
+		fp, err := parseFieldParams(f.Tag.Get("ber"))
+		if err != nil {
+			return nil, err
+		}
+
+		fv := rv.Field(i)
+		if fv.IsZero() {
+			if fp.optional {
+				continue
+			}
+			if k := fv.Kind(); k == reflect.Ptr || k == reflect.Interface {
+				return nil, fmt.Errorf("ber: missing mandatory field %s.%s", t.Name(), f.Name)
+			}
+		}
+
+		e, err := marshalValue(fv, fp)
+		if err != nil {
+			return nil, fmt.Errorf("ber: %s.%s: %w", t.Name(), f.Name, err)
+		}
+		v.Add(e)
+	}
+	return v, nil
+}
+
+func unmarshalValue(val *Value, rv reflect.Value, p fieldParams) error {
+	if rv.Type() == valueType {
+		x := *val
+		if p.tagged && p.explicit {
+			inner, err := val.Unwrap()
+			if err != nil {
+				return err
+			}
+			x = *inner
+		}
+		rv.Set(reflect.ValueOf(&x))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(rv.Type().Elem())
+		if err := unmarshalValue(val, ptr.Elem(), p); err != nil {
+			return err
+		}
+		rv.Set(ptr)
+		return nil
+	case reflect.Interface:
+		if p.tagged {
+			inner, err := val.Unwrap()
+			if err != nil {
+				return err
+			}
+			val = inner
+		}
+		return unmarshalChoice(val, rv)
+	}
+
+	if p.tagged && p.explicit {
+		inner, err := val.Unwrap()
+		if err != nil {
+			return err
+		}
+		val = inner
+	} else if !p.tagged {
+		if class, tag, ok := universalTag(rv.Type(), p); ok && !val.Is(class, tag) {
+			return fmt.Errorf("ber: unexpected %s for %s", val.TagString(), rv.Type())
+		}
+	}
+	return unmarshalUntagged(val, rv, p)
+}
+
+func unmarshalChoice(val *Value, rv reflect.Value) error {
+	for _, alt := range lookupChoice(rv.Type()) {
+		if !val.Is(alt.Class, alt.Tag) {
+			continue
+		}
+
+		x := reflect.New(reflect.TypeOf(alt.Type)).Elem()
+		ap := fieldParams{class: alt.Class, tag: alt.Tag, tagged: true, explicit: alt.Explicit}
+		if err := unmarshalValue(val, x, ap); err != nil {
+			return err
+		}
+		rv.Set(x)
+		return nil
+	}
+	return fmt.Errorf("ber: unexpected %s for %s", val.TagString(), rv.Type())
+}
+
+func unmarshalUntagged(val *Value, rv reflect.Value, p fieldParams) error {
+	switch builtinType(rv.Type()) {
+	case oidType:
+		oid, err := val.ObjectIdentifier()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(oid).Convert(rv.Type()))
+		return nil
+	case bitStringType:
+		b, unused, err := val.BitString()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(asn1.BitString{Bytes: b, BitLength: len(b)*8 - unused}).Convert(rv.Type()))
+		return nil
+	case nullType:
+		if !val.IsNull() {
+			return fmt.Errorf("ber: unexpected %s for NULL", val.TagString())
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if p.null {
+			rv.SetBool(true)
+			return nil
+		}
+		b, err := val.Bool()
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := val.Int()
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("ber: integer %d overflows %s", i, rv.Type())
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := val.Int()
+		if err != nil {
+			return err
+		}
+		if i < 0 || rv.OverflowUint(uint64(i)) {
+			return fmt.Errorf("ber: integer %d overflows %s", i, rv.Type())
+		}
+		rv.SetUint(uint64(i))
+		return nil
+	case reflect.String:
+		b, err := val.Bytes()
+		if err != nil {
+			return err
+		}
+		rv.SetString(string(b))
+		return nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := val.Bytes()
+			if err != nil {
+				return err
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+		if !val.Constructed {
+			return fmt.Errorf("ber: unexpected primitive %s for %s", val.TagString(), rv.Type())
+		}
+
+		s := reflect.MakeSlice(rv.Type(), len(val.Elements), len(val.Elements))
+		for i, e := range val.Elements {
+			if err := unmarshalValue(e, s.Index(i), fieldParams{}); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Array:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		b, err := val.Bytes()
+		if err != nil {
+			return err
+		}
+		if len(b) != rv.Len() {
+			return fmt.Errorf("ber: unexpected length %d for %s", len(b), rv.Type())
+		}
+		reflect.Copy(rv, reflect.ValueOf(b))
+		return nil
+	case reflect.Struct:
+		return unmarshalStruct(val, rv, p)
+	}
+	return fmt.Errorf("ber: unsupported type: %s", rv.Type())
+}
+
+func unmarshalStruct(val *Value, rv reflect.Value, p fieldParams) error {
+	if !val.Constructed {
+		return fmt.Errorf("ber: unexpected primitive %s for %s", val.TagString(), rv.Type())
+	}
+
+	t := rv.Type()
+	cursor := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("ber") == "-" {
+			continue
+		}
+		fp, err := parseFieldParams(f.Tag.Get("ber"))
+		if err != nil {
+			return err
+		}
+
+		idx := -1
+		if p.set {
+			for j, e := range val.Elements {
+				if matches(e, f.Type, fp) {
+					idx = j
+					break
+				}
+			}
+		} else if cursor < len(val.Elements) && matches(val.Elements[cursor], f.Type, fp) {
+			idx = cursor
+			cursor++
+		}
+
+		if idx < 0 {
+			if fp.optional {
+				continue
+			}
+			return fmt.Errorf("ber: missing mandatory field %s.%s", t.Name(), f.Name)
+		}
+		if err := unmarshalValue(val.Elements[idx], rv.Field(i), fp); err != nil {
+			return fmt.Errorf("ber: %s.%s: %w", t.Name(), f.Name, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the Value can be decoded into the field of type t.
+func matches(v *Value, t reflect.Type, p fieldParams) bool {
+	if p.tagged {
+		return v.Is(p.class, p.tag)
+	}
+
+	for t.Kind() == reflect.Ptr && t != valueType {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		for _, alt := range lookupChoice(t) {
+			if v.Is(alt.Class, alt.Tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	class, tag, ok := universalTag(t, p)
+	return !ok || v.Is(class, tag)
+}
+
+// universalTag returns the tag of the type when it is not tagged. ok is false
+// if the type matches any tag.
+func universalTag(t reflect.Type, p fieldParams) (class, tag int, ok bool) {
+	if t == valueType {
+		return 0, 0, false
+	}
+
+	switch builtinType(t) {
+	case oidType:
+		return Universal, TagObjectIdentifier, true
+	case bitStringType:
+		return Universal, TagBitString, true
+	case nullType:
+		return Universal, TagNull, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if p.null {
+			return Universal, TagNull, true
+		}
+		return Universal, TagBoolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if p.enum {
+			return Universal, TagEnumerated, true
+		}
+		return Universal, TagInteger, true
+	case reflect.String:
+		return Universal, TagOctetString, true
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Universal, TagOctetString, true
+		}
+	}
+
+	if p.set {
+		return Universal, TagSet, true
+	}
+	return Universal, TagSequence, true
+}
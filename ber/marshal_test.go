@@ -0,0 +1,206 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber_test
+
+import (
+	"encoding/asn1"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+type legID interface {
+	isLegID()
+}
+
+type sendingSideID []byte
+
+func (sendingSideID) isLegID() {}
+
+type receivingSideID []byte
+
+func (receivingSideID) isLegID() {}
+
+func init() {
+	ber.RegisterChoice((*legID)(nil),
+		ber.Alternative{Class: ber.ContextSpecific, Tag: 0, Type: sendingSideID(nil)},
+		ber.Alternative{Class: ber.ContextSpecific, Tag: 1, Type: receivingSideID(nil)},
+	)
+}
+
+type bcsmEvent struct {
+	EventType   int   `ber:"tag:0,enum"`
+	MonitorMode int   `ber:"tag:1,enum"`
+	LegID       legID `ber:"tag:2,optional"`
+	Timer       *int  `ber:"tag:30,explicit,optional"`
+}
+
+type proprietaryArg struct {
+	ServiceKey  int                   `ber:"tag:0"`
+	Number      []byte                `ber:"tag:1,optional"`
+	Name        string                `ber:"tag:2,optional"`
+	Events      []*bcsmEvent          `ber:"tag:3"`
+	Urgent      bool                  `ber:"tag:4,null"`
+	Context     asn1.ObjectIdentifier `ber:"tag:5,optional"`
+	Flags       asn1.BitString        `ber:"tag:6,optional"`
+	Unspecified *ber.Value            `ber:"tag:7,explicit,optional"`
+	Count       uint8
+	Leg         legID
+	internal    int
+}
+
+func TestMarshal(t *testing.T) {
+	timer := 30
+	arg := &proprietaryArg{
+		ServiceKey: 100,
+		Number:     []byte{0x91, 0x21, 0x43},
+		Events: []*bcsmEvent{
+			{EventType: 7, MonitorMode: 1, LegID: sendingSideID{0x02}},
+			{EventType: 9, MonitorMode: 0, LegID: receivingSideID{0x01}, Timer: &timer},
+		},
+		Urgent:      true,
+		Context:     asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 50, 1},
+		Flags:       asn1.BitString{Bytes: []byte{0xc0}, BitLength: 2},
+		Unspecified: ber.NewInteger(1),
+		Count:       200,
+		Leg:         receivingSideID{0x01},
+	}
+	serialized := []byte{
+		0x80, 0x01, 0x64, 0x81, 0x03, 0x91, 0x21, 0x43, 0xa3, 0x1f, 0x30, 0x0b, 0x80, 0x01, 0x07, 0x81,
+		0x01, 0x01, 0xa2, 0x03, 0x80, 0x01, 0x02, 0x30, 0x10, 0x80, 0x01, 0x09, 0x81, 0x01, 0x00, 0xa2,
+		0x03, 0x81, 0x01, 0x01, 0xbe, 0x03, 0x02, 0x01, 0x1e, 0x84, 0x00, 0x85, 0x07, 0x04, 0x00, 0x00,
+		0x01, 0x00, 0x32, 0x01, 0x86, 0x02, 0x06, 0xc0, 0xa7, 0x03, 0x02, 0x01, 0x01, 0x02, 0x02, 0x00,
+		0xc8, 0x81, 0x01, 0x01,
+	}
+
+	b, err := ber.Marshal(arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", b, serialized)
+
+	got := &proprietaryArg{}
+	if err := ber.Unmarshal(serialized, got); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
+
+func TestMarshalWithComponent(t *testing.T) {
+	arg := &bcsmEvent{EventType: 7, MonitorMode: 1}
+	b, err := ber.Marshal(arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := tcap.NewInvoke(1, -1, 23, true, b)
+	cb, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := tcap.ParseComponent(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &bcsmEvent{}
+	if err := ber.Unmarshal(parsed.Parameter.Value, got); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	if _, err := ber.Marshal(1); err == nil {
+		t.Error("expected error for non-struct value")
+	}
+	if _, err := ber.Marshal(&proprietaryArg{Events: []*bcsmEvent{}}); err == nil {
+		t.Error("expected error for missing mandatory CHOICE")
+	}
+	if err := ber.Unmarshal([]byte{0x81, 0x01, 0x01}, &proprietaryArg{}); err == nil {
+		t.Error("expected error for missing mandatory field")
+	}
+	if _, err := ber.Marshal(&struct{ N uint64 }{N: 1 << 63}); err == nil {
+		t.Error("expected error for uint64 overflowing INTEGER")
+	}
+}
+
+type reason interface {
+	isReason()
+}
+
+type reasonNone ber.Null
+
+func (reasonNone) isReason() {}
+
+type reasonOID asn1.ObjectIdentifier
+
+func (reasonOID) isReason() {}
+
+func init() {
+	ber.RegisterChoice((*reason)(nil),
+		ber.Alternative{Class: ber.ContextSpecific, Tag: 0, Type: reasonNone{}},
+		ber.Alternative{Class: ber.ContextSpecific, Tag: 1, Type: reasonOID(nil)},
+	)
+}
+
+func TestMarshalChoiceOfBuiltinTypes(t *testing.T) {
+	cases := []struct {
+		description string
+		structured  reason
+		serialized  []byte
+	}{
+		{"NULL", reasonNone{}, []byte{0x80, 0x00}},
+		{"OBJECT IDENTIFIER", reasonOID{0, 4, 0, 0, 1, 0, 50, 1}, []byte{0x81, 0x07, 0x04, 0x00, 0x00, 0x01, 0x00, 0x32, 0x01}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			v, err := ber.MarshalValue(&c.structured)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := v.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "serialized", b, c.serialized)
+
+			var r reason
+			if err := ber.UnmarshalValue(v, &r); err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "structured", r, c.structured)
+		})
+	}
+}
+
+func TestMarshalNullAndEmptySequence(t *testing.T) {
+	cases := []struct {
+		description string
+		structured  interface{}
+		serialized  []byte
+	}{
+		{"NULL", &ber.Null{}, []byte{0x05, 0x00}},
+		{"defined on NULL", &reasonNone{}, []byte{0x05, 0x00}},
+		{"empty SEQUENCE", &struct{}{}, []byte{0x30, 0x00}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			v, err := ber.MarshalValue(c.structured)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := v.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "serialized", b, c.serialized)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+// EncodeInteger returns the contents octets of INTEGER (or ENUMERATED) in two's complement.
+func EncodeInteger(v int64) []byte {
+	n := 1
+	for x := v; x > 127 || x < -128; x >>= 8 {
+		n++
+	}
+
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = uint8(v)
+		v >>= 8
+	}
+	return b
+}
+
+// DecodeInteger decodes the contents octets of INTEGER (or ENUMERATED).
+func DecodeInteger(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("ber: empty integer")
+	}
+	if len(b) > 8 {
+		return 0, fmt.Errorf("ber: integer too large")
+	}
+
+	v := int64(int8(b[0]))
+	for _, o := range b[1:] {
+		v = v<<8 | int64(o)
+	}
+	return v, nil
+}
+
+// EncodeBoolean returns the contents octets of BOOLEAN.
+func EncodeBoolean(v bool) []byte {
+	if v {
+		return []byte{0xff}
+	}
+	return []byte{0x00}
+}
+
+// DecodeBoolean decodes the contents octets of BOOLEAN.
+func DecodeBoolean(b []byte) (bool, error) {
+	if len(b) != 1 {
+		return false, fmt.Errorf("ber: invalid boolean length: %d", len(b))
+	}
+	return b[0] != 0, nil
+}
+
+// EncodeObjectIdentifier returns the contents octets of OBJECT IDENTIFIER.
+func EncodeObjectIdentifier(oid asn1.ObjectIdentifier) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, fmt.Errorf("ber: invalid object identifier: %v", oid)
+	}
+
+	b := appendBase128(nil, oid[0]*40+oid[1])
+	for _, arc := range oid[2:] {
+		if arc < 0 {
+			return nil, fmt.Errorf("ber: invalid object identifier: %v", oid)
+		}
+		b = appendBase128(b, arc)
+	}
+	return b, nil
+}
+
+// DecodeObjectIdentifier decodes the contents octets of OBJECT IDENTIFIER.
+func DecodeObjectIdentifier(b []byte) (asn1.ObjectIdentifier, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("ber: empty object identifier")
+	}
+
+	var arcs []int
+	v := 0
+	for i, o := range b {
+		if v > 1<<24 {
+			return nil, fmt.Errorf("ber: object identifier arc too large")
+		}
+		v = v<<7 | int(o&0x7f)
+		if o&0x80 != 0 {
+			if i == len(b)-1 {
+				return nil, fmt.Errorf("ber: truncated object identifier")
+			}
+			continue
+		}
+		arcs = append(arcs, v)
+		v = 0
+	}
+
+	oid := make(asn1.ObjectIdentifier, 0, len(arcs)+1)
+	switch {
+	case arcs[0] < 40:
+		oid = append(oid, 0, arcs[0])
+	case arcs[0] < 80:
+		oid = append(oid, 1, arcs[0]-40)
+	default:
+		oid = append(oid, 2, arcs[0]-80)
+	}
+	return append(oid, arcs[1:]...), nil
+}
+
+func appendBase128(b []byte, v int) []byte {
+	for i := base128Len(v) - 1; i >= 0; i-- {
+		o := uint8(v>>(7*i)) & 0x7f
+		if i != 0 {
+			o |= 0x80
+		}
+		b = append(b, o)
+	}
+	return b
+}
+
+// EncodeBitString returns the contents octets of BIT STRING with the number of unused bits
+// in the last octet.
+func EncodeBitString(b []byte, unused int) ([]byte, error) {
+	if unused < 0 || unused > 7 || (len(b) == 0 && unused != 0) {
+		return nil, fmt.Errorf("ber: invalid number of unused bits: %d", unused)
+	}
+	return append([]byte{uint8(unused)}, b...), nil
+}
+
+// DecodeBitString decodes the contents octets of BIT STRING and returns the bits
+// with the number of unused bits in the last octet.
+func DecodeBitString(b []byte) ([]byte, int, error) {
+	if len(b) == 0 || b[0] > 7 || (len(b) == 1 && b[0] != 0) {
+		return nil, 0, fmt.Errorf("ber: invalid bit string")
+	}
+	return b[1:], int(b[0]), nil
+}
@@ -0,0 +1,354 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+)
+
+// Value is a BER encoded value in the form of tree.
+//
+// Primitive Value holds its contents octets in Contents, and constructed Value
+// holds its components in Elements. Tagged values and CHOICEs are represented
+// by changing Class and Tag (IMPLICIT) or by wrapping with a constructed Value (EXPLICIT).
+type Value struct {
+	Class       int
+	Constructed bool
+	Tag         int
+	Contents    []byte
+	Elements    []*Value
+}
+
+// NewPrimitive creates a new primitive Value.
+func NewPrimitive(class, tag int, contents []byte) *Value {
+	return &Value{Class: class, Tag: tag, Contents: contents}
+}
+
+// NewConstructed creates a new constructed Value.
+func NewConstructed(class, tag int, elems ...*Value) *Value {
+	return &Value{Class: class, Constructed: true, Tag: tag, Elements: elems}
+}
+
+// NewBoolean creates a new BOOLEAN.
+func NewBoolean(v bool) *Value {
+	return NewPrimitive(Universal, TagBoolean, EncodeBoolean(v))
+}
+
+// NewInteger creates a new INTEGER.
+func NewInteger(v int64) *Value {
+	return NewPrimitive(Universal, TagInteger, EncodeInteger(v))
+}
+
+// NewEnumerated creates a new ENUMERATED.
+func NewEnumerated(v int64) *Value {
+	return NewPrimitive(Universal, TagEnumerated, EncodeInteger(v))
+}
+
+// NewOctetString creates a new OCTET STRING.
+func NewOctetString(v []byte) *Value {
+	return NewPrimitive(Universal, TagOctetString, v)
+}
+
+// NewNull creates a new NULL.
+func NewNull() *Value {
+	return NewPrimitive(Universal, TagNull, nil)
+}
+
+// NewBitString creates a new BIT STRING.
+func NewBitString(b []byte, unused int) (*Value, error) {
+	v, err := EncodeBitString(b, unused)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrimitive(Universal, TagBitString, v), nil
+}
+
+// NewObjectIdentifier creates a new OBJECT IDENTIFIER.
+func NewObjectIdentifier(oid asn1.ObjectIdentifier) (*Value, error) {
+	v, err := EncodeObjectIdentifier(oid)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrimitive(Universal, TagObjectIdentifier, v), nil
+}
+
+// NewSequence creates a new SEQUENCE (or SEQUENCE OF).
+func NewSequence(elems ...*Value) *Value {
+	return NewConstructed(Universal, TagSequence, elems...)
+}
+
+// NewSet creates a new SET (or SET OF).
+func NewSet(elems ...*Value) *Value {
+	return NewConstructed(Universal, TagSet, elems...)
+}
+
+// Implicit returns a copy of v tagged with the context-specific tag number in IMPLICIT mode.
+//
+// It is also used to build an alternative of CHOICE.
+func Implicit(tag int, v *Value) *Value {
+	x := *v
+	x.Class = ContextSpecific
+	x.Tag = tag
+	return &x
+}
+
+// Explicit returns v wrapped by the context-specific tag number in EXPLICIT mode.
+func Explicit(tag int, v *Value) *Value {
+	return NewConstructed(ContextSpecific, tag, v)
+}
+
+// ParseValue parses the first TLV in b as a Value recursively.
+//
+// The Contents of the returned Value refers to the same underlying array as b.
+func ParseValue(b []byte) (*Value, error) {
+	e, err := ParseElement(b)
+	if err != nil {
+		return nil, err
+	}
+	return valueFromElement(e)
+}
+
+// ParseValues parses b as a list of Values.
+func ParseValues(b []byte) ([]*Value, error) {
+	elems, err := ParseElements(b)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]*Value, len(elems))
+	for i, e := range elems {
+		if vals[i], err = valueFromElement(e); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+func valueFromElement(e *Element) (*Value, error) {
+	v := &Value{Class: e.Class, Constructed: e.Constructed, Tag: e.Tag}
+	if !e.Constructed {
+		v.Contents = e.Value
+		return v, nil
+	}
+
+	var err error
+	if v.Elements, err = ParseValues(e.Value); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MarshalBinary returns the byte sequence generated from a Value.
+func (v *Value) MarshalBinary() ([]byte, error) {
+	return v.AppendTo(make([]byte, 0, v.MarshalLen())), nil
+}
+
+// AppendTo appends the byte sequence of the Value to b.
+func (v *Value) AppendTo(b []byte) []byte {
+	if !v.Constructed {
+		return Append(b, v.Class, false, v.Tag, v.Contents)
+	}
+
+	b = AppendHeader(b, v.Class, true, v.Tag, v.contentsLen())
+	for _, e := range v.Elements {
+		b = e.AppendTo(b)
+	}
+	return b
+}
+
+// MarshalLen returns the serial length of Value.
+func (v *Value) MarshalLen() int {
+	l := v.contentsLen()
+	return HeaderLen(v.Tag, l) + l
+}
+
+// ContentsBytes returns the contents octets of Value, which is encoded from
+// Elements if the Value is constructed.
+func (v *Value) ContentsBytes() []byte {
+	if !v.Constructed {
+		return v.Contents
+	}
+
+	b := make([]byte, 0, v.contentsLen())
+	for _, e := range v.Elements {
+		b = e.AppendTo(b)
+	}
+	return b
+}
+
+func (v *Value) contentsLen() int {
+	if !v.Constructed {
+		return len(v.Contents)
+	}
+
+	l := 0
+	for _, e := range v.Elements {
+		l += e.MarshalLen()
+	}
+	return l
+}
+
+// Is reports whether the Value has the given class and tag number.
+func (v *Value) Is(class, tag int) bool {
+	return v.Class == class && v.Tag == tag
+}
+
+// Child returns the first component of constructed Value with the given class and tag number.
+//
+// It returns nil if not found.
+func (v *Value) Child(class, tag int) *Value {
+	for _, e := range v.Elements {
+		if e.Is(class, tag) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Add appends the components to the constructed Value.
+func (v *Value) Add(elems ...*Value) *Value {
+	v.Elements = append(v.Elements, elems...)
+	return v
+}
+
+// Unwrap returns the component of the Value tagged in EXPLICIT mode.
+func (v *Value) Unwrap() (*Value, error) {
+	if !v.Constructed || len(v.Elements) != 1 {
+		return nil, fmt.Errorf("ber: value with tag %d is not explicitly tagged", v.Tag)
+	}
+	return v.Elements[0], nil
+}
+
+func (v *Value) primitive() ([]byte, error) {
+	if v.Constructed {
+		return nil, fmt.Errorf("ber: value with tag %d is not primitive", v.Tag)
+	}
+	return v.Contents, nil
+}
+
+// Bool returns the Value as BOOLEAN.
+func (v *Value) Bool() (bool, error) {
+	b, err := v.primitive()
+	if err != nil {
+		return false, err
+	}
+	return DecodeBoolean(b)
+}
+
+// Int returns the Value as INTEGER or ENUMERATED.
+func (v *Value) Int() (int64, error) {
+	b, err := v.primitive()
+	if err != nil {
+		return 0, err
+	}
+	return DecodeInteger(b)
+}
+
+// Bytes returns the Value as OCTET STRING.
+func (v *Value) Bytes() ([]byte, error) {
+	return v.primitive()
+}
+
+// BitString returns the Value as BIT STRING with the number of unused bits.
+func (v *Value) BitString() ([]byte, int, error) {
+	b, err := v.primitive()
+	if err != nil {
+		return nil, 0, err
+	}
+	return DecodeBitString(b)
+}
+
+// ObjectIdentifier returns the Value as OBJECT IDENTIFIER.
+func (v *Value) ObjectIdentifier() (asn1.ObjectIdentifier, error) {
+	b, err := v.primitive()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeObjectIdentifier(b)
+}
+
+// IsNull reports whether the Value is a NULL, regardless of its tag.
+func (v *Value) IsNull() bool {
+	return !v.Constructed && len(v.Contents) == 0
+}
+
+// SetBool sets the contents of the Value as BOOLEAN.
+func (v *Value) SetBool(b bool) {
+	v.Constructed, v.Elements = false, nil
+	v.Contents = EncodeBoolean(b)
+}
+
+// SetInt sets the contents of the Value as INTEGER or ENUMERATED.
+func (v *Value) SetInt(i int64) {
+	v.Constructed, v.Elements = false, nil
+	v.Contents = EncodeInteger(i)
+}
+
+// SetBytes sets the contents of the Value as OCTET STRING.
+func (v *Value) SetBytes(b []byte) {
+	v.Constructed, v.Elements = false, nil
+	v.Contents = b
+}
+
+// SetBitString sets the contents of the Value as BIT STRING.
+func (v *Value) SetBitString(b []byte, unused int) error {
+	c, err := EncodeBitString(b, unused)
+	if err != nil {
+		return err
+	}
+	v.Constructed, v.Elements = false, nil
+	v.Contents = c
+	return nil
+}
+
+// SetObjectIdentifier sets the contents of the Value as OBJECT IDENTIFIER.
+func (v *Value) SetObjectIdentifier(oid asn1.ObjectIdentifier) error {
+	c, err := EncodeObjectIdentifier(oid)
+	if err != nil {
+		return err
+	}
+	v.Constructed, v.Elements = false, nil
+	v.Contents = c
+	return nil
+}
+
+var universalNames = map[int]string{
+	TagBoolean:          "BOOLEAN",
+	TagInteger:          "INTEGER",
+	TagBitString:        "BIT STRING",
+	TagOctetString:      "OCTET STRING",
+	TagNull:             "NULL",
+	TagObjectIdentifier: "OBJECT IDENTIFIER",
+	TagEnumerated:       "ENUMERATED",
+	TagSequence:         "SEQUENCE",
+	TagSet:              "SET",
+}
+
+var classPrefixes = []string{"UNIVERSAL ", "APPLICATION ", "", "PRIVATE "}
+
+// TagString returns the tag of Value in ASN.1 notation, such as "INTEGER" or "[0]".
+func (v *Value) TagString() string {
+	if v.Class == Universal {
+		if n, ok := universalNames[v.Tag]; ok {
+			return n
+		}
+	}
+	return fmt.Sprintf("[%s%d]", classPrefixes[v.Class&0x3], v.Tag)
+}
+
+// String returns Value in human readable string.
+func (v *Value) String() string {
+	if !v.Constructed {
+		return fmt.Sprintf("%s %x", v.TagString(), v.Contents)
+	}
+
+	elems := make([]string, len(v.Elements))
+	for i, e := range v.Elements {
+		elems[i] = e.String()
+	}
+	return fmt.Sprintf("%s {%s}", v.TagString(), strings.Join(elems, ", "))
+}
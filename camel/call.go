@@ -0,0 +1,427 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Message Type definitions used in MiscCallInfo.
+const (
+	MessageTypeRequest int = iota
+	MessageTypeNotification
+)
+
+// InitialDPArg represents InitialDPArg.
+//
+// The numbers such as CalledPartyNumber are kept in the format defined in ISUP.
+type InitialDPArg struct {
+	ServiceKey            int
+	CalledPartyNumber     []byte
+	CallingPartyNumber    []byte
+	CallingPartysCategory []byte
+	LocationNumber        []byte
+	OriginalCalledPartyID []byte
+	BearerCapability      []byte
+	EventTypeBCSM         *int
+	RedirectingPartyID    []byte
+	IMSI                  string
+	CallReferenceNumber   []byte
+	MSCAddress            *gsmmap.AddressString
+	CalledPartyBCDNumber  []byte
+	TimeAndTimezone       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InitialDPArg.
+func (i *InitialDPArg) MarshalBinary() ([]byte, error) {
+	var err error
+	v := appendInteger(nil, 0, i.ServiceKey)
+	v = appendOctets(v, 2, i.CalledPartyNumber)
+	v = appendOctets(v, 3, i.CallingPartyNumber)
+	v = appendOctets(v, 5, i.CallingPartysCategory)
+	v = appendOctets(v, 10, i.LocationNumber)
+	v = appendOctets(v, 12, i.OriginalCalledPartyID)
+	if i.BearerCapability != nil {
+		v = append(v, param.Sequence(27, ber.Append(nil, ber.ContextSpecific, false, 0, i.BearerCapability))...)
+	}
+	if i.EventTypeBCSM != nil {
+		v = appendInteger(v, 28, *i.EventTypeBCSM)
+	}
+	v = appendOctets(v, 29, i.RedirectingPartyID)
+	if i.IMSI != "" {
+		if v, err = appendTBCD(v, 50, i.IMSI); err != nil {
+			return nil, err
+		}
+	}
+	v = appendOctets(v, 54, i.CallReferenceNumber)
+	if i.MSCAddress != nil {
+		if v, err = appendAddress(v, 55, i.MSCAddress); err != nil {
+			return nil, err
+		}
+	}
+	v = appendOctets(v, 56, i.CalledPartyBCDNumber)
+	v = appendOctets(v, 57, i.TimeAndTimezone)
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InitialDPArg.
+func (i *InitialDPArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "InitialDPArg")
+	if err != nil {
+		return err
+	}
+
+	hasServiceKey := false
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if i.ServiceKey, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+			hasServiceKey = true
+		case 2:
+			i.CalledPartyNumber = e.Value
+		case 3:
+			i.CallingPartyNumber = e.Value
+		case 5:
+			i.CallingPartysCategory = e.Value
+		case 10:
+			i.LocationNumber = e.Value
+		case 12:
+			i.OriginalCalledPartyID = e.Value
+		case 27:
+			bc, err := e.Children()
+			if err != nil {
+				return err
+			}
+			if len(bc) == 0 {
+				return protocol.Invalid("bearerCapability")
+			}
+			i.BearerCapability = bc[0].Value
+		case 28:
+			if i.EventTypeBCSM, err = param.DecodeIntegerPtr(e); err != nil {
+				return err
+			}
+		case 29:
+			i.RedirectingPartyID = e.Value
+		case 50:
+			i.IMSI = gsmmap.DecodeTBCD(e.Value)
+		case 54:
+			i.CallReferenceNumber = e.Value
+		case 55:
+			if i.MSCAddress, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 56:
+			i.CalledPartyBCDNumber = e.Value
+		case 57:
+			i.TimeAndTimezone = e.Value
+		}
+	}
+
+	if !hasServiceKey {
+		return protocol.Missing("serviceKey")
+	}
+	return nil
+}
+
+// ConnectArg represents ConnectArg.
+type ConnectArg struct {
+	DestinationRoutingAddress [][]byte
+	OriginalCalledPartyID     []byte
+	CallingPartysCategory     []byte
+	RedirectingPartyID        []byte
+	RedirectionInformation    []byte
+	SuppressionOfAnnouncement bool
+	OCSIApplicable            bool
+}
+
+// MarshalBinary returns the byte sequence generated from a ConnectArg.
+func (c *ConnectArg) MarshalBinary() ([]byte, error) {
+	if len(c.DestinationRoutingAddress) == 0 {
+		return nil, protocol.Missing("destinationRoutingAddress")
+	}
+
+	var dra []byte
+	for _, a := range c.DestinationRoutingAddress {
+		dra = append(dra, param.OctetString(a)...)
+	}
+	v := param.Sequence(0, dra)
+	v = appendOctets(v, 6, c.OriginalCalledPartyID)
+	v = appendOctets(v, 28, c.CallingPartysCategory)
+	v = appendOctets(v, 29, c.RedirectingPartyID)
+	v = appendOctets(v, 30, c.RedirectionInformation)
+	if c.SuppressionOfAnnouncement {
+		v = appendNull(v, 55)
+	}
+	if c.OCSIApplicable {
+		v = appendNull(v, 56)
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ConnectArg.
+func (c *ConnectArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ConnectArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			dra, err := e.Children()
+			if err != nil {
+				return err
+			}
+			for _, a := range dra {
+				c.DestinationRoutingAddress = append(c.DestinationRoutingAddress, a.Value)
+			}
+		case 6:
+			c.OriginalCalledPartyID = e.Value
+		case 28:
+			c.CallingPartysCategory = e.Value
+		case 29:
+			c.RedirectingPartyID = e.Value
+		case 30:
+			c.RedirectionInformation = e.Value
+		case 55:
+			c.SuppressionOfAnnouncement = true
+		case 56:
+			c.OCSIApplicable = true
+		}
+	}
+
+	if len(c.DestinationRoutingAddress) == 0 {
+		return protocol.Missing("destinationRoutingAddress")
+	}
+	return nil
+}
+
+// ReleaseCallArg represents ReleaseCallArg, which is a Cause in the format defined in ISUP.
+type ReleaseCallArg struct {
+	Cause []byte
+}
+
+// NewReleaseCallArg creates a new ReleaseCallArg with the cause value, coded in ITU-T standard
+// and located at the user.
+func NewReleaseCallArg(cause uint8) *ReleaseCallArg {
+	return &ReleaseCallArg{Cause: []byte{0x80, 0x80 | cause}}
+}
+
+// MarshalBinary returns the byte sequence generated from a ReleaseCallArg.
+func (r *ReleaseCallArg) MarshalBinary() ([]byte, error) {
+	if len(r.Cause) < 2 {
+		return nil, protocol.Invalid("cause")
+	}
+	return param.OctetString(r.Cause), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ReleaseCallArg.
+func (r *ReleaseCallArg) UnmarshalBinary(b []byte) error {
+	v, err := protocol.ParseOctetString(b, "ReleaseCallArg")
+	if err != nil {
+		return err
+	}
+	if len(v) < 2 {
+		return protocol.Invalid("cause")
+	}
+	r.Cause = v
+	return nil
+}
+
+// CauseValue returns the cause value in the Cause.
+func (r *ReleaseCallArg) CauseValue() uint8 {
+	if len(r.Cause) < 2 {
+		return 0
+	}
+	return r.Cause[1] & 0x7f
+}
+
+// BCSMEvent represents BCSMEvent.
+type BCSMEvent struct {
+	EventTypeBCSM    int
+	MonitorMode      int
+	LegID            *LegID
+	ApplicationTimer *int
+}
+
+func (e *BCSMEvent) marshal() []byte {
+	v := appendInteger(nil, 0, e.EventTypeBCSM)
+	v = appendInteger(v, 1, e.MonitorMode)
+	if e.LegID != nil {
+		v = append(v, param.Sequence(2, e.LegID.marshal())...)
+	}
+	if e.ApplicationTimer != nil {
+		v = append(v, param.Sequence(30, appendInteger(nil, 1, *e.ApplicationTimer))...)
+	}
+	return param.Sequence(-1, v)
+}
+
+func parseBCSMEvent(elem *ber.Element) (*BCSMEvent, error) {
+	elems, err := elem.Children()
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &BCSMEvent{}
+	for _, e := range elems {
+		switch e.Tag {
+		case 0:
+			if ev.EventTypeBCSM, err = param.DecodeInteger(e); err != nil {
+				return nil, err
+			}
+		case 1:
+			if ev.MonitorMode, err = param.DecodeInteger(e); err != nil {
+				return nil, err
+			}
+		case 2:
+			if ev.LegID, err = parseLegID(e.Value); err != nil {
+				return nil, err
+			}
+		case 30:
+			criteria, err := e.Children()
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range criteria {
+				if c.Tag == 1 {
+					if ev.ApplicationTimer, err = param.DecodeIntegerPtr(c); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return ev, nil
+}
+
+// RequestReportBCSMEventArg represents RequestReportBCSMEventArg.
+type RequestReportBCSMEventArg struct {
+	BCSMEvents []*BCSMEvent
+}
+
+// MarshalBinary returns the byte sequence generated from a RequestReportBCSMEventArg.
+func (r *RequestReportBCSMEventArg) MarshalBinary() ([]byte, error) {
+	if len(r.BCSMEvents) == 0 {
+		return nil, protocol.Missing("bcsmEvents")
+	}
+
+	var events []byte
+	for _, e := range r.BCSMEvents {
+		events = append(events, e.marshal()...)
+	}
+	return param.Sequence(-1, param.Sequence(0, events)), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RequestReportBCSMEventArg.
+func (r *RequestReportBCSMEventArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "RequestReportBCSMEventArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if !e.Is(ber.ContextSpecific, 0) {
+			continue
+		}
+		events, err := e.Children()
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			bcsm, err := parseBCSMEvent(ev)
+			if err != nil {
+				return err
+			}
+			r.BCSMEvents = append(r.BCSMEvents, bcsm)
+		}
+	}
+
+	if len(r.BCSMEvents) == 0 {
+		return protocol.Missing("bcsmEvents")
+	}
+	return nil
+}
+
+// EventReportBCSMArg represents EventReportBCSMArg.
+//
+// EventSpecificInformationBCSM is kept as the contents octets of the CHOICE.
+type EventReportBCSMArg struct {
+	EventTypeBCSM                int
+	EventSpecificInformationBCSM []byte
+	LegID                        *LegID
+	MessageType                  *int
+}
+
+// MarshalBinary returns the byte sequence generated from an EventReportBCSMArg.
+func (e *EventReportBCSMArg) MarshalBinary() ([]byte, error) {
+	v := appendInteger(nil, 0, e.EventTypeBCSM)
+	if e.EventSpecificInformationBCSM != nil {
+		v = append(v, param.Sequence(2, e.EventSpecificInformationBCSM)...)
+	}
+	if e.LegID != nil {
+		v = append(v, param.Sequence(3, e.LegID.marshal())...)
+	}
+	if e.MessageType != nil {
+		v = append(v, param.Sequence(4, appendInteger(nil, 0, *e.MessageType))...)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an EventReportBCSMArg.
+func (e *EventReportBCSMArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "EventReportBCSMArg")
+	if err != nil {
+		return err
+	}
+
+	hasEventType := false
+	for _, elem := range elems {
+		if elem.Class != ber.ContextSpecific {
+			continue
+		}
+		switch elem.Tag {
+		case 0:
+			if e.EventTypeBCSM, err = param.DecodeInteger(elem); err != nil {
+				return err
+			}
+			hasEventType = true
+		case 2:
+			e.EventSpecificInformationBCSM = elem.Value
+		case 3:
+			if e.LegID, err = parseLegID(elem.Value); err != nil {
+				return err
+			}
+		case 4:
+			info, err := elem.Children()
+			if err != nil {
+				return err
+			}
+			for _, i := range info {
+				if i.Tag == 0 {
+					if e.MessageType, err = param.DecodeIntegerPtr(i); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if !hasEventType {
+		return protocol.Missing("eventTypeBCSM")
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package camel provides the typed parameters of CAP (CAMEL Application Part) phase 2 to 4
+operations defined in 3GPP TS 29.078, which can be set into and retrieved from
+the Parameter of tcap.Component.
+
+The application contexts of CAP are registered to the tcap package on init, so that
+Context() of tcap.Dialogue returns their names. Use tcap.NewAARQWithOID with the
+OIDs defined in this package to build the Dialogue Portion.
+*/
+package camel
+
+import (
+	"encoding/asn1"
+
+	"slices"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Operation Code definitions.
+//
+// They are untyped constants to be used as the opCode of tcap.NewInvoke as they are.
+const (
+	InitialDP              = 0
+	Connect                = 20
+	ReleaseCall            = 22
+	RequestReportBCSMEvent = 23
+	EventReportBCSM        = 24
+	Continue               = 31
+	ApplyCharging          = 35
+	ApplyChargingReport    = 36
+	ActivityTest           = 55
+	InitialDPSMS           = 60
+	ConnectSMS             = 62
+	ContinueSMS            = 65
+	ReleaseSMS             = 66
+	InitialDPGPRS          = 78
+)
+
+// Application Context definitions.
+var (
+	CAPv1GsmSSFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 50, 0}
+	CAPv2GsmSSFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 50, 1}
+	CAPv2AssistHandoffToGsmSCF = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 51, 1}
+	CAPv2GsmSRFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 52, 1}
+	CAPv3GsmSSFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 4}
+	CAPv3AssistHandoffToGsmSCF = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 6}
+	CAPv3GsmSRFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 20, 3, 14}
+	CAPv3GprsSSFToGsmSCF       = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 50}
+	CAPv3GsmSCFToGprsSSF       = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 51}
+	CAPv3SMS                   = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 21, 3, 61}
+	CAPv4GsmSSFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 22, 3, 4}
+	CAPv4AssistHandoffToGsmSCF = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 22, 3, 6}
+	CAPv4GsmSCFToGsmSSFGeneric = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 22, 3, 8}
+	CAPv4GsmSRFToGsmSCF        = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 22, 3, 14}
+	CAPv4SMS                   = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 22, 3, 61}
+)
+
+// Parameter is the argument of CAP operation.
+//
+// MarshalBinary returns the whole TLV of the parameter, and UnmarshalBinary
+// accepts the same.
+type Parameter = param.Parameter
+
+var operations = map[uint8]param.Operation{
+	InitialDP:              {Name: "initialDP", Arg: func() Parameter { return &InitialDPArg{} }},
+	Connect:                {Name: "connect", Arg: func() Parameter { return &ConnectArg{} }},
+	ReleaseCall:            {Name: "releaseCall", Arg: func() Parameter { return &ReleaseCallArg{} }},
+	RequestReportBCSMEvent: {Name: "requestReportBCSMEvent", Arg: func() Parameter { return &RequestReportBCSMEventArg{} }},
+	EventReportBCSM:        {Name: "eventReportBCSM", Arg: func() Parameter { return &EventReportBCSMArg{} }},
+	Continue:               {Name: "continue"},
+	ApplyCharging:          {Name: "applyCharging", Arg: func() Parameter { return &ApplyChargingArg{} }},
+	ApplyChargingReport:    {Name: "applyChargingReport", Arg: func() Parameter { return &ApplyChargingReportArg{} }},
+	ActivityTest:           {Name: "activityTest"},
+	InitialDPSMS:           {Name: "initialDPSMS", Arg: func() Parameter { return &InitialDPSMSArg{} }},
+	ConnectSMS:             {Name: "connectSMS", Arg: func() Parameter { return &ConnectSMSArg{} }},
+	ContinueSMS:            {Name: "continueSMS"},
+	ReleaseSMS:             {Name: "releaseSMS", Arg: func() Parameter { return &ReleaseSMSArg{} }},
+	InitialDPGPRS:          {Name: "initialDPGPRS", Arg: func() Parameter { return &InitialDPGPRSArg{} }},
+}
+
+type context struct {
+	name string
+	ops  []uint8
+}
+
+var (
+	callOps = []uint8{
+		InitialDP, Connect, ReleaseCall, RequestReportBCSMEvent, EventReportBCSM,
+		Continue, ApplyCharging, ApplyChargingReport, ActivityTest,
+	}
+	smsOps  = []uint8{InitialDPSMS, ConnectSMS, ContinueSMS, ReleaseSMS}
+	gprsOps = []uint8{InitialDPGPRS}
+)
+
+var contexts = map[string]context{
+	CAPv1GsmSSFToGsmSCF.String():        {"CAP-v1-gsmSSF-to-gsmSCF-AC", []uint8{InitialDP, Connect, ReleaseCall, RequestReportBCSMEvent, EventReportBCSM, Continue, ActivityTest}},
+	CAPv2GsmSSFToGsmSCF.String():        {"CAP-v2-gsmSSF-to-gsmSCF-AC", callOps},
+	CAPv2AssistHandoffToGsmSCF.String(): {"CAP-v2-assist-gsmSSF-to-gsmSCF-AC", []uint8{ActivityTest}},
+	CAPv2GsmSRFToGsmSCF.String():        {"CAP-v2-gsmSRF-to-gsmSCF-AC", []uint8{ActivityTest}},
+	CAPv3GsmSSFToGsmSCF.String():        {"capssf-scfGenericAC-v3", callOps},
+	CAPv3AssistHandoffToGsmSCF.String(): {"capssf-scfAssistHandoffAC-v3", []uint8{ActivityTest}},
+	CAPv3GsmSRFToGsmSCF.String():        {"gsmSRF-gsmSCF-ac-v3", []uint8{ActivityTest}},
+	CAPv3GprsSSFToGsmSCF.String():       {"cap3-gprssf-scfAC", gprsOps},
+	CAPv3GsmSCFToGprsSSF.String():       {"cap3-gsmscf-gprsssfAC", gprsOps},
+	CAPv3SMS.String():                   {"cap3-sms-AC", smsOps},
+	CAPv4GsmSSFToGsmSCF.String():        {"capssf-scfGenericAC-v4", callOps},
+	CAPv4AssistHandoffToGsmSCF.String(): {"capssf-scfAssistHandoffAC-v4", []uint8{ActivityTest}},
+	CAPv4GsmSCFToGsmSSFGeneric.String(): {"capscf-ssfGenericAC-v4", callOps},
+	CAPv4GsmSRFToGsmSCF.String():        {"gsmSRF-gsmSCF-ac-v4", []uint8{ActivityTest}},
+	CAPv4SMS.String():                   {"cap4-sms-AC", smsOps},
+}
+
+func init() {
+	for _, oid := range []asn1.ObjectIdentifier{
+		CAPv1GsmSSFToGsmSCF, CAPv2GsmSSFToGsmSCF, CAPv2AssistHandoffToGsmSCF, CAPv2GsmSRFToGsmSCF,
+		CAPv3GsmSSFToGsmSCF, CAPv3AssistHandoffToGsmSCF, CAPv3GsmSRFToGsmSCF, CAPv3GprsSSFToGsmSCF,
+		CAPv3GsmSCFToGprsSSF, CAPv3SMS, CAPv4GsmSSFToGsmSCF, CAPv4AssistHandoffToGsmSCF,
+		CAPv4GsmSCFToGsmSSFGeneric, CAPv4GsmSRFToGsmSCF, CAPv4SMS,
+	} {
+		tcap.RegisterApplicationContext(oid, contexts[oid.String()].name)
+		Registry.RegisterContext(oid, contexts[oid.String()].ops...)
+	}
+	tcap.DefaultOperationRegistry.Include(Registry)
+}
+
+var table = &param.Table{
+	Protocol:   protocol,
+	Operations: operations,
+}
+
+// Registry holds the CAP operations and application contexts supported by this
+// package, which is included in tcap.DefaultOperationRegistry on init.
+var Registry = table.Registry()
+
+// OperationName returns the name of operation in string.
+func OperationName(opCode uint8) string {
+	return table.OperationName(opCode)
+}
+
+// Operations returns the Operation Codes supported by this package in the application context.
+func Operations(acn asn1.ObjectIdentifier) []uint8 {
+	return contexts[acn.String()].ops
+}
+
+// NewArgument returns an empty argument of the operation.
+//
+// It returns nil without error for the operations that have no argument, such as Continue.
+func NewArgument(opCode uint8) (Parameter, error) {
+	return table.NewArgument(opCode)
+}
+
+// MarshalParameter returns the Parameter as an IE to be set in tcap.Component.
+func MarshalParameter(p Parameter) (*tcap.IE, error) {
+	return param.Marshal(p)
+}
+
+// UnmarshalParameter sets the values retrieved from the IE in tcap.Component in p.
+func UnmarshalParameter(ie *tcap.IE, p Parameter) error {
+	return table.Unmarshal(ie, p)
+}
+
+// NewInvoke returns a new Invoke Component with the argument of the operation.
+//
+// arg can be nil for the operations without argument.
+func NewInvoke(invID int, opCode uint8, arg Parameter) (*tcap.Component, error) {
+	return param.NewInvoke(invID, opCode, arg)
+}
+
+// ParseArgument parses the Parameter of Invoke Component as the argument of
+// the operation in the application context given.
+//
+// It returns nil without error for the operations that have no argument.
+func ParseArgument(acn asn1.ObjectIdentifier, c *tcap.Component) (Parameter, error) {
+	if !slices.Contains(Operations(acn), c.OpCode()) {
+		return nil, protocol.Unsupported(acn.String(), c.OpCode())
+	}
+	return table.ParseArgument(c)
+}
@@ -0,0 +1,200 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel_test
+
+import (
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/camel"
+	"github.com/wmnsk/go-tcap/gsmmap"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+var testcases = []struct {
+	description string
+	opCode      uint8
+	arg         camel.Parameter
+}{
+	{
+		description: "initialDP",
+		opCode:      camel.InitialDP,
+		arg: &camel.InitialDPArg{
+			ServiceKey:            100,
+			CallingPartyNumber:    []byte{0x04, 0x13, 0x18, 0x09, 0x21, 0x43, 0x65},
+			CallingPartysCategory: []byte{0x0a},
+			BearerCapability:      []byte{0x80, 0x90, 0xa3},
+			EventTypeBCSM:         intPtr(camel.CollectedInfo),
+			IMSI:                  "001010123456789",
+			CallReferenceNumber:   []byte{0x01, 0x02, 0x03, 0x04},
+			MSCAddress:            gsmmap.NewAddressString("819012345678"),
+			CalledPartyBCDNumber:  []byte{0x81, 0x10, 0x32, 0x54},
+			TimeAndTimezone:       []byte{0x02, 0x62, 0x01, 0x81, 0x21, 0x43, 0x00, 0x23},
+		},
+	}, {
+		description: "connect",
+		opCode:      camel.Connect,
+		arg: &camel.ConnectArg{
+			DestinationRoutingAddress: [][]byte{{0x84, 0x10, 0x18, 0x09, 0x21, 0x43}},
+			SuppressionOfAnnouncement: true,
+		},
+	}, {
+		description: "releaseCall",
+		opCode:      camel.ReleaseCall,
+		arg:         camel.NewReleaseCallArg(16),
+	}, {
+		description: "requestReportBCSMEvent",
+		opCode:      camel.RequestReportBCSMEvent,
+		arg: &camel.RequestReportBCSMEventArg{
+			BCSMEvents: []*camel.BCSMEvent{
+				{EventTypeBCSM: camel.OAnswer, MonitorMode: camel.MonitorModeNotifyAndContinue, LegID: camel.NewSendingSideID(camel.LegType2)},
+				{EventTypeBCSM: camel.ODisconnect, MonitorMode: camel.MonitorModeInterrupted, LegID: camel.NewSendingSideID(camel.LegType1)},
+				{EventTypeBCSM: camel.ONoAnswer, MonitorMode: camel.MonitorModeInterrupted, ApplicationTimer: intPtr(30)},
+			},
+		},
+	}, {
+		description: "eventReportBCSM",
+		opCode:      camel.EventReportBCSM,
+		arg: &camel.EventReportBCSMArg{
+			EventTypeBCSM: camel.ODisconnect,
+			LegID:         camel.NewReceivingSideID(camel.LegType1),
+			MessageType:   intPtr(camel.MessageTypeRequest),
+		},
+	}, {
+		description: "applyCharging",
+		opCode:      camel.ApplyCharging,
+		arg: &camel.ApplyChargingArg{
+			MaxCallPeriodDuration:     600,
+			ReleaseIfDurationExceeded: true,
+			PartyToCharge:             camel.NewSendingSideID(camel.LegType1),
+		},
+	}, {
+		description: "applyChargingReport",
+		opCode:      camel.ApplyChargingReport,
+		arg: &camel.ApplyChargingReportArg{
+			PartyToCharge:        camel.NewReceivingSideID(camel.LegType1),
+			TimeIfNoTariffSwitch: intPtr(1200),
+			LegActive:            true,
+		},
+	}, {
+		description: "initialDPSMS",
+		opCode:      camel.InitialDPSMS,
+		arg: &camel.InitialDPSMSArg{
+			ServiceKey:                  1,
+			DestinationSubscriberNumber: []byte{0x91, 0x10, 0x32, 0x54},
+			CallingPartyNumber:          gsmmap.NewAddressString("819012345678"),
+			EventTypeSMS:                intPtr(camel.SMSCollectedInfo),
+			IMSI:                        "001010123456789",
+			SMSCAddress:                 gsmmap.NewAddressString("819000000003"),
+		},
+	}, {
+		description: "connectSMS",
+		opCode:      camel.ConnectSMS,
+		arg: &camel.ConnectSMSArg{
+			DestinationSubscriberNumber: []byte{0x91, 0x10, 0x32, 0x54},
+		},
+	}, {
+		description: "releaseSMS",
+		opCode:      camel.ReleaseSMS,
+		arg:         &camel.ReleaseSMSArg{RPCause: 21},
+	}, {
+		description: "initialDPGPRS",
+		opCode:      camel.InitialDPGPRS,
+		arg: &camel.InitialDPGPRSArg{
+			ServiceKey:      2,
+			GPRSEventType:   camel.PDPContextEstablishment,
+			MSISDN:          gsmmap.NewAddressString("819012345678"),
+			IMSI:            "001010123456789",
+			AccessPointName: []byte{0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x65, 0x74},
+			ChargingID:      []byte{0x00, 0x00, 0x00, 0x01},
+		},
+	},
+}
+
+func TestParameters(t *testing.T) {
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			ie, err := camel.MarshalParameter(c.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := camel.NewArgument(c.opCode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := camel.UnmarshalParameter(ie, got); err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", got, c.arg)
+		})
+	}
+}
+
+func TestParseArgument(t *testing.T) {
+	c, err := camel.NewInvoke(1, camel.InitialDP, &camel.InitialDPArg{ServiceKey: 100, IMSI: "001010123456789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	comps, err := tcap.ParseComponents(append([]byte{0x6c, uint8(len(b))}, b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := camel.ParseArgument(camel.CAPv2GsmSSFToGsmSCF, comps.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", arg, &camel.InitialDPArg{ServiceKey: 100, IMSI: "001010123456789"})
+
+	if _, err := camel.ParseArgument(camel.CAPv3SMS, comps.Component[0]); err == nil {
+		t.Error("expected error for unsupported operation in the context")
+	}
+}
+
+func TestApplicationContext(t *testing.T) {
+	d := tcap.NewDialogue(1, 1, tcap.NewAARQWithOID(1, camel.CAPv2GsmSSFToGsmSCF), nil)
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := tcap.ParseDialogue(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", parsed.ApplicationContextOID(), camel.CAPv2GsmSSFToGsmSCF)
+	verify.Values(t, "", parsed.Context(), "CAP-v2-gsmSSF-to-gsmSCF-AC")
+}
+
+func TestLongParameter(t *testing.T) {
+	arg := &camel.InitialDPArg{ServiceKey: 100, CalledPartyNumber: make([]byte, 200)}
+	c, err := camel.NewInvoke(1, camel.InitialDP, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := (&tcap.TCAP{Transaction: tcap.NewBegin(0x11111111, nil), Components: tcap.NewComponents(c)}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := camel.ParseArgument(camel.CAPv2GsmSSFToGsmSCF, msg.Components.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
@@ -0,0 +1,191 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// ApplyChargingArg represents ApplyChargingArg with timeDurationCharging
+// in aChBillingChargingCharacteristics.
+//
+// MaxCallPeriodDuration is in 100 milliseconds and TariffSwitchInterval is in
+// seconds, as defined in 3GPP TS 29.078.
+type ApplyChargingArg struct {
+	MaxCallPeriodDuration     int
+	ReleaseIfDurationExceeded bool
+	TariffSwitchInterval      *int
+	PartyToCharge             *LegID
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingArg.
+func (a *ApplyChargingArg) MarshalBinary() ([]byte, error) {
+	tdc := appendInteger(nil, 0, a.MaxCallPeriodDuration)
+	if a.ReleaseIfDurationExceeded {
+		tdc = appendBoolean(tdc, 1, true)
+	}
+	if a.TariffSwitchInterval != nil {
+		tdc = appendInteger(tdc, 2, *a.TariffSwitchInterval)
+	}
+
+	v := appendOctets(nil, 0, param.Sequence(0, tdc))
+	if a.PartyToCharge != nil {
+		v = append(v, param.Sequence(2, a.PartyToCharge.marshal())...)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingArg.
+func (a *ApplyChargingArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ApplyChargingArg")
+	if err != nil {
+		return err
+	}
+
+	hasCharacteristics := false
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			tdc, err := protocol.ParseSequence(e.Value, "aChBillingChargingCharacteristics")
+			if err != nil {
+				return err
+			}
+			for _, t := range tdc {
+				switch t.Tag {
+				case 0:
+					if a.MaxCallPeriodDuration, err = param.DecodeInteger(t); err != nil {
+						return err
+					}
+				case 1:
+					if a.ReleaseIfDurationExceeded, err = ber.DecodeBoolean(t.Value); err != nil {
+						return err
+					}
+				case 2:
+					if a.TariffSwitchInterval, err = param.DecodeIntegerPtr(t); err != nil {
+						return err
+					}
+				}
+			}
+			hasCharacteristics = true
+		case 2:
+			if a.PartyToCharge, err = parseLegID(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !hasCharacteristics {
+		return protocol.Missing("aChBillingChargingCharacteristics")
+	}
+	return nil
+}
+
+// ApplyChargingReportArg represents ApplyChargingReportArg with timeDurationChargingResult
+// in CAMEL-CallResult.
+//
+// Either of TimeIfNoTariffSwitch or TimeSinceTariffSwitch should be set. The
+// times are in 100 milliseconds, except TariffSwitchInterval in seconds.
+type ApplyChargingReportArg struct {
+	PartyToCharge              *LegID
+	TimeIfNoTariffSwitch       *int
+	TimeSinceTariffSwitch      *int
+	TariffSwitchInterval       *int
+	LegActive                  bool
+	CallLegReleasedAtTCPExpiry bool
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) MarshalBinary() ([]byte, error) {
+	if a.PartyToCharge == nil {
+		return nil, protocol.Missing("partyToCharge")
+	}
+
+	var ti []byte
+	switch {
+	case a.TimeIfNoTariffSwitch != nil:
+		ti = appendInteger(nil, 0, *a.TimeIfNoTariffSwitch)
+	case a.TimeSinceTariffSwitch != nil:
+		ts := appendInteger(nil, 0, *a.TimeSinceTariffSwitch)
+		if a.TariffSwitchInterval != nil {
+			ts = appendInteger(ts, 1, *a.TariffSwitchInterval)
+		}
+		ti = param.Sequence(1, ts)
+	default:
+		return nil, protocol.Missing("timeInformation")
+	}
+
+	v := param.Sequence(0, a.PartyToCharge.marshal())
+	v = append(v, param.Sequence(1, ti)...)
+	v = appendBoolean(v, 2, a.LegActive)
+	if a.CallLegReleasedAtTCPExpiry {
+		v = appendNull(v, 3)
+	}
+	return param.OctetString(param.Sequence(0, v)), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) UnmarshalBinary(b []byte) error {
+	result, err := protocol.ParseOctetString(b, "ApplyChargingReportArg")
+	if err != nil {
+		return err
+	}
+	elems, err := protocol.ParseSequence(result, "timeDurationChargingResult")
+	if err != nil {
+		return err
+	}
+
+	a.LegActive = true
+	for _, e := range elems {
+		switch e.Tag {
+		case 0:
+			if a.PartyToCharge, err = parseLegID(e.Value); err != nil {
+				return err
+			}
+		case 1:
+			ti, err := ber.ParseElement(e.Value)
+			if err != nil {
+				return err
+			}
+			switch ti.Tag {
+			case 0:
+				if a.TimeIfNoTariffSwitch, err = param.DecodeIntegerPtr(ti); err != nil {
+					return err
+				}
+			case 1:
+				ts, err := ti.Children()
+				if err != nil {
+					return err
+				}
+				for _, t := range ts {
+					switch t.Tag {
+					case 0:
+						if a.TimeSinceTariffSwitch, err = param.DecodeIntegerPtr(t); err != nil {
+							return err
+						}
+					case 1:
+						if a.TariffSwitchInterval, err = param.DecodeIntegerPtr(t); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		case 2:
+			if a.LegActive, err = ber.DecodeBoolean(e.Value); err != nil {
+				return err
+			}
+		case 3:
+			a.CallLegReleasedAtTCPExpiry = true
+		}
+	}
+
+	if a.PartyToCharge == nil {
+		return protocol.Missing("partyToCharge")
+	}
+	return nil
+}
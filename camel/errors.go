@@ -0,0 +1,20 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel
+
+import "github.com/wmnsk/go-tcap/internal/param"
+
+// protocol is the prefix of the errors of this package.
+const protocol param.Protocol = "camel"
+
+// InvalidParameterError indicates that the parameter is malformed.
+type InvalidParameterError = param.InvalidParameterError
+
+// MissingParameterError indicates that a mandatory parameter is missing.
+type MissingParameterError = param.MissingParameterError
+
+// UnsupportedOperationError indicates that the operation is not supported
+// in the application context.
+type UnsupportedOperationError = param.UnsupportedOperationError
@@ -0,0 +1,112 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// GPRS Event Type definitions.
+const (
+	GPRSAttach                 int = 1
+	GPRSAttachChangeOfPosition int = 2
+	GPRSDetached               int = 3
+	PDPContextEstablishment    int = 11
+	PDPContextEstablishmentAck int = 12
+	GPRSDisconnect             int = 13
+	PDPContextChangeOfPosition int = 14
+)
+
+// InitialDPGPRSArg represents InitialDPGPRSArg.
+//
+// The optional parameters not listed in this struct are ignored on decoding.
+type InitialDPGPRSArg struct {
+	ServiceKey           int
+	GPRSEventType        int
+	MSISDN               *gsmmap.AddressString
+	IMSI                 string
+	TimeAndTimezone      []byte
+	AccessPointName      []byte
+	RouteingAreaIdentity []byte
+	ChargingID           []byte
+	GGSNAddress          []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InitialDPGPRSArg.
+func (i *InitialDPGPRSArg) MarshalBinary() ([]byte, error) {
+	if i.MSISDN == nil {
+		return nil, protocol.Missing("mSISDN")
+	}
+	if i.IMSI == "" {
+		return nil, protocol.Missing("iMSI")
+	}
+
+	v := appendInteger(nil, 0, i.ServiceKey)
+	v = appendInteger(v, 1, i.GPRSEventType)
+	v, err := appendAddress(v, 2, i.MSISDN)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = appendTBCD(v, 3, i.IMSI); err != nil {
+		return nil, err
+	}
+	v = appendOctets(v, 4, i.TimeAndTimezone)
+	v = appendOctets(v, 8, i.AccessPointName)
+	v = appendOctets(v, 9, i.RouteingAreaIdentity)
+	v = appendOctets(v, 10, i.ChargingID)
+	v = appendOctets(v, 15, i.GGSNAddress)
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InitialDPGPRSArg.
+func (i *InitialDPGPRSArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "InitialDPGPRSArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if i.ServiceKey, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+		case 1:
+			if i.GPRSEventType, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+		case 2:
+			if i.MSISDN, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 3:
+			i.IMSI = gsmmap.DecodeTBCD(e.Value)
+		case 4:
+			i.TimeAndTimezone = e.Value
+		case 8:
+			i.AccessPointName = e.Value
+		case 9:
+			i.RouteingAreaIdentity = e.Value
+		case 10:
+			i.ChargingID = e.Value
+		case 15:
+			i.GGSNAddress = e.Value
+		}
+	}
+
+	if i.MSISDN == nil {
+		return protocol.Missing("mSISDN")
+	}
+	if i.IMSI == "" {
+		return protocol.Missing("iMSI")
+	}
+	return nil
+}
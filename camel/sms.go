@@ -0,0 +1,204 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package camel
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Event Type SMS definitions.
+const (
+	SMSCollectedInfo     int = 1
+	OSMSFailure          int = 2
+	OSMSSubmission       int = 3
+	SMSDeliveryRequested int = 11
+	TSMSFailure          int = 12
+	TSMSDelivery         int = 13
+)
+
+// InitialDPSMSArg represents InitialDPSMSArg.
+type InitialDPSMSArg struct {
+	ServiceKey                  int
+	DestinationSubscriberNumber []byte
+	CallingPartyNumber          *gsmmap.AddressString
+	EventTypeSMS                *int
+	IMSI                        string
+	SMSCAddress                 *gsmmap.AddressString
+	TimeAndTimezone             []byte
+	TPShortMessageSpecificInfo  []byte
+	TPProtocolIdentifier        []byte
+	TPDataCodingScheme          []byte
+	MSCAddress                  *gsmmap.AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from an InitialDPSMSArg.
+func (i *InitialDPSMSArg) MarshalBinary() ([]byte, error) {
+	var err error
+	v := appendInteger(nil, 0, i.ServiceKey)
+	v = appendOctets(v, 1, i.DestinationSubscriberNumber)
+	if i.CallingPartyNumber != nil {
+		if v, err = appendAddress(v, 2, i.CallingPartyNumber); err != nil {
+			return nil, err
+		}
+	}
+	if i.EventTypeSMS != nil {
+		v = appendInteger(v, 3, *i.EventTypeSMS)
+	}
+	if i.IMSI != "" {
+		if v, err = appendTBCD(v, 4, i.IMSI); err != nil {
+			return nil, err
+		}
+	}
+	if i.SMSCAddress != nil {
+		if v, err = appendAddress(v, 7, i.SMSCAddress); err != nil {
+			return nil, err
+		}
+	}
+	v = appendOctets(v, 8, i.TimeAndTimezone)
+	v = appendOctets(v, 9, i.TPShortMessageSpecificInfo)
+	v = appendOctets(v, 10, i.TPProtocolIdentifier)
+	v = appendOctets(v, 11, i.TPDataCodingScheme)
+	if i.MSCAddress != nil {
+		if v, err = appendAddress(v, 15, i.MSCAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InitialDPSMSArg.
+func (i *InitialDPSMSArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "InitialDPSMSArg")
+	if err != nil {
+		return err
+	}
+
+	hasServiceKey := false
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if i.ServiceKey, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+			hasServiceKey = true
+		case 1:
+			i.DestinationSubscriberNumber = e.Value
+		case 2:
+			if i.CallingPartyNumber, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 3:
+			if i.EventTypeSMS, err = param.DecodeIntegerPtr(e); err != nil {
+				return err
+			}
+		case 4:
+			i.IMSI = gsmmap.DecodeTBCD(e.Value)
+		case 7:
+			if i.SMSCAddress, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 8:
+			i.TimeAndTimezone = e.Value
+		case 9:
+			i.TPShortMessageSpecificInfo = e.Value
+		case 10:
+			i.TPProtocolIdentifier = e.Value
+		case 11:
+			i.TPDataCodingScheme = e.Value
+		case 15:
+			if i.MSCAddress, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !hasServiceKey {
+		return protocol.Missing("serviceKey")
+	}
+	return nil
+}
+
+// ConnectSMSArg represents ConnectSMSArg.
+type ConnectSMSArg struct {
+	CallingPartysNumber         *gsmmap.AddressString
+	DestinationSubscriberNumber []byte
+	SMSCAddress                 *gsmmap.AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from a ConnectSMSArg.
+func (c *ConnectSMSArg) MarshalBinary() ([]byte, error) {
+	var (
+		v   []byte
+		err error
+	)
+	if c.CallingPartysNumber != nil {
+		if v, err = appendAddress(v, 0, c.CallingPartysNumber); err != nil {
+			return nil, err
+		}
+	}
+	v = appendOctets(v, 1, c.DestinationSubscriberNumber)
+	if c.SMSCAddress != nil {
+		if v, err = appendAddress(v, 2, c.SMSCAddress); err != nil {
+			return nil, err
+		}
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ConnectSMSArg.
+func (c *ConnectSMSArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ConnectSMSArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if c.CallingPartysNumber, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 1:
+			c.DestinationSubscriberNumber = e.Value
+		case 2:
+			if c.SMSCAddress, err = gsmmap.ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReleaseSMSArg represents ReleaseSMSArg, which is an RP-Cause.
+type ReleaseSMSArg struct {
+	RPCause uint8
+}
+
+// MarshalBinary returns the byte sequence generated from a ReleaseSMSArg.
+func (r *ReleaseSMSArg) MarshalBinary() ([]byte, error) {
+	return param.OctetString([]byte{r.RPCause}), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ReleaseSMSArg.
+func (r *ReleaseSMSArg) UnmarshalBinary(b []byte) error {
+	v, err := protocol.ParseOctetString(b, "ReleaseSMSArg")
+	if err != nil {
+		return err
+	}
+	if len(v) != 1 {
+		return protocol.Invalid("rPCause")
+	}
+	r.RPCause = v[0]
+	return nil
+}
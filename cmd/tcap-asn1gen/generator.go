@@ -0,0 +1,769 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+type generator struct {
+	types    map[string]*asnType
+	values   map[string]int64
+	reserved map[string]bool
+	emitted  map[string]bool
+
+	decls []string
+	inits []string
+	warn  func(format string, args ...interface{})
+}
+
+func newGenerator(mods []*module, warn func(format string, args ...interface{})) *generator {
+	g := &generator{
+		types:    map[string]*asnType{},
+		values:   map[string]int64{},
+		reserved: map[string]bool{},
+		emitted:  map[string]bool{},
+		warn:     warn,
+	}
+
+	for _, m := range mods {
+		normalize(m)
+		for _, a := range m.types {
+			if _, ok := g.types[a.name]; ok {
+				warn("duplicate type %s in %s is ignored", a.name, m.name)
+				continue
+			}
+			g.types[a.name] = a.typ
+			g.reserved[goName(a.name)] = true
+		}
+		for _, v := range m.values {
+			g.values[v.name] = v.value
+		}
+	}
+	return g
+}
+
+// normalize applies the tagging mode of the module to the types in it.
+func normalize(m *module) {
+	var walk func(t *asnType)
+	walk = func(t *asnType) {
+		if t == nil {
+			return
+		}
+		if m.automatic && (t.kind == kindSequence || t.kind == kindSet || t.kind == kindChoice) {
+			tagged := false
+			for _, c := range t.components {
+				if c.typ.tag != nil {
+					tagged = true
+				}
+			}
+			if !tagged {
+				n := 0
+				for _, c := range t.components {
+					if c.componentsOf {
+						continue
+					}
+					c.typ.tag = &tag{class: classContextSpecific, number: n, mode: tagImplicit}
+					n++
+				}
+			}
+		}
+		if t.tag != nil && t.tag.mode == tagDefault {
+			t.tag.mode = m.tagDefault
+		}
+		for _, c := range t.components {
+			walk(c.typ)
+		}
+		walk(t.elem)
+	}
+
+	for _, a := range m.types {
+		walk(a.typ)
+	}
+	for _, op := range m.operations {
+		walk(op.arg)
+		walk(op.res)
+	}
+	for _, e := range m.errors {
+		walk(e.param)
+	}
+}
+
+// goName converts the ASN.1 identifier into the exported Go identifier.
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "-") {
+		if part == "" {
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// unique returns the name not used by the other types.
+func (g *generator) unique(name string) string {
+	for g.reserved[name] || g.emitted[name] {
+		name += "Type"
+	}
+	return name
+}
+
+func (g *generator) addDecl(format string, args ...interface{}) {
+	g.decls = append(g.decls, fmt.Sprintf(format, args...))
+}
+
+// resolve follows the references and returns the type actually used, or nil
+// if it cannot be resolved.
+func (g *generator) resolve(t *asnType) *asnType {
+	for i := 0; t != nil && i < 32; i++ {
+		if t.kind != kindReference {
+			return t
+		}
+		t = g.types[t.ref]
+	}
+	return nil
+}
+
+// effectiveTag returns the outermost tag of the type following the references.
+func (g *generator) effectiveTag(t *asnType) *tag {
+	for i := 0; t != nil && i < 32; i++ {
+		if t.tag != nil {
+			return t.tag
+		}
+		if t.kind != kindReference {
+			return nil
+		}
+		t = g.types[t.ref]
+	}
+	return nil
+}
+
+// goType returns the Go type of t, generating the named type for the inline
+// constructed types with ctx.
+func (g *generator) goType(t *asnType, ctx string) string {
+	switch t.kind {
+	case kindReference:
+		if _, ok := g.types[t.ref]; !ok {
+			g.warn("unresolved type %s is represented as *ber.Value", t.ref)
+			return "*ber.Value"
+		}
+		return goName(t.ref)
+	case kindBoolean:
+		return "bool"
+	case kindInteger:
+		return "int"
+	case kindEnumerated:
+		name := g.unique(ctx)
+		g.emitEnum(name, "", t)
+		return name
+	case kindOctetString:
+		return "[]byte"
+	case kindBitString:
+		return "asn1.BitString"
+	case kindNull:
+		return "ber.Null"
+	case kindObjectIdentifier:
+		return "asn1.ObjectIdentifier"
+	case kindCharacterString:
+		return "string"
+	case kindSequence, kindSet:
+		name := g.unique(ctx)
+		g.emitStruct(name, "", t)
+		return name
+	case kindChoice:
+		name := g.unique(ctx)
+		g.emitChoice(name, "", t)
+		return name
+	case kindSequenceOf, kindSetOf:
+		if t.elem.tag != nil {
+			g.warn("tag of the element of %s is ignored", ctx)
+		}
+		return "[]" + g.goType(t.elem, ctx+"Item")
+	}
+	return "*ber.Value"
+}
+
+// params returns the options of struct tag for the type.
+func (g *generator) params(t *asnType, optional, field bool) string {
+	var opts []string
+	base := g.resolve(t)
+	if tg := g.effectiveTag(t); tg != nil {
+		switch tg.class {
+		case classUniversal:
+			opts = append(opts, "universal")
+		case classApplication:
+			opts = append(opts, "application")
+		case classPrivate:
+			opts = append(opts, "private")
+		}
+		opts = append(opts, fmt.Sprintf("tag:%d", tg.number))
+		if tg.mode == tagExplicit || base == nil || base.kind == kindAny || base.kind == kindChoice {
+			opts = append(opts, "explicit")
+		}
+	} else if base != nil && base.kind == kindCharacterString {
+		opts = append(opts, "universal", fmt.Sprintf("tag:%d", base.univTag))
+	}
+
+	null := false
+	if base != nil {
+		switch base.kind {
+		case kindEnumerated:
+			opts = append(opts, "enum")
+		case kindSet, kindSetOf:
+			opts = append(opts, "set")
+		case kindNull:
+			if field {
+				opts = append(opts, "null")
+				null = true
+			}
+		}
+	}
+	if optional && !null {
+		opts = append(opts, "optional")
+	}
+	return strings.Join(opts, ",")
+}
+
+// universalTag returns the tag number of the type without tag.
+func universalTag(t *asnType) int {
+	switch t.kind {
+	case kindBoolean:
+		return 1
+	case kindInteger:
+		return 2
+	case kindBitString:
+		return 3
+	case kindOctetString:
+		return 4
+	case kindNull:
+		return 5
+	case kindObjectIdentifier:
+		return 6
+	case kindEnumerated:
+		return 10
+	case kindSet, kindSetOf:
+		return 17
+	case kindCharacterString:
+		return t.univTag
+	}
+	return 16
+}
+
+func (g *generator) emitAssignment(name string, t *asnType) {
+	gn := goName(name)
+	if g.emitted[gn] {
+		return
+	}
+
+	switch t.kind {
+	case kindSequence, kindSet:
+		g.emitStruct(gn, name, t)
+	case kindChoice:
+		g.emitChoice(gn, name, t)
+	case kindEnumerated:
+		g.emitEnum(gn, name, t)
+	case kindSequenceOf, kindSetOf:
+		g.emitted[gn] = true
+		g.addDecl("// %s represents %s.\ntype %s %s", gn, name, gn, g.goType(t, gn))
+	case kindBoolean, kindInteger, kindOctetString, kindCharacterString, kindNull:
+		g.emitted[gn] = true
+		g.addDecl("// %s represents %s.\ntype %s %s", gn, name, gn, g.goType(t, gn))
+		if t.kind == kindInteger {
+			g.emitNamedNumbers(gn, gn, t.named)
+		}
+	default:
+		g.emitted[gn] = true
+		g.addDecl("// %s represents %s.\ntype %s = %s", gn, name, gn, g.goType(t, gn))
+		if t.kind == kindBitString {
+			g.emitNamedNumbers(gn, "", t.named)
+		}
+	}
+}
+
+func (g *generator) emitNamedNumbers(prefix, typ string, named []namedNumber) {
+	if len(named) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s values.\nconst (\n", prefix)
+	for _, n := range named {
+		if typ != "" {
+			fmt.Fprintf(&b, "\t%s%s %s = %d\n", prefix, goName(n.name), typ, n.value)
+		} else {
+			fmt.Fprintf(&b, "\t%s%s = %d\n", prefix, goName(n.name), n.value)
+		}
+	}
+	b.WriteString(")")
+	g.decls = append(g.decls, b.String())
+}
+
+func (g *generator) emitEnum(gn, name string, t *asnType) {
+	g.emitted[gn] = true
+	if name == "" {
+		name = "ENUMERATED"
+	}
+	g.addDecl("// %s represents %s.\ntype %s int", gn, name, gn)
+	g.emitNamedNumbers(gn, gn, t.named)
+}
+
+// components returns the components of SEQUENCE or SET, expanding COMPONENTS OF.
+func (g *generator) components(t *asnType, depth int) []*component {
+	var comps []*component
+	for _, c := range t.components {
+		if !c.componentsOf {
+			comps = append(comps, c)
+			continue
+		}
+		base := g.resolve(c.typ)
+		if base == nil || depth > 8 || (base.kind != kindSequence && base.kind != kindSet) {
+			g.warn("COMPONENTS OF %s cannot be expanded", c.typ.ref)
+			continue
+		}
+		comps = append(comps, g.components(base, depth+1)...)
+	}
+	return comps
+}
+
+func (g *generator) emitStruct(gn, name string, t *asnType) {
+	g.emitted[gn] = true
+	if name == "" {
+		name = "SEQUENCE"
+		if t.kind == kindSet {
+			name = "SET"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s represents %s.\ntype %s struct {\n", gn, name, gn)
+	fields := map[string]bool{}
+	for _, c := range g.components(t, 0) {
+		fname := goName(c.name)
+		for fields[fname] {
+			fname += "_"
+		}
+		fields[fname] = true
+
+		ftype := g.fieldType(c, gn+goName(c.name))
+		if p := g.params(c.typ, c.optional, true); p != "" {
+			fmt.Fprintf(&b, "\t%s %s `ber:\"%s\"`\n", fname, ftype, p)
+		} else {
+			fmt.Fprintf(&b, "\t%s %s\n", fname, ftype)
+		}
+	}
+	b.WriteString("}")
+	g.decls = append(g.decls, b.String())
+}
+
+func (g *generator) fieldType(c *component, ctx string) string {
+	base := g.resolve(c.typ)
+	if base != nil && base.kind == kindNull {
+		return "bool"
+	}
+
+	typ := g.goType(c.typ, ctx)
+	if c.optional && base != nil {
+		switch base.kind {
+		case kindBoolean, kindInteger, kindEnumerated, kindSequence, kindSet:
+			return "*" + typ
+		}
+	}
+	return typ
+}
+
+var classNames = []string{"ber.Universal", "ber.Application", "ber.ContextSpecific", "ber.Private"}
+
+func (g *generator) emitChoice(gn, name string, t *asnType) {
+	g.emitted[gn] = true
+	if name == "" {
+		name = "CHOICE"
+	}
+	method := "is" + gn
+	g.addDecl("// %s represents %s.\ntype %s interface {\n\t%s()\n}", gn, name, gn, method)
+
+	var alts []string
+	for _, c := range t.components {
+		an := g.unique(gn + goName(c.name))
+		base := g.resolve(c.typ)
+		if c.componentsOf || base == nil || base.kind == kindChoice || base.kind == kindAny {
+			g.warn("alternative %s of %s is not supported", c.name, gn)
+			continue
+		}
+
+		switch c.typ.kind {
+		case kindSequence, kindSet:
+			g.emitStruct(an, "", c.typ)
+		case kindEnumerated:
+			g.emitEnum(an, "", c.typ)
+		default:
+			typ := g.goType(c.typ, an)
+			g.emitted[an] = true
+			g.addDecl("// %s is the alternative %s of %s.\ntype %s %s", an, c.name, gn, an, typ)
+		}
+		g.addDecl("func (%s) %s() {}", an, method)
+
+		class, number, explicit := classUniversal, universalTag(base), false
+		if tg := g.effectiveTag(c.typ); tg != nil {
+			class, number, explicit = tg.class, tg.number, tg.mode == tagExplicit
+		}
+		mode := ""
+		if explicit {
+			mode = " Explicit: true,"
+		}
+		alts = append(alts, fmt.Sprintf("ber.Alternative{Class: %s, Tag: %d,%s Type: *new(%s)},",
+			classNames[class], number, mode, an))
+	}
+	g.inits = append(g.inits, fmt.Sprintf("ber.RegisterChoice((*%s)(nil),\n%s\n)", gn, strings.Join(alts, "\n")))
+}
+
+// registryEntry is the operation or error with the code resolved.
+type registryEntry struct {
+	name   string
+	code   int64
+	consts string
+}
+
+func (g *generator) code(name string, code int64, ref string, ok bool) (int64, bool) {
+	if !ok {
+		g.warn("%s has no local code and is ignored", name)
+		return 0, false
+	}
+	if ref != "" {
+		v, found := g.values[ref]
+		if !found {
+			g.warn("code %s of %s is unresolved and %s is ignored", ref, name, name)
+			return 0, false
+		}
+		code = v
+	}
+	if code < 0 || code > 0xff {
+		g.warn("code %d of %s is out of range and %s is ignored", code, name, name)
+		return 0, false
+	}
+	return code, true
+}
+
+// parameter returns the constructor and params of the argument, result or error parameter.
+func (g *generator) parameter(t *asnType, ctx string) string {
+	if t == nil {
+		return "parameter{}"
+	}
+	var typ string
+	if t.kind == kindReference {
+		typ = g.goType(t, ctx)
+	} else {
+		typ = g.goType(t, g.unique(ctx))
+	}
+	return fmt.Sprintf("parameter{func() interface{} { return new(%s) }, %q}", typ, g.params(t, false, false))
+}
+
+func (g *generator) emitRegistry(mods []*module) {
+	var (
+		ops, errs                     []registryEntry
+		argLns, resLns, errLns, regLn []string
+		opCodes                       = map[int64]bool{}
+		errCodes                      = map[int64]bool{}
+		errByName                     = map[string]string{}
+	)
+
+	for _, m := range mods {
+		for _, e := range m.errors {
+			code, ok := g.code(e.name, e.code, e.codeRef, e.hasCode)
+			if !ok {
+				continue
+			}
+			if errCodes[code] {
+				g.warn("error %s has duplicate code %d and is ignored", e.name, code)
+				continue
+			}
+			errCodes[code] = true
+			c := "Err" + goName(e.name)
+			errs = append(errs, registryEntry{e.name, code, c})
+			errByName[e.name] = c
+			errLns = append(errLns, fmt.Sprintf("%s: %s,", c, g.parameter(e.param, goName(e.name)+"Param")))
+		}
+	}
+
+	for _, m := range mods {
+		for _, op := range m.operations {
+			code, ok := g.code(op.name, op.code, op.codeRef, op.hasCode)
+			if !ok {
+				continue
+			}
+			if opCodes[code] {
+				g.warn("operation %s has duplicate code %d and is ignored", op.name, code)
+				continue
+			}
+			opCodes[code] = true
+			c := "Op" + goName(op.name)
+			ops = append(ops, registryEntry{op.name, code, c})
+			argLns = append(argLns, fmt.Sprintf("%s: %s,", c, g.parameter(op.arg, goName(op.name)+"Arg")))
+			resLns = append(resLns, fmt.Sprintf("%s: %s,", c, g.parameter(op.res, goName(op.name)+"Res")))
+
+			// the errors are left nil, i.e., any is allowed, unless all of
+			// them are resolved, not to reject the valid ones.
+			var codes []string
+			for _, e := range op.errors {
+				if ec, ok := errByName[e]; ok {
+					codes = append(codes, ec)
+				}
+			}
+			errList := ""
+			if len(codes) > 0 && len(codes) == len(op.errors) {
+				errList = ", Errors: []uint8{" + strings.Join(codes, ", ") + "}"
+			}
+			regLn = append(regLn, fmt.Sprintf("&tcap.Operation{Code: %s, Name: %q, Argument: arguments[%s].decoder(), Result: results[%s].decoder()%s},",
+				c, op.name, c, c, errList))
+		}
+	}
+	if len(ops) == 0 && len(errs) == 0 {
+		return
+	}
+
+	consts := func(title string, entries []registryEntry) string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "// %s Code definitions.\nconst (\n", title)
+		for _, e := range entries {
+			fmt.Fprintf(&b, "\t%s = %d\n", e.consts, e.code)
+		}
+		b.WriteString(")")
+		return b.String()
+	}
+
+	var reg strings.Builder
+	reg.WriteString("// Register registers the operations and errors in r.\nfunc Register(r *tcap.OperationRegistry) {\n")
+	if len(regLn) > 0 {
+		fmt.Fprintf(&reg, "r.RegisterOperation(\n%s\n)\n", strings.Join(regLn, "\n"))
+	}
+	for _, e := range errs {
+		fmt.Fprintf(&reg, "r.RegisterError(&tcap.ErrorDefinition{Code: %s, Name: %q, Parameter: errorParameters[%s].decoder()})\n",
+			e.consts, e.name, e.consts)
+	}
+	reg.WriteString("}")
+
+	g.decls = append(g.decls, consts("Operation", ops), consts("Error", errs), registryTypes,
+		"var arguments = map[uint8]parameter{\n"+strings.Join(argLns, "\n")+"\n}",
+		"var results = map[uint8]parameter{\n"+strings.Join(resLns, "\n")+"\n}",
+		"var errorParameters = map[uint8]parameter{\n"+strings.Join(errLns, "\n")+"\n}",
+		reg.String(), registryFuncs)
+	g.inits = append(g.inits, "Register(Registry)\ntcap.DefaultOperationRegistry.Include(Registry)")
+}
+
+const registryTypes = `type parameter struct {
+	newValue func() interface{}
+	params   string
+}
+
+func (p parameter) marshal(v interface{}) (*ber.Value, error) {
+	if p.newValue == nil {
+		return nil, fmt.Errorf("unexpected parameter: %T", v)
+	}
+	if want := p.newValue(); reflect.TypeOf(v) != reflect.TypeOf(want) {
+		return nil, fmt.Errorf("parameter must be %T, got %T", want, v)
+	}
+	return ber.MarshalValueWithParams(v, p.params)
+}
+
+func (p parameter) decoder() tcap.ParameterDecoder {
+	if p.newValue == nil {
+		return nil
+	}
+	return func(val *ber.Value) (interface{}, error) {
+		v := p.newValue()
+		if err := ber.UnmarshalValueWithParams(val, v, p.params); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}`
+
+const registryFuncs = `// Registry is the OperationRegistry with the operations and errors, which
+// is included in tcap.DefaultOperationRegistry on init.
+var Registry = tcap.NewOperationRegistry()
+
+// OperationName returns the name of operation in string.
+func OperationName(opCode uint8) string {
+	return Registry.OperationName(opCode)
+}
+
+// ErrorName returns the name of error in string.
+func ErrorName(errCode uint8) string {
+	return Registry.ErrorName(errCode)
+}
+
+// Errors returns the Error Codes that the operation may return, or nil if any
+// is allowed or the operation is unknown.
+func Errors(opCode uint8) []uint8 {
+	if op := Registry.Operation(opCode); op != nil {
+		return op.Errors
+	}
+	return nil
+}
+
+// NewArgument returns a pointer to an empty argument of the operation, or nil
+// if the operation has no argument.
+func NewArgument(opCode uint8) interface{} {
+	if f := arguments[opCode].newValue; f != nil {
+		return f()
+	}
+	return nil
+}
+
+// NewResult returns a pointer to an empty result of the operation, or nil
+// if the operation has no result.
+func NewResult(opCode uint8) interface{} {
+	if f := results[opCode].newValue; f != nil {
+		return f()
+	}
+	return nil
+}
+
+// NewInvoke returns a new Invoke Component with the argument of the operation.
+//
+// arg must be the pointer of the same type as NewArgument returns, or nil.
+func NewInvoke(invID int, opCode uint8, arg interface{}) (*tcap.Component, error) {
+	p, ok := arguments[opCode]
+	if !ok {
+		return nil, fmt.Errorf("unknown operation: %d", opCode)
+	}
+	return withParameter(tcap.NewInvoke(invID, -1, int(opCode), true, nil), p, arg)
+}
+
+// NewReturnResult returns a new Return Result (Last) Component with the result of the operation.
+//
+// res must be the pointer of the same type as NewResult returns, or nil.
+func NewReturnResult(invID int, opCode uint8, res interface{}) (*tcap.Component, error) {
+	p, ok := results[opCode]
+	if !ok {
+		return nil, fmt.Errorf("unknown operation: %d", opCode)
+	}
+	return withParameter(tcap.NewReturnResult(invID, int(opCode), true, true, nil), p, res)
+}
+
+// NewReturnError returns a new Return Error Component with the parameter of the error.
+func NewReturnError(invID int, errCode uint8, param interface{}) (*tcap.Component, error) {
+	p, ok := errorParameters[errCode]
+	if !ok {
+		return nil, fmt.Errorf("unknown error: %d", errCode)
+	}
+	return withParameter(tcap.NewReturnError(invID, int(errCode), true, nil), p, param)
+}
+
+func withParameter(c *tcap.Component, p parameter, v interface{}) (*tcap.Component, error) {
+	if v == nil {
+		return c, nil
+	}
+	val, err := p.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetParameter(val); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseArgument parses the Parameter of Invoke Component as the argument of the operation.
+func ParseArgument(c *tcap.Component) (interface{}, error) {
+	if Registry.Operation(c.OpCode()) == nil {
+		return nil, fmt.Errorf("unknown operation: %d", c.OpCode())
+	}
+	return Registry.Decode(c, nil)
+}
+
+// ParseResult parses the Parameter of Return Result Component as the result of the operation.
+func ParseResult(c *tcap.Component) (interface{}, error) {
+	if Registry.Operation(c.OpCode()) == nil {
+		return nil, fmt.Errorf("unknown operation: %d", c.OpCode())
+	}
+	return Registry.Decode(c, nil)
+}
+
+// ParseErrorParameter parses the Parameter of Return Error Component as the parameter of the error.
+func ParseErrorParameter(c *tcap.Component) (interface{}, error) {
+	if c.ErrorCode == nil || len(c.ErrorCode.Value) != 1 {
+		return nil, fmt.Errorf("invalid error code")
+	}
+	if Registry.Error(c.ErrorCode.Value[0]) == nil {
+		return nil, fmt.Errorf("unknown error: %d", c.ErrorCode.Value[0])
+	}
+	return Registry.Decode(c, nil)
+}`
+
+// generate returns the Go source generated from the modules.
+func generate(pkg string, mods []*module, warn func(format string, args ...interface{})) ([]byte, error) {
+	g := newGenerator(mods, warn)
+
+	var consts []string
+	for _, m := range mods {
+		for _, v := range m.values {
+			if n := goName(v.name); !g.reserved[n] {
+				g.reserved[n] = true
+				consts = append(consts, fmt.Sprintf("%s = %d", n, v.value))
+			}
+		}
+	}
+	if len(consts) > 0 {
+		g.decls = append(g.decls, "// Value definitions.\nconst (\n"+strings.Join(consts, "\n")+"\n)")
+	}
+
+	for _, m := range mods {
+		for _, a := range m.types {
+			if g.types[a.name] == a.typ {
+				g.emitAssignment(a.name, a.typ)
+			}
+		}
+	}
+	g.emitRegistry(mods)
+	if len(g.inits) > 0 {
+		g.decls = append(g.decls, "func init() {\n"+strings.Join(g.inits, "\n\n")+"\n}")
+	}
+
+	body := strings.Join(g.decls, "\n\n")
+	var std, ext []string
+	for _, imp := range []struct{ path, ident string }{
+		{"encoding/asn1", "asn1."},
+		{"fmt", "fmt."},
+		{"reflect", "reflect."},
+		{"github.com/wmnsk/go-tcap", "tcap."},
+		{"github.com/wmnsk/go-tcap/ber", "ber."},
+	} {
+		if !strings.Contains(body, imp.ident) {
+			continue
+		}
+		if strings.Contains(imp.path, ".") {
+			ext = append(ext, fmt.Sprintf("%q", imp.path))
+		} else {
+			std = append(std, fmt.Sprintf("%q", imp.path))
+		}
+	}
+	imports := strings.Join(std, "\n")
+	if len(std) > 0 && len(ext) > 0 {
+		imports += "\n\n"
+	}
+	imports += strings.Join(ext, "\n")
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by tcap-asn1gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if imports != "" {
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", imports)
+	}
+	b.WriteString(body)
+	b.WriteString("\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return b.Bytes(), fmt.Errorf("failed to format the generated code: %w", err)
+	}
+	return src, nil
+}
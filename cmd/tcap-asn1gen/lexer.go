@@ -0,0 +1,115 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	return fmt.Sprintf("%q at line %d", t.text, t.line)
+}
+
+// multi-character symbols, longest first.
+var symbols = []string{"::=", "...", "..", "[[", "]]"}
+
+// tokenize splits the ASN.1 source into tokens, dropping the comments.
+func tokenize(src string) ([]token, error) {
+	var (
+		toks []token
+		line = 1
+		r    = []rune(src)
+	)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			// comment ends with another "--" or the end of line.
+			i += 2
+			for i < len(r) && r[i] != '\n' {
+				if r[i] == '-' && i+1 < len(r) && r[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			end := strings.Index(string(r[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at line %d", line)
+			}
+			comment := []rune(string(r[i+2:])[:end])
+			line += strings.Count(string(comment), "\n")
+			i += 2 + len(comment) + 2
+		case unicode.IsLetter(c):
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) ||
+				(r[j] == '-' && j+1 < len(r) && r[j+1] != '-' && (unicode.IsLetter(r[j+1]) || unicode.IsDigit(r[j+1])))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, string(r[i:j]), line})
+			i = j
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			j := i + 1
+			for j < len(r) && unicode.IsDigit(r[j]) {
+				j++
+			}
+			toks = append(toks, token{tokNumber, string(r[i:j]), line})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(r) && r[j] != c {
+				if r[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated string at line %d", line)
+			}
+			j++
+			// binary or hexadecimal string such as '0101'B.
+			if c == '\'' && j < len(r) && (r[j] == 'B' || r[j] == 'H') {
+				j++
+			}
+			toks = append(toks, token{tokString, string(r[i:j]), line})
+			i = j
+		default:
+			sym := string(c)
+			for _, s := range symbols {
+				if strings.HasPrefix(string(r[i:min(i+len(s), len(r))]), s) {
+					sym = s
+					break
+				}
+			}
+			toks = append(toks, token{tokSymbol, sym, line})
+			i += len([]rune(sym))
+		}
+	}
+	return toks, nil
+}
@@ -0,0 +1,78 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcap-asn1gen generates Go types of TCAP user protocols from ASN.1 modules.
+
+The types are generated with the struct tags of ber package, and the operations
+and errors defined with OPERATION and ERROR (in both X.880 information object
+and the older macro notation) are registered with their codes, so that the
+arguments and results can be set into and retrieved from tcap.Component. The
+generated Register registers them in a tcap.OperationRegistry, and they are
+included in tcap.DefaultOperationRegistry on init.
+
+	tcap-asn1gen -pkg mymap -o mymap/types.go MAP-CommonDataTypes.asn MAP-Operations.asn
+
+Parameterized types, information object classes and the alternatives of CHOICE
+that are CHOICE or ANY are not supported; the types that cannot be resolved are
+represented as *ber.Value, which keeps the raw value as it is. Warnings about
+them are printed to the standard error.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		pkg = flag.String("pkg", "main", "Name of the package of the generated code.")
+		out = flag.String("o", "", "File to write the generated code to. Standard output is used if empty.")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file.asn...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var src strings.Builder
+	for _, f := range flag.Args() {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src.Write(b)
+		src.WriteString("\n")
+	}
+
+	mods, err := parseModules(src.String())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := generate(*pkg, mods, func(format string, args ...interface{}) {
+		log.Printf("warning: "+format, args...)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(code); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
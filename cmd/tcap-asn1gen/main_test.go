@@ -0,0 +1,127 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	goparser "go/parser"
+	gotoken "go/token"
+	"strings"
+	"testing"
+)
+
+const testModule = `
+Test-Module { itu-t 1 2 } DEFINITIONS IMPLICIT TAGS ::= BEGIN
+IMPORTS
+	ExtensionContainer FROM MAP-ExtensionDataTypes { itu-t 3 };
+
+maxNum INTEGER ::= 10
+
+TBCD-STRING ::= OCTET STRING
+IMSI ::= TBCD-STRING (SIZE (3..8))
+
+LegID ::= CHOICE {
+	sendingSideID   [0] OCTET STRING (SIZE(1)),
+	receivingSideID [1] OCTET STRING (SIZE(1))
+}
+
+Cause ::= ENUMERATED { busy(1), noReply(2), ... }
+
+Empty ::= NULL
+EmptyRes ::= SEQUENCE { ... }
+
+UpdateArg ::= SEQUENCE {
+	imsi   IMSI,
+	legID  [3] LegID OPTIONAL,
+	cause  [4] Cause OPTIONAL,
+	flag   [5] NULL OPTIONAL,
+	label  VisibleString OPTIONAL,
+	items  [7] SEQUENCE SIZE (1..maxNum) OF SEQUENCE { n INTEGER } OPTIONAL,
+	ext    [8] ExtensionContainer OPTIONAL,
+	...,
+	[[ extra [10] SET { a [0] INTEGER } OPTIONAL ]]
+}
+
+update OPERATION ::= {
+	ARGUMENT UpdateArg
+	ERRORS { systemFailure }
+	CODE local:2
+}
+
+systemFailure ERROR ::= { PARAMETER Cause CODE local:34 }
+
+old OPERATION
+	ARGUMENT arg SEQUENCE { x [0] INTEGER }
+	::= localValue 9
+
+END
+`
+
+func TestGenerate(t *testing.T) {
+	mods, err := parseModules(testModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	code, err := generate("test", mods, func(format string, args ...interface{}) {
+		warnings = append(warnings, format)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goparser.ParseFile(gotoken.NewFileSet(), "test.go", code, 0); err != nil {
+		t.Fatalf("generated code is invalid: %s\n%s", err, code)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1: %v", len(warnings), warnings)
+	}
+
+	for _, want := range []string{
+		"MaxNum = 10",
+		"type IMSI = TBCDSTRING",
+		"type TBCDSTRING []byte",
+		"type LegIDSendingSideID []byte",
+		"ber.Alternative{Class: ber.ContextSpecific, Tag: 1, Type: *new(LegIDReceivingSideID)}",
+		"CauseNoReply Cause = 2",
+		"type Empty ber.Null",
+		"type EmptyRes struct {\n}",
+		"LegID LegID                `ber:\"tag:3,explicit,optional\"`",
+		"Cause *Cause               `ber:\"tag:4,enum,optional\"`",
+		"Flag  bool                 `ber:\"tag:5,null\"`",
+		"Label string               `ber:\"universal,tag:26,optional\"`",
+		"Items []UpdateArgItemsItem `ber:\"tag:7,optional\"`",
+		"Ext   *ber.Value           `ber:\"tag:8,explicit,optional\"`",
+		"Extra *UpdateArgExtra      `ber:\"tag:10,set,optional\"`",
+		"OpUpdate = 2",
+		"OpOld    = 9",
+		"ErrSystemFailure = 34",
+		"var errorParameters = map[uint8]parameter{",
+		"func Register(r *tcap.OperationRegistry) {",
+		"Errors: []uint8{ErrSystemFailure}",
+		"tcap.DefaultOperationRegistry.Include(Registry)",
+		"X int `ber:\"tag:0\"`",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated code does not contain %q\n%s", want, code)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"updateLocation", "UpdateLocation"},
+		{"ISDN-AddressString", "ISDNAddressString"},
+		{"gsm-BearerCapability", "GsmBearerCapability"},
+		{"opcode-initialDP", "OpcodeInitialDP"},
+	}
+
+	for _, c := range cases {
+		if got := goName(c.in); got != c.out {
+			t.Errorf("goName(%q) = %q, want %q", c.in, got, c.out)
+		}
+	}
+}
@@ -0,0 +1,880 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind of ASN.1 types.
+const (
+	kindReference int = iota
+	kindBoolean
+	kindInteger
+	kindEnumerated
+	kindOctetString
+	kindBitString
+	kindNull
+	kindObjectIdentifier
+	kindCharacterString
+	kindSequence
+	kindSet
+	kindSequenceOf
+	kindSetOf
+	kindChoice
+	kindAny
+)
+
+// Tag Class definitions, the same as the ones in ber package.
+const (
+	classUniversal = iota
+	classApplication
+	classContextSpecific
+	classPrivate
+)
+
+// Tagging mode definitions.
+const (
+	tagDefault = iota
+	tagImplicit
+	tagExplicit
+)
+
+// universal tag numbers of the character string types.
+var characterStrings = map[string]int{
+	"UTF8String":      12,
+	"NumericString":   18,
+	"PrintableString": 19,
+	"TeletexString":   20,
+	"T61String":       20,
+	"IA5String":       22,
+	"GraphicString":   25,
+	"VisibleString":   26,
+	"ISO646String":    26,
+	"GeneralString":   27,
+	"BMPString":       30,
+}
+
+type tag struct {
+	class  int
+	number int
+	mode   int
+}
+
+type namedNumber struct {
+	name  string
+	value int64
+}
+
+type component struct {
+	name         string
+	typ          *asnType
+	optional     bool
+	componentsOf bool
+}
+
+type asnType struct {
+	kind       int
+	tag        *tag
+	ref        string
+	univTag    int
+	components []*component
+	elem       *asnType
+	named      []namedNumber
+}
+
+type module struct {
+	name       string
+	tagDefault int
+	automatic  bool
+	imports    map[string]string
+	types      []*typeAssignment
+	values     []*valueAssignment
+	operations []*operation
+	errors     []*errorDef
+}
+
+type typeAssignment struct {
+	name string
+	typ  *asnType
+}
+
+type valueAssignment struct {
+	name  string
+	value int64
+}
+
+type operation struct {
+	name     string
+	code     int64
+	codeRef  string
+	hasCode  bool
+	arg, res *asnType
+	errors   []string
+	linked   []string
+}
+
+type errorDef struct {
+	name    string
+	code    int64
+	codeRef string
+	hasCode bool
+	param   *asnType
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	mod  *module
+}
+
+func parseModules(src string) ([]*module, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks}
+	var mods []*module
+	for !p.eof() {
+		m, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, m)
+	}
+	return mods, nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *parser) peek(n int) token {
+	if p.pos+n >= len(p.toks) {
+		return token{kind: tokSymbol}
+	}
+	return p.toks[p.pos+n]
+}
+
+func (p *parser) is(texts ...string) bool {
+	for i, t := range texts {
+		if p.peek(i).text != t {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) next() token {
+	t := p.peek(0)
+	p.pos++
+	return t
+}
+
+func (p *parser) accept(texts ...string) bool {
+	if !p.is(texts...) {
+		return false
+	}
+	p.pos += len(texts)
+	return true
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("expected %q, got %v", text, t)
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("expected identifier, got %v", t)
+	}
+	return t.text, nil
+}
+
+// skipBalanced skips the tokens in the brackets starting at the current position.
+func (p *parser) skipBalanced() {
+	depth := 0
+	for !p.eof() {
+		switch p.next().text {
+		case "{", "(", "[", "[[":
+			depth++
+		case "}", ")", "]", "]]":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+func isUpper(s string) bool {
+	return s != "" && unicode.IsUpper([]rune(s)[0])
+}
+
+func isAllUpper(s string) bool {
+	return strings.ToUpper(s) == s && isUpper(s)
+}
+
+func (p *parser) parseModule() (*module, error) {
+	m := &module{imports: map[string]string{}}
+	p.mod = m
+
+	var err error
+	if m.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		p.skipBalanced()
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.accept("IMPLICIT", "TAGS"):
+		m.tagDefault = tagImplicit
+	case p.accept("EXPLICIT", "TAGS"):
+		m.tagDefault = tagExplicit
+	case p.accept("AUTOMATIC", "TAGS"):
+		m.tagDefault = tagImplicit
+		m.automatic = true
+	default:
+		m.tagDefault = tagExplicit
+	}
+	p.accept("EXTENSIBILITY", "IMPLIED")
+	if err := p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	if p.accept("EXPORTS") {
+		for !p.eof() && !p.accept(";") {
+			p.next()
+		}
+	}
+	if p.accept("IMPORTS") {
+		p.parseImports()
+	}
+
+	for !p.eof() && !p.is("END") {
+		start := p.pos
+		if err := p.parseAssignment(); err != nil {
+			return nil, fmt.Errorf("%s: %w", m.name, err)
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	if err := p.expect("END"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (p *parser) parseImports() {
+	var symbols []string
+	for !p.eof() && !p.accept(";") {
+		t := p.next()
+		switch {
+		case t.text == "FROM":
+			from := p.next().text
+			for _, s := range symbols {
+				p.mod.imports[s] = from
+			}
+			symbols = nil
+			// module identifier in the form of OID or value reference.
+			if p.is("{") {
+				p.skipBalanced()
+			} else if !isUpper(p.peek(0).text) && p.peek(1).text != "," && p.peek(1).text != "FROM" && p.peek(0).kind == tokIdent {
+				p.next()
+			}
+		case t.text == "{":
+			// parameterized reference such as "Foo{}".
+			p.pos--
+			p.skipBalanced()
+		case t.kind == tokIdent:
+			symbols = append(symbols, t.text)
+		}
+	}
+}
+
+// isAssignmentStart reports whether an assignment starts at the given position.
+func (p *parser) isAssignmentStart(i int) bool {
+	if i >= len(p.toks) || p.toks[i].kind != tokIdent || p.toks[i].text == "END" {
+		return false
+	}
+	if i+1 < len(p.toks) && p.toks[i+1].text == "::=" {
+		return true
+	}
+	if i+1 < len(p.toks) && p.toks[i+1].text == "{" {
+		// parameterized assignment.
+		depth := 0
+		for j := i + 1; j < len(p.toks); j++ {
+			switch p.toks[j].text {
+			case "{":
+				depth++
+			case "}":
+				depth--
+			}
+			if depth == 0 {
+				return j+1 < len(p.toks) && p.toks[j+1].text == "::="
+			}
+		}
+		return false
+	}
+	if isUpper(p.toks[i].text) {
+		return false
+	}
+	if i+1 < len(p.toks) && (p.toks[i+1].text == "OPERATION" || p.toks[i+1].text == "ERROR") {
+		return true
+	}
+	// value assignment with the type in a few tokens.
+	for j := i + 1; j < len(p.toks) && j < i+5; j++ {
+		switch t := p.toks[j]; {
+		case t.text == "::=":
+			return j > i+1
+		case t.kind == tokIdent && isUpper(t.text), t.text == "." || t.text == "&":
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// skipAssignment skips the tokens until the next assignment.
+func (p *parser) skipAssignment() {
+	depth := 0
+	for !p.eof() {
+		if depth == 0 && p.pos > 0 && p.isAssignmentStart(p.pos) {
+			return
+		}
+		if depth == 0 && p.is("END") {
+			return
+		}
+		switch p.next().text {
+		case "{", "(", "[", "[[":
+			depth++
+		case "}", ")", "]", "]]":
+			depth--
+		}
+	}
+}
+
+func (p *parser) parseAssignment() error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	// parameterized assignments are not supported.
+	if p.is("{") {
+		p.skipAssignment()
+		return nil
+	}
+
+	if isUpper(name) {
+		if !p.accept("::=") {
+			p.skipAssignment()
+			return nil
+		}
+		// information object classes and object sets are not supported.
+		if p.is("CLASS") || isAllUpper(name) && p.is("{") {
+			p.skipAssignment()
+			return nil
+		}
+
+		start := p.pos
+		t, err := p.parseType()
+		if err != nil {
+			p.pos = start
+			p.skipAssignment()
+			return nil
+		}
+		p.mod.types = append(p.mod.types, &typeAssignment{name: name, typ: t})
+		if !p.isAssignmentStart(p.pos) && !p.is("END") {
+			p.skipAssignment()
+		}
+		return nil
+	}
+
+	switch {
+	case p.is("OPERATION") && (p.is("OPERATION", "::=", "{") || !p.is("OPERATION", "::=")):
+		p.next()
+		return p.parseOperation(name)
+	case p.is("ERROR") && (p.is("ERROR", "::=", "{") || !p.is("ERROR", "::=")):
+		p.next()
+		return p.parseError(name)
+	}
+
+	// value assignment of integer or code, e.g., "maxNum INTEGER ::= 10" or
+	// "opcode-initialDP Code ::= local:0".
+	for i := 0; i < 4; i++ {
+		if p.peek(i).text != "::=" {
+			continue
+		}
+		p.pos += i + 1
+		if p.peek(0).kind == tokNumber || p.is("local", ":") || p.is("localValue") {
+			if v, ref, ok := p.parseCode(); ok && ref == "" {
+				p.mod.values = append(p.mod.values, &valueAssignment{name: name, value: v})
+			}
+		}
+		break
+	}
+	p.skipAssignment()
+	return nil
+}
+
+// parseCode parses the operation or error code in the form of "local:N", "localValue N" or "N".
+func (p *parser) parseCode() (code int64, ref string, ok bool) {
+	switch {
+	case p.accept("local", ":"), p.accept("localValue"):
+	case p.is("global"), p.is("globalValue"):
+		p.next()
+		p.accept(":")
+		if p.is("{") {
+			p.skipBalanced()
+		} else {
+			p.next()
+		}
+		return 0, "", false
+	}
+
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseInt(t.text, 10, 64)
+		return v, "", err == nil
+	case tokIdent:
+		return 0, t.text, true
+	}
+	return 0, "", false
+}
+
+// parseReferenceList parses the list of references such as "{ a | b }" or "{ a, b }".
+func (p *parser) parseReferenceList() []string {
+	if !p.is("{") {
+		p.next()
+		return nil
+	}
+
+	var refs []string
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		default:
+			if t.kind == tokIdent && depth == 1 && !isUpper(t.text) {
+				refs = append(refs, t.text)
+			}
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	return refs
+}
+
+// parseOperationType parses the type of ARGUMENT, RESULT and PARAMETER, which
+// may be preceded by the name of value in macro notation.
+func (p *parser) parseOperationType() (*asnType, error) {
+	if p.peek(0).kind == tokIdent && !isUpper(p.peek(0).text) {
+		p.next()
+	}
+	return p.parseType()
+}
+
+var operationKeywords = map[string]bool{
+	"ARGUMENT": true, "RESULT": true, "ERRORS": true, "LINKED": true, "CODE": true,
+	"PARAMETER": true, "RETURN": true, "ALWAYS": true, "OPTIONAL": true, "SYNCHRONOUS": true,
+	"IDEMPOTENT": true, "PRIORITY": true,
+}
+
+func (p *parser) parseOperationBody(op *operation, e *errorDef) error {
+	objectSyntax := p.accept("::=", "{")
+	for !p.eof() {
+		switch {
+		case objectSyntax && p.accept("}"):
+			return nil
+		case !objectSyntax && p.accept("::="):
+			code, ref, ok := p.parseCode()
+			if op != nil {
+				op.code, op.codeRef, op.hasCode = code, ref, ok
+			} else {
+				e.code, e.codeRef, e.hasCode = code, ref, ok
+			}
+			return nil
+		case p.accept("ARGUMENT"):
+			t, err := p.parseOperationType()
+			if err != nil {
+				return err
+			}
+			op.arg = t
+		case p.accept("RESULT"):
+			if p.is("FALSE") || p.is("TRUE") {
+				p.next()
+				continue
+			}
+			if p.peek(0).kind == tokIdent && (operationKeywords[p.peek(0).text] || p.peek(0).text == "::=") {
+				continue
+			}
+			t, err := p.parseOperationType()
+			if err != nil {
+				return err
+			}
+			if op != nil {
+				op.res = t
+			}
+		case p.accept("PARAMETER"):
+			t, err := p.parseOperationType()
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				e.param = t
+			}
+		case p.accept("ERRORS"):
+			errs := p.parseReferenceList()
+			if op != nil {
+				op.errors = errs
+			}
+		case p.accept("LINKED"):
+			linked := p.parseReferenceList()
+			if op != nil {
+				op.linked = linked
+			}
+		case p.accept("CODE"):
+			code, ref, ok := p.parseCode()
+			if op != nil {
+				op.code, op.codeRef, op.hasCode = code, ref, ok
+			} else {
+				e.code, e.codeRef, e.hasCode = code, ref, ok
+			}
+		case p.is("{"), p.is("("):
+			p.skipBalanced()
+		default:
+			if !objectSyntax && p.isAssignmentStart(p.pos) {
+				return nil
+			}
+			p.next()
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseOperation(name string) error {
+	op := &operation{name: name}
+	if err := p.parseOperationBody(op, nil); err != nil {
+		return err
+	}
+	p.mod.operations = append(p.mod.operations, op)
+	return nil
+}
+
+func (p *parser) parseError(name string) error {
+	e := &errorDef{name: name}
+	if err := p.parseOperationBody(nil, e); err != nil {
+		return err
+	}
+	p.mod.errors = append(p.mod.errors, e)
+	return nil
+}
+
+func (p *parser) parseTag() (*tag, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	t := &tag{class: classContextSpecific}
+	switch {
+	case p.accept("APPLICATION"):
+		t.class = classApplication
+	case p.accept("PRIVATE"):
+		t.class = classPrivate
+	case p.accept("UNIVERSAL"):
+		t.class = classUniversal
+	}
+
+	n := p.next()
+	switch n.kind {
+	case tokNumber:
+		v, err := strconv.Atoi(n.text)
+		if err != nil {
+			return nil, err
+		}
+		t.number = v
+	case tokIdent:
+		found := false
+		for _, v := range p.mod.values {
+			if v.name == n.text {
+				t.number, found = int(v.value), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown value in tag: %v", n)
+		}
+	default:
+		return nil, fmt.Errorf("invalid tag: %v", n)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.accept("IMPLICIT"):
+		t.mode = tagImplicit
+	case p.accept("EXPLICIT"):
+		t.mode = tagExplicit
+	}
+	return t, nil
+}
+
+// skipConstraints skips the constraints and the parameters following the type.
+func (p *parser) skipConstraints() {
+	for p.is("(") || p.is("{") {
+		p.skipBalanced()
+	}
+}
+
+func (p *parser) parseType() (*asnType, error) {
+	var tg *tag
+	if p.is("[") {
+		var err error
+		if tg, err = p.parseTag(); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := p.parseUntaggedType()
+	if err != nil {
+		return nil, err
+	}
+	if tg != nil {
+		if t.tag != nil {
+			// the type is tagged twice, e.g., [0] EXPLICIT [1] IMPLICIT X.
+			return &asnType{kind: kindAny, tag: tg}, nil
+		}
+		t.tag = tg
+	}
+	p.skipConstraints()
+	return t, nil
+}
+
+func (p *parser) parseUntaggedType() (*asnType, error) {
+	if p.is("[") {
+		return p.parseType()
+	}
+
+	t := p.next()
+	if t.kind != tokIdent {
+		return nil, fmt.Errorf("expected type, got %v", t)
+	}
+
+	switch t.text {
+	case "BOOLEAN":
+		return &asnType{kind: kindBoolean}, nil
+	case "INTEGER":
+		typ := &asnType{kind: kindInteger}
+		if p.is("{") {
+			typ.named = p.parseNamedNumbers()
+		}
+		return typ, nil
+	case "ENUMERATED":
+		return &asnType{kind: kindEnumerated, named: p.parseNamedNumbers()}, nil
+	case "NULL":
+		return &asnType{kind: kindNull}, nil
+	case "OCTET":
+		if err := p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		return &asnType{kind: kindOctetString}, nil
+	case "BIT":
+		if err := p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		typ := &asnType{kind: kindBitString}
+		if p.is("{") {
+			typ.named = p.parseNamedNumbers()
+		}
+		return typ, nil
+	case "OBJECT":
+		if err := p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		return &asnType{kind: kindObjectIdentifier}, nil
+	case "SEQUENCE", "SET":
+		return p.parseConstructed(t.text)
+	case "CHOICE":
+		comps, err := p.parseComponents()
+		if err != nil {
+			return nil, err
+		}
+		return &asnType{kind: kindChoice, components: comps}, nil
+	case "ANY":
+		if p.accept("DEFINED", "BY") {
+			p.next()
+		}
+		return &asnType{kind: kindAny}, nil
+	case "EXTERNAL", "EMBEDDED", "REAL", "GeneralizedTime", "UTCTime", "ObjectDescriptor":
+		if t.text == "EMBEDDED" {
+			p.accept("PDV")
+		}
+		return &asnType{kind: kindAny}, nil
+	}
+
+	if u, ok := characterStrings[t.text]; ok {
+		return &asnType{kind: kindCharacterString, univTag: u}, nil
+	}
+
+	// field of information object class, e.g. MAP-EXTENSION.&ExtensionType.
+	if p.is(".", "&") || p.is(".") {
+		for p.accept(".") {
+			p.accept("&")
+			p.next()
+		}
+		return &asnType{kind: kindAny}, nil
+	}
+	if isAllUpper(t.text) && strings.Contains(t.text, "-") && p.is(".") {
+		return &asnType{kind: kindAny}, nil
+	}
+
+	// parameterized reference, which is resolved by name only.
+	if p.is("{") {
+		p.skipBalanced()
+	}
+	return &asnType{kind: kindReference, ref: t.text}, nil
+}
+
+func (p *parser) parseConstructed(keyword string) (*asnType, error) {
+	// SEQUENCE SIZE (1..N) OF or SEQUENCE (SIZE (1..N)) OF.
+	if p.accept("SIZE") {
+		p.skipBalanced()
+	} else if p.is("(") {
+		p.skipBalanced()
+	}
+
+	if p.accept("OF") {
+		// the name of the element may be given, e.g., SEQUENCE OF item Type.
+		if p.peek(0).kind == tokIdent && !isUpper(p.peek(0).text) {
+			p.next()
+		}
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		kind := kindSequenceOf
+		if keyword == "SET" {
+			kind = kindSetOf
+		}
+		return &asnType{kind: kind, elem: elem}, nil
+	}
+
+	comps, err := p.parseComponents()
+	if err != nil {
+		return nil, err
+	}
+	kind := kindSequence
+	if keyword == "SET" {
+		kind = kindSet
+	}
+	return &asnType{kind: kind, components: comps}, nil
+}
+
+func (p *parser) parseNamedNumbers() []namedNumber {
+	if !p.accept("{") {
+		return nil
+	}
+
+	var named []namedNumber
+	next := int64(0)
+	for !p.eof() && !p.accept("}") {
+		t := p.next()
+		if t.kind != tokIdent {
+			continue
+		}
+		v := next
+		if p.accept("(") {
+			n := p.next()
+			if x, err := strconv.ParseInt(n.text, 10, 64); err == nil {
+				v = x
+			}
+			p.accept(")")
+		}
+		named = append(named, namedNumber{name: t.text, value: v})
+		next = v + 1
+	}
+	return named
+}
+
+func (p *parser) parseComponents() ([]*component, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var comps []*component
+	for !p.eof() {
+		switch {
+		case p.accept("}"):
+			return comps, nil
+		case p.accept(","), p.accept("[["), p.accept("]]"):
+			continue
+		case p.accept("..."):
+			// exception specification.
+			if p.accept("!") {
+				if p.is("(") {
+					p.skipBalanced()
+				} else {
+					p.next()
+				}
+			}
+			continue
+		case p.accept("COMPONENTS", "OF"):
+			t, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			comps = append(comps, &component{typ: t, componentsOf: true})
+			continue
+		}
+
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		c := &component{name: name, typ: t}
+		switch {
+		case p.accept("OPTIONAL"):
+			c.optional = true
+		case p.accept("DEFAULT"):
+			c.optional = true
+			p.skipValue()
+		}
+		comps = append(comps, c)
+	}
+	return nil, fmt.Errorf("unterminated components")
+}
+
+// skipValue skips the value such as "TRUE", "{ a, b }" or "-1".
+func (p *parser) skipValue() {
+	if p.is("{") || p.is("(") {
+		p.skipBalanced()
+		return
+	}
+	p.next()
+}
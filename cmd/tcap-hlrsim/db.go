@@ -0,0 +1,268 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Subscriber is an entry in the subscriber database.
+//
+// MSC and VLR are the numbers of the serving MSC and VLR, which are updated by
+// UpdateLocation. MSRN is the roaming number returned by SendRoutingInfo.
+// Errors are the names or codes of MAP errors returned instead of the result,
+// keyed by the name of operation, or "*" for any operation.
+type Subscriber struct {
+	IMSI         string            `yaml:"imsi"`
+	MSISDN       string            `yaml:"msisdn"`
+	MSC          string            `yaml:"msc,omitempty"`
+	VLR          string            `yaml:"vlr,omitempty"`
+	MSRN         string            `yaml:"msrn,omitempty"`
+	State        string            `yaml:"state,omitempty"`
+	Auth         string            `yaml:"auth,omitempty"`
+	Teleservices []string          `yaml:"teleservices,omitempty"`
+	Errors       map[string]string `yaml:"errors,omitempty"`
+	Delay        time.Duration     `yaml:"delay,omitempty"`
+
+	state        int
+	teleservices [][]byte
+	errors       map[string]uint8
+}
+
+var subscriberStates = map[string]int{
+	"":             gsmmap.AssumedIdle,
+	"assumedIdle":  gsmmap.AssumedIdle,
+	"camelBusy":    gsmmap.CamelBusy,
+	"notReachable": gsmmap.NetDetNotReachable,
+	"notProvided":  gsmmap.NotProvidedFromVLR,
+}
+
+// init validates the fields and sets the values used in the responses.
+func (s *Subscriber) init() error {
+	if s.IMSI == "" && s.MSISDN == "" {
+		return fmt.Errorf("either imsi or msisdn is required")
+	}
+
+	var ok bool
+	if s.state, ok = subscriberStates[s.State]; !ok {
+		return fmt.Errorf("invalid state: %s", s.State)
+	}
+	switch s.Auth {
+	case "", "gsm", "umts":
+	default:
+		return fmt.Errorf("invalid auth: %s", s.Auth)
+	}
+
+	s.teleservices = nil
+	for _, ts := range s.Teleservices {
+		b, err := hex.DecodeString(ts)
+		if err != nil || len(b) != 1 {
+			return fmt.Errorf("invalid teleservice: %s", ts)
+		}
+		s.teleservices = append(s.teleservices, b)
+	}
+
+	s.errors = map[string]uint8{}
+	for op, name := range s.Errors {
+		code, ok := errorCode(name)
+		if !ok {
+			return fmt.Errorf("invalid error for %s: %s", op, name)
+		}
+		s.errors[op] = code
+	}
+	return nil
+}
+
+// errorFor returns the MAP error to be returned for the operation, if any.
+func (s *Subscriber) errorFor(opCode uint8) (uint8, bool) {
+	if code, ok := s.errors[gsmmap.OperationName(opCode)]; ok {
+		return code, true
+	}
+	code, ok := s.errors["*"]
+	return code, ok
+}
+
+// errorCode returns the code of MAP error given by name or number.
+func errorCode(s string) (uint8, bool) {
+	if v, err := strconv.ParseUint(s, 0, 8); err == nil {
+		return uint8(v), true
+	}
+	for code := 0; code <= 0xff; code++ {
+		if gsmmap.ErrorName(uint8(code)) == s {
+			return uint8(code), true
+		}
+	}
+	return 0, false
+}
+
+// DB is the subscriber database looked up by IMSI and MSISDN.
+type DB struct {
+	mu       sync.RWMutex
+	byIMSI   map[string]*Subscriber
+	byMSISDN map[string]*Subscriber
+}
+
+// NewDB creates a new DB with the subscribers.
+func NewDB(subs []*Subscriber) (*DB, error) {
+	db := &DB{byIMSI: map[string]*Subscriber{}, byMSISDN: map[string]*Subscriber{}}
+	for i, s := range subs {
+		if err := s.init(); err != nil {
+			return nil, fmt.Errorf("subscriber %d: %w", i, err)
+		}
+		if s.IMSI != "" {
+			db.byIMSI[s.IMSI] = s
+		}
+		if s.MSISDN != "" {
+			db.byMSISDN[s.MSISDN] = s
+		}
+	}
+	return db, nil
+}
+
+// LoadDB reads the subscribers from YAML or CSV, chosen by the extension of name.
+func LoadDB(name string, b []byte) (*DB, error) {
+	var (
+		subs []*Subscriber
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		subs, err = parseYAML(b)
+	case ".csv":
+		subs, err = parseCSV(b)
+	default:
+		return nil, fmt.Errorf("unknown format of subscriber database: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(subs)
+}
+
+func parseYAML(b []byte) ([]*Subscriber, error) {
+	var doc struct {
+		Subscribers []*Subscriber `yaml:"subscribers"`
+	}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.Subscribers, nil
+}
+
+// parseCSV parses the CSV with a header line of the column names, which are
+// the same as the keys in YAML. The teleservices are separated by spaces, and
+// the errors are the pairs of operation and error like "sendRoutingInfo=absentSubscriber".
+func parseCSV(b []byte) ([]*Subscriber, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []*Subscriber
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return subs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := &Subscriber{}
+		for i, v := range rec {
+			if v == "" {
+				continue
+			}
+			switch header[i] {
+			case "imsi":
+				s.IMSI = v
+			case "msisdn":
+				s.MSISDN = v
+			case "msc":
+				s.MSC = v
+			case "vlr":
+				s.VLR = v
+			case "msrn":
+				s.MSRN = v
+			case "state":
+				s.State = v
+			case "auth":
+				s.Auth = v
+			case "teleservices":
+				s.Teleservices = strings.Fields(v)
+			case "errors":
+				s.Errors = map[string]string{}
+				for _, pair := range strings.Fields(v) {
+					op, name, ok := strings.Cut(pair, "=")
+					if !ok {
+						return nil, fmt.Errorf("invalid errors: %s", v)
+					}
+					s.Errors[op] = name
+				}
+			case "delay":
+				if s.Delay, err = time.ParseDuration(v); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("unknown column: %s", header[i])
+			}
+		}
+		subs = append(subs, s)
+	}
+}
+
+// ByIMSI returns the copy of subscriber with the IMSI.
+func (db *DB) ByIMSI(imsi string) (*Subscriber, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s, ok := db.byIMSI[imsi]
+	if !ok {
+		return nil, false
+	}
+	c := *s
+	return &c, true
+}
+
+// ByMSISDN returns the copy of subscriber with the MSISDN.
+func (db *DB) ByMSISDN(msisdn string) (*Subscriber, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s, ok := db.byMSISDN[msisdn]
+	if !ok {
+		return nil, false
+	}
+	c := *s
+	return &c, true
+}
+
+// UpdateLocation sets the serving MSC and VLR of the subscriber.
+func (db *DB) UpdateLocation(imsi, msc, vlr string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if s, ok := db.byIMSI[imsi]; ok {
+		s.MSC, s.VLR = msc, vlr
+	}
+}
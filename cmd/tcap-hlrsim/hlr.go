@@ -0,0 +1,322 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/gsmmap"
+)
+
+// hlr answers the MAP operations with the subscribers in the DB.
+type hlr struct {
+	db        *DB
+	hlrNumber string
+	timeout   time.Duration
+}
+
+// handler returns the result of the operation or the MAP error to be returned.
+type handler func(d *dialogue, ctx context.Context, sub *Subscriber, arg gsmmap.Parameter) (gsmmap.Parameter, uint8, error)
+
+var handlers = map[uint8]handler{
+	gsmmap.SendRoutingInfo:        (*dialogue).sendRoutingInfo,
+	gsmmap.SendRoutingInfoForSM:   (*dialogue).sendRoutingInfoForSM,
+	gsmmap.UpdateLocation:         (*dialogue).updateLocation,
+	gsmmap.SendAuthenticationInfo: (*dialogue).sendAuthenticationInfo,
+	gsmmap.AnyTimeInterrogation:   (*dialogue).anyTimeInterrogation,
+}
+
+func (h *hlr) serve(ctx context.Context, ep *tcap.Endpoint) {
+	for {
+		s, err := ep.Accept(ctx)
+		if err != nil {
+			return
+		}
+		go h.dialogue(ctx, s)
+	}
+}
+
+// dialogue is a dialogue with the peer in progress.
+type dialogue struct {
+	h     *hlr
+	s     *tcap.Session
+	invID int
+}
+
+func (h *hlr) dialogue(ctx context.Context, s *tcap.Session) {
+	defer s.Abort()
+
+	d := &dialogue{h: h, s: s}
+	t, err := s.Receive(ctx)
+	if err != nil {
+		s.Logger().Warn("failed to receive", "error", err)
+		return
+	}
+	s.Accept()
+
+	// The dialogue opened without any Invoke is confirmed first.
+	if t.Components == nil && t.Transaction.Type.Code() == tcap.Begin {
+		if err := d.send(tcap.NewContinue(0, 0, []byte{})); err != nil {
+			return
+		}
+		if t, err = s.Receive(ctx); err != nil {
+			s.Logger().Warn("failed to receive", "error", err)
+			return
+		}
+	}
+
+	comps, err := d.handle(ctx, t)
+	if err != nil {
+		s.Logger().Warn("failed to handle", "error", err)
+		return
+	}
+	if err := d.send(tcap.NewEnd(0, []byte{}), comps...); err != nil {
+		return
+	}
+}
+
+// handle returns the responses to the Invokes in the message.
+func (d *dialogue) handle(ctx context.Context, t *tcap.TCAP) ([]*tcap.Component, error) {
+	if t.Components == nil {
+		return nil, nil
+	}
+
+	var comps []*tcap.Component
+	for _, c := range t.Components.Component {
+		if c.Type.Code() != tcap.Invoke {
+			continue
+		}
+		r, err := d.invoke(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		comps = append(comps, r)
+	}
+	return comps, nil
+}
+
+// invoke returns the response to the Invoke.
+func (d *dialogue) invoke(ctx context.Context, c *tcap.Component) (*tcap.Component, error) {
+	opCode, invID := c.OpCode(), int(c.InvID())
+	d.s.Logger().Info("received Invoke", "operation", gsmmap.OperationName(opCode))
+
+	f, ok := handlers[opCode]
+	if !ok {
+		return tcap.NewReject(invID, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil), nil
+	}
+	arg, err := gsmmap.NewArgument(opCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := gsmmap.UnmarshalParameter(c.Parameter, arg); err != nil {
+		d.s.Logger().Warn("rejecting mistyped parameter", "error", err)
+		return tcap.NewReject(invID, tcap.InvokeProblem, tcap.InvokeProblemMistypedParameter, nil), nil
+	}
+
+	sub, ok := d.h.subscriber(arg)
+	if !ok {
+		return tcap.NewReturnError(invID, int(gsmmap.UnknownSubscriber), true, nil), nil
+	}
+	if sub.Delay > 0 {
+		select {
+		case <-time.After(sub.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if errCode, ok := sub.errorFor(opCode); ok {
+		return tcap.NewReturnError(invID, int(errCode), true, nil), nil
+	}
+
+	res, errCode, err := f(d, ctx, sub, arg)
+	if err != nil {
+		return nil, err
+	}
+	if errCode != 0 {
+		return tcap.NewReturnError(invID, int(errCode), true, nil), nil
+	}
+	return gsmmap.NewReturnResult(invID, opCode, res)
+}
+
+// subscriber looks up the subscriber identified in the argument.
+func (h *hlr) subscriber(arg gsmmap.Parameter) (*Subscriber, bool) {
+	byMSISDN := func(a *gsmmap.AddressString) (*Subscriber, bool) {
+		if a == nil {
+			return nil, false
+		}
+		return h.db.ByMSISDN(a.Digits)
+	}
+
+	switch a := arg.(type) {
+	case *gsmmap.SendRoutingInfoArg:
+		return byMSISDN(a.MSISDN)
+	case *gsmmap.RoutingInfoForSMArg:
+		return byMSISDN(a.MSISDN)
+	case *gsmmap.UpdateLocationArg:
+		return h.db.ByIMSI(a.IMSI)
+	case *gsmmap.SendAuthenticationInfoArg:
+		return h.db.ByIMSI(a.IMSI)
+	case *gsmmap.AnyTimeInterrogationArg:
+		if a.IMSI != "" {
+			return h.db.ByIMSI(a.IMSI)
+		}
+		return byMSISDN(a.MSISDN)
+	}
+	return nil, false
+}
+
+func (d *dialogue) sendRoutingInfo(_ context.Context, sub *Subscriber, _ gsmmap.Parameter) (gsmmap.Parameter, uint8, error) {
+	if sub.state == gsmmap.NetDetNotReachable || sub.MSRN == "" {
+		return nil, gsmmap.AbsentSubscriber, nil
+	}
+	res := &gsmmap.SendRoutingInfoRes{
+		IMSI:          sub.IMSI,
+		RoamingNumber: gsmmap.NewAddressString(sub.MSRN),
+	}
+	if sub.MSC != "" {
+		res.VMSCAddress = gsmmap.NewAddressString(sub.MSC)
+	}
+	return res, 0, nil
+}
+
+func (d *dialogue) sendRoutingInfoForSM(_ context.Context, sub *Subscriber, _ gsmmap.Parameter) (gsmmap.Parameter, uint8, error) {
+	if sub.state == gsmmap.NetDetNotReachable || sub.MSC == "" {
+		return nil, gsmmap.AbsentSubscriberSM, nil
+	}
+	return &gsmmap.RoutingInfoForSMRes{
+		IMSI:              sub.IMSI,
+		NetworkNodeNumber: gsmmap.NewAddressString(sub.MSC),
+	}, 0, nil
+}
+
+// updateLocation sends the subscriber data to the VLR with InsertSubscriberData
+// before updating the location and returning the result.
+func (d *dialogue) updateLocation(ctx context.Context, sub *Subscriber, arg gsmmap.Parameter) (gsmmap.Parameter, uint8, error) {
+	status := 0 // serviceGranted
+	isd := &gsmmap.InsertSubscriberDataArg{SubscriberStatus: &status}
+	if sub.MSISDN != "" {
+		isd.MSISDN = gsmmap.NewAddressString(sub.MSISDN)
+	}
+	if err := d.insertSubscriberData(ctx, isd); err != nil {
+		return nil, 0, err
+	}
+	if len(sub.teleservices) > 0 {
+		if err := d.insertSubscriberData(ctx, &gsmmap.InsertSubscriberDataArg{TeleserviceList: sub.teleservices}); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var msc, vlr string
+	a := arg.(*gsmmap.UpdateLocationArg)
+	if a.MSCNumber != nil {
+		msc = a.MSCNumber.Digits
+	}
+	if a.VLRNumber != nil {
+		vlr = a.VLRNumber.Digits
+	}
+	d.h.db.UpdateLocation(sub.IMSI, msc, vlr)
+
+	return &gsmmap.UpdateLocationRes{HLRNumber: gsmmap.NewAddressString(d.h.hlrNumber)}, 0, nil
+}
+
+// insertSubscriberData sends InsertSubscriberData in Continue and waits for the result.
+func (d *dialogue) insertSubscriberData(ctx context.Context, arg *gsmmap.InsertSubscriberDataArg) error {
+	d.invID++
+	c, err := gsmmap.NewInvoke(d.invID, gsmmap.InsertSubscriberData, arg)
+	if err != nil {
+		return err
+	}
+	if err := d.send(tcap.NewContinue(0, 0, []byte{}), c); err != nil {
+		return err
+	}
+
+	t, err := d.s.Receive(ctx)
+	if err != nil {
+		return err
+	}
+	if t.Components != nil {
+		for _, c := range t.Components.Component {
+			if int(c.InvID()) != d.invID {
+				continue
+			}
+			switch c.Type.Code() {
+			case tcap.ReturnResultLast:
+				return nil
+			case tcap.ReturnError:
+				return fmt.Errorf("insertSubscriberData failed: %s", gsmmap.ErrorName(c.OpCode()))
+			case tcap.Reject:
+				return errors.New("insertSubscriberData rejected")
+			}
+		}
+	}
+	return fmt.Errorf("no result of insertSubscriberData in %s", t.Transaction.MessageTypeString())
+}
+
+// sendAuthenticationInfo returns the random vectors, as many as requested up
+// to 5.
+func (d *dialogue) sendAuthenticationInfo(_ context.Context, sub *Subscriber, arg gsmmap.Parameter) (gsmmap.Parameter, uint8, error) {
+	n := arg.(*gsmmap.SendAuthenticationInfoArg).NumberOfRequestedVectors
+	if n < 1 {
+		n = 1
+	}
+	if n > 5 {
+		n = 5
+	}
+
+	res := &gsmmap.SendAuthenticationInfoRes{}
+	for range n {
+		if sub.Auth == "gsm" {
+			res.Triplets = append(res.Triplets, &gsmmap.AuthenticationTriplet{
+				RAND: random(16), SRES: random(4), Kc: random(8),
+			})
+			continue
+		}
+		res.Quintuplets = append(res.Quintuplets, &gsmmap.AuthenticationQuintuplet{
+			RAND: random(16), XRES: random(8), CK: random(16), IK: random(16), AUTN: random(16),
+		})
+	}
+	return res, 0, nil
+}
+
+func (d *dialogue) anyTimeInterrogation(_ context.Context, sub *Subscriber, arg gsmmap.Parameter) (gsmmap.Parameter, uint8, error) {
+	req := arg.(*gsmmap.AnyTimeInterrogationArg).RequestedInfo
+
+	res := &gsmmap.AnyTimeInterrogationRes{}
+	if req.LocationInformation && (sub.VLR != "" || sub.MSC != "") {
+		age := 0
+		loc := &gsmmap.LocationInformation{AgeOfLocationInformation: &age}
+		if sub.VLR != "" {
+			loc.VLRNumber = gsmmap.NewAddressString(sub.VLR)
+		}
+		if sub.MSC != "" {
+			loc.MSCNumber = gsmmap.NewAddressString(sub.MSC)
+		}
+		res.SubscriberInfo.LocationInformation = loc
+	}
+	if req.SubscriberState {
+		res.SubscriberInfo.SubscriberState = &gsmmap.SubscriberState{State: sub.state}
+	}
+	return res, 0, nil
+}
+
+// send sends the message with the components.
+func (d *dialogue) send(tr *tcap.Transaction, comps ...*tcap.Component) error {
+	t := &tcap.TCAP{Transaction: tr}
+	if len(comps) > 0 {
+		t.Components = tcap.NewComponents(comps...)
+	}
+	return d.s.Send(t)
+}
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return b
+}
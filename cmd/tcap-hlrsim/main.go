@@ -0,0 +1,144 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcap-hlrsim is an HLR simulator that answers the MAP operations with
+the subscribers in the database given.
+
+The operations supported are SendRoutingInfo, SendRoutingInfoForSM,
+UpdateLocation, SendAuthenticationInfo and AnyTimeInterrogation. On
+UpdateLocation, the subscriber data are sent to the VLR with
+InsertSubscriberData in Continue before the result is returned in End. The
+other operations are rejected.
+
+The database is in YAML or CSV, chosen by the extension of the file.
+
+	subscribers:
+	  - imsi: "001010123456789"
+	    msisdn: "819012345678"
+	    msc: "819000000001"
+	    vlr: "819000000001"
+	    msrn: "819099990001"
+	    auth: umts
+	    teleservices: ["11", "21"]
+	  - imsi: "001010123456790"
+	    msisdn: "819012345679"
+	    state: notReachable
+	    errors: {sendAuthenticationInfo: systemFailure}
+	    delay: 500ms
+
+	imsi,msisdn,msc,vlr,msrn,state,auth,teleservices,errors,delay
+	001010123456789,819012345678,819000000001,819000000001,819099990001,,umts,11 21,,
+	001010123456790,819012345679,,,,notReachable,,,sendAuthenticationInfo=systemFailure,500ms
+
+The errors are the MAP errors returned instead of the results, keyed by the
+operation or "*" for any operation, and the delay is the time to wait before
+answering. The unknown subscribers are answered with unknownSubscriber.
+
+The simulator accepts M3UA associations at -listen, or establishes one with
+the peer at -connect, and answers to the Calling Party Address of the requests.
+
+	tcap-hlrsim -db subscribers.yaml -listen 127.0.0.2:2905 -gt 819011111111
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/wmnsk/go-m3ua"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func main() {
+	var (
+		dbFile    = flag.String("db", "", "Subscriber database in YAML or CSV.")
+		hlrNumber = flag.String("hlr-number", "819011111111", "HLR Number returned by UpdateLocation.")
+		listen    = flag.String("listen", "127.0.0.2:2905", "Local IP and Port to accept M3UA associations on.")
+		connect   = flag.String("connect", "", "Remote IP and Port to connect to instead of listening.")
+		opc       = flag.Uint("opc", 2, "Originating Point Code.")
+		dpc       = flag.Uint("dpc", 1, "Destination Point Code.")
+		gt        = flag.String("gt", "819011111111", "Calling Party GT of the responses.")
+		ssn       = flag.Uint("ssn", 6, "Calling Party SSN of the responses.")
+		timeout   = flag.Duration("timeout", 10*time.Second, "Time to wait for the messages from the peer.")
+		verbose   = flag.Bool("v", false, "Print the operations received and the logs of the tcap package.")
+	)
+	flag.Parse()
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	if !*verbose {
+		tcap.DisableLogging()
+	}
+
+	if *dbFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	b, err := os.ReadFile(*dbFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := LoadDB(*dbFile, b)
+	if err != nil {
+		log.Fatalf("invalid database %s: %v", *dbFile, err)
+	}
+	h := &hlr{db: db, hlrNumber: *hlrNumber, timeout: *timeout}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	local, err := transport.GTAddress(uint8(*ssn), *gt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := m3ua.NewConfig(uint32(*opc), uint32(*dpc), m3params.ServiceIndSCCP, 0, 0, 1).EnableHeartbeat(0, 0)
+
+	if *connect != "" {
+		conn, err := transport.DialM3UA(ctx, *connect, cfg, local, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("connected to %s", *connect)
+		h.run(ctx, conn)
+		return
+	}
+
+	l, err := transport.ListenM3UA(*listen, cfg, local)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	log.Printf("listening on %s", *listen)
+
+	for {
+		conn, err := l.Accept(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("failed to accept: %v", err)
+			continue
+		}
+		log.Print("association established")
+		go h.run(ctx, conn)
+	}
+}
+
+// run answers the requests on the connection until it is closed.
+func (h *hlr) run(ctx context.Context, conn transport.Conn) {
+	ep := tcap.NewEndpoint(conn)
+	ep.ReceiveTimeout = h.timeout
+	go h.serve(ctx, ep)
+	if err := ep.Serve(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("association closed: %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func loadTestDB(t *testing.T, name string) *DB {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadDB(name, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newClient(t *testing.T, db *DB) *tcap.Endpoint {
+	t.Helper()
+	tcap.DisableLogging()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	conn, peer := transport.Pipe()
+	h := &hlr{db: db, hlrNumber: "819011111111", timeout: time.Second}
+	go h.run(ctx, peer)
+
+	client := tcap.NewEndpoint(conn)
+	go client.Serve(ctx)
+	return client
+}
+
+func receive(t *testing.T, s *tcap.Session) *tcap.TCAP {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	m, err := s.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func begin(t *testing.T, client *tcap.Endpoint, appCtx uint8, c *tcap.Component) *tcap.Session {
+	t.Helper()
+	s, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &tcap.TCAP{
+		Transaction: tcap.NewBegin(0, []byte{}),
+		Dialogue:    tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQ(1, appCtx, 3), []byte{}),
+	}
+	if c != nil {
+		m.Components = tcap.NewComponents(c)
+	}
+	if err := s.Send(m); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func invoke(t *testing.T, opCode uint8, arg gsmmap.Parameter) *tcap.Component {
+	t.Helper()
+	c, err := gsmmap.NewInvoke(1, opCode, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestOperations(t *testing.T) {
+	client := newClient(t, loadTestDB(t, "testdata/subscribers.yaml"))
+
+	age := 0
+	cases := []struct {
+		description string
+		appCtx      uint8
+		opCode      uint8
+		arg         gsmmap.Parameter
+		want        gsmmap.Parameter
+		errCode     uint8
+	}{
+		{
+			"SendRoutingInfo", tcap.LocationInfoRetrievalContext, gsmmap.SendRoutingInfo,
+			&gsmmap.SendRoutingInfoArg{MSISDN: gsmmap.NewAddressString("819012345678"), GMSCAddress: gsmmap.NewAddressString("819000000300")},
+			&gsmmap.SendRoutingInfoRes{
+				IMSI:          "001010123456789",
+				RoamingNumber: gsmmap.NewAddressString("819099990001"),
+				VMSCAddress:   gsmmap.NewAddressString("819000000001"),
+			}, 0,
+		}, {
+			"SendRoutingInfo/no MSRN", tcap.LocationInfoRetrievalContext, gsmmap.SendRoutingInfo,
+			&gsmmap.SendRoutingInfoArg{MSISDN: gsmmap.NewAddressString("819012345679"), GMSCAddress: gsmmap.NewAddressString("819000000300")},
+			nil, gsmmap.AbsentSubscriber,
+		}, {
+			"SendRoutingInfoForSM", tcap.ShortMsgGatewayContext, gsmmap.SendRoutingInfoForSM,
+			&gsmmap.RoutingInfoForSMArg{MSISDN: gsmmap.NewAddressString("819012345679"), ServiceCentreAddress: gsmmap.NewAddressString("819000000100")},
+			&gsmmap.RoutingInfoForSMRes{IMSI: "001010123456790", NetworkNodeNumber: gsmmap.NewAddressString("819000000001")}, 0,
+		}, {
+			"SendRoutingInfoForSM/unknown", tcap.ShortMsgGatewayContext, gsmmap.SendRoutingInfoForSM,
+			&gsmmap.RoutingInfoForSMArg{MSISDN: gsmmap.NewAddressString("819000000000"), ServiceCentreAddress: gsmmap.NewAddressString("819000000100")},
+			nil, gsmmap.UnknownSubscriber,
+		}, {
+			"AnyTimeInterrogation", tcap.AnyTimeInfoEnquiryContext, gsmmap.AnyTimeInterrogation,
+			&gsmmap.AnyTimeInterrogationArg{
+				IMSI:          "001010123456789",
+				RequestedInfo: gsmmap.RequestedInfo{LocationInformation: true, SubscriberState: true},
+				GSMSCFAddress: gsmmap.NewAddressString("819000000200"),
+			},
+			&gsmmap.AnyTimeInterrogationRes{SubscriberInfo: gsmmap.SubscriberInfo{
+				LocationInformation: &gsmmap.LocationInformation{
+					AgeOfLocationInformation: &age,
+					VLRNumber:                gsmmap.NewAddressString("819000000001"),
+					MSCNumber:                gsmmap.NewAddressString("819000000001"),
+				},
+				SubscriberState: &gsmmap.SubscriberState{State: gsmmap.AssumedIdle},
+			}}, 0,
+		}, {
+			"AnyTimeInterrogation/by MSISDN", tcap.AnyTimeInfoEnquiryContext, gsmmap.AnyTimeInterrogation,
+			&gsmmap.AnyTimeInterrogationArg{
+				MSISDN:        gsmmap.NewAddressString("819012345680"),
+				RequestedInfo: gsmmap.RequestedInfo{SubscriberState: true},
+				GSMSCFAddress: gsmmap.NewAddressString("819000000200"),
+			},
+			nil, gsmmap.AbsentSubscriber,
+		}, {
+			"SendAuthenticationInfo/injected", tcap.InfoRetrievalContext, gsmmap.SendAuthenticationInfo,
+			&gsmmap.SendAuthenticationInfoArg{IMSI: "001010123456791", NumberOfRequestedVectors: 1},
+			nil, gsmmap.SystemFailure,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			s := begin(t, client, c.appCtx, invoke(t, c.opCode, c.arg))
+			end := receive(t, s)
+			verify.Values(t, "type", end.Transaction.MessageTypeString(), "End")
+			verify.Values(t, "AARE", end.Dialogue != nil && end.Dialogue.DialoguePDU.Type.Code() == tcap.AARE, true)
+
+			comp := end.Components.Component[0]
+			if c.errCode != 0 {
+				verify.Values(t, "error", comp.ErrorCode.Value, []byte{c.errCode})
+				return
+			}
+			res, err := gsmmap.ParseResult(c.appCtx, comp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "result", res, c.want)
+		})
+	}
+}
+
+func TestSendAuthenticationInfo(t *testing.T) {
+	client := newClient(t, loadTestDB(t, "testdata/subscribers.yaml"))
+
+	cases := []struct {
+		imsi                  string
+		triplets, quintuplets int
+	}{
+		{"001010123456789", 0, 5},
+		{"001010123456790", 5, 0},
+	}
+	for _, c := range cases {
+		s := begin(t, client, tcap.InfoRetrievalContext, invoke(t, gsmmap.SendAuthenticationInfo,
+			&gsmmap.SendAuthenticationInfoArg{IMSI: c.imsi, NumberOfRequestedVectors: 5}))
+		end := receive(t, s)
+		res, err := gsmmap.ParseResult(tcap.InfoRetrievalContext, end.Components.Component[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sai := res.(*gsmmap.SendAuthenticationInfoRes)
+		verify.Values(t, c.imsi+" triplets", len(sai.Triplets), c.triplets)
+		verify.Values(t, c.imsi+" quintuplets", len(sai.Quintuplets), c.quintuplets)
+	}
+}
+
+func TestUpdateLocation(t *testing.T) {
+	db := loadTestDB(t, "testdata/subscribers.yaml")
+	client := newClient(t, db)
+
+	s := begin(t, client, tcap.NetworkLocUpContext, invoke(t, gsmmap.UpdateLocation, &gsmmap.UpdateLocationArg{
+		IMSI:      "001010123456789",
+		MSCNumber: gsmmap.NewAddressString("819000000002"),
+		VLRNumber: gsmmap.NewAddressString("819000000003"),
+	}))
+
+	// InsertSubscriberData with MSISDN first, and then with the teleservices.
+	var isds []*gsmmap.InsertSubscriberDataArg
+	for i := 0; i < 2; i++ {
+		cont := receive(t, s)
+		verify.Values(t, "type", cont.Transaction.MessageTypeString(), "Continue")
+		verify.Values(t, "AARE", cont.Dialogue != nil, i == 0)
+
+		comp := cont.Components.Component[0]
+		arg, err := gsmmap.ParseArgument(tcap.NetworkLocUpContext, comp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isds = append(isds, arg.(*gsmmap.InsertSubscriberDataArg))
+
+		res, err := gsmmap.NewReturnResult(int(comp.InvID()), gsmmap.InsertSubscriberData, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			// The result is optional, and the bare ReturnResultLast is sent.
+			res.ResultRetres, res.OperationCode, res.Parameter = nil, nil, nil
+			res.SetLength()
+		}
+		if err := s.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{}), Components: tcap.NewComponents(res)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verify.Values(t, "MSISDN", isds[0].MSISDN, gsmmap.NewAddressString("819012345678"))
+	verify.Values(t, "teleservices", isds[1].TeleserviceList, [][]byte{{0x11}, {0x21}})
+
+	end := receive(t, s)
+	res, err := gsmmap.ParseResult(tcap.NetworkLocUpContext, end.Components.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "result", res, &gsmmap.UpdateLocationRes{HLRNumber: gsmmap.NewAddressString("819011111111")})
+
+	sub, _ := db.ByIMSI("001010123456789")
+	verify.Values(t, "MSC", sub.MSC, "819000000002")
+	verify.Values(t, "VLR", sub.VLR, "819000000003")
+}
+
+func TestDialogueWithoutInvoke(t *testing.T) {
+	client := newClient(t, loadTestDB(t, "testdata/subscribers.yaml"))
+
+	s := begin(t, client, tcap.ShortMsgGatewayContext, nil)
+	cont := receive(t, s)
+	verify.Values(t, "type", cont.Transaction.MessageTypeString(), "Continue")
+	verify.Values(t, "AARE", cont.Dialogue != nil, true)
+
+	c := invoke(t, gsmmap.SendRoutingInfoForSM, &gsmmap.RoutingInfoForSMArg{
+		MSISDN: gsmmap.NewAddressString("819012345678"), ServiceCentreAddress: gsmmap.NewAddressString("819000000100"),
+	})
+	if err := s.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{}), Components: tcap.NewComponents(c)}); err != nil {
+		t.Fatal(err)
+	}
+	end := receive(t, s)
+	verify.Values(t, "type", end.Transaction.MessageTypeString(), "End")
+	verify.Values(t, "AARE", end.Dialogue == nil, true)
+	verify.Values(t, "component", end.Components.Component[0].ComponentTypeString(), "returnResultLast")
+}
+
+func TestReject(t *testing.T) {
+	client := newClient(t, loadTestDB(t, "testdata/subscribers.yaml"))
+
+	cases := []struct {
+		description string
+		comp        *tcap.Component
+		problem     uint8
+	}{
+		{"unsupported", tcap.NewInvoke(1, -1, int(gsmmap.CancelLocation), true, nil), tcap.InvokeProblemUnrecognizedOperation},
+		{"mistyped", tcap.NewInvoke(1, -1, int(gsmmap.SendRoutingInfo), true, []byte{0x04, 0x01, 0x00}), tcap.InvokeProblemMistypedParameter},
+	}
+	for _, c := range cases {
+		s := begin(t, client, tcap.LocationInfoRetrievalContext, c.comp)
+		end := receive(t, s)
+		comp := end.Components.Component[0]
+		verify.Values(t, c.description+" type", comp.ComponentTypeString(), "reject")
+		verify.Values(t, c.description+" problem", comp.ProblemCode.Value, []byte{c.problem})
+	}
+}
+
+func TestLoadDB(t *testing.T) {
+	for _, name := range []string{"testdata/subscribers.yaml", "testdata/subscribers.csv"} {
+		db := loadTestDB(t, name)
+
+		sub, ok := db.ByMSISDN("819012345678")
+		if !ok {
+			t.Fatalf("%s: subscriber not found", name)
+		}
+		verify.Values(t, name+" IMSI", sub.IMSI, "001010123456789")
+		verify.Values(t, name+" teleservices", sub.teleservices, [][]byte{{0x11}, {0x21}})
+
+		sub, ok = db.ByIMSI("001010123456791")
+		if !ok {
+			t.Fatalf("%s: subscriber not found", name)
+		}
+		verify.Values(t, name+" state", sub.state, gsmmap.NetDetNotReachable)
+		verify.Values(t, name+" delay", sub.Delay, 10*time.Millisecond)
+		code, _ := sub.errorFor(gsmmap.SendAuthenticationInfo)
+		verify.Values(t, name+" SAI error", code, gsmmap.SystemFailure)
+		code, _ = sub.errorFor(gsmmap.UpdateLocation)
+		verify.Values(t, name+" UL error", code, gsmmap.AbsentSubscriber)
+	}
+}
+
+func TestLoadDBErrors(t *testing.T) {
+	cases := []struct {
+		name, db, want string
+	}{
+		{"db.json", "{}", "unknown format"},
+		{"db.yaml", "subscribers:\n  - imsi: '001'\n    state: busy\n", "invalid state: busy"},
+		{"db.yaml", "subscribers:\n  - imsi: '001'\n    errors: {'*': noSuchError}\n", "invalid error for *: noSuchError"},
+		{"db.yaml", "subscribers:\n  - state: camelBusy\n", "either imsi or msisdn is required"},
+		{"db.csv", "imsi,color\n001,red\n", "unknown column: color"},
+		{"db.csv", "imsi,teleservices\n001,111\n", "invalid teleservice: 111"},
+	}
+	for _, c := range cases {
+		if _, err := LoadDB(c.name, []byte(c.db)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: unexpected error: %v", c.want, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcap-replay replays the TCAP dialogues recorded in a pcap/pcapng
+capture or JSON against a system under test, acting as the initiator or the
+responder of them, and reports the differences of the messages from the peer.
+
+The messages of the -side are sent with the TID and the invoke IDs allocated by
+the peer, and the messages from the peer are compared with the recorded ones
+after the TID and the invoke IDs of the peer are matched. The fields not to
+compare are given with -mask in the paths of the JSON representation of the
+tcap package, where "*" matches any key or index.
+
+	tcap-replay -r capture.pcapng -addr 127.0.0.2:2905 -remote-gt 819011111111
+	tcap-replay -json dialogues.json -side responder -addr 127.0.0.2:2905 -mask 'components.*.parameter'
+
+The JSON is a sequence or an array of the messages in the form below, which
+can be written from a capture with -write-json.
+
+	{"time": "2024-01-02T03:04:05Z", "message": {"transaction": {"type": "begin", "otid": "11111111"}, ...}}
+
+With -transport loopback, the other side is also replayed in the process,
+which is useful to check the recording.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/wmnsk/go-m3ua"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/replay"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func main() {
+	var (
+		capture   = flag.String("r", "", "pcap or pcapng capture to read the dialogues from.")
+		jsonFile  = flag.String("json", "", "JSON to read the dialogues from.")
+		writeJSON = flag.String("write-json", "", "Write the dialogues read to the file in JSON and exit.")
+		sideName  = flag.String("side", "initiator", "Side to play: initiator or responder.")
+		masks     = flag.String("mask", "", "Comma-separated paths of the fields not to compare.")
+		timeout   = flag.Duration("timeout", 5*time.Second, "Time to wait for each message from the peer.")
+		transp    = flag.String("transport", "m3ua", "Transport to use: m3ua or loopback.")
+		addr      = flag.String("addr", "127.0.0.2:2905", "Remote IP and Port to connect to with m3ua.")
+		opc       = flag.Uint("opc", 1, "Originating Point Code with m3ua.")
+		dpc       = flag.Uint("dpc", 2, "Destination Point Code with m3ua.")
+		localGT   = flag.String("local-gt", "819000000000", "Calling Party GT with m3ua.")
+		localSSN  = flag.Uint("local-ssn", 8, "Calling Party SSN with m3ua.")
+		remoteGT  = flag.String("remote-gt", "819011111111", "Called Party GT with m3ua. Ignored by the responder, which answers to the Calling Party.")
+		remoteSSN = flag.Uint("remote-ssn", 6, "Called Party SSN with m3ua.")
+		verbose   = flag.Bool("v", false, "Print the logs of the tcap package.")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	if !*verbose {
+		tcap.DisableLogging()
+	}
+
+	var side replay.Side
+	switch *sideName {
+	case "initiator":
+		side = replay.Initiator
+	case "responder":
+		side = replay.Responder
+	default:
+		log.Fatalf("unknown side: %s", *sideName)
+	}
+	if (*capture == "") == (*jsonFile == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	rec, err := load(*capture, *jsonFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *writeJSON != "" {
+		f, err := os.Create(*writeJSON)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := rec.WriteJSON(f); err != nil {
+			log.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var (
+		conn transport.Conn
+		peer *replay.Player
+	)
+	switch *transp {
+	case "loopback":
+		var peerConn transport.Conn
+		conn, peerConn = transport.Pipe()
+		other := replay.Responder
+		if side == replay.Responder {
+			other = replay.Initiator
+		}
+		peer = replay.NewPlayer(peerConn, other)
+		peer.Timeout = *timeout
+	case "m3ua":
+		local, err := transport.GTAddress(uint8(*localSSN), *localGT)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var remote *params.PartyAddress
+		if side == replay.Initiator {
+			if remote, err = transport.GTAddress(uint8(*remoteSSN), *remoteGT); err != nil {
+				log.Fatal(err)
+			}
+		}
+		cfg := m3ua.NewConfig(uint32(*opc), uint32(*dpc), m3params.ServiceIndSCCP, 0, 0, 1).EnableHeartbeat(0, 0)
+		if conn, err = transport.DialM3UA(ctx, *addr, cfg, local, remote); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown transport: %s", *transp)
+	}
+	defer conn.Close()
+
+	p := replay.NewPlayer(conn, side)
+	p.Timeout = *timeout
+	if *masks != "" {
+		p.Masks = strings.Split(*masks, ",")
+	}
+
+	if failed := play(ctx, p, peer, rec.Dialogues, os.Stdout); failed > 0 {
+		fmt.Printf("%d of %d dialogues failed\n", failed, len(rec.Dialogues))
+		os.Exit(1)
+	}
+}
+
+func load(capture, jsonFile string) (*replay.Recording, error) {
+	name, read := capture, replay.ReadPcap
+	if jsonFile != "" {
+		name, read = jsonFile, replay.ReadJSON
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rec, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return rec, nil
+}
+
+// play plays the dialogues one by one with p, and with peer on the other side
+// if not nil. It prints the result of each dialogue to w, and returns the
+// number of the dialogues that failed.
+func play(ctx context.Context, p, peer *replay.Player, dialogues []*replay.Dialogue, w io.Writer) int {
+	failed := 0
+	for i, d := range dialogues {
+		done := make(chan error, 1)
+		if peer != nil {
+			go func() {
+				_, err := peer.Play(ctx, d)
+				done <- err
+			}()
+		} else {
+			done <- nil
+		}
+
+		diffs, err := p.Play(ctx, d)
+		if perr := <-done; err == nil && perr != nil {
+			err = fmt.Errorf("peer: %w", perr)
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "dialogue %d: %v\n", i, err)
+		case len(diffs) > 0:
+			fmt.Fprintf(w, "dialogue %d: %d differences\n", i, len(diffs))
+			for _, diff := range diffs {
+				fmt.Fprintf(w, "  %s\n", diff)
+			}
+		default:
+			fmt.Fprintf(w, "dialogue %d: ok, %d messages\n", i, len(d.Messages))
+			continue
+		}
+		failed++
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return failed
+}
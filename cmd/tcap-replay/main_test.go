@@ -0,0 +1,107 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/replay"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func TestPlay(t *testing.T) {
+	tcap.DisableLogging()
+	b, err := os.ReadFile("testdata/dialogues.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the peer links an unexpected operation in the second dialogue.
+	changed := strings.Replace(string(b), `"linkedID":1,"opCode":{"local":3}`, `"linkedID":1,"opCode":{"local":4}`, 1)
+
+	cases := []struct {
+		description string
+		side        replay.Side
+		peer        string
+		masks       []string
+		failed      int
+		want        string
+	}{
+		{
+			"initiator", replay.Initiator, string(b), nil, 0,
+			"dialogue 0: ok, 2 messages\ndialogue 1: ok, 4 messages\n",
+		}, {
+			"responder", replay.Responder, string(b), nil, 0,
+			"dialogue 0: ok, 2 messages\ndialogue 1: ok, 4 messages\n",
+		}, {
+			"differences", replay.Initiator, changed, nil, 1,
+			"dialogue 0: ok, 2 messages\ndialogue 1: 1 differences\n  message 1: components.0.opCode.local: got 4, want 3\n",
+		}, {
+			"masked", replay.Initiator, changed, []string{"components.*.opCode"}, 0,
+			"dialogue 0: ok, 2 messages\ndialogue 1: ok, 4 messages\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			rec, err := replay.ReadJSON(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			peerRec, err := replay.ReadJSON(strings.NewReader(c.peer))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conn, peerConn := transport.Pipe()
+			p := replay.NewPlayer(conn, c.side)
+			p.Masks = c.masks
+			peer := replay.NewPlayer(peerConn, replay.Responder)
+			if c.side == replay.Responder {
+				peer.Side = replay.Initiator
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			out := &bytes.Buffer{}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for _, d := range peerRec.Dialogues {
+					if _, err := peer.Play(ctx, d); err != nil {
+						t.Error(err)
+					}
+				}
+			}()
+			failed := play(ctx, p, nil, rec.Dialogues, out)
+			<-done
+
+			verify.Values(t, "failed", failed, c.failed)
+			verify.Values(t, "output", out.String(), c.want)
+		})
+	}
+}
+
+func TestPlayLoopback(t *testing.T) {
+	rec, err := load("", "testdata/dialogues.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, peerConn := transport.Pipe()
+	out := &bytes.Buffer{}
+	failed := play(context.Background(), replay.NewPlayer(conn, replay.Initiator), replay.NewPlayer(peerConn, replay.Responder), rec.Dialogues, out)
+	verify.Values(t, "failed", failed, 0)
+	verify.Values(t, "output", out.String(), "dialogue 0: ok, 2 messages\ndialogue 1: ok, 4 messages\n")
+
+	if _, err := load("testdata/missing.pcap", ""); err == nil {
+		t.Error("no error with missing file")
+	}
+}
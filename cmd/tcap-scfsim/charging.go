@@ -0,0 +1,115 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Account is a prepaid account of the subscriber.
+type Account struct {
+	IMSI    string `yaml:"imsi"`
+	Balance int    `yaml:"balance"`
+}
+
+// Accounts are the prepaid accounts looked up by IMSI.
+//
+// The calls of the subscribers without account are given the default balance,
+// which is not kept after the call.
+type Accounts struct {
+	mu      sync.Mutex
+	byIMSI  map[string]*Account
+	balance int
+}
+
+// NewAccounts creates a new Accounts with the accounts and the default balance.
+func NewAccounts(accts []*Account, balance int) (*Accounts, error) {
+	a := &Accounts{byIMSI: map[string]*Account{}, balance: balance}
+	for i, acct := range accts {
+		if acct.IMSI == "" {
+			return nil, fmt.Errorf("account %d: imsi is required", i)
+		}
+		if acct.Balance < 0 {
+			return nil, fmt.Errorf("account %d: negative balance: %d", i, acct.Balance)
+		}
+		a.byIMSI[acct.IMSI] = acct
+	}
+	return a, nil
+}
+
+// LoadAccounts reads the accounts in YAML.
+//
+//	accounts:
+//	  - imsi: "001010123456789"
+//	    balance: 300
+func LoadAccounts(b []byte, balance int) (*Accounts, error) {
+	var doc struct {
+		Accounts []*Account `yaml:"accounts"`
+	}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return NewAccounts(doc.Accounts, balance)
+}
+
+// get returns the account of the subscriber.
+func (a *Accounts) get(imsi string) *Account {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if acct, ok := a.byIMSI[imsi]; ok {
+		return acct
+	}
+	return &Account{IMSI: imsi, Balance: a.balance}
+}
+
+// Balance returns the balance of the account.
+func (a *Accounts) Balance(acct *Account) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return acct.Balance
+}
+
+// debit subtracts the units from the account and returns the balance.
+func (a *Accounts) debit(acct *Account, units int) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acct.Balance -= units
+	if acct.Balance < 0 {
+		acct.Balance = 0
+	}
+	return acct.Balance
+}
+
+// tariff is the price of the calls in units per second, which changes to
+// switchRate when the time charged for the call reaches switchAfter, if set.
+type tariff struct {
+	rate        int
+	switchRate  int
+	switchAfter time.Duration
+	grant       time.Duration
+}
+
+// charge returns the units for the duration in 100 milliseconds, rounded up.
+func charge(tenths, rate int) int {
+	return (tenths*rate + 9) / 10
+}
+
+// affordable returns the seconds of the call the balance covers, when the
+// tariff switches after before seconds, or does not switch if before is negative.
+func (t *tariff) affordable(balance, rate, before int) int {
+	if before < 0 || before*rate >= balance {
+		return balance / rate
+	}
+	return before + (balance-before*rate)/t.switchRate
+}
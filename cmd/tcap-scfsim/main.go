@@ -0,0 +1,175 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcap-scfsim is a gsmSCF simulator that controls prepaid calls reported
+by the gsmSSF with CAP InitialDP.
+
+On InitialDP, the balance of the subscriber is checked, and the call is
+continued with RequestReportBCSMEvent, ApplyCharging and Continue, or released
+with ReleaseCall if the balance is not enough for a second. Each
+ApplyChargingReport is charged to the balance, and the next period is granted
+with ApplyCharging until the credit is exhausted, when ReleaseCall is sent. The
+dialogue is ended when the call is disconnected or abandoned.
+
+The calls are charged at -rate units per second, which changes to -switch-rate
+when the time charged for the call reaches -tariff-switch. The tariff switch
+is given to the gsmSSF with the tariffSwitchInterval of ApplyCharging when it
+comes in the period granted.
+
+The balances of the subscribers are read from the YAML file given with
+-accounts, and the subscribers without account are given -balance for each call.
+
+	accounts:
+	  - imsi: "001010123456789"
+	    balance: 300
+
+The simulator accepts M3UA associations at -listen, or establishes one with
+the peer at -connect, and answers to the Calling Party Address of the requests.
+With -transport loopback, it runs a gsmSSF that makes -n calls of -duration
+in the process instead, which is useful to try the charging logic.
+
+	tcap-scfsim -accounts accounts.yaml -listen 127.0.0.2:2905 -gt 819022222222
+	tcap-scfsim -transport loopback -balance 100 -rate 1 -duration 2m -grant 30s -v
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/wmnsk/go-m3ua"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func main() {
+	var (
+		acctFile     = flag.String("accounts", "", "Prepaid accounts in YAML.")
+		balance      = flag.Int("balance", 0, "Balance of the subscribers without account.")
+		rate         = flag.Int("rate", 1, "Units charged per second.")
+		switchRate   = flag.Int("switch-rate", 1, "Units charged per second after the tariff switch.")
+		switchAfter  = flag.Duration("tariff-switch", 0, "Time charged for the call until the tariff switch. 0 means no switch.")
+		grant        = flag.Duration("grant", 60*time.Second, "Maximum call period granted with each ApplyCharging.")
+		releaseCause = flag.Uint("release-cause", 31, "Cause value of ReleaseCall.")
+		transp       = flag.String("transport", "m3ua", "Transport to use: m3ua or loopback.")
+		listen       = flag.String("listen", "127.0.0.2:2905", "Local IP and Port to accept M3UA associations on.")
+		connect      = flag.String("connect", "", "Remote IP and Port to connect to instead of listening.")
+		opc          = flag.Uint("opc", 2, "Originating Point Code.")
+		dpc          = flag.Uint("dpc", 1, "Destination Point Code.")
+		gt           = flag.String("gt", "819022222222", "Calling Party GT of the responses.")
+		ssn          = flag.Uint("ssn", 146, "Calling Party SSN of the responses.")
+		timeout      = flag.Duration("timeout", 5*time.Minute, "Time to wait for the messages from the gsmSSF.")
+		count        = flag.Int("n", 1, "Number of calls to make with loopback.")
+		duration     = flag.Duration("duration", time.Minute, "Duration of the calls to make with loopback.")
+		imsi         = flag.String("imsi", "001010123456789", "IMSI of the calls to make with loopback.")
+		verbose      = flag.Bool("v", false, "Print the progress of the calls and the logs of the tcap package.")
+	)
+	flag.Parse()
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	if !*verbose {
+		tcap.DisableLogging()
+	}
+
+	if *rate < 1 || *switchRate < 1 || *grant < time.Second || *releaseCause > 0x7f {
+		flag.Usage()
+		os.Exit(2)
+	}
+	accounts, err := NewAccounts(nil, *balance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *acctFile != "" {
+		b, err := os.ReadFile(*acctFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if accounts, err = LoadAccounts(b, *balance); err != nil {
+			log.Fatalf("invalid accounts %s: %v", *acctFile, err)
+		}
+	}
+	sc := &scf{
+		accounts:     accounts,
+		tariff:       &tariff{rate: *rate, switchRate: *switchRate, switchAfter: *switchAfter, grant: *grant},
+		releaseCause: uint8(*releaseCause),
+		timeout:      *timeout,
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	switch *transp {
+	case "loopback":
+		conn, peer := transport.Pipe()
+		go sc.run(ctx, peer)
+
+		ssf := &ssf{ep: tcap.NewEndpoint(conn), imsi: *imsi, duration: *duration, timeout: *timeout}
+		go ssf.ep.Serve(ctx)
+		for i := 0; i < *count; i++ {
+			r, err := ssf.call(ctx)
+			if err != nil {
+				log.Fatalf("call %d: %v", i, err)
+			}
+			log.Printf("call %d: %s", i, r)
+		}
+		return
+	case "m3ua":
+	default:
+		log.Fatalf("unknown transport: %s", *transp)
+	}
+
+	local, err := transport.GTAddress(uint8(*ssn), *gt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := m3ua.NewConfig(uint32(*opc), uint32(*dpc), m3params.ServiceIndSCCP, 0, 0, 1).EnableHeartbeat(0, 0)
+
+	if *connect != "" {
+		conn, err := transport.DialM3UA(ctx, *connect, cfg, local, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("connected to %s", *connect)
+		sc.run(ctx, conn)
+		return
+	}
+
+	l, err := transport.ListenM3UA(*listen, cfg, local)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	log.Printf("listening on %s", *listen)
+
+	for {
+		conn, err := l.Accept(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("failed to accept: %v", err)
+			continue
+		}
+		log.Print("association established")
+		go sc.run(ctx, conn)
+	}
+}
+
+// run controls the calls on the connection until it is closed.
+func (sc *scf) run(ctx context.Context, conn transport.Conn) {
+	ep := tcap.NewEndpoint(conn)
+	ep.ReceiveTimeout = sc.timeout
+	go sc.serve(ctx, ep)
+	if err := ep.Serve(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("association closed: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func newSSF(t *testing.T, tr *tariff) (*ssf, *Accounts) {
+	t.Helper()
+	tcap.DisableLogging()
+
+	b, err := os.ReadFile("testdata/accounts.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := LoadAccounts(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	conn, peer := transport.Pipe()
+	sc := &scf{accounts: accounts, tariff: tr, releaseCause: 31, timeout: time.Second}
+	go sc.run(ctx, peer)
+
+	f := &ssf{ep: tcap.NewEndpoint(conn), timeout: time.Second}
+	go f.ep.Serve(ctx)
+	return f, accounts
+}
+
+func TestCall(t *testing.T) {
+	cases := []struct {
+		description string
+		tariff      *tariff
+		imsi        string
+		duration    time.Duration
+		want        string
+		balance     int
+	}{
+		{
+			"disconnected", &tariff{rate: 1, grant: 30 * time.Second},
+			"001010123456789", 50 * time.Second,
+			"50s charged in 2 periods, disconnected", 50,
+		}, {
+			"credit exhausted", &tariff{rate: 1, grant: 30 * time.Second},
+			"001010123456790", 2 * time.Minute,
+			"45s charged in 2 periods, released by gsmSCF", 0,
+		}, {
+			// 20s at 1 and 10s at 3 in the first period, and 10s at 3 in the second.
+			"tariff switch", &tariff{rate: 1, switchRate: 3, switchAfter: 20 * time.Second, grant: 30 * time.Second},
+			"001010123456789", 40 * time.Second,
+			"40s charged in 2 periods, disconnected", 20,
+		}, {
+			"no account", &tariff{rate: 1, grant: 30 * time.Second},
+			"001010000000000", time.Minute,
+			"0s charged in 0 periods, released by gsmSCF", 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			f, accounts := newSSF(t, c.tariff)
+			f.imsi, f.duration = c.imsi, c.duration
+
+			r, err := f.call(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "result", r.String(), c.want)
+			verify.Values(t, "balance", accounts.Balance(accounts.get(c.imsi)), c.balance)
+			verify.Values(t, "sessions left", f.ep.Sessions(), 0)
+		})
+	}
+}
+
+func TestAffordable(t *testing.T) {
+	tr := &tariff{rate: 2, switchRate: 4}
+	cases := []struct {
+		balance, before, want int
+	}{
+		{100, -1, 50},
+		{100, 60, 50},
+		{100, 20, 35},
+		{1, -1, 0},
+	}
+	for _, c := range cases {
+		verify.Values(t, "affordable", tr.affordable(c.balance, tr.rate, c.before), c.want)
+	}
+	verify.Values(t, "charge", charge(15, 3), 5)
+}
+
+func TestLoadAccountsErrors(t *testing.T) {
+	cases := []struct {
+		yaml, want string
+	}{
+		{"accounts:\n  - balance: 10\n", "account 0: imsi is required"},
+		{"accounts:\n  - imsi: '001'\n    balance: -1\n", "account 0: negative balance: -1"},
+		{"accounts:\n  - imsi: '001'\n    credit: 1\n", "field credit not found"},
+	}
+	for _, c := range cases {
+		if _, err := LoadAccounts([]byte(c.yaml), 0); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: unexpected error: %v", c.want, err)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/camel"
+)
+
+// scf controls the prepaid calls reported with InitialDP.
+type scf struct {
+	accounts *Accounts
+	tariff   *tariff
+
+	releaseCause uint8
+	timeout      time.Duration
+}
+
+// events are the BCSM events requested with RequestReportBCSMEvent.
+var events = []*camel.BCSMEvent{
+	{EventTypeBCSM: camel.RouteSelectFailure, MonitorMode: camel.MonitorModeNotifyAndContinue},
+	{EventTypeBCSM: camel.OCalledPartyBusy, MonitorMode: camel.MonitorModeNotifyAndContinue},
+	{EventTypeBCSM: camel.ONoAnswer, MonitorMode: camel.MonitorModeNotifyAndContinue},
+	{EventTypeBCSM: camel.OAnswer, MonitorMode: camel.MonitorModeNotifyAndContinue},
+	{EventTypeBCSM: camel.ODisconnect, MonitorMode: camel.MonitorModeNotifyAndContinue, LegID: camel.NewSendingSideID(camel.LegType1)},
+	{EventTypeBCSM: camel.ODisconnect, MonitorMode: camel.MonitorModeNotifyAndContinue, LegID: camel.NewSendingSideID(camel.LegType2)},
+	{EventTypeBCSM: camel.OAbandon, MonitorMode: camel.MonitorModeNotifyAndContinue},
+}
+
+func (sc *scf) serve(ctx context.Context, ep *tcap.Endpoint) {
+	for {
+		s, err := ep.Accept(ctx)
+		if err != nil {
+			return
+		}
+		go sc.call(ctx, s)
+	}
+}
+
+// call is a call under the control of the gsmSCF.
+type call struct {
+	sc    *scf
+	s     *tcap.Session
+	invID int
+
+	acct     *Account
+	used     time.Duration
+	switched bool
+}
+
+func (sc *scf) call(ctx context.Context, s *tcap.Session) {
+	defer s.Abort()
+
+	c := &call{sc: sc, s: s}
+	for {
+		t, err := s.Receive(ctx)
+		if err != nil {
+			s.Logger().Warn("failed to receive", "error", err)
+			return
+		}
+		accepted := s.Accept()
+
+		comps, end, err := c.handle(t)
+		if err != nil {
+			s.Logger().Warn("failed to handle", "error", err)
+			return
+		}
+		switch t.Transaction.Type.Code() {
+		case tcap.End, tcap.Abort:
+			return
+		}
+
+		if end {
+			_ = c.send(tcap.NewEnd(0, []byte{}), comps...)
+			return
+		}
+		if len(comps) > 0 || accepted {
+			if err := c.send(tcap.NewContinue(0, 0, []byte{}), comps...); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle returns the components to be sent in response to the message, and
+// whether to end the dialogue.
+func (c *call) handle(t *tcap.TCAP) ([]*tcap.Component, bool, error) {
+	if t.Components == nil {
+		return nil, false, nil
+	}
+
+	var (
+		comps []*tcap.Component
+		end   bool
+	)
+	for _, comp := range t.Components.Component {
+		if comp.Type.Code() != tcap.Invoke {
+			c.s.Logger().Info("received " + comp.ComponentTypeString())
+			continue
+		}
+
+		opCode, invID := comp.OpCode(), int(comp.InvID())
+		arg, err := camel.NewArgument(opCode)
+		if err != nil {
+			comps = append(comps, tcap.NewReject(invID, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil))
+			continue
+		}
+		if arg != nil {
+			if err := camel.UnmarshalParameter(comp.Parameter, arg); err != nil {
+				c.s.Logger().Warn("rejecting mistyped parameter", "error", err)
+				comps = append(comps, tcap.NewReject(invID, tcap.InvokeProblem, tcap.InvokeProblemMistypedParameter, nil))
+				continue
+			}
+		}
+
+		var (
+			r    []*tcap.Component
+			over bool
+		)
+		switch a := arg.(type) {
+		case *camel.InitialDPArg:
+			r, over, err = c.initialDP(a)
+		case *camel.EventReportBCSMArg:
+			over = c.eventReportBCSM(a)
+		case *camel.ApplyChargingReportArg:
+			r, over, err = c.applyChargingReport(a)
+		default:
+			if opCode == camel.ActivityTest {
+				r = []*tcap.Component{tcap.NewReturnResult(invID, camel.ActivityTest, true, true, nil)}
+				break
+			}
+			r = []*tcap.Component{tcap.NewReject(invID, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil)}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		comps = append(comps, r...)
+		end = end || over
+	}
+	return comps, end, nil
+}
+
+// initialDP starts the monitoring and charging of the call, or releases it
+// if the balance is not enough for a second.
+func (c *call) initialDP(arg *camel.InitialDPArg) ([]*tcap.Component, bool, error) {
+	if c.acct != nil {
+		return nil, false, fmt.Errorf("unexpected InitialDP")
+	}
+	c.acct = c.sc.accounts.get(arg.IMSI)
+	c.s.Logger().Info("received InitialDP", "imsi", arg.IMSI, "service_key", arg.ServiceKey, "balance", c.sc.accounts.Balance(c.acct))
+
+	ac, ok := c.applyCharging()
+	if !ok {
+		r, err := c.releaseCall()
+		return r, true, err
+	}
+
+	var comps []*tcap.Component
+	for _, p := range []struct {
+		opCode uint8
+		arg    camel.Parameter
+	}{
+		{camel.RequestReportBCSMEvent, &camel.RequestReportBCSMEventArg{BCSMEvents: events}},
+		{camel.ApplyCharging, ac},
+		{camel.Continue, nil},
+	} {
+		comp, err := c.invoke(p.opCode, p.arg)
+		if err != nil {
+			return nil, false, err
+		}
+		comps = append(comps, comp)
+	}
+	return comps, false, nil
+}
+
+// eventReportBCSM reports whether the call is over with the event.
+func (c *call) eventReportBCSM(arg *camel.EventReportBCSMArg) bool {
+	c.s.Logger().Info("received EventReportBCSM", "event", arg.EventTypeBCSM)
+	return arg.EventTypeBCSM != camel.OAnswer
+}
+
+// applyChargingReport debits the account for the time used, and grants the
+// next period or releases the call if the credit is exhausted.
+//
+// The times reported are in 100 milliseconds.
+func (c *call) applyChargingReport(arg *camel.ApplyChargingReportArg) ([]*tcap.Component, bool, error) {
+	if c.acct == nil {
+		return nil, false, fmt.Errorf("ApplyChargingReport before InitialDP")
+	}
+
+	var units, tenths int
+	switch {
+	case arg.TimeIfNoTariffSwitch != nil:
+		tenths = *arg.TimeIfNoTariffSwitch
+		units = charge(tenths, c.rate())
+	case arg.TimeSinceTariffSwitch != nil:
+		before := 0
+		if arg.TariffSwitchInterval != nil {
+			before = *arg.TariffSwitchInterval
+		}
+		tenths = before + *arg.TimeSinceTariffSwitch
+		units = charge(before, c.sc.tariff.rate) + charge(*arg.TimeSinceTariffSwitch, c.sc.tariff.switchRate)
+		c.switched = true
+	}
+	c.used += time.Duration(tenths) * 100 * time.Millisecond
+	balance := c.sc.accounts.debit(c.acct, units)
+	c.s.Logger().Info("received ApplyChargingReport", "charged", units, "balance", balance)
+
+	if !arg.LegActive {
+		return nil, false, nil
+	}
+	ac, ok := c.applyCharging()
+	if !ok {
+		r, err := c.releaseCall()
+		return r, true, err
+	}
+	comp, err := c.invoke(camel.ApplyCharging, ac)
+	if err != nil {
+		return nil, false, err
+	}
+	return []*tcap.Component{comp}, false, nil
+}
+
+// applyCharging returns the argument of ApplyCharging for the next period,
+// or false if the balance is not enough for a second.
+//
+// The duration and the tariff switch interval are in seconds.
+func (c *call) applyCharging() (*camel.ApplyChargingArg, bool) {
+	before := -1
+	if t := c.sc.tariff; t.switchAfter > 0 && !c.switched {
+		if before = int((t.switchAfter - c.used + time.Second - 1) / time.Second); before <= 0 {
+			before, c.switched = -1, true
+		}
+	}
+
+	secs := c.sc.tariff.affordable(c.sc.accounts.Balance(c.acct), c.rate(), before)
+	if secs < 1 {
+		return nil, false
+	}
+
+	period := int(c.sc.tariff.grant / time.Second)
+	if secs <= period {
+		period = secs
+	}
+	arg := &camel.ApplyChargingArg{
+		MaxCallPeriodDuration:     period * 10,
+		ReleaseIfDurationExceeded: period == secs,
+	}
+	if before > 0 && before < period {
+		arg.TariffSwitchInterval = &before
+	}
+	return arg, true
+}
+
+func (c *call) releaseCall() ([]*tcap.Component, error) {
+	c.s.Logger().Info("releasing the call: credit exhausted")
+	comp, err := c.invoke(camel.ReleaseCall, camel.NewReleaseCallArg(c.sc.releaseCause))
+	if err != nil {
+		return nil, err
+	}
+	return []*tcap.Component{comp}, nil
+}
+
+// rate returns the current rate of the call.
+func (c *call) rate() int {
+	if c.switched {
+		return c.sc.tariff.switchRate
+	}
+	return c.sc.tariff.rate
+}
+
+func (c *call) invoke(opCode uint8, arg camel.Parameter) (*tcap.Component, error) {
+	c.invID++
+	return camel.NewInvoke(c.invID, opCode, arg)
+}
+
+// send sends the message with the components.
+func (c *call) send(tr *tcap.Transaction, comps ...*tcap.Component) error {
+	t := &tcap.TCAP{Transaction: tr}
+	if len(comps) > 0 {
+		t.Components = tcap.NewComponents(comps...)
+	}
+	return c.s.Send(t)
+}
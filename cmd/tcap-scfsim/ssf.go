@@ -0,0 +1,153 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/camel"
+)
+
+// ssf plays the gsmSSF with the loopback.
+//
+// The calls are not made in real time, i.e., each period granted is reported
+// as used at once, up to the duration of the call.
+type ssf struct {
+	ep       *tcap.Endpoint
+	imsi     string
+	duration time.Duration
+	timeout  time.Duration
+}
+
+// result is the outcome of a call made by ssf.
+type result struct {
+	periods  int
+	used     time.Duration
+	released bool
+}
+
+func (r *result) String() string {
+	end := "disconnected"
+	if r.released {
+		end = "released by gsmSCF"
+	}
+	return fmt.Sprintf("%s charged in %d periods, %s", r.used, r.periods, end)
+}
+
+// call makes a call and reports the time used until it is disconnected or
+// released.
+func (f *ssf) call(ctx context.Context) (*result, error) {
+	s, err := f.ep.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Abort()
+
+	invID := 0
+	invoke := func(opCode uint8, arg camel.Parameter) (*tcap.Component, error) {
+		invID++
+		return camel.NewInvoke(invID, opCode, arg)
+	}
+
+	eventType := camel.CollectedInfo
+	idp, err := invoke(camel.InitialDP, &camel.InitialDPArg{ServiceKey: 100, EventTypeBCSM: &eventType, IMSI: f.imsi})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(&tcap.TCAP{
+		Transaction: tcap.NewBegin(0, []byte{}),
+		Dialogue:    tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQWithOID(1, camel.CAPv2GsmSSFToGsmSCF), []byte{}),
+		Components:  tcap.NewComponents(idp),
+	}); err != nil {
+		return nil, err
+	}
+
+	r := &result{}
+	remaining := f.duration
+	for {
+		rctx, cancel := context.WithTimeout(ctx, f.timeout)
+		t, err := s.Receive(rctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		var ac *camel.ApplyChargingArg
+		if t.Components != nil {
+			for _, c := range t.Components.Component {
+				if c.Type.Code() != tcap.Invoke {
+					continue
+				}
+				switch c.OpCode() {
+				case camel.ApplyCharging:
+					ac = &camel.ApplyChargingArg{}
+					if err := camel.UnmarshalParameter(c.Parameter, ac); err != nil {
+						return nil, err
+					}
+				case camel.ReleaseCall:
+					r.released = true
+				}
+			}
+		}
+
+		switch t.Transaction.Type.Code() {
+		case tcap.End:
+			return r, nil
+		case tcap.Abort:
+			return nil, fmt.Errorf("aborted by gsmSCF")
+		}
+		if ac == nil {
+			continue
+		}
+
+		var comps []*tcap.Component
+		if r.periods == 0 {
+			answer, err := invoke(camel.EventReportBCSM, &camel.EventReportBCSMArg{EventTypeBCSM: camel.OAnswer})
+			if err != nil {
+				return nil, err
+			}
+			comps = append(comps, answer)
+		}
+
+		used := time.Duration(ac.MaxCallPeriodDuration) * 100 * time.Millisecond
+		if used > remaining {
+			used = remaining
+		}
+		remaining -= used
+		r.used += used
+		r.periods++
+
+		acr := &camel.ApplyChargingReportArg{PartyToCharge: camel.NewReceivingSideID(camel.LegType1), LegActive: remaining > 0}
+		tenths := int(used / (100 * time.Millisecond))
+		if tsi := ac.TariffSwitchInterval; tsi != nil && *tsi*10 < tenths {
+			before, since := *tsi*10, tenths-*tsi*10
+			acr.TariffSwitchInterval, acr.TimeSinceTariffSwitch = &before, &since
+		} else {
+			acr.TimeIfNoTariffSwitch = &tenths
+		}
+		report, err := invoke(camel.ApplyChargingReport, acr)
+		if err != nil {
+			return nil, err
+		}
+		comps = append(comps, report)
+
+		if remaining == 0 {
+			disconnect, err := invoke(camel.EventReportBCSM, &camel.EventReportBCSMArg{
+				EventTypeBCSM: camel.ODisconnect, LegID: camel.NewReceivingSideID(camel.LegType1),
+			})
+			if err != nil {
+				return nil, err
+			}
+			comps = append(comps, disconnect)
+		}
+
+		if err := s.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{}), Components: tcap.NewComponents(comps...)}); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcapdump decodes TCAP messages and prints them in a tree like Wireshark.
+
+The messages are given as hex strings in the arguments, as lines of hex strings
+in the standard input, as a binary file with -b, or as a pcap/pcapng capture
+with -r, from which TCAP is retrieved through Ethernet/IP/SCTP/M3UA/SCCP.
+
+	tcapdump 62144804111111116b0c...
+	tcapdump -r sigtran.pcapng
+	tcapdump -variant ansi -b query.bin
+
+The names of application contexts and operations are shown for the ones
+registered in the tcap package.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-tcap"
+	_ "github.com/wmnsk/go-tcap/camel"
+	_ "github.com/wmnsk/go-tcap/inap"
+	"github.com/wmnsk/go-tcap/pcap"
+)
+
+func main() {
+	var (
+		capture = flag.String("r", "", "Read frames from the pcap or pcapng file.")
+		binFile = flag.String("b", "", "Read a TCAP message from the binary file.")
+		variant = flag.String("variant", "auto", "TCAP variant to decode as: auto, itu or ansi.")
+		useBER  = flag.Bool("ber", false, "Decode ITU-T TCAP with ParseBER, which accepts indefinite length.")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	v, err := parseVariant(*variant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d := &dumper{w: os.Stdout, variant: v, ber: *useBER}
+
+	switch {
+	case *capture != "":
+		f, err := os.Open(*capture)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		err = d.dumpCapture(f)
+	case *binFile != "":
+		var b []byte
+		if b, err = os.ReadFile(*binFile); err == nil {
+			err = d.dump(b)
+		}
+	case flag.NArg() > 0:
+		for _, arg := range flag.Args() {
+			if err = d.dumpHex(arg); err != nil {
+				break
+			}
+		}
+	default:
+		s := bufio.NewScanner(os.Stdin)
+		s.Buffer(nil, 1<<20)
+		for s.Scan() {
+			if strings.TrimSpace(s.Text()) == "" {
+				continue
+			}
+			if err = d.dumpHex(s.Text()); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = s.Err()
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseVariant(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "auto":
+		return tcap.VariantAuto, nil
+	case "itu":
+		return tcap.VariantITU, nil
+	case "ansi":
+		return tcap.VariantANSI, nil
+	}
+	return 0, fmt.Errorf("unknown variant: %s", s)
+}
+
+// dumper decodes the input and prints the messages.
+type dumper struct {
+	w       io.Writer
+	variant int
+	ber     bool
+}
+
+// decodeHex decodes the hex string, ignoring whitespaces, colons and "0x" prefix.
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	s = strings.NewReplacer(" ", "", "\t", "", ":", "", "\r", "").Replace(s)
+	return hex.DecodeString(s)
+}
+
+func (d *dumper) dumpHex(s string) error {
+	b, err := decodeHex(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex input: %w", err)
+	}
+	return d.dump(b)
+}
+
+// decode parses b as TCAP messages of the variant.
+func (d *dumper) decode(b []byte) ([]tcap.Message, error) {
+	v := d.variant
+	if v == tcap.VariantAuto {
+		v = tcap.DetectVariant(b)
+	}
+	if v != tcap.VariantITU || !d.ber {
+		m, err := tcap.ParseWithVariant(b, v)
+		if err != nil {
+			return nil, err
+		}
+		return []tcap.Message{m}, nil
+	}
+
+	ts, err := tcap.ParseBER(b)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]tcap.Message, len(ts))
+	for i, t := range ts {
+		msgs[i] = t
+	}
+	return msgs, nil
+}
+
+func (d *dumper) dump(b []byte) error {
+	msgs, err := d.decode(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode %x: %w", b, err)
+	}
+
+	p := &printer{w: d.w}
+	for _, m := range msgs {
+		p.printMessage(m)
+	}
+	return nil
+}
+
+// dumpCapture prints the TCAP messages in the capture. The frames that do not
+// contain TCAP are skipped, and the messages failed to decode are reported
+// without stopping.
+func (d *dumper) dumpCapture(r io.Reader) error {
+	c, err := pcap.NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	for n := 1; ; {
+		pkt, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var ferr *pcap.FrameError
+		if errors.As(err, &ferr) {
+			fmt.Fprintf(d.w, "%s\n", err)
+			continue
+		}
+		if pkt == nil {
+			return err
+		}
+
+		fmt.Fprintf(d.w, "Message %d: %s, OPC: %d, DPC: %d, SCCP %s, CdPA: %s, CgPA: %s\n",
+			n, pkt.Time.UTC().Format("2006-01-02 15:04:05.000000"), pkt.OPC, pkt.DPC,
+			pkt.SCCPType, addressString(pkt.CalledPartyAddress), addressString(pkt.CallingPartyAddress),
+		)
+		if err := d.dump(pkt.Payload); err != nil {
+			fmt.Fprintf(d.w, "    %s\n", err)
+		}
+		n++
+	}
+}
+
+// addressString returns the GT and SSN of the SCCP address.
+func addressString(a *params.PartyAddress) string {
+	if a == nil {
+		return ""
+	}
+	if a.GlobalTitle == nil {
+		return fmt.Sprintf("SSN %d", a.SubsystemNumber)
+	}
+	return fmt.Sprintf("%s (SSN %d)", a.Address(), a.SubsystemNumber)
+}
@@ -0,0 +1,181 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-sccp/utils"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/camel"
+	"github.com/wmnsk/go-tcap/pcap"
+)
+
+func beginInvoke(t *testing.T) []byte {
+	t.Helper()
+	b, err := tcap.NewBeginInvokeWithDialogue(
+		0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3,
+		[]byte{0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+const beginInvokeTree = `Transaction Capabilities Application Part
+    begin
+        otid: 11111111
+    dialoguePortion
+        oid: 0.0.17.773.1.1.1
+        dialogueRequest (AARQ)
+            protocol-version: 1
+            application-context-name: 0.4.0.0.1.0.2.3 (locationCancellationContext)
+    components
+        invoke
+            invokeID: 0
+            opCode: localValue: 3 (cancelLocation)
+            parameter
+                SEQUENCE
+                    OCTET STRING: 00010121436587f9
+`
+
+func TestDecodeHex(t *testing.T) {
+	for _, s := range []string{"62 0a 48", "0x620a48", "62:0a:48\r", "\t620A48 "} {
+		b, err := decodeHex(s)
+		if err != nil {
+			t.Fatalf("%q: %s", s, err)
+		}
+		verify.Values(t, s, b, []byte{0x62, 0x0a, 0x48})
+	}
+}
+
+func TestDump(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := &dumper{w: buf, variant: tcap.VariantAuto}
+	if err := d.dump(beginInvoke(t)); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "tree", buf.String(), beginInvokeTree)
+
+	buf.Reset()
+	d.ber = true
+	if err := d.dump(beginInvoke(t)); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "tree with ParseBER", buf.String(), beginInvokeTree)
+}
+
+func TestDumpOperationName(t *testing.T) {
+	dump := func(m *tcap.TCAP) string {
+		t.Helper()
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		d := &dumper{w: buf, variant: tcap.VariantAuto}
+		if err := d.dump(b); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	// 22 is releaseCall in CAP and sendRoutingInfo in MAP.
+	out := dump(&tcap.TCAP{
+		Transaction: tcap.NewBegin(0x11111111, nil),
+		Dialogue:    tcap.NewDialogue(1, 1, tcap.NewAARQWithOID(1, camel.CAPv2GsmSSFToGsmSCF), nil),
+		Components:  tcap.NewComponents(tcap.NewInvoke(0, -1, 22, true, nil)),
+	})
+	if !strings.Contains(out, "opCode: localValue: 22 (releaseCall)\n") {
+		t.Errorf("name in CAP dialogue not shown:\n%s", out)
+	}
+
+	out = dump(&tcap.TCAP{
+		Transaction: tcap.NewContinue(0x11111111, 0x22222222, nil),
+		Components:  tcap.NewComponents(tcap.NewInvoke(1, -1, 22, true, nil)),
+	})
+	if !strings.Contains(out, "opCode: localValue: 22\n") {
+		t.Errorf("ambiguous name shown without Dialogue:\n%s", out)
+	}
+}
+
+func TestDumpANSI(t *testing.T) {
+	b, err := tcap.NewANSIQuery(0x01020304, true,
+		tcap.NewANSIInvoke(1, -1, 0x89, 15, false, true, []byte{0x81, 0x01, 0x00}),
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	d := &dumper{w: buf, variant: tcap.VariantAuto}
+	if err := d.dump(b); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "tree", buf.String(), `Transaction Capabilities Application Part (ANSI)
+    queryWithPermission
+        transactionID: 01020304
+    componentPortion
+        invokeLast
+            componentIDs: 01
+            operationCode: private, family: 0x89, specifier: 15
+            parameterSet
+                [1]: 00
+`)
+}
+
+func TestDumpCapture(t *testing.T) {
+	ai := params.NewAddressIndicator(false, true, false, params.GTITTNPESNAI)
+	gt := func(digits string) *params.GlobalTitle {
+		return params.NewGlobalTitle(
+			params.GTITTNPESNAI, params.TranslationType(0), params.NPISDNTelephony,
+			params.ESBCDEven, params.NAIInternationalNumber, utils.MustBCDEncode(digits),
+		)
+	}
+
+	capture := &bytes.Buffer{}
+	w, err := pcap.NewWriter(capture, pcap.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePacket(&pcap.Packet{
+		Time:                time.Unix(1700000000, 500000),
+		OPC:                 1,
+		DPC:                 2,
+		CalledPartyAddress:  params.NewCalledPartyAddress(ai, 0, 6, gt("1234567890")),
+		CallingPartyAddress: params.NewCallingPartyAddress(ai, 0, 7, gt("9876543210")),
+		Payload:             beginInvoke(t),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	d := &dumper{w: buf, variant: tcap.VariantAuto}
+	if err := d.dumpCapture(capture); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "Message 1: 2023-11-14 22:13:20.000500, OPC: 1, DPC: 2, SCCP UDT, CdPA: 1234567890 (SSN 6), CgPA: 9876543210 (SSN 7)\n"
+	verify.Values(t, "capture", buf.String(), header+beginInvokeTree)
+}
+
+func TestParseVariant(t *testing.T) {
+	for s, want := range map[string]int{"auto": tcap.VariantAuto, "ITU": tcap.VariantITU, "ansi": tcap.VariantANSI} {
+		got, err := parseVariant(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, s, got, want)
+	}
+	if _, err := parseVariant("japan"); err == nil || !strings.Contains(err.Error(), "unknown variant") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
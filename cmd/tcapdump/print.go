@@ -0,0 +1,321 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// printer prints the tree of TCAP message in the style of Wireshark.
+type printer struct {
+	w     io.Writer
+	depth int
+}
+
+func (p *printer) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, "%s%s\n", strings.Repeat("    ", p.depth), fmt.Sprintf(format, args...))
+}
+
+// node prints the title and the children printed by f in one deeper level.
+func (p *printer) node(title string, f func()) {
+	p.line("%s", title)
+	p.depth++
+	f()
+	p.depth--
+}
+
+func (p *printer) printMessage(msg tcap.Message) {
+	switch m := msg.(type) {
+	case *tcap.TCAP:
+		p.printTCAP(m)
+	case *tcap.ANSITCAP:
+		p.printANSI(m)
+	}
+}
+
+func (p *printer) printTCAP(t *tcap.TCAP) {
+	p.node("Transaction Capabilities Application Part", func() {
+		if tx := t.Transaction; tx != nil {
+			p.node(lowerFirst(tx.MessageTypeString()), func() {
+				if tx.OrigTransactionID != nil {
+					p.line("otid: %x", tx.OrigTransactionID.Value)
+				}
+				if tx.DestTransactionID != nil {
+					p.line("dtid: %x", tx.DestTransactionID.Value)
+				}
+				if tx.PAbortCause != nil {
+					p.line("p-abortCause: %s (%x)", tx.AbortCause(), tx.PAbortCause.Value)
+				}
+			})
+		}
+		if t.Dialogue != nil {
+			p.printDialogue(t.Dialogue)
+		}
+		if t.Components != nil {
+			// The names are ambiguous without the application context, e.g.,
+			// 22 is sendRoutingInfo in MAP and releaseCall in CAP, and they
+			// are not shown for such codes if the message has no Dialogue.
+			var acn asn1.ObjectIdentifier
+			if t.Dialogue != nil {
+				acn = t.Dialogue.ApplicationContextOID()
+			}
+			r := tcap.DefaultOperationRegistry.ForContext(acn)
+			p.node("components", func() {
+				for _, c := range t.Components.Component {
+					p.printComponent(c, r)
+				}
+			})
+		}
+	})
+}
+
+func (p *printer) printDialogue(d *tcap.Dialogue) {
+	p.node("dialoguePortion", func() {
+		if oid := d.ObjectIdentifier; oid != nil {
+			if v, err := ber.DecodeObjectIdentifier(oid.Value); err == nil {
+				p.line("oid: %s", v)
+			} else {
+				p.line("oid: %x", oid.Value)
+			}
+		}
+
+		pdu := d.DialoguePDU
+		if pdu == nil {
+			return
+		}
+		p.node(dialoguePDUName(pdu), func() {
+			if pdu.ProtocolVersion != nil {
+				p.line("protocol-version: %s", pdu.Version())
+			}
+			if pdu.ApplicationContextName != nil {
+				acn := fmt.Sprintf("%x", pdu.ApplicationContextName.Value)
+				if oid := pdu.ApplicationContextOID(); oid != nil {
+					acn = oid.String()
+				}
+				if name := pdu.Context(); name != "" {
+					acn += " (" + name + ")"
+				}
+				p.line("application-context-name: %s", acn)
+			}
+			if pdu.Result != nil {
+				p.line("result: %s", valueOrHex(pdu.Result.Value))
+			}
+			if pdu.ResultSourceDiagnostic != nil {
+				p.line("result-source-diagnostic: %s", valueOrHex(pdu.ResultSourceDiagnostic.Value))
+			}
+			if pdu.AbortSource != nil {
+				p.line("abort-source: %x", pdu.AbortSource.Value)
+			}
+			if pdu.UserInformation != nil {
+				p.node("user-information", func() {
+					p.printValues(pdu.UserInformation.Value)
+				})
+			}
+		})
+	})
+}
+
+func (p *printer) printComponent(c *tcap.Component, r *tcap.OperationRegistry) {
+	p.node(c.ComponentTypeString(), func() {
+		if c.InvokeID != nil {
+			p.line("invokeID: %d", c.InvID())
+		}
+		if c.LinkedID != nil && len(c.LinkedID.Value) > 0 {
+			p.line("linkedID: %d", c.LinkedID.Value[0])
+		}
+		if c.OperationCode != nil {
+			p.line("opCode: %s%s", codeString(c.OperationCode), nameSuffix(codeName(c.OperationCode, r.OperationName)))
+		}
+		if c.ErrorCode != nil {
+			p.line("errorCode: %s%s", codeString(c.ErrorCode), nameSuffix(codeName(c.ErrorCode, r.ErrorName)))
+		}
+		if c.ProblemCode != nil {
+			p.line("problem: [%d] %x", c.ProblemCode.Tag.Code(), c.ProblemCode.Value)
+		}
+		if c.Parameter != nil {
+			p.node("parameter", func() {
+				v, err := c.ParameterValue()
+				if err != nil {
+					p.line("%x", c.Parameter.Value)
+					return
+				}
+				p.printValue(v)
+			})
+		}
+	})
+}
+
+func (p *printer) printANSI(t *tcap.ANSITCAP) {
+	p.node("Transaction Capabilities Application Part (ANSI)", func() {
+		p.node(t.PackageTypeString(), func() {
+			if t.TransactionID != nil {
+				p.line("transactionID: %x", t.TransactionID.Value)
+			}
+			if t.PAbortCause != nil {
+				p.line("p-abortCause: %x", t.PAbortCause.Value)
+			}
+			if t.UserAbortInformation != nil {
+				p.line("userAbortInformation: %x", t.UserAbortInformation.Value)
+			}
+		})
+		if t.Dialogue != nil {
+			p.node("dialoguePortion", func() {
+				if oid := t.ApplicationContextOID(); oid != nil {
+					p.line("applicationContext: %s%s", oid, nameSuffix(tcap.LookupApplicationContext(oid)))
+				} else {
+					p.line("%x", t.Dialogue.Value)
+				}
+			})
+		}
+		if t.Components != nil {
+			p.node("componentPortion", func() {
+				for _, c := range t.Components.Component {
+					p.printANSIComponent(c)
+				}
+			})
+		}
+	})
+}
+
+func (p *printer) printANSIComponent(c *tcap.ANSIComponent) {
+	p.node(c.ComponentTypeString(), func() {
+		if c.ComponentID != nil {
+			p.line("componentIDs: %x", c.ComponentID.Value)
+		}
+		if c.OperationCode != nil {
+			kind := "private"
+			if c.IsNational() {
+				kind = "national"
+			}
+			op := c.OpCode()
+			p.line("operationCode: %s, family: %#02x, specifier: %d", kind, op>>8, op&0xff)
+		}
+		if c.ErrorCode != nil {
+			p.line("errorCode: %#02x", c.ErrCode())
+		}
+		if c.ProblemCode != nil {
+			pt, ps := c.Problem()
+			p.line("problemCode: type: %d, specifier: %d", pt, ps)
+		}
+		if c.Parameter != nil && len(c.Parameter.Value) > 0 {
+			p.node("parameterSet", func() {
+				p.printValues(c.Parameter.Value)
+			})
+		}
+	})
+}
+
+// printValues prints the BER values in b, or the hex dump if b cannot be
+// decoded as BER.
+func (p *printer) printValues(b []byte) {
+	vals, err := ber.ParseValues(b)
+	if err != nil {
+		p.line("%x", b)
+		return
+	}
+	for _, v := range vals {
+		p.printValue(v)
+	}
+}
+
+func (p *printer) printValue(v *ber.Value) {
+	if !v.Constructed {
+		p.line("%s: %s", v.TagString(), primitiveString(v))
+		return
+	}
+	p.node(v.TagString(), func() {
+		for _, e := range v.Elements {
+			p.printValue(e)
+		}
+	})
+}
+
+// primitiveString returns the contents of the primitive value, decoding the
+// universal types that can be shown in a readable form.
+func primitiveString(v *ber.Value) string {
+	if v.Class == ber.Universal {
+		switch v.Tag {
+		case ber.TagInteger, ber.TagEnumerated:
+			if i, err := v.Int(); err == nil {
+				return fmt.Sprintf("%d", i)
+			}
+		case ber.TagBoolean:
+			if b, err := v.Bool(); err == nil {
+				return fmt.Sprintf("%t", b)
+			}
+		case ber.TagObjectIdentifier:
+			if oid, err := v.ObjectIdentifier(); err == nil {
+				return oid.String() + nameSuffix(tcap.LookupApplicationContext(oid))
+			}
+		case ber.TagNull:
+			return "NULL"
+		}
+	}
+	return fmt.Sprintf("%x", v.Contents)
+}
+
+func dialoguePDUName(pdu *tcap.DialoguePDU) string {
+	switch pdu.Type.Code() {
+	case tcap.AARQ:
+		return "dialogueRequest (AARQ)"
+	case tcap.AARE:
+		return "dialogueResponse (AARE)"
+	case tcap.ABRT:
+		return "dialogueAbort (ABRT)"
+	}
+	return fmt.Sprintf("unknown (%#x)", pdu.Type)
+}
+
+// codeString returns the Operation Code or Error Code in the form of "localValue: N".
+func codeString(ie *tcap.IE) string {
+	if ie.Tag == 0x06 {
+		if oid, err := ber.DecodeObjectIdentifier(ie.Value); err == nil {
+			return "globalValue: " + oid.String()
+		}
+	}
+	v, err := ber.DecodeInteger(ie.Value)
+	if err != nil {
+		return fmt.Sprintf("%x", ie.Value)
+	}
+	return fmt.Sprintf("localValue: %d", v)
+}
+
+// valueOrHex returns the nested BER values in one line, or hex dump if it cannot be decoded.
+func valueOrHex(b []byte) string {
+	v, err := ber.ParseValue(b)
+	if err != nil {
+		return fmt.Sprintf("%x", b)
+	}
+	return v.String()
+}
+
+// codeName returns the name of the local code looked up with name, or empty
+// string if the code is empty.
+func codeName(ie *tcap.IE, name func(uint8) string) string {
+	if len(ie.Value) == 0 {
+		return ""
+	}
+	return name(ie.Value[0])
+}
+
+func nameSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,183 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+)
+
+// generator runs the script in the dialogues at the rate given.
+type generator struct {
+	ep     *tcap.Endpoint
+	script *script
+
+	count       int
+	rate        float64
+	outstanding int
+	timeout     time.Duration
+
+	imsiPrefix, msisdnPrefix string
+
+	stats *stats
+}
+
+// run starts count dialogues and waits for all of them to finish.
+func (g *generator) run(ctx context.Context) {
+	var tick <-chan time.Time
+	if g.rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / g.rate))
+		defer t.Stop()
+		tick = t.C
+	}
+	sem := make(chan struct{}, g.outstanding)
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	for i := 0; i < g.count; i++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			start := time.Now()
+			err := g.dialogue(ctx, i)
+			g.stats.add(time.Since(start), err)
+		}(i)
+	}
+}
+
+// dialogue executes the steps of script in a new Session.
+func (g *generator) dialogue(ctx context.Context, i int) error {
+	steps, err := g.script.steps(randomVars(i, g.imsiPrefix, g.msisdnPrefix))
+	if err != nil {
+		return err
+	}
+	s, err := g.ep.NewSession()
+	if err != nil {
+		return err
+	}
+	defer s.Abort()
+
+	for n, st := range steps {
+		if st.send {
+			if err := s.Send(st.msg); err != nil {
+				return fmt.Errorf("step %d: %w", n, err)
+			}
+			continue
+		}
+
+		rctx, cancel := context.WithTimeout(ctx, g.timeout)
+		t, err := s.Receive(rctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("step %d: %w", n, err)
+		}
+		if err := match(st.desc, t); err != nil {
+			return fmt.Errorf("step %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+// stats collects the results of the dialogues.
+type stats struct {
+	mu        sync.Mutex
+	start     time.Time
+	latencies []time.Duration
+	failures  map[string]int
+}
+
+func newStats() *stats {
+	return &stats{start: time.Now(), failures: map[string]int{}}
+}
+
+func (s *stats) add(latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failures[err.Error()]++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+}
+
+// succeeded returns the number of the dialogues that succeeded.
+func (s *stats) succeeded() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.latencies)
+}
+
+// report prints the summary of the results.
+func (s *stats) report(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := 0
+	for _, n := range s.failures {
+		failed += n
+	}
+	total := len(s.latencies) + failed
+	elapsed := time.Since(s.start)
+
+	fmt.Fprintf(w, "dialogues: %d, succeeded: %d, failed: %d, elapsed: %v", total, len(s.latencies), failed, elapsed.Round(time.Millisecond))
+	if elapsed > 0 {
+		fmt.Fprintf(w, ", rate: %.1f/s", float64(total)/elapsed.Seconds())
+	}
+	fmt.Fprintln(w)
+
+	if l := s.latencies; len(l) > 0 {
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		var sum time.Duration
+		for _, d := range l {
+			sum += d
+		}
+		fmt.Fprintf(w, "latency: min %v, avg %v, p50 %v, p95 %v, p99 %v, max %v\n",
+			l[0], sum/time.Duration(len(l)), percentile(l, 50), percentile(l, 95), percentile(l, 99), l[len(l)-1],
+		)
+	}
+
+	reasons := make([]string, 0, len(s.failures))
+	for r := range s.failures {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+	for _, r := range reasons {
+		fmt.Fprintf(w, "failure: %d x %s\n", s.failures[r], r)
+	}
+}
+
+// percentile returns the p-th percentile of the sorted durations.
+func percentile(l []time.Duration, p int) time.Duration {
+	i := (len(l)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return l[i]
+}
@@ -0,0 +1,152 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command tcapgen generates TCAP traffic by running a call-flow script in many
+dialogues, and reports the latency and success statistics.
+
+The script is a YAML document with the steps to send messages and to expect
+messages from the peer, each of which is a message in the format of the
+scenario package. The TIDs are allocated at random for each dialogue, and
+{{.IMSI}}, {{.MSISDN}} and {{.Index}} in the script are replaced with the
+random IMSI and MSISDN and the index of the dialogue.
+
+	name: routing info for SM
+	steps:
+	  - send:
+	      type: begin
+	      dialogue: {type: AARQ, context: shortMsgGatewayContext, version: 3}
+	  - expect:
+	      type: continue
+	  - send:
+	      type: continue
+	      components:
+	        - type: invoke
+	          invokeID: 1
+	          opCode: sendRoutingInfoForSM
+	          map:
+	            msisdn: {natureofaddress: 1, numberingplan: 1, digits: "{{.MSISDN}}"}
+	  - expect:
+	      type: end
+	      components: [{type: returnResultLast, invokeID: 1}]
+
+The messages expected are compared by the message type, the dialogue type and
+the types, invoke IDs and codes of the components.
+
+With -transport loopback, the peer is played in the process by sending the
+messages expected and expecting the messages sent. With -transport m3ua, the
+dialogues are run against the peer at -addr over SCTP/M3UA/SCCP.
+
+	tcapgen -script sri-sm.yaml -n 10000 -rate 500 -outstanding 100
+	tcapgen -script sri-sm.yaml -transport m3ua -addr 127.0.0.2:2905 -remote-gt 819011111111
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/wmnsk/go-m3ua"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func main() {
+	var (
+		scriptFile   = flag.String("script", "", "Call-flow script in YAML.")
+		count        = flag.Int("n", 1, "Number of dialogues to run.")
+		rate         = flag.Float64("rate", 0, "Dialogues to start per second. 0 means as fast as possible.")
+		outstanding  = flag.Int("outstanding", 10, "Maximum number of dialogues in progress.")
+		timeout      = flag.Duration("timeout", 5*time.Second, "Time to wait for each message expected.")
+		imsiPrefix   = flag.String("imsi-prefix", "00101", "Prefix of random IMSIs.")
+		msisdnPrefix = flag.String("msisdn-prefix", "81", "Prefix of random MSISDNs.")
+		transp       = flag.String("transport", "loopback", "Transport to use: loopback or m3ua.")
+		delay        = flag.Duration("delay", 0, "Delay of the responses from the peer in loopback.")
+		addr         = flag.String("addr", "127.0.0.2:2905", "Remote IP and Port to connect to with m3ua.")
+		opc          = flag.Uint("opc", 1, "Originating Point Code with m3ua.")
+		dpc          = flag.Uint("dpc", 2, "Destination Point Code with m3ua.")
+		localGT      = flag.String("local-gt", "819000000000", "Calling Party GT with m3ua.")
+		localSSN     = flag.Uint("local-ssn", 8, "Calling Party SSN with m3ua.")
+		remoteGT     = flag.String("remote-gt", "819011111111", "Called Party GT with m3ua.")
+		remoteSSN    = flag.Uint("remote-ssn", 6, "Called Party SSN with m3ua.")
+		verbose      = flag.Bool("v", false, "Print the logs of the tcap package and the peer in loopback.")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	if !*verbose {
+		tcap.DisableLogging()
+	}
+
+	if *scriptFile == "" || *count <= 0 || *outstanding <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	b, err := os.ReadFile(*scriptFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := loadScript(b)
+	if err != nil {
+		log.Fatalf("invalid script %s: %v", *scriptFile, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var conn transport.Conn
+	switch *transp {
+	case "loopback":
+		var peer transport.Conn
+		conn, peer = transport.Pipe()
+		r := &responder{ep: tcap.NewEndpoint(peer), script: s, delay: *delay}
+		r.ep.Logger = tcap.DefaultLogger().With("side", "responder")
+		go r.ep.Serve(ctx)
+		go r.serve(ctx)
+	case "m3ua":
+		local, err := transport.GTAddress(uint8(*localSSN), *localGT)
+		if err != nil {
+			log.Fatal(err)
+		}
+		remote, err := transport.GTAddress(uint8(*remoteSSN), *remoteGT)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg := m3ua.NewConfig(uint32(*opc), uint32(*dpc), m3params.ServiceIndSCCP, 0, 0, 1).EnableHeartbeat(0, 0)
+		if conn, err = transport.DialM3UA(ctx, *addr, cfg, local, remote); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown transport: %s", *transp)
+	}
+
+	g := &generator{
+		ep:           tcap.NewEndpoint(conn),
+		script:       s,
+		count:        *count,
+		rate:         *rate,
+		outstanding:  *outstanding,
+		timeout:      *timeout,
+		imsiPrefix:   *imsiPrefix,
+		msisdnPrefix: *msisdnPrefix,
+		stats:        newStats(),
+	}
+	go g.ep.Serve(ctx)
+
+	if s.name != "" {
+		fmt.Printf("running %q in %d dialogues\n", s.name, *count)
+	}
+	g.run(ctx)
+	g.stats.report(os.Stdout)
+	g.ep.Close()
+
+	if g.stats.succeeded() != *count {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func loadTestScript(t *testing.T) *script {
+	t.Helper()
+	b, err := os.ReadFile("testdata/sri-sm.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := loadScript(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newGenerator(t *testing.T, s *script, delay time.Duration) *generator {
+	t.Helper()
+	tcap.DisableLogging()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	conn, peer := transport.Pipe()
+	r := &responder{ep: tcap.NewEndpoint(peer), script: s, delay: delay}
+	go r.ep.Serve(ctx)
+	go r.serve(ctx)
+
+	g := &generator{
+		ep:           tcap.NewEndpoint(conn),
+		script:       s,
+		outstanding:  4,
+		timeout:      time.Second,
+		imsiPrefix:   "00101",
+		msisdnPrefix: "81",
+		stats:        newStats(),
+	}
+	go g.ep.Serve(ctx)
+	return g
+}
+
+func TestGenerator(t *testing.T) {
+	g := newGenerator(t, loadTestScript(t), 0)
+	g.count, g.rate = 50, 1000
+	g.run(context.Background())
+
+	verify.Values(t, "succeeded", g.stats.succeeded(), 50)
+	verify.Values(t, "sessions left", g.ep.Sessions(), 0)
+
+	buf := &bytes.Buffer{}
+	g.stats.report(buf)
+	if !strings.HasPrefix(buf.String(), "dialogues: 50, succeeded: 50, failed: 0") || !strings.Contains(buf.String(), "latency: min") {
+		t.Errorf("unexpected report:\n%s", buf)
+	}
+}
+
+func TestGeneratorTimeout(t *testing.T) {
+	g := newGenerator(t, loadTestScript(t), 100*time.Millisecond)
+	g.count, g.timeout = 3, 10*time.Millisecond
+	g.run(context.Background())
+
+	verify.Values(t, "succeeded", g.stats.succeeded(), 0)
+	buf := &bytes.Buffer{}
+	g.stats.report(buf)
+	if !strings.Contains(buf.String(), "failure: 3 x step 1: context deadline exceeded") {
+		t.Errorf("unexpected report:\n%s", buf)
+	}
+}
+
+func TestScriptVars(t *testing.T) {
+	steps, err := loadTestScript(t).steps(&Vars{IMSI: "001010123456789", MSISDN: "819012345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "steps", len(steps), 4)
+
+	b, err := steps[2].msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MSISDN in TBCD.
+	if !bytes.Contains(b, []byte{0x91, 0x18, 0x09, 0x21, 0x43, 0x65, 0x87}) {
+		t.Errorf("MSISDN is not in the Invoke: %x", b)
+	}
+}
+
+func TestLoadScriptErrors(t *testing.T) {
+	cases := []struct {
+		description, yaml, want string
+	}{
+		{"no steps", "name: empty\n", "the first step must send begin"},
+		{"expect first", "steps:\n  - expect: {type: begin}\n", "the first step must send begin"},
+		{"send and expect", "steps:\n  - send: {type: begin}\n  - send: {type: end}\n    expect: {type: end}\n", "step 1: either send or expect"},
+		{"unknown field", "steps:\n  - sned: {type: begin}\n", "field sned not found"},
+		{"unknown var", "steps:\n  - send: {type: begin, otid: '{{.OTID}}'}\n", "OTID"},
+		{"invalid message", "steps:\n  - send: {type: begin}\n  - expect: {type: finish}\n", `step 1: scenario: invalid type: "finish"`},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := loadScript([]byte(c.yaml)); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRandomVars(t *testing.T) {
+	v := randomVars(7, "00101", "8190")
+	verify.Values(t, "Index", v.Index, 7)
+	if len(v.IMSI) != 15 || !strings.HasPrefix(v.IMSI, "00101") || strings.Trim(v.IMSI, "0123456789") != "" {
+		t.Errorf("unexpected IMSI: %s", v.IMSI)
+	}
+	if len(v.MSISDN) != 12 || !strings.HasPrefix(v.MSISDN, "8190") {
+		t.Errorf("unexpected MSISDN: %s", v.MSISDN)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	l := make([]time.Duration, 100)
+	for i := range l {
+		l[i] = time.Duration(i+1) * time.Millisecond
+	}
+	verify.Values(t, "p50", percentile(l, 50), 50*time.Millisecond)
+	verify.Values(t, "p99", percentile(l, 99), 99*time.Millisecond)
+	verify.Values(t, "p99 of one", percentile(l[:1], 99), time.Millisecond)
+}
@@ -0,0 +1,60 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+)
+
+// responder plays the peer side of the script, i.e., it expects the messages
+// to send and sends the messages to expect. It is used with the loopback.
+type responder struct {
+	ep     *tcap.Endpoint
+	script *script
+	delay  time.Duration
+}
+
+func (r *responder) serve(ctx context.Context) {
+	for i := 0; ; i++ {
+		s, err := r.ep.Accept(ctx)
+		if err != nil {
+			return
+		}
+		go r.dialogue(ctx, s, i)
+	}
+}
+
+func (r *responder) dialogue(ctx context.Context, s *tcap.Session, i int) {
+	defer s.Abort()
+
+	steps, err := r.script.steps(randomVars(i, "", ""))
+	if err != nil {
+		s.Logger().Warn("failed to execute the script", "error", err)
+		return
+	}
+	for n, st := range steps {
+		if !st.send {
+			if r.delay > 0 {
+				time.Sleep(r.delay)
+			}
+			if err := s.Send(st.msg); err != nil {
+				return
+			}
+			continue
+		}
+
+		t, err := s.Receive(ctx)
+		if err != nil {
+			return
+		}
+		if err := match(st.desc, t); err != nil {
+			s.Logger().Warn("unexpected message", "step", n, "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"text/template"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/scenario"
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a call flow executed in each dialogue.
+//
+// The steps are the messages sent to the peer and the messages expected from
+// it, in the format of the scenario package. The TIDs can be omitted as they
+// are allocated for each dialogue.
+type Script struct {
+	Name        string  `yaml:"name,omitempty"`
+	Description string  `yaml:"description,omitempty"`
+	Steps       []*Step `yaml:"steps"`
+}
+
+// Step is either a message to send or a message to expect.
+type Step struct {
+	Send   *scenario.Message `yaml:"send,omitempty"`
+	Expect *scenario.Message `yaml:"expect,omitempty"`
+}
+
+// Vars are the values given to the script template for each dialogue.
+type Vars struct {
+	Index  int
+	IMSI   string
+	MSISDN string
+}
+
+// step is a Step compiled for a dialogue.
+type step struct {
+	send bool
+	msg  *tcap.TCAP
+	desc *scenario.Message
+}
+
+// script is the template of Script.
+type script struct {
+	name string
+	tmpl *template.Template
+}
+
+// loadScript parses the script as a template and checks that it compiles.
+func loadScript(b []byte) (*script, error) {
+	tmpl, err := template.New("script").Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	s := &script{tmpl: tmpl}
+
+	sc, err := s.execute(&Vars{IMSI: "001010000000000", MSISDN: "810000000000"})
+	if err != nil {
+		return nil, err
+	}
+	if len(sc.Steps) == 0 || sc.Steps[0].Send == nil || sc.Steps[0].Send.Type != "begin" {
+		return nil, fmt.Errorf("the first step must send begin")
+	}
+	if _, err := compile(sc); err != nil {
+		return nil, err
+	}
+	s.name = sc.Name
+	return s, nil
+}
+
+func (s *script) execute(v *Vars) (*Script, error) {
+	buf := &bytes.Buffer{}
+	if err := s.tmpl.Execute(buf, v); err != nil {
+		return nil, err
+	}
+
+	d := yaml.NewDecoder(buf)
+	d.KnownFields(true)
+	sc := &Script{}
+	if err := d.Decode(sc); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// steps returns the steps of the script for the dialogue with the Vars.
+func (s *script) steps(v *Vars) ([]*step, error) {
+	sc, err := s.execute(v)
+	if err != nil {
+		return nil, err
+	}
+	return compile(sc)
+}
+
+func compile(sc *Script) ([]*step, error) {
+	steps := make([]*step, len(sc.Steps))
+	for i, st := range sc.Steps {
+		m := st.Send
+		switch {
+		case st.Send != nil && st.Expect != nil, st.Send == nil && st.Expect == nil:
+			return nil, fmt.Errorf("step %d: either send or expect must be given", i)
+		case st.Expect != nil:
+			m = st.Expect
+		}
+
+		// TIDs are overwritten by the Session.
+		switch m.Type {
+		case "begin", "continue":
+			if m.OTID == "" {
+				m.OTID = "00000000"
+			}
+		}
+		switch m.Type {
+		case "end", "continue", "abort":
+			if m.DTID == "" {
+				m.DTID = "00000000"
+			}
+		}
+
+		t, err := m.TCAP()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		steps[i] = &step{send: st.Send != nil, msg: t, desc: m}
+	}
+	return steps, nil
+}
+
+// match checks if the message received matches the expected one.
+//
+// The message type, the dialogue type and the types, invoke IDs and codes of
+// the components are compared. The parameters are not.
+func match(want *scenario.Message, got *tcap.TCAP) error {
+	m, err := scenario.NewMessage(got, false)
+	if err != nil {
+		return err
+	}
+	if m.Type != want.Type {
+		return fmt.Errorf("got %s, want %s", m.Type, want.Type)
+	}
+	if want.Dialogue != nil {
+		if m.Dialogue == nil || m.Dialogue.Type != want.Dialogue.Type {
+			return fmt.Errorf("no %s in %s", want.Dialogue.Type, m.Type)
+		}
+	}
+	if len(m.Components) != len(want.Components) {
+		return fmt.Errorf("got %d components, want %d", len(m.Components), len(want.Components))
+	}
+	for i, c := range want.Components {
+		g := m.Components[i]
+		switch {
+		case g.Type != c.Type:
+			return fmt.Errorf("component %d: got %s, want %s", i, g.Type, c.Type)
+		case c.InvokeID != nil && (g.InvokeID == nil || *g.InvokeID != *c.InvokeID):
+			return fmt.Errorf("component %d: unexpected invokeID", i)
+		case !sameCode(c.OpCode, g.OpCode):
+			return fmt.Errorf("component %d: unexpected opCode", i)
+		case !sameCode(c.ErrorCode, g.ErrorCode):
+			return fmt.Errorf("component %d: unexpected errorCode", i)
+		}
+	}
+	return nil
+}
+
+func sameCode(want, got *scenario.Code) bool {
+	if want == nil {
+		return true
+	}
+	if got == nil {
+		return false
+	}
+	return want.Local == got.Local && want.Global.Equal(got.Global)
+}
+
+// randomVars returns the Vars with the IMSI and MSISDN of random digits after
+// the prefixes.
+func randomVars(i int, imsiPrefix, msisdnPrefix string) *Vars {
+	return &Vars{
+		Index:  i,
+		IMSI:   randomDigits(imsiPrefix, 15),
+		MSISDN: randomDigits(msisdnPrefix, 12),
+	}
+}
+
+func randomDigits(prefix string, n int) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for b.Len() < n {
+		b.WriteByte('0' + byte(rand.IntN(10)))
+	}
+	return b.String()
+}
@@ -6,17 +6,36 @@ package tcap_test
 
 import (
 	"encoding"
+	"errors"
 	"testing"
 
 	"github.com/pascaldekloe/goe/verify"
 	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
 )
 
 type serializable interface {
 	encoding.BinaryMarshaler
+	encoding.BinaryAppender
+	MarshalTo(b []byte) error
 	MarshalLen() int
 }
 
+func withParameter(c *tcap.Component, v *ber.Value) *tcap.Component {
+	if err := c.SetParameter(v); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// withoutResult removes the optional SEQUENCE of the Operation Code and the
+// Parameter from ReturnResult.
+func withoutResult(c *tcap.Component) *tcap.Component {
+	c.ResultRetres, c.OperationCode, c.Parameter = nil, nil, nil
+	c.SetLength()
+	return c
+}
+
 var testcases = []struct {
 	description string
 	structured  serializable
@@ -260,6 +279,16 @@ var testcases = []struct {
 			0x67, 0x0b, 0x49, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x4a, 0x01, 0x00, 0xfa, 0xce,
 		},
 		parseFunc: func(b []byte) (serializable, error) { return tcap.ParseTransaction(b) },
+	}, {
+		description: "Transaction/U-Abort",
+		structured: func() *tcap.Transaction {
+			t := tcap.NewAbort(0xdeadbeef, 0, []byte{0xfa, 0xce})
+			t.PAbortCause = nil
+			t.SetLength()
+			return t
+		}(),
+		serialized: []byte{0x67, 0x08, 0x49, 0x04, 0xde, 0xad, 0xbe, 0xef, 0xfa, 0xce},
+		parseFunc:  func(b []byte) (serializable, error) { return tcap.ParseTransaction(b) },
 	},
 	// Dialogue Portion
 	{
@@ -388,6 +417,15 @@ var testcases = []struct {
 
 			return v, nil
 		},
+	}, {
+		description: "Components/returnResultLast without result",
+		structured:  tcap.NewComponents(withoutResult(tcap.NewReturnResult(1, 0, true, true, nil))),
+		serialized: []byte{
+			0x6c, 0x05, 0xa2, 0x03, 0x02, 0x01, 0x01,
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return tcap.ParseComponents(b)
+		},
 	}, {
 		description: "Components/returnError",
 		structured:  tcap.NewComponents(tcap.NewReturnError(0, 71, true, []byte{0xde, 0xad, 0xbe, 0xef})),
@@ -404,6 +442,47 @@ var testcases = []struct {
 
 			return v, nil
 		},
+	}, {
+		description: "Components/returnError with ENUMERATED parameter",
+		structured:  tcap.NewComponents(withParameter(tcap.NewReturnError(0, 1, true, nil), ber.NewEnumerated(2))),
+		serialized: []byte{
+			0x6c, 0x0b, 0xa3, 0x09, 0x02, 0x01, 0x00, 0x02, 0x01, 0x01, 0x0a, 0x01, 0x02,
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return tcap.ParseComponents(b)
+		},
+	}, {
+		description: "Components/reject",
+		structured:  tcap.NewComponents(tcap.NewReject(1, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil)),
+		serialized: []byte{
+			0x6c, 0x08, 0xa4, 0x06, 0x02, 0x01, 0x01, 0x81, 0x01, 0x01,
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return tcap.ParseComponents(b)
+		},
+	}, {
+		description: "Components/invoke with linkedID",
+		structured:  tcap.NewComponents(tcap.NewInvoke(5, 1, 3, true, nil)),
+		serialized: []byte{
+			0x6c, 0x0b, 0xa1, 0x09, 0x02, 0x01, 0x05, 0x80, 0x01, 0x01, 0x02, 0x01, 0x03,
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return tcap.ParseComponents(b)
+		},
+	}, {
+		description: "Components/returnError without parameter and invoke",
+		structured: tcap.NewComponents(
+			tcap.NewReturnError(1, 1, true, nil),
+			tcap.NewInvoke(2, -1, 3, true, nil),
+		),
+		serialized: []byte{
+			0x6c, 0x10,
+			0xa3, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01,
+			0xa1, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03,
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return tcap.ParseComponents(b)
+		},
 	},
 	// Generic IE
 	{
@@ -440,6 +519,26 @@ func TestCodec(t *testing.T) {
 			}
 		})
 
+		t.Run("Append / "+c.description, func(t *testing.T) {
+			prefix := []byte{0xde, 0xad}
+			b, err := c.structured.AppendBinary(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", b, append([]byte{0xde, 0xad}, c.serialized...))
+		})
+
+		t.Run("MarshalTo / "+c.description, func(t *testing.T) {
+			b := make([]byte, len(c.serialized))
+			if err := c.structured.MarshalTo(b); err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", b, c.serialized)
+			if err := c.structured.MarshalTo(b[:len(b)-1]); err == nil {
+				t.Error("no error with short buffer")
+			}
+		})
+
 		t.Run("Len / "+c.description, func(t *testing.T) {
 			if got, want := c.structured.MarshalLen(), len(c.serialized); got != want {
 				t.Fatalf("got %v want %v", got, want)
@@ -447,3 +546,106 @@ func TestCodec(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTransactionTruncated(t *testing.T) {
+	for _, b := range [][]byte{
+		{0x62},
+		{0x62, 0x06, 0x48, 0x04, 0xde},
+		{0x65, 0x08, 0x48, 0x04, 0xde, 0xad, 0xbe, 0xef},
+		{0x67, 0x09, 0x49, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x4a},
+	} {
+		if _, err := tcap.ParseTransaction(b); err == nil {
+			t.Errorf("%x: no error", b)
+		}
+	}
+}
+
+// NewReject tagged the Component with the tag of Invoke, a1 instead of a4, so
+// the peer took the Reject for an Invoke without Operation Code.
+func TestNewRejectTag(t *testing.T) {
+	c := tcap.NewReject(1, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil)
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "tag", b[0], uint8(0xa4))
+
+	comps, err := tcap.ParseComponents(append([]byte{0x6c, uint8(len(b))}, b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "type", comps.Component[0].ComponentTypeString(), "reject")
+}
+
+func TestParseShortTID(t *testing.T) {
+	for _, c := range []struct {
+		b    []byte
+		otid uint32
+		dtid uint32
+	}{
+		{[]byte{0x64, 0x03, 0x49, 0x01, 0x05}, 0, 0x05},
+		{[]byte{0x67, 0x04, 0x49, 0x02, 0x01, 0x02}, 0, 0x0102},
+		{[]byte{0x62, 0x03, 0x48, 0x01, 0x07}, 0x07, 0},
+		{[]byte{0x65, 0x08, 0x48, 0x03, 0x01, 0x02, 0x03, 0x49, 0x01, 0x05}, 0x010203, 0x05},
+	} {
+		m, err := tcap.Parse(c.b)
+		if err != nil {
+			t.Errorf("%x: %v", c.b, err)
+			continue
+		}
+		verify.Values(t, "OTID", m.OTID(), c.otid)
+		verify.Values(t, "DTID", m.DTID(), c.dtid)
+
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, "marshalled", b, c.b)
+	}
+
+	for _, b := range [][]byte{
+		{0x64, 0x02, 0x49, 0x00},
+		{0x62, 0x07, 0x48, 0x05, 0x01, 0x02, 0x03, 0x04, 0x05},
+		{0x65, 0x08, 0x48, 0x04, 0x01, 0x02, 0x03, 0x04, 0x49, 0x00},
+	} {
+		var tidErr *tcap.InvalidTIDError
+		if _, err := tcap.Parse(b); !errors.As(err, &tidErr) {
+			t.Errorf("%x: unexpected error: %v", b, err)
+		}
+	}
+}
+
+func TestParameterValue(t *testing.T) {
+	oid, err := ber.NewObjectIdentifier([]int{0, 4, 0, 0, 1, 0, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := ber.NewSequence(
+		ber.Implicit(0, ber.NewOctetString([]byte{0x00, 0x01, 0x01, 0x21})),
+		ber.Explicit(1, oid),
+		ber.NewNull(),
+	)
+
+	c := tcap.NewInvoke(1, -1, 2, true, nil)
+	if err := c.SetParameter(param); err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := tcap.ParseComponent(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.ParameterValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, param)
+
+	if err := c.SetParameter(ber.NewPrimitive(ber.ContextSpecific, 50, nil)); err == nil {
+		t.Error("expected error for the tag that cannot be represented as IE")
+	}
+}
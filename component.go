@@ -7,6 +7,8 @@ package tcap
 import (
 	"fmt"
 	"io"
+
+	"github.com/wmnsk/go-tcap/ber"
 )
 
 // Component Type definitions.
@@ -119,7 +121,7 @@ func NewInvoke(invID, lkID, opCode int, isLocal bool, param []byte) *Component {
 
 	if param != nil {
 		if err := c.setParameterFromBytes(param); err != nil {
-			logf("failed to build Parameter: %v", err)
+			DefaultLogger().Warn("failed to build Parameter", "invoke_id", invID, "opcode", opCode, "error", err)
 		}
 	}
 
@@ -149,7 +151,7 @@ func NewReturnResult(invID, opCode int, isLocal, isLast bool, param []byte) *Com
 
 	if param != nil {
 		if err := c.setParameterFromBytes(param); err != nil {
-			logf("failed to build Parameter: %v", err)
+			DefaultLogger().Warn("failed to build Parameter", "invoke_id", invID, "opcode", opCode, "error", err)
 		}
 	}
 
@@ -171,7 +173,7 @@ func NewReturnError(invID, errCode int, isLocal bool, param []byte) *Component {
 
 	if param != nil {
 		if err := c.setParameterFromBytes(param); err != nil {
-			logf("failed to build Parameter: %v", err)
+			DefaultLogger().Warn("failed to build Parameter", "invoke_id", invID, "error", err)
 		}
 	}
 
@@ -182,7 +184,7 @@ func NewReturnError(invID, errCode int, isLocal bool, param []byte) *Component {
 // NewReject returns a new single Reject Component.
 func NewReject(invID, problemType int, problemCode uint8, param []byte) *Component {
 	c := &Component{
-		Type: NewContextSpecificConstructorTag(Invoke),
+		Type: NewContextSpecificConstructorTag(Reject),
 		InvokeID: &IE{
 			Tag:    NewUniversalPrimitiveTag(2),
 			Length: 1,
@@ -197,7 +199,7 @@ func NewReject(invID, problemType int, problemCode uint8, param []byte) *Compone
 
 	if param != nil {
 		if err := c.setParameterFromBytes(param); err != nil {
-			logf("failed to build Parameter: %v", err)
+			DefaultLogger().Warn("failed to build Parameter", "invoke_id", invID, "error", err)
 		}
 	}
 
@@ -225,113 +227,107 @@ func NewErrorCode(code int, isLocal bool) *IE {
 
 // MarshalBinary returns the byte sequence generated from a Components instance.
 func (c *Components) MarshalBinary() ([]byte, error) {
-	b := make([]byte, c.MarshalLen())
-	if err := c.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return marshalBinary(c)
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (c *Components) MarshalTo(b []byte) error {
-	b[0] = uint8(c.Tag)
-	b[1] = c.Length
+	return marshalTo(c, b)
+}
 
-	cursor := 2
+// AppendBinary appends the byte sequence generated from a Components instance to b.
+func (c *Components) AppendBinary(b []byte) ([]byte, error) {
+	return c.appendTo(b, MarshalOptions{})
+}
+
+func (c *Components) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, c.Tag)
 	for _, comp := range c.Component {
-		compLen := comp.MarshalLen()
-		if err := comp.MarshalTo(b[cursor : cursor+compLen]); err != nil {
-			return err
+		var err error
+		if b, err = comp.appendTo(b, o); err != nil {
+			return nil, err
 		}
-		cursor += compLen
 	}
-	return nil
+	return endTLV(b, off, c.Tag, c.Length, o)
 }
 
-// MarshalBinary returns the byte sequence generated from a Components instance.
+// MarshalBinary returns the byte sequence generated from a Component instance.
 func (c *Component) MarshalBinary() ([]byte, error) {
-	b := make([]byte, c.MarshalLen())
-	if err := c.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return marshalBinary(c)
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (c *Component) MarshalTo(b []byte) error {
-	b[0] = uint8(c.Type)
-	b[1] = c.Length
+	return marshalTo(c, b)
+}
 
-	var offset = 2
-	if field := c.InvokeID; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
+// AppendBinary appends the byte sequence generated from a Component instance to b.
+func (c *Component) AppendBinary(b []byte) ([]byte, error) {
+	return c.appendTo(b, MarshalOptions{})
+}
 
+func (c *Component) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, c.Type)
+
+	var err error
 	switch c.Type.Code() {
 	case Invoke:
-		if field := c.LinkedID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
+		b, err = appendIEs(b, o, c.InvokeID, c.LinkedID, c.OperationCode, c.Parameter)
+	case ReturnResultLast, ReturnResultNotLast:
+		if b, err = appendIE(b, c.InvokeID, o); err == nil {
+			b, err = c.appendResult(b, o)
 		}
+	case ReturnError:
+		b, err = appendIEs(b, o, c.InvokeID, c.ErrorCode, c.Parameter)
+	case Reject:
+		b, err = appendIEs(b, o, c.InvokeID, c.ProblemCode)
+	default:
+		b, err = appendIE(b, c.InvokeID, o)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if field := c.OperationCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+	return endTLV(b, off, c.Type, c.Length, o)
+}
 
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
-	case ReturnResultLast, ReturnResultNotLast:
-		if field := c.ResultRetres; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+// appendResult appends the SEQUENCE in ReturnResult, which is ResultRetres
+// containing the Operation Code and the Parameter.
+func (c *Component) appendResult(b []byte, o MarshalOptions) ([]byte, error) {
+	rr := c.ResultRetres
+	switch {
+	case rr == nil:
+		return appendIEs(b, o, c.OperationCode, c.Parameter)
+	case c.OperationCode == nil && c.Parameter == nil:
+		return appendIE(b, rr, o)
+	}
 
-		if field := c.OperationCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+	b, off := beginTLV(b, rr.Tag)
+	b, err := appendIEs(b, o, c.OperationCode, c.Parameter)
+	if err != nil {
+		return nil, err
+	}
+	return endTLV(b, off, rr.Tag, rr.Length, o)
+}
 
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
-	case ReturnError:
-		if field := c.ErrorCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+// resultLen returns the serial length of the SEQUENCE appended by appendResult.
+func (c *Component) resultLen() int {
+	l := 0
+	if field := c.OperationCode; field != nil {
+		l += field.MarshalLen()
+	}
+	if field := c.Parameter; field != nil {
+		l += field.MarshalLen()
+	}
 
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
-	case Reject:
-		if field := c.ProblemCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
+	rr := c.ResultRetres
+	switch {
+	case rr == nil:
+		return l
+	case c.OperationCode == nil && c.Parameter == nil:
+		return rr.MarshalLen()
 	}
-	return nil
+	return tlvLen(l)
 }
 
 // ParseComponents parses given byte sequence as an Components.
@@ -351,23 +347,19 @@ func (c *Components) UnmarshalBinary(b []byte) error {
 
 	c.Tag = Tag(b[0])
 	c.Length = b[1]
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
 
-	var offset = 2
-	for {
-		if len(b) < 2 {
-			break
-		}
-
-		comp, err := ParseComponent(b[offset:])
+	for offset+2 <= len(b) {
+		comp := &Component{}
+		n, err := comp.unmarshal(b[offset:])
 		if err != nil {
 			return err
 		}
 		c.Component = append(c.Component, comp)
-
-		if len(b[offset:]) == int(comp.Length)+2 {
-			break
-		}
-		b = b[offset+comp.MarshalLen()-2:]
+		offset += n
 	}
 	return nil
 }
@@ -383,34 +375,48 @@ func ParseComponent(b []byte) (*Component, error) {
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an Component.
 func (c *Component) UnmarshalBinary(b []byte) error {
+	_, err := c.unmarshal(b)
+	return err
+}
+
+// unmarshal sets the values of the Component at the beginning of b, and
+// returns the number of bytes consumed.
+func (c *Component) unmarshal(b []byte) (int, error) {
 	if len(b) < 2 {
-		return io.ErrUnexpectedEOF
+		return 0, io.ErrUnexpectedEOF
 	}
 	c.Type = Tag(b[0])
 	c.Length = b[1]
+	n, offset, err := parseLength(b)
+	if err != nil {
+		return 0, err
+	}
+	// b may be followed by other Components.
+	if l := offset + n; l < len(b) {
+		b = b[:l]
+	}
+	return len(b), c.parseFields(b, offset)
+}
 
+func (c *Component) parseFields(b []byte, offset int) error {
 	var err error
-	var offset = 2
-	c.InvokeID, err = ParseIE(b[offset:])
+	c.InvokeID, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += c.InvokeID.MarshalLen()
 
 	switch c.Type.Code() {
 	case Invoke:
-		/* TODO: Implement LinkedID Parser.
-		c.LinkedID, err = ParseIE(b[offset:])
-		if err != nil {
-			return err
+		if offset < len(b) && Tag(b[offset]) == NewContextSpecificPrimitiveTag(0) {
+			c.LinkedID, offset, err = parseIEAt(b, offset)
+			if err != nil {
+				return err
+			}
 		}
-		offset += c.LinkedID.MarshalLen()
-		*/
-		c.OperationCode, err = ParseIE(b[offset:])
+		c.OperationCode, offset, err = parseIEAt(b, offset)
 		if err != nil {
 			return err
 		}
-		offset += c.OperationCode.MarshalLen()
 
 		if offset >= len(b) {
 			return nil
@@ -420,18 +426,21 @@ func (c *Component) UnmarshalBinary(b []byte) error {
 			return err
 		}
 	case ReturnResultLast, ReturnResultNotLast:
-		c.ResultRetres, err = ParseIE(b[offset:])
+		// The SEQUENCE of the Operation Code and the Parameter is optional.
+		if offset >= len(b) {
+			return nil
+		}
+		c.ResultRetres, _, err = parseIEAt(b, offset)
 		if err != nil {
 			return err
 		}
 		offset = 0
-		b = c.ResultRetres.Value[offset:]
+		b = c.ResultRetres.Value
 
-		c.OperationCode, err = ParseIE(b[offset:])
+		c.OperationCode, offset, err = parseIEAt(b, offset)
 		if err != nil {
 			return err
 		}
-		offset += c.OperationCode.MarshalLen()
 
 		if offset >= len(b) {
 			return nil
@@ -441,11 +450,10 @@ func (c *Component) UnmarshalBinary(b []byte) error {
 			return err
 		}
 	case ReturnError:
-		c.ErrorCode, err = ParseIE(b[offset:])
+		c.ErrorCode, offset, err = parseIEAt(b, offset)
 		if err != nil {
 			return err
 		}
-		offset += c.ErrorCode.MarshalLen()
 
 		if offset >= len(b) {
 			return nil
@@ -455,7 +463,7 @@ func (c *Component) UnmarshalBinary(b []byte) error {
 			return err
 		}
 	case Reject:
-		c.ProblemCode, err = ParseIE(b[offset:])
+		c.ProblemCode, _, err = parseIEAt(b, offset)
 		if err != nil {
 			return err
 		}
@@ -465,6 +473,8 @@ func (c *Component) UnmarshalBinary(b []byte) error {
 
 // setParameterFromBytes sets the Parameter field from given bytes.
 //
+// The given bytes are treated as the contents of SEQUENCE, which is the most common
+// form of the Parameter. Use SetParameter to set the Parameter with arbitrary tag.
 // It sets the value as it is if the given bytes cannot be parsed as (a set of) IE.
 func (c *Component) setParameterFromBytes(b []byte) error {
 	if b == nil {
@@ -472,7 +482,7 @@ func (c *Component) setParameterFromBytes(b []byte) error {
 	}
 	ies, err := ParseMultiIEs(b)
 	if err != nil {
-		logf("failed to parse given bytes, building it anyway: %v", err)
+		DefaultLogger().Debug("failed to parse given bytes as IEs, building Parameter anyway", "error", err)
 		c.Parameter = &IE{
 			// TODO: tag should not be determined here.
 			Tag:   NewUniversalConstructorTag(0x10),
@@ -491,6 +501,32 @@ func (c *Component) setParameterFromBytes(b []byte) error {
 	return nil
 }
 
+// SetParameter sets the Parameter field from the BER value given, and updates
+// the length of Component.
+//
+// Unlike the param given to NewInvoke and others, the tag of Parameter is taken
+// from v, so that any type such as OCTET STRING or tagged value can be used.
+func (c *Component) SetParameter(v *ber.Value) error {
+	ie, err := NewIEFromValue(v)
+	if err != nil {
+		return err
+	}
+
+	c.Parameter = ie
+	c.SetLength()
+	return nil
+}
+
+// ParameterValue returns the Parameter field as a BER value.
+//
+// It returns nil without error if the Component has no Parameter.
+func (c *Component) ParameterValue() (*ber.Value, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+	return c.Parameter.BERValue()
+}
+
 // SetValsFrom sets the values from IE parsed by ParseBER.
 func (c *Components) SetValsFrom(berParsed *IE) error {
 	c.Tag = berParsed.Tag
@@ -557,7 +593,11 @@ func (c *Components) SetValsFrom(berParsed *IE) error {
 
 // MarshalLen returns the serial length of Components.
 func (c *Components) MarshalLen() int {
-	var l = 2
+	return tlvLen(c.valueLen())
+}
+
+func (c *Components) valueLen() int {
+	l := 0
 	for _, comp := range c.Component {
 		l += comp.MarshalLen()
 	}
@@ -566,7 +606,11 @@ func (c *Components) MarshalLen() int {
 
 // MarshalLen returns the serial length of Component.
 func (c *Component) MarshalLen() int {
-	var l = 2 + c.InvokeID.MarshalLen()
+	return tlvLen(c.valueLen())
+}
+
+func (c *Component) valueLen() int {
+	l := c.InvokeID.MarshalLen()
 	switch c.Type.Code() {
 	case Invoke:
 		if field := c.LinkedID; field != nil {
@@ -579,15 +623,7 @@ func (c *Component) MarshalLen() int {
 			l += field.MarshalLen()
 		}
 	case ReturnResultLast, ReturnResultNotLast:
-		if field := c.ResultRetres; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := c.OperationCode; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := c.Parameter; field != nil {
-			l += field.MarshalLen()
-		}
+		l += c.resultLen()
 	case ReturnError:
 		if field := c.ErrorCode; field != nil {
 			l += field.MarshalLen()
@@ -605,11 +641,10 @@ func (c *Component) MarshalLen() int {
 
 // SetLength sets the length in Length field.
 func (c *Components) SetLength() {
-	c.Length = 0
 	for _, comp := range c.Component {
 		comp.SetLength()
-		c.Length += uint8(comp.MarshalLen())
 	}
+	c.Length = lengthOctet(c.valueLen())
 }
 
 // SetLength sets the length in Length field.
@@ -642,31 +677,19 @@ func (c *Component) SetLength() {
 		l += c.SequenceTag.MarshalLen()
 	}
 	if field := c.ResultRetres; field != nil {
-		field.Length = uint8(l)
+		field.Length = lengthOctet(l)
 	}
-	c.Length = uint8(c.MarshalLen() - 2)
+	c.Length = lengthOctet(c.valueLen())
 }
 
 // ComponentTypeString returns the Component Type in string.
 func (c *Component) ComponentTypeString() string {
-	switch c.Type.Code() {
-	case Invoke:
-		return "invoke"
-	case ReturnResultLast:
-		return "returnResultLast"
-	case ReturnError:
-		return "returnError"
-	case Reject:
-		return "reject"
-	case ReturnResultNotLast:
-		return "returnResultNotLast"
-	}
-	return ""
+	return componentTypeNames[c.Type.Code()]
 }
 
 // InvID returns the InvID in string.
 func (c *Component) InvID() uint8 {
-	if c.InvokeID != nil {
+	if c.InvokeID != nil && len(c.InvokeID.Value) > 0 {
 		return c.InvokeID.Value[0]
 	}
 	return 0
@@ -675,9 +698,13 @@ func (c *Component) InvID() uint8 {
 // OpCode returns the OpCode in string.
 func (c *Component) OpCode() uint8 {
 	if c.Type.Code() == ReturnError {
-		return c.ErrorCode.Value[0]
+		if c.ErrorCode != nil && len(c.ErrorCode.Value) > 0 {
+			return c.ErrorCode.Value[0]
+		}
 	} else if c.Type.Code() != Reject {
-		return c.OperationCode.Value[0]
+		if c.OperationCode != nil && len(c.OperationCode.Value) > 0 {
+			return c.OperationCode.Value[0]
+		}
 	}
 	return 0
 }
@@ -691,17 +718,55 @@ func (c *Components) String() string {
 	)
 }
 
+// OperationName returns the name of operation registered in DefaultOperationRegistry.
+//
+// It returns empty string if the Component has no Operation Code or the operation
+// is not registered. As the Component does not know its application context, it
+// also returns empty string if the code has different names in the user
+// protocols registered. Use OperationName of DefaultOperationRegistry.ForContext
+// to look it up in the application context.
+func (c *Component) OperationName() string {
+	if c.OperationCode == nil || len(c.OperationCode.Value) == 0 {
+		return ""
+	}
+	return DefaultOperationRegistry.OperationName(c.OperationCode.Value[0])
+}
+
+// ErrorName returns the name of error registered in DefaultOperationRegistry.
+//
+// It returns empty string if the Component has no Error Code or the error
+// is not registered, or if the code has different names in the user protocols
+// registered, as OperationName does.
+func (c *Component) ErrorName() string {
+	if c.ErrorCode == nil || len(c.ErrorCode.Value) == 0 {
+		return ""
+	}
+	return DefaultOperationRegistry.ErrorName(c.ErrorCode.Value[0])
+}
+
 // String returns Component in human readable string.
+//
+// The names of operation and error are shown next to the codes if they are
+// registered in DefaultOperationRegistry.
 func (c *Component) String() string {
-	return fmt.Sprintf("{Type: %#x, Length: %d, ResultRetres: %v, InvokeID: %v, LinkedID: %v, OperationCode: %v, ErrorCode: %v, ProblemCode: %v, Parameter: %v}",
+	return fmt.Sprintf("{Type: %#x, Length: %d, ResultRetres: %v, InvokeID: %v, LinkedID: %v, OperationCode: %v%s, ErrorCode: %v%s, ProblemCode: %v, Parameter: %v}",
 		c.Type,
 		c.Length,
 		c.ResultRetres,
 		c.InvokeID,
 		c.LinkedID,
 		c.OperationCode,
+		nameSuffix(c.OperationName()),
 		c.ErrorCode,
+		nameSuffix(c.ErrorName()),
 		c.ProblemCode,
 		c.Parameter,
 	)
 }
+
+func nameSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
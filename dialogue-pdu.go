@@ -266,8 +266,8 @@ func NewAUDT(protover int, context, contextver uint8, userinfo ...*IE) *Dialogue
 
 // MarshalBinary returns the byte sequence generated from a DialoguePDU.
 func (d *DialoguePDU) MarshalBinary() ([]byte, error) {
-	b := make([]byte, d.MarshalLen())
-	if err := d.MarshalTo(b); err != nil {
+	b, err := marshalBinary(d)
+	if err != nil {
 		return nil, fmt.Errorf("failed to marshal DialoguePDU: %w", err)
 	}
 	return b, nil
@@ -275,105 +275,33 @@ func (d *DialoguePDU) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *DialoguePDU) MarshalTo(b []byte) error {
-	if len(b) < 2 {
-		return io.ErrUnexpectedEOF
-	}
+	return marshalTo(d, b)
+}
 
-	b[0] = uint8(d.Type)
-	b[1] = d.Length
+// AppendBinary appends the byte sequence generated from a DialoguePDU to b.
+func (d *DialoguePDU) AppendBinary(b []byte) ([]byte, error) {
+	return d.appendTo(b, MarshalOptions{})
+}
 
+func (d *DialoguePDU) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, d.Type)
+
+	var err error
 	switch d.Type.Code() {
 	case AARQ:
-		return d.marshalAARQTo(b)
+		b, err = appendIEs(b, o, d.ProtocolVersion, d.ApplicationContextName, d.UserInformation)
 	case AARE:
-		return d.marshalAARETo(b)
+		b, err = appendIEs(b, o, d.ProtocolVersion, d.ApplicationContextName, d.Result, d.ResultSourceDiagnostic, d.UserInformation)
 	case ABRT:
-		return d.marshalABRTTo(b)
+		b, err = appendIEs(b, o, d.AbortSource, d.UserInformation)
 	default:
-		return &InvalidCodeError{Code: d.Type.Code()}
-	}
-}
-
-func (d *DialoguePDU) marshalAARQTo(b []byte) error {
-	var offset = 2
-	if field := d.ProtocolVersion; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.ApplicationContextName; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func (d *DialoguePDU) marshalAARETo(b []byte) error {
-	var offset = 2
-	if field := d.ProtocolVersion; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.ApplicationContextName; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.Result; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.ResultSourceDiagnostic; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func (d *DialoguePDU) marshalABRTTo(b []byte) error {
-	var offset = 2
-	if field := d.AbortSource; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
+		return nil, &InvalidCodeError{Code: d.Type.Code()}
 	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return endTLV(b, off, d.Type, d.Length, o)
 }
 
 // ParseDialoguePDU parses given byte sequence as an DialoguePDU.
@@ -407,19 +335,19 @@ func (d *DialoguePDU) UnmarshalBinary(b []byte) error {
 }
 
 func (d *DialoguePDU) parseAARQFromBytes(b []byte) error {
-	var err error
-	var offset = 2
-	d.ProtocolVersion, err = ParseIE(b[offset:])
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
+	d.ProtocolVersion, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.ProtocolVersion.MarshalLen()
 
-	d.ApplicationContextName, err = ParseIE(b[offset:])
+	d.ApplicationContextName, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.ApplicationContextName.MarshalLen()
 
 	if offset < len(b)-1 {
 		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
@@ -434,31 +362,29 @@ func (d *DialoguePDU) parseAARQFromBytes(b []byte) error {
 }
 
 func (d *DialoguePDU) parseAAREFromBytes(b []byte) error {
-	var err error
-	var offset = 2
-	d.ProtocolVersion, err = ParseIE(b[offset:])
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
+	d.ProtocolVersion, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.ProtocolVersion.MarshalLen()
 
-	d.ApplicationContextName, err = ParseIE(b[offset:])
+	d.ApplicationContextName, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.ApplicationContextName.MarshalLen()
 
-	d.Result, err = ParseIE(b[offset:])
+	d.Result, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.Result.MarshalLen()
 
-	d.ResultSourceDiagnostic, err = ParseIE(b[offset:])
+	d.ResultSourceDiagnostic, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.ResultSourceDiagnostic.MarshalLen()
 
 	if offset < len(b)-1 {
 		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
@@ -473,13 +399,14 @@ func (d *DialoguePDU) parseAAREFromBytes(b []byte) error {
 }
 
 func (d *DialoguePDU) parseABRTFromBytes(b []byte) error {
-	var err error
-	var offset = 2
-	d.AbortSource, err = ParseIE(b[offset:])
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
+	d.AbortSource, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.AbortSource.MarshalLen()
 	if offset < len(b)-1 {
 		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
 			d.UserInformation, err = ParseIE(b[offset:])
@@ -494,7 +421,11 @@ func (d *DialoguePDU) parseABRTFromBytes(b []byte) error {
 
 // MarshalLen returns the serial length of DialoguePDU.
 func (d *DialoguePDU) MarshalLen() int {
-	l := 2
+	return tlvLen(d.valueLen())
+}
+
+func (d *DialoguePDU) valueLen() int {
+	l := 0
 	switch d.Type.Code() {
 	case AARQ:
 		if field := d.ProtocolVersion; field != nil {
@@ -559,7 +490,7 @@ func (d *DialoguePDU) SetLength() {
 	if field := d.UserInformation; field != nil {
 		field.SetLength()
 	}
-	d.Length = uint8(d.MarshalLen() - 2)
+	d.Length = lengthOctet(d.valueLen())
 }
 
 // DialogueType returns the name of Dialogue Type in string.
@@ -595,6 +526,13 @@ func (d *DialoguePDU) Context() string {
 	}
 
 	if d.Type.Code() == AARQ || d.Type.Code() == AARE {
+		if name := LookupApplicationContext(d.ApplicationContextOID()); name != "" {
+			return name
+		}
+		if !isMAPContext(appCtx) {
+			return ""
+		}
+
 		switch appCtx.Value[7] {
 		case NetworkLocUpContext:
 			return "networkLocUpContext"
@@ -692,6 +630,10 @@ func (d *DialoguePDU) ContextVersion() string {
 		return ""
 	}
 
+	if !isMAPContext(appCtx) {
+		return ""
+	}
+
 	if d.Type.Code() == AARQ || d.Type.Code() == AARE {
 		return fmt.Sprintf("%d", appCtx.Value[8])
 	}
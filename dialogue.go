@@ -39,7 +39,7 @@ func NewDialogue(oid, ver uint8, pdu *DialoguePDU, payload []byte) *Dialogue {
 		},
 		SingleAsn1Type: &IE{
 			Tag:    NewContextSpecificConstructorTag(0),
-			Length: uint8(pdu.MarshalLen()),
+			Length: lengthOctet(pdu.MarshalLen()),
 		},
 		DialoguePDU: pdu,
 		Payload:     payload,
@@ -51,8 +51,8 @@ func NewDialogue(oid, ver uint8, pdu *DialoguePDU, payload []byte) *Dialogue {
 
 // MarshalBinary returns the byte sequence generated from a Dialogue.
 func (d *Dialogue) MarshalBinary() ([]byte, error) {
-	b := make([]byte, d.MarshalLen())
-	if err := d.MarshalTo(b); err != nil {
+	b, err := marshalBinary(d)
+	if err != nil {
 		return nil, fmt.Errorf("failed to marshal Dialogue: %w", err)
 	}
 	return b, nil
@@ -60,43 +60,55 @@ func (d *Dialogue) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *Dialogue) MarshalTo(b []byte) error {
-	if len(b) < 4 {
-		return io.ErrUnexpectedEOF
-	}
-	b[0] = uint8(d.Tag)
-	b[1] = d.Length
-	b[2] = uint8(d.ExternalTag)
-	b[3] = d.ExternalLength
+	return marshalTo(d, b)
+}
 
-	var offset = 4
-	if field := d.ObjectIdentifier; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
+// AppendBinary appends the byte sequence generated from a Dialogue to b.
+//
+// The DialoguePDU is put in the single-ASN1-type, and the Payload is appended
+// after it, inside the external and counted in its length.
+func (d *Dialogue) AppendBinary(b []byte) ([]byte, error) {
+	return d.appendTo(b, MarshalOptions{})
+}
+
+func (d *Dialogue) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	return d.appendPortion(b, o, d.Payload)
+}
 
-	if d.SingleAsn1Type == nil {
-		copy(b[offset:], d.Payload)
-		return nil
+// appendWith appends the Dialogue Portion without the Payload.
+func (d *Dialogue) appendWith(b []byte, o MarshalOptions) ([]byte, error) {
+	return d.appendPortion(b, o, nil)
+}
+
+func (d *Dialogue) appendPortion(b []byte, o MarshalOptions, payload []byte) ([]byte, error) {
+	b, off := beginTLV(b, d.Tag)
+	b, extOff := beginTLV(b, d.ExternalTag)
+	b, err := appendIE(b, d.ObjectIdentifier, o)
+	if err != nil {
+		return nil, err
 	}
 
-	if field := d.DialoguePDU; field != nil {
-		d.SingleAsn1Type.Value = make([]byte, field.MarshalLen())
-		if err := field.MarshalTo(d.SingleAsn1Type.Value); err != nil {
-			return err
+	if field := d.SingleAsn1Type; field != nil {
+		if pdu := d.DialoguePDU; pdu != nil {
+			var asn1Off int
+			b, asn1Off = beginTLV(b, field.Tag)
+			if b, err = pdu.appendTo(b, o); err != nil {
+				return nil, err
+			}
+			b, err = endTLV(b, asn1Off, field.Tag, field.Length, o)
+		} else {
+			b, err = appendIE(b, field, o)
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
+	b = append(b, payload...)
 
-	d.SingleAsn1Type.SetLength()
-	if err := d.SingleAsn1Type.MarshalTo(b[offset : offset+d.SingleAsn1Type.MarshalLen()]); err != nil {
-		return err
+	if b, err = endTLV(b, extOff, d.ExternalTag, d.ExternalLength, o); err != nil {
+		return nil, err
 	}
-	offset += d.SingleAsn1Type.MarshalLen()
-
-	copy(b[offset:], d.Payload)
-
-	return nil
+	return endTLV(b, off, d.Tag, d.Length, o)
 }
 
 // ParseDialogue parses given byte sequence as an Dialogue.
@@ -117,22 +129,30 @@ func (d *Dialogue) UnmarshalBinary(b []byte) error {
 
 	d.Tag = Tag(b[0])
 	d.Length = b[1]
-	d.ExternalTag = Tag(b[2])
-	d.ExternalLength = b[3]
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	var offset = 4
-	d.ObjectIdentifier, err = ParseIE(b[offset:])
+	if len(b) < offset+3 {
+		return io.ErrUnexpectedEOF
+	}
+	d.ExternalTag = Tag(b[offset])
+	d.ExternalLength = b[offset+1]
+	_, extOffset, err := parseLength(b[offset:])
 	if err != nil {
 		return err
 	}
-	offset += d.ObjectIdentifier.MarshalLen()
+	offset += extOffset
 
-	d.SingleAsn1Type, err = ParseIE(b[offset:])
+	d.ObjectIdentifier, offset, err = parseIEAt(b, offset)
+	if err != nil {
+		return err
+	}
+	d.SingleAsn1Type, offset, err = parseIEAt(b, offset)
 	if err != nil {
 		return err
 	}
-	offset += d.SingleAsn1Type.MarshalLen()
 
 	d.DialoguePDU, err = ParseDialoguePDU(d.SingleAsn1Type.Value)
 	if err != nil {
@@ -187,30 +207,51 @@ func (d *Dialogue) SetValsFrom(berParsed *IE) error {
 	return nil
 }
 
-// MarshalLen returns the serial length of Dialogue.
+// MarshalLen returns the serial length of Dialogue, including the Payload.
 func (d *Dialogue) MarshalLen() int {
-	l := 4
+	return tlvLen(tlvLen(d.externalLen() + len(d.Payload)))
+}
+
+// portionLen returns the serial length of Dialogue without the Payload.
+func (d *Dialogue) portionLen() int {
+	return tlvLen(tlvLen(d.externalLen()))
+}
+
+// externalLen returns the length of the contents of the external.
+func (d *Dialogue) externalLen() int {
+	l := 0
 	if field := d.ObjectIdentifier; field != nil {
 		l += field.MarshalLen()
 	}
-	if field := d.DialoguePDU; field != nil {
-		l += field.MarshalLen() + 2 // 2 = singleAsn1Type IE Header
+	if field := d.SingleAsn1Type; field != nil {
+		if pdu := d.DialoguePDU; pdu != nil {
+			l += tlvLen(pdu.MarshalLen())
+		} else {
+			l += field.MarshalLen()
+		}
 	}
-
-	return l + len(d.Payload)
+	return l
 }
 
-// SetLength sets the length in Length field.
+// SetLength sets the length in Length field, including the Payload.
 func (d *Dialogue) SetLength() {
+	d.setLength(len(d.Payload))
+}
+
+// setLength sets the length in Length field with the payload of n octets.
+func (d *Dialogue) setLength(n int) {
 	if d.ObjectIdentifier != nil {
 		d.ObjectIdentifier.SetLength()
 	}
 	if d.DialoguePDU != nil {
 		d.DialoguePDU.SetLength()
+		if d.SingleAsn1Type != nil {
+			d.SingleAsn1Type.Length = lengthOctet(d.DialoguePDU.MarshalLen())
+		}
 	}
 
-	d.Length = uint8(d.MarshalLen() - 2)
-	d.ExternalLength = uint8(d.MarshalLen() - 4)
+	d.ExternalLength = lengthOctet(d.externalLen() + n)
+	d.Length = lengthOctet(tlvLen(d.externalLen() + n))
 }
 
 // String returns the SCCP common header values in human readable format.
@@ -0,0 +1,207 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"io"
+	"sync"
+)
+
+// Marshalable is implemented by TCAP, ANSITCAP, their portions, the components
+// and IE, to be marshalled with MarshalOptions.
+//
+// They are marshalled by appending the children one after another and setting
+// the lengths after the contents, so marshalling a tree is linear in its size.
+type Marshalable interface {
+	appendTo(b []byte, o MarshalOptions) ([]byte, error)
+	MarshalLen() int
+}
+
+// MarshalOptions are the options of marshalling.
+//
+// By default, the lengths are computed from the contents, in the short form for
+// the contents up to 127 octets and in the long form for the longer ones. The
+// Length fields are not used nor updated.
+type MarshalOptions struct {
+	// ShortForm restricts the lengths to the short form, for the peers that
+	// do not accept the long form. The contents longer than 127 octets are
+	// *UnsupportedEncodingError.
+	ShortForm bool
+
+	// Raw writes the Length fields as they are, without checking them against
+	// the contents, e.g., to craft malformed messages for testing. The Length
+	// in the long form, 0x81 to 0x84, is followed by the length of the
+	// contents in that number of octets.
+	Raw bool
+}
+
+// Marshal returns the byte sequence generated from v with the options.
+func (o MarshalOptions) Marshal(v Marshalable) ([]byte, error) {
+	return v.appendTo(make([]byte, 0, v.MarshalLen()), o)
+}
+
+// Append appends the byte sequence generated from v with the options to b.
+func (o MarshalOptions) Append(b []byte, v Marshalable) ([]byte, error) {
+	return v.appendTo(b, o)
+}
+
+func marshalBinary(v Marshalable) ([]byte, error) {
+	return MarshalOptions{}.Marshal(v)
+}
+
+func marshalTo(v Marshalable, b []byte) error {
+	if len(b) < v.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+	_, err := v.appendTo(b[:0], MarshalOptions{})
+	return err
+}
+
+// lengthLen returns the number of the length octets for the contents of n
+// octets.
+func lengthLen(n int) int {
+	l := 1
+	if n > 0x7f {
+		for ; n > 0; n >>= 8 {
+			l++
+		}
+	}
+	return l
+}
+
+// tlvLen returns the serial length of the TLV with the contents of n octets.
+func tlvLen(n int) int {
+	return 1 + lengthLen(n) + n
+}
+
+// lengthOctet returns the first length octet for the contents of n octets,
+// which is the number of the length octets following in the long form.
+func lengthOctet(n int) uint8 {
+	if n > 0x7f {
+		return 0x80 | uint8(lengthLen(n)-1)
+	}
+	return uint8(n)
+}
+
+// beginTLV appends the tag and the placeholder of the length, which is set by
+// endTLV after the contents are appended.
+func beginTLV(b []byte, tag Tag) ([]byte, int) {
+	return append(b, uint8(tag), 0), len(b) + 1
+}
+
+// endTLV sets the length of the contents appended after the length octet at
+// off, or the length given in raw mode. The contents are moved forward to make
+// room for the long form.
+func endTLV(b []byte, off int, tag Tag, length uint8, o MarshalOptions) ([]byte, error) {
+	n := len(b) - off - 1
+	first := length
+	if !o.Raw {
+		if n > 0x7f && o.ShortForm {
+			return nil, &UnsupportedEncodingError{Tag: tag.Code(), Length: n}
+		}
+		first = lengthOctet(n)
+	}
+	b[off] = first
+	if first <= 0x80 {
+		return b, nil
+	}
+
+	k := int(first & 0x7f)
+	if k > 4 {
+		// only in raw mode, where nothing is checked.
+		return b, nil
+	}
+	b = append(b, make([]byte, k)...)
+	copy(b[off+1+k:], b[off+1:len(b)-k])
+	for i := range k {
+		b[off+1+i] = uint8(n >> (8 * (k - 1 - i)))
+	}
+	return b, nil
+}
+
+// appendIEs appends the IEs that are not nil to b.
+func appendIEs(b []byte, o MarshalOptions, ies ...*IE) ([]byte, error) {
+	for _, i := range ies {
+		var err error
+		if b, err = appendIE(b, i, o); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendIE appends the IE to b if not nil.
+func appendIE(b []byte, i *IE, o MarshalOptions) ([]byte, error) {
+	if i == nil {
+		return b, nil
+	}
+	return i.appendTo(b, o)
+}
+
+// Encoder marshals TCAP messages into the buffers reused through sync.Pool, so
+// that marshalling at high rates does not produce garbage.
+//
+// Options can be set before use.
+//
+//	buf, err := enc.Encode(t)
+//	if err != nil { ... }
+//	conn.Write(buf.Bytes())
+//	enc.Put(buf)
+//
+// It is safe for concurrent use.
+type Encoder struct {
+	Options MarshalOptions
+
+	pool sync.Pool
+}
+
+// Buffer is a buffer of Encoder.
+type Buffer struct {
+	b []byte
+}
+
+// Bytes returns the message encoded, which is valid until the Buffer is put
+// back to the Encoder.
+func (b *Buffer) Bytes() []byte {
+	return b.b
+}
+
+// NewEncoder creates a new Encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{pool: sync.Pool{New: func() any { return &Buffer{b: make([]byte, 0, 256)} }}}
+}
+
+// Encode marshals t into a Buffer from the pool. The Buffer should be put back
+// with Put when it is no longer used.
+func (e *Encoder) Encode(t *TCAP) (*Buffer, error) {
+	buf := e.pool.Get().(*Buffer)
+	b, err := e.Options.Append(buf.b[:0], t)
+	if err != nil {
+		e.Put(buf)
+		return nil, err
+	}
+	buf.b = b
+	return buf, nil
+}
+
+// Put puts the Buffer back to the pool.
+func (e *Encoder) Put(buf *Buffer) {
+	// not to keep the huge buffers for the rare huge messages.
+	if cap(buf.b) > 0xffff {
+		return
+	}
+	buf.b = buf.b[:0]
+	e.pool.Put(buf)
+}
+
+// EncodeTo marshals t and writes it to w with a Buffer from the pool.
+func (e *Encoder) EncodeTo(w io.Writer, t *TCAP) (int, error) {
+	buf, err := e.Encode(t)
+	if err != nil {
+		return 0, err
+	}
+	defer e.Put(buf)
+	return w.Write(buf.Bytes())
+}
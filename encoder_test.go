@@ -0,0 +1,98 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+func TestEncoder(t *testing.T) {
+	m := tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})
+	want := mustMarshal(t, m)
+	enc := tcap.NewEncoder()
+
+	for range 3 {
+		buf, err := enc.Encode(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, "encoded", buf.Bytes(), want)
+		enc.Put(buf)
+	}
+
+	w := &bytes.Buffer{}
+	n, err := enc.EncodeTo(w, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "written", n, len(want))
+	verify.Values(t, "written bytes", w.Bytes(), want)
+
+	bad := &tcap.TCAP{Transaction: m.Transaction, Dialogue: tcap.NewDialogue(tcap.DialogueAsID, 1, &tcap.DialoguePDU{Type: tcap.NewApplicationWideConstructorTag(5)}, nil)}
+	if _, err := enc.Encode(bad); err == nil {
+		t.Error("no error with unknown DialoguePDU")
+	}
+}
+
+func TestAppendBinaryAllocs(t *testing.T) {
+	m := tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})
+	buf := make([]byte, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := m.AppendBinary(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+	})
+	verify.Values(t, "allocs", allocs, 0.0)
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	m, err := tcap.Parse(benchmarkMessage(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	m.Transaction.Payload, m.Dialogue.Payload = nil, nil
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := m.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendBinary(b *testing.B) {
+	m, err := tcap.Parse(benchmarkMessage(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	m.Transaction.Payload, m.Dialogue.Payload = nil, nil
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := m.AppendBinary(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	m, err := tcap.Parse(benchmarkMessage(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	m.Transaction.Payload, m.Dialogue.Payload = nil, nil
+	enc := tcap.NewEncoder()
+	b.ReportAllocs()
+	for b.Loop() {
+		buf, err := enc.Encode(m)
+		if err != nil {
+			b.Fatal(err)
+		}
+		enc.Put(buf)
+	}
+}
@@ -0,0 +1,948 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"context"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrEndpointClosed is returned by the methods of Endpoint and Session after
+// the Endpoint is closed.
+var ErrEndpointClosed = errors.New("tcap: endpoint closed")
+
+// ErrNoStore is returned by Restore if Store of the Endpoint is not set.
+var ErrNoStore = errors.New("tcap: no Store to restore Sessions from")
+
+// Endpoint handles the TCAP transactions over a connection.
+//
+// The connection is message-oriented: each Read returns a TCAP message and each
+// Write sends one, as the conns in the transport package do. Endpoint
+// dispatches the messages received to the Session by the DTID, keeps the
+// DialogueState of each Session up to date and aborts the transactions that it
+// does not know with P-Abort.
+//
+// Registry, Store, Logger, Metrics, Tracer, InvokeTimeout and ReceiveTimeout
+// can be set before calling Serve. If Store is set, the DialogueState is saved
+// every time it changes, and deleted when the transaction ends. Another
+// Endpoint with the same Store can take over the dialogues with Restore. If Logger or
+// Tracer is nil, DefaultLogger or DefaultTracer is used. ReceiveTimeout limits
+// the time Receive of the Sessions waits for a message if it is not zero.
+//
+// Validation is opt-in: if Registry is set, e.g., to gsmmap.Registry, the
+// Components received in a dialogue whose application context is registered
+// in it are validated and decoded with it. Those with a problem are removed
+// from the message and rejected with the Reject Components sent in the next
+// message of the Session.
+//
+// The Endpoint runs the invocation timers of the Invokes sent, whose class and
+// timeout are taken from Registry, or DefaultOperationRegistry if it is nil,
+// and calls InvokeTimeout for each of those expired if it is set.
+//
+// If the connection has the methods ReadFrom and WriteTo as net.PacketConn,
+// e.g., transport.M3UA, where the dialogues with different peers share the
+// connection, each Session keeps the address of its peer taken from the
+// messages received and sends the messages to it.
+type Endpoint struct {
+	Registry       *OperationRegistry
+	Store          Store
+	Logger         *slog.Logger
+	Metrics        Metrics
+	Tracer         Tracer
+	InvokeTimeout  func(s *Session, inv *InvokeState)
+	ReceiveTimeout time.Duration
+
+	conn io.ReadWriter
+	wmu  sync.Mutex
+
+	mu       sync.Mutex
+	sessions map[uint32]*Session
+	accept   chan *Session
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewEndpoint creates a new Endpoint that works on conn.
+func NewEndpoint(conn io.ReadWriter) *Endpoint {
+	return &Endpoint{
+		conn:     conn,
+		sessions: map[uint32]*Session{},
+		accept:   make(chan *Session, 64),
+		done:     make(chan struct{}),
+	}
+}
+
+// Serve reads the messages from the connection and dispatches them until the
+// ctx is done or the connection fails.
+//
+// The Endpoint is closed when Serve returns.
+func (e *Endpoint) Serve(ctx context.Context) error {
+	go func() {
+		select {
+		case <-ctx.Done():
+			e.Close()
+		case <-e.done:
+		}
+	}()
+	defer e.Close()
+
+	pc, _ := e.conn.(packetConn)
+	buf := make([]byte, 0xffff)
+	for {
+		var (
+			n    int
+			addr net.Addr
+			err  error
+		)
+		if pc != nil {
+			n, addr, err = pc.ReadFrom(buf)
+		} else {
+			n, err = e.conn.Read(buf)
+		}
+		if err != nil {
+			select {
+			case <-e.done:
+				return ErrEndpointClosed
+			default:
+				return err
+			}
+		}
+		e.handle(append([]byte(nil), buf[:n]...), addr)
+	}
+}
+
+// packetConn is the connection that reads and writes the messages with the
+// address of the peer.
+type packetConn interface {
+	ReadFrom(b []byte) (int, net.Addr, error)
+	WriteTo(b []byte, addr net.Addr) (int, error)
+}
+
+// addrCodec is the connection whose addresses of the peers can be saved in
+// RemoteAddress of DialogueState, e.g., transport.M3UA.
+type addrCodec interface {
+	MarshalAddr(addr net.Addr) ([]byte, error)
+	UnmarshalAddr(b []byte) (net.Addr, error)
+}
+
+// Close closes the Endpoint, the connection and all the Sessions.
+func (e *Endpoint) Close() error {
+	var err error
+	e.once.Do(func() {
+		close(e.done)
+		if c, ok := e.conn.(io.Closer); ok {
+			err = c.Close()
+		}
+
+		e.mu.Lock()
+		for tid, s := range e.sessions {
+			s.terminate()
+			delete(e.sessions, tid)
+			if e.Metrics != nil {
+				e.Metrics.DialogueEnded(time.Since(s.started))
+			}
+		}
+		e.mu.Unlock()
+	})
+	return err
+}
+
+// NewSession starts a new Session with a local TID that is not in use.
+//
+// The first message sent in the Session should be Begin.
+func (e *Endpoint) NewSession() (*Session, error) {
+	return e.NewSessionContext(context.Background())
+}
+
+// NewSessionContext is the same as NewSession, but the span of the dialogue is
+// started as a child of the span in ctx if any.
+func (e *Endpoint) NewSessionContext(ctx context.Context) (*Session, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.newSessionLocked(ctx)
+}
+
+// Restore rebuilds the Sessions of the transactions saved in Store, e.g., by
+// another Endpoint before failover, and returns them. The messages with their
+// local TIDs are dispatched to them as to the others.
+//
+// The invocation timers of the Invokes outstanding are restarted, and those
+// that expired during the failover expire at once. If the connection saves
+// the addresses of the peers, as transport.M3UA does, the Sessions send the
+// messages to the peers saved. The transactions that the Endpoint already has
+// are not restored.
+func (e *Endpoint) Restore() ([]*Session, error) {
+	if e.Store == nil {
+		return nil, ErrNoStore
+	}
+	states, err := e.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil, ErrEndpointClosed
+	default:
+	}
+
+	var restored []*Session
+	for _, st := range states {
+		if _, ok := e.sessions[st.LocalTID]; ok || st.LocalTID == 0 || st.State == StateIdle {
+			continue
+		}
+
+		s := newSession(context.Background(), e, st.LocalTID)
+		s.state = st
+		if c, ok := e.conn.(addrCodec); ok && len(st.RemoteAddress) > 0 {
+			addr, err := c.UnmarshalAddr(st.RemoteAddress)
+			if err != nil {
+				e.logger().Warn("failed to restore the address of the peer", "tid", fmt.Sprintf("%08x", st.LocalTID), "error", err)
+			} else {
+				s.peer = addr
+				s.trace.peer(addr)
+			}
+		}
+		s.mu.Lock()
+		s.resetTimerLocked()
+		s.mu.Unlock()
+
+		e.sessions[st.LocalTID] = s
+		if e.Metrics != nil {
+			e.Metrics.DialogueStarted()
+		}
+		restored = append(restored, s)
+	}
+	return restored, nil
+}
+
+// Accept waits for a Begin or Unidirectional from the peer and returns the
+// Session started by it. The message can be retrieved with Receive.
+func (e *Endpoint) Accept(ctx context.Context) (*Session, error) {
+	select {
+	case s := <-e.accept:
+		return s, nil
+	case <-e.done:
+		return nil, ErrEndpointClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Sessions returns the number of the Sessions whose transaction is not ended.
+func (e *Endpoint) Sessions() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.sessions)
+}
+
+// handle dispatches the message received from the peer at addr, which is nil
+// if the connection does not tell it.
+func (e *Endpoint) handle(b []byte, addr net.Addr) {
+	t, err := parseUntrusted(b)
+	if err != nil || t.Transaction == nil {
+		e.logger().Warn("failed to parse TCAP message, discarding", "error", err)
+		var tidErr *InvalidTIDError
+		if errors.As(err, &tidErr) {
+			e.abortBadlyFormatted(b, addr)
+		}
+		return
+	}
+
+	e.logMessage("received message", t)
+	if e.Metrics != nil {
+		e.Metrics.Message(t, false)
+	}
+
+	ts := t.Transaction
+	switch ts.Type.Code() {
+	case Begin:
+		e.mu.Lock()
+		s, err := e.newSessionLocked(context.Background())
+		e.mu.Unlock()
+		if err != nil {
+			return
+		}
+		if err := s.receive(t, addr); err != nil {
+			s.terminate()
+			e.remove(s.LocalTID())
+			return
+		}
+
+		select {
+		case e.accept <- s:
+		default:
+			e.logger().Warn("too many Sessions waiting for Accept, aborting", messageAttrs(t)...)
+			s.Send(&TCAP{Transaction: NewAbort(0, ResourceLimitation, []byte{})})
+		}
+	case Unidirectional:
+		s := newSession(context.Background(), e, 0)
+		err := s.receive(t, addr)
+		s.terminate()
+		if err != nil {
+			return
+		}
+
+		select {
+		case e.accept <- s:
+		default:
+			e.logger().Warn("too many Sessions waiting for Accept, discarding Unidirectional", messageAttrs(t)...)
+		}
+	case Continue, End, Abort:
+		dtid := localTID(ts.DestTransactionID)
+		e.mu.Lock()
+		s, ok := e.sessions[dtid]
+		e.mu.Unlock()
+		if !ok {
+			e.logger().Warn("unknown DTID, discarding", messageAttrs(t)...)
+			if ts.Type.Code() == Continue {
+				abort := NewAbort(0, UnrecognizedTransactionID, []byte{})
+				abort.DestTransactionID = newTID(9, tidFrom(ts.OrigTransactionID))
+				e.write(&TCAP{Transaction: abort}, addr)
+			}
+			return
+		}
+		s.receive(t, addr)
+	default:
+		e.logger().Warn("unknown message type, discarding", "type", ts.Type.Code())
+	}
+}
+
+// abortBadlyFormatted sends P-Abort with BadlyFormattedTransactionPortion for
+// the message with the invalid TID, if the OTID of the peer is valid.
+func (e *Endpoint) abortBadlyFormatted(b []byte, addr net.Addr) {
+	if len(b) < 2 || (Tag(b[0]).Code() != Begin && Tag(b[0]).Code() != Continue) {
+		return
+	}
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return
+	}
+	otid, _, err := parseTID(b, offset)
+	if err != nil || otid.Tag != 0x48 {
+		return
+	}
+	abort := NewAbort(0, BadlyFormattedTransactionPortion, []byte{})
+	abort.DestTransactionID = newTID(9, tidFrom(otid))
+	e.write(&TCAP{Transaction: abort}, addr)
+}
+
+func (e *Endpoint) newSessionLocked(ctx context.Context) (*Session, error) {
+	select {
+	case <-e.done:
+		return nil, ErrEndpointClosed
+	default:
+	}
+
+	for {
+		tid := rand.Uint32()
+		if _, ok := e.sessions[tid]; ok || tid == 0 {
+			continue
+		}
+		s := newSession(ctx, e, tid)
+		e.sessions[tid] = s
+		if e.Metrics != nil {
+			e.Metrics.DialogueStarted()
+		}
+		return s, nil
+	}
+}
+
+func (e *Endpoint) remove(tid uint32) {
+	e.mu.Lock()
+	s, ok := e.sessions[tid]
+	delete(e.sessions, tid)
+	e.mu.Unlock()
+
+	if ok && e.Metrics != nil {
+		e.Metrics.DialogueEnded(time.Since(s.started))
+	}
+
+	if e.Store != nil && tid != 0 {
+		if err := e.Store.Delete(tid); err != nil {
+			e.logger().Error("failed to delete DialogueState", "tid", fmt.Sprintf("%08x", tid), "error", err)
+		}
+	}
+}
+
+func (e *Endpoint) save(s *DialogueState) {
+	if e.Store == nil || s.LocalTID == 0 {
+		return
+	}
+	if err := e.Store.Save(s); err != nil {
+		e.logger().Error("failed to save DialogueState", "tid", fmt.Sprintf("%08x", s.LocalTID), "error", err)
+	}
+}
+
+// encoder marshals the messages written by the Endpoints.
+var encoder = NewEncoder()
+
+// write sends the message to the peer at addr, or the one of the connection if
+// addr is nil.
+func (e *Endpoint) write(t *TCAP, addr net.Addr) error {
+	buf, err := encoder.Encode(t)
+	if err != nil {
+		return err
+	}
+	defer encoder.Put(buf)
+
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+
+	select {
+	case <-e.done:
+		return ErrEndpointClosed
+	default:
+	}
+	if pc, ok := e.conn.(packetConn); ok && addr != nil {
+		_, err = pc.WriteTo(buf.Bytes(), addr)
+	} else {
+		_, err = e.conn.Write(buf.Bytes())
+	}
+	if err != nil {
+		return err
+	}
+	e.logMessage("sent message", t)
+	if e.Metrics != nil {
+		e.Metrics.Message(t, true)
+	}
+	return nil
+}
+
+// logger returns the logger of the Endpoint with the address of the peer.
+// registry returns the OperationRegistry to update the DialogueStates with.
+func (e *Endpoint) registry() *OperationRegistry {
+	if e.Registry == nil {
+		return DefaultOperationRegistry
+	}
+	return e.Registry
+}
+
+func (e *Endpoint) logger() *slog.Logger {
+	l := e.Logger
+	if l == nil {
+		l = DefaultLogger()
+	}
+	if c, ok := e.conn.(interface{ RemoteAddr() net.Addr }); ok {
+		if addr := c.RemoteAddr(); addr != nil {
+			l = l.With("peer", addr.String())
+		}
+	}
+	return l
+}
+
+// tracer returns the Tracer of the Endpoint.
+func (e *Endpoint) tracer() Tracer {
+	if e.Tracer != nil {
+		return e.Tracer
+	}
+	return DefaultTracer()
+}
+
+// partyAddresses returns the SCCP addresses of the connection if known.
+func (e *Endpoint) partyAddresses() (local, remote string) {
+	if c, ok := e.conn.(interface{ PartyAddresses() (string, string) }); ok {
+		return c.PartyAddresses()
+	}
+	return "", ""
+}
+
+// logMessage logs the message and its components at debug level.
+func (e *Endpoint) logMessage(msg string, t *TCAP) {
+	l := e.logger()
+	if !l.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	l = l.With(messageAttrs(t)...)
+	l.Debug(msg)
+	if t.Components == nil {
+		return
+	}
+	for _, c := range t.Components.Component {
+		attrs := []any{"component", c.ComponentTypeString()}
+		if c.InvokeID != nil && len(c.InvokeID.Value) > 0 {
+			attrs = append(attrs, "invoke_id", c.InvID())
+		}
+		if c.OperationCode != nil && len(c.OperationCode.Value) > 0 {
+			attrs = append(attrs, "opcode", c.OpCode())
+		}
+		l.Debug(msg+" component", attrs...)
+	}
+}
+
+// messageAttrs returns the attributes of the message to log: the message type,
+// the TIDs and the Application Context Name.
+func messageAttrs(t *TCAP) []any {
+	var attrs []any
+	if ts := t.Transaction; ts != nil {
+		attrs = append(attrs, "type", ts.MessageTypeString())
+		if ts.OrigTransactionID != nil {
+			attrs = append(attrs, "otid", ts.OTID())
+		}
+		if ts.DestTransactionID != nil {
+			attrs = append(attrs, "dtid", ts.DTID())
+		}
+	}
+	if t.Dialogue != nil {
+		if oid := t.Dialogue.ApplicationContextOID(); oid != nil {
+			attrs = append(attrs, "acn", oid.String())
+		}
+	}
+	return attrs
+}
+
+// Session is a transaction with the peer in an Endpoint.
+type Session struct {
+	e       *Endpoint
+	started time.Time
+
+	mu      sync.Mutex
+	state   *DialogueState
+	peer    net.Addr
+	trace   *dialogueTrace
+	queue   []*TCAP
+	rejects []*Component
+	aarq    asn1.ObjectIdentifier
+	aare    *Dialogue
+	timer   *time.Timer
+	ended   bool
+	notify  chan struct{}
+}
+
+func newSession(ctx context.Context, e *Endpoint, tid uint32) *Session {
+	local, remote := e.partyAddresses()
+	return &Session{
+		e:       e,
+		started: time.Now(),
+		state:   NewDialogueState(tid, nil, nil),
+		trace:   newDialogueTrace(ctx, e.tracer(), tid, local, remote),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Context returns the context with the span of the dialogue, to start the spans
+// of the application layers as its children.
+func (s *Session) Context() context.Context {
+	return s.trace.ctx
+}
+
+// InvokeContext returns the context with the span of the Invoke sent or
+// received with the Invoke ID in the Session. If the span has ended, the
+// context of the dialogue is returned.
+func (s *Session) InvokeContext(invID uint8, sent bool) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inv, ok := s.trace.invokes[invokeKey{sent, invID}]; ok {
+		return inv.ctx
+	}
+	return s.trace.ctx
+}
+
+// Logger returns the logger of the Endpoint with the local TID of the Session,
+// to log the events of the application layers in the dialogue.
+func (s *Session) Logger() *slog.Logger {
+	return s.e.logger().With("tid", fmt.Sprintf("%08x", s.LocalTID()))
+}
+
+// Accept accepts the dialogue opened by the peer with AARQ. The AARE with the
+// result accepted is sent in the Dialogue Portion of the next Continue or End
+// sent in the Session, unless it has one already.
+//
+// It reports whether the AARE is to be sent, which is false if the dialogue is
+// not opened with AARQ or accepted already.
+func (s *Session) Accept() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.aarq == nil {
+		return false
+	}
+	s.aare = NewDialogue(DialogueAsID, 1, NewAAREWithOID(1, s.aarq, Accepted, DialogueServiceUser, Null), []byte{})
+	s.aarq = nil
+	return true
+}
+
+// Peer returns the address of the peer taken from the last message received in
+// the Session, or nil if the connection of the Endpoint does not tell it.
+func (s *Session) Peer() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.peer
+}
+
+// LocalTID returns the TID allocated by this side.
+func (s *Session) LocalTID() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.LocalTID
+}
+
+// RemoteTID returns the TID allocated by the peer, in 1 to 4 octets.
+func (s *Session) RemoteTID() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]byte(nil), s.state.RemoteTID...)
+}
+
+// State returns the copy of the DialogueState of the Session.
+func (s *Session) State() *DialogueState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.Clone()
+}
+
+// ExpiredInvokes removes the Invokes sent in the Session whose invocation
+// timer has expired and returns them. They are counted as the invoke timeouts
+// in the Metrics of the Endpoint, and their spans end with an error.
+//
+// The timers are run by the Endpoint, which calls this on expiry and passes
+// the Invokes to InvokeTimeout; call this only to check them earlier.
+func (s *Session) ExpiredInvokes() []*InvokeState {
+	s.mu.Lock()
+	expired := s.state.ExpiredInvokes(time.Now())
+	s.trace.expired(expired)
+	s.resetTimerLocked()
+	state := s.state.Clone()
+	s.mu.Unlock()
+
+	if len(expired) == 0 {
+		return nil
+	}
+	s.e.save(state)
+	if m := s.e.Metrics; m != nil {
+		for _, inv := range expired {
+			m.InvokeTimeout(inv)
+		}
+	}
+	return expired
+}
+
+// expire is called when the earliest invocation timer in the Session expires.
+func (s *Session) expire() {
+	for _, inv := range s.ExpiredInvokes() {
+		if f := s.e.InvokeTimeout; f != nil {
+			f(s, inv)
+		}
+	}
+}
+
+// resetTimerLocked starts the timer for the earliest deadline of the Invokes.
+func (s *Session) resetTimerLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.ended {
+		return
+	}
+
+	var next time.Time
+	for _, inv := range s.state.Invokes {
+		if !inv.Deadline.IsZero() && (next.IsZero() || inv.Deadline.Before(next)) {
+			next = inv.Deadline
+		}
+	}
+	if !next.IsZero() {
+		s.timer = time.AfterFunc(time.Until(next), s.expire)
+	}
+}
+
+// Send sends the TCAP message in the Session.
+//
+// The OTID and DTID in the Transaction Portion are overwritten with the TIDs of
+// the Session, and the lengths are set. The Rejects of the Components received
+// with a problem are added to the Components of Continue and End, and so is
+// the AARE to the Dialogue Portion if the dialogue is accepted with Accept.
+// The Session ends when End or Abort is sent.
+func (s *Session) Send(t *TCAP) error {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return &DialogueNotFoundError{LocalTID: s.state.LocalTID}
+	}
+
+	ts := t.Transaction
+	if ts == nil {
+		s.mu.Unlock()
+		return errors.New("tcap: no Transaction Portion to send")
+	}
+	switch ts.Type.Code() {
+	case Begin:
+		ts.OrigTransactionID = newTID(8, binary.BigEndian.AppendUint32(nil, s.state.LocalTID))
+	case Continue:
+		ts.OrigTransactionID = newTID(8, binary.BigEndian.AppendUint32(nil, s.state.LocalTID))
+		ts.DestTransactionID = newTID(9, s.state.RemoteTID)
+	case End, Abort:
+		ts.DestTransactionID = newTID(9, s.state.RemoteTID)
+	}
+	if code := ts.Type.Code(); len(s.rejects) > 0 && (code == Continue || code == End) {
+		if t.Components == nil {
+			t.Components = NewComponents(s.rejects...)
+		} else {
+			t.Components.Component = append(s.rejects, t.Components.Component...)
+		}
+		s.rejects = nil
+	}
+	if code := ts.Type.Code(); s.aare != nil && t.Dialogue == nil && (code == Continue || code == End) {
+		t.Dialogue = s.aare
+	}
+	s.aare = nil
+	if t.Dialogue != nil || t.Components != nil {
+		ts.Payload = []byte{}
+	}
+	if t.Dialogue != nil && t.Components != nil {
+		t.Dialogue.Payload = []byte{}
+	}
+	t.SetLength()
+
+	s.state.UpdateWithRegistry(t, true, s.e.registry())
+	s.resetTimerLocked()
+	// traced before written not to miss the response coming back at once.
+	s.trace.message(t, true, s.state)
+	ended := s.state.State == StateIdle
+	state := s.state.Clone()
+	peer := s.peer
+	s.mu.Unlock()
+
+	if err := s.e.write(t, peer); err != nil {
+		s.mu.Lock()
+		s.trace.failed(err)
+		s.mu.Unlock()
+		return err
+	}
+	if ended {
+		s.terminate()
+		s.e.remove(state.LocalTID)
+	} else {
+		s.e.save(state)
+	}
+	return nil
+}
+
+// Receive returns the next message received in the Session.
+//
+// After the messages that end the transaction are all received, it returns
+// *DialogueNotFoundError. If ReceiveTimeout of the Endpoint is set and no
+// message is received in it, context.DeadlineExceeded is returned.
+func (s *Session) Receive(ctx context.Context) (*TCAP, error) {
+	if d := s.e.ReceiveTimeout; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			t := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return t, nil
+		}
+		if s.ended {
+			s.mu.Unlock()
+			return nil, &DialogueNotFoundError{LocalTID: s.state.LocalTID}
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Abort ends the transaction with U-Abort without waiting for the peer.
+//
+// If the transaction is not started by Begin from this side, nothing is sent.
+func (s *Session) Abort() error {
+	s.mu.Lock()
+	sendAbort := !s.ended && s.state.State != StateIdle && s.state.State != StateInitiationSent
+	s.mu.Unlock()
+
+	if sendAbort {
+		ts := NewAbort(0, 0, []byte{})
+		ts.PAbortCause = nil
+		return s.Send(&TCAP{Transaction: ts})
+	}
+	s.terminate()
+	s.e.remove(s.LocalTID())
+	return nil
+}
+
+// receive updates the Session with the message received and queues it. It
+// returns the error if the message cannot be processed, which is discarded.
+func (s *Session) receive(t *TCAP, addr net.Addr) error {
+	state, ended, err := s.update(t, addr)
+	if err != nil {
+		s.e.logger().Warn("failed to process message, discarding", append(messageAttrs(t), "error", err)...)
+		return err
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+
+	if ended {
+		s.terminate()
+		s.e.remove(state.LocalTID)
+	} else {
+		s.e.save(state)
+	}
+	return nil
+}
+
+// update updates the state of the Session with the message received and
+// queues it. The panic on the malformed message is turned into an error not
+// to leave the Session locked.
+func (s *Session) update(t *TCAP, addr net.Addr) (state *DialogueState, ended bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tcap: malformed message: %v", r)
+		}
+	}()
+
+	if addr != nil {
+		s.peer = addr
+		s.trace.peer(addr)
+		if c, ok := s.e.conn.(addrCodec); ok {
+			if b, err := c.MarshalAddr(addr); err == nil {
+				s.state.RemoteAddress = b
+			}
+		}
+	}
+	if d := t.Dialogue; d != nil && d.DialoguePDU != nil && d.DialoguePDU.Type.Code() == AARQ {
+		s.aarq = d.ApplicationContextOID()
+	}
+	rejected, rejects := s.validateLocked(t)
+	s.state.UpdateWithRegistry(t, false, s.e.registry())
+	s.trace.message(t, false, s.state)
+	if len(rejects) > 0 {
+		t.Components.Component = slices.DeleteFunc(t.Components.Component, func(c *Component) bool {
+			return rejected[c]
+		})
+		s.rejects = append(s.rejects, rejects...)
+	}
+	ended = s.state.State == StateIdle
+	s.resetTimerLocked()
+	s.queue = append(s.queue, t)
+	return s.state.Clone(), ended, nil
+}
+
+// validateLocked validates and decodes the Components received with the
+// Registry of the Endpoint, if the application context of the dialogue is
+// registered in it. It returns the Components with a problem and the Rejects
+// to be sent for them.
+func (s *Session) validateLocked(t *TCAP) (map[*Component]bool, []*Component) {
+	r := s.e.Registry
+	if r == nil || t.Components == nil {
+		return nil, nil
+	}
+	acn := s.state.ApplicationContextOID()
+	if t.Dialogue != nil {
+		if oid := t.Dialogue.ApplicationContextOID(); oid != nil {
+			acn = oid
+		}
+	}
+	if !r.hasContext(acn) {
+		return nil, nil
+	}
+
+	var (
+		rejected = map[*Component]bool{}
+		rejects  []*Component
+	)
+	for _, c := range t.Components.Component {
+		var inv *InvokeState
+		switch c.Type.Code() {
+		case Reject:
+			continue
+		case Invoke:
+			if c.LinkedID != nil && len(c.LinkedID.Value) > 0 {
+				inv = s.state.Invoke(c.LinkedID.Value[0])
+			}
+		default:
+			if c.InvokeID != nil && len(c.InvokeID.Value) > 0 {
+				inv = s.state.Invoke(c.InvID())
+			}
+		}
+
+		err := r.Validate(acn, c, inv)
+		if err == nil {
+			_, err = r.ForContext(acn).Decode(c, inv)
+		}
+		var p *ComponentProblemError
+		if !errors.As(err, &p) {
+			continue
+		}
+		s.e.logger().Warn("rejecting Component", "invoke_id", p.InvokeID, "problem_type", p.ProblemType, "problem_code", p.ProblemCode, "error", err)
+		rejected[c] = true
+		rejects = append(rejects, NewReject(int(p.InvokeID), p.ProblemType, p.ProblemCode, nil))
+	}
+	return rejected, rejects
+}
+
+func (s *Session) terminate() {
+	s.mu.Lock()
+	s.ended = true
+	s.rejects = nil
+	s.aare = nil
+	s.resetTimerLocked()
+	s.trace.end()
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// parseUntrusted parses b received from the peer, turning the panic on the
+// malformed message into an error.
+func parseUntrusted(b []byte) (t *TCAP, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tcap: malformed message: %v", r)
+		}
+	}()
+	return Parse(b)
+}
+
+func newTID(tag int, tid []byte) *IE {
+	return NewIE(NewApplicationWidePrimitiveTag(tag), append([]byte(nil), tid...))
+}
+
+// localTID returns the DTID received as the TID allocated by this side, which
+// is always 4 octets.
+func localTID(ie *IE) uint32 {
+	if ie == nil || len(ie.Value) != 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ie.Value)
+}
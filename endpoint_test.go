@@ -0,0 +1,630 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/tracetest"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func newEndpoints(t *testing.T) (client, server *tcap.Endpoint) {
+	t.Helper()
+	tcap.DisableLogging()
+
+	a, b := transport.Pipe()
+	client, server = tcap.NewEndpoint(a), tcap.NewEndpoint(b)
+	ctx, cancel := context.WithCancel(context.Background())
+	go client.Serve(ctx)
+	go server.Serve(ctx)
+	t.Cleanup(cancel)
+	return client, server
+}
+
+func receive(t *testing.T, s *tcap.Session) *tcap.TCAP {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	m, err := s.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestEndpoint(t *testing.T) {
+	client, server := newEndpoints(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin := receive(t, ss)
+	verify.Values(t, "OTID", begin.OTID(), cs.LocalTID())
+	verify.Values(t, "RemoteTID", ss.RemoteTID(), binary.BigEndian.AppendUint32(nil, cs.LocalTID()))
+	verify.Values(t, "server state", ss.State().State, tcap.StateInitiationReceived)
+
+	if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	cont := receive(t, cs)
+	verify.Values(t, "OTID", cont.OTID(), ss.LocalTID())
+	verify.Values(t, "DTID", cont.DTID(), cs.LocalTID())
+	verify.Values(t, "client state", cs.State().State, tcap.StateActive)
+	verify.Values(t, "outstanding invokes", len(cs.State().Invokes), 1)
+
+	if err := ss.Send(tcap.NewEndReturnResult(0, 0, 3, true, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+	end := receive(t, cs)
+	verify.Values(t, "DTID", end.DTID(), cs.LocalTID())
+	verify.Values(t, "client state", cs.State().State, tcap.StateIdle)
+
+	var nferr *tcap.DialogueNotFoundError
+	if _, err := cs.Receive(ctx); !errors.As(err, &nferr) {
+		t.Errorf("unexpected error after End: %v", err)
+	}
+	if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); !errors.As(err, &nferr) {
+		t.Errorf("unexpected error sending after End: %v", err)
+	}
+	verify.Values(t, "client sessions", client.Sessions(), 0)
+	verify.Values(t, "server sessions", server.Sessions(), 0)
+}
+
+func TestEndpointLogger(t *testing.T) {
+	tcap.DisableLogging()
+	buf := &bytes.Buffer{}
+	a, b := transport.Pipe()
+	client, server := tcap.NewEndpoint(a), tcap.NewEndpoint(b)
+	server.Logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go client.Serve(ctx)
+	go server.Serve(ctx)
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ss)
+	if err := ss.Send(tcap.NewEndReturnResult(0, 0, 3, true, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		r := map[string]any{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		delete(r, "time")
+		got = append(got, r)
+	}
+	otid := fmt.Sprintf("%08x", cs.LocalTID())
+	verify.Values(t, "records", got, []map[string]any{
+		{"level": "DEBUG", "msg": "received message", "type": "Begin", "otid": otid, "acn": "0.4.0.0.1.0.2.3"},
+		{"level": "DEBUG", "msg": "received message component", "type": "Begin", "otid": otid, "acn": "0.4.0.0.1.0.2.3", "component": "invoke", "invoke_id": 0.0, "opcode": 3.0},
+		{"level": "DEBUG", "msg": "sent message", "type": "End", "dtid": otid},
+		{"level": "DEBUG", "msg": "sent message component", "type": "End", "dtid": otid, "component": "returnResultLast", "invoke_id": 0.0, "opcode": 3.0},
+	})
+}
+
+func TestEndpointTracer(t *testing.T) {
+	client, server := newEndpoints(t)
+	rec, srec := tracetest.NewRecorder(), tracetest.NewRecorder()
+	client.Tracer, server.Tracer = rec, srec
+
+	ctx, app := rec.Start(context.Background(), "app")
+	cs, err := client.NewSessionContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin := tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})
+	begin.Components.Component = append(begin.Components.Component, tcap.NewInvoke(1, -1, 2, true, nil))
+	if err := cs.Send(begin); err != nil {
+		t.Fatal(err)
+	}
+	_, lookup := rec.Start(cs.InvokeContext(0, true), "app.lookup")
+	lookup.End()
+
+	ss, err := server.Accept(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ss)
+	if err := ss.Send(&tcap.TCAP{
+		Transaction: tcap.NewEnd(0, []byte{}),
+		Components: tcap.NewComponents(
+			tcap.NewReturnError(1, 1, true, nil),
+			tcap.NewReturnResult(0, 3, true, true, nil),
+		),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, cs)
+	for client.Sessions() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	app.End()
+
+	spans := rec.Spans()
+	for i := range spans {
+		spans[i].Start, spans[i].End = time.Time{}, time.Time{}
+		for j := range spans[i].Events {
+			spans[i].Events[j].Time = time.Time{}
+		}
+	}
+	verify.Values(t, "spans", spans, []tracetest.SpanData{
+		{ID: 1, Name: "app", Attributes: map[string]string{}, Ended: true},
+		{
+			ID: 2, ParentID: 1, Name: "tcap.dialogue",
+			Attributes: map[string]string{
+				"tcap.local_tid": fmt.Sprintf("%08x", cs.LocalTID()),
+				"tcap.acn":       "0.4.0.0.1.0.2.3",
+			},
+			Events: []tracetest.Event{
+				{Name: "tcap.message", Attributes: map[string]string{"tcap.message_type": "Begin", "tcap.direction": "sent"}},
+				{Name: "tcap.message", Attributes: map[string]string{"tcap.message_type": "End", "tcap.direction": "received"}},
+			},
+			Ended: true,
+		},
+		{
+			ID: 3, ParentID: 2, Name: "tcap.invoke",
+			Attributes: map[string]string{"tcap.invoke_id": "0", "tcap.opcode": "3", "tcap.direction": "sent"},
+			Ended:      true,
+		},
+		{
+			ID: 4, ParentID: 2, Name: "tcap.invoke",
+			Attributes: map[string]string{"tcap.invoke_id": "1", "tcap.opcode": "2", "tcap.direction": "sent"},
+			Err:        errors.New("tcap: return error: 1"),
+			Ended:      true,
+		},
+		{ID: 5, ParentID: 3, Name: "app.lookup", Attributes: map[string]string{}, Ended: true},
+	})
+
+	// the server learns the TID of the client and answers the Invokes received.
+	spans = srec.Spans()
+	verify.Values(t, "server spans", len(spans), 3)
+	verify.Values(t, "server remote TID", spans[0].Attributes["tcap.remote_tid"], fmt.Sprintf("%08x", cs.LocalTID()))
+	for _, s := range spans {
+		if !s.Ended {
+			t.Errorf("span %d not ended", s.ID)
+		}
+	}
+	verify.Values(t, "server invoke direction", spans[1].Attributes["tcap.direction"], "received")
+}
+
+func TestEndpointReject(t *testing.T) {
+	client, server := newEndpoints(t)
+	server.Registry = newTestRegistry()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin := tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})
+	if err := begin.Components.Component[0].SetParameter(ber.NewSequence(ber.NewOctetString([]byte{0x00, 0x01}))); err != nil {
+		t.Fatal(err)
+	}
+	begin.Components.Component = append(begin.Components.Component, tcap.NewInvoke(1, -1, 67, true, nil))
+	if err := cs.Send(begin); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comps := receive(t, ss).Components.Component
+	verify.Values(t, "received", len(comps), 1)
+	verify.Values(t, "opcode", comps[0].OpCode(), uint8(3))
+
+	if err := ss.Send(tcap.NewEndReturnResult(0, 0, 3, true, nil)); err != nil {
+		t.Fatal(err)
+	}
+	comps = receive(t, cs).Components.Component
+	verify.Values(t, "sent", len(comps), 2)
+	verify.Values(t, "reject", comps[0].ComponentTypeString(), "reject")
+	verify.Values(t, "invoke ID", comps[0].InvID(), uint8(1))
+	verify.Values(t, "problem", comps[0].ProblemCode.Value, []byte{tcap.InvokeProblemUnrecognizedOperation})
+	verify.Values(t, "result", comps[1].ComponentTypeString(), "returnResultLast")
+}
+
+func TestSessionAccept(t *testing.T) {
+	client, server := newEndpoints(t)
+	server.ReceiveTimeout = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ss)
+	if _, err := ss.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want DeadlineExceeded", err)
+	}
+	verify.Values(t, "accepted", ss.Accept(), true)
+	verify.Values(t, "accepted again", ss.Accept(), false)
+
+	if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewEnd(0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	end := receive(t, cs)
+	if end.Dialogue == nil || end.Dialogue.DialoguePDU == nil {
+		t.Fatal("no AARE in End")
+	}
+	verify.Values(t, "PDU", end.Dialogue.DialoguePDU.Type.Code(), tcap.AARE)
+	verify.Values(t, "ACN", end.Dialogue.ApplicationContextOID().String(), "0.4.0.0.1.0.2.3")
+}
+
+type testAddr string
+
+func (a testAddr) Network() string { return "test" }
+func (a testAddr) String() string  { return string(a) }
+
+type packet struct {
+	b    []byte
+	addr net.Addr
+}
+
+// packetConn is a connection with ReadFrom and WriteTo, which does not support
+// Write not to send without the address.
+type packetConn struct {
+	in, out chan packet
+}
+
+func (c *packetConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+func (c *packetConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	p, ok := <-c.in
+	if !ok {
+		return 0, nil, transport.ErrClosed
+	}
+	return copy(b, p.b), p.addr, nil
+}
+
+func (c *packetConn) Write(b []byte) (int, error) {
+	return 0, errors.New("no address to send to")
+}
+
+func (c *packetConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.out <- packet{append([]byte(nil), b...), addr}
+	return len(b), nil
+}
+
+func TestEndpointPeer(t *testing.T) {
+	tcap.DisableLogging()
+	conn := &packetConn{in: make(chan packet, 4), out: make(chan packet, 4)}
+	server := tcap.NewEndpoint(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go server.Serve(ctx)
+
+	for i, addr := range []testAddr{"peer-a", "peer-b"} {
+		b, err := tcap.NewBeginInvoke(uint32(i+1), 0, 3, []byte{}).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.in <- packet{b, addr}
+	}
+
+	for _, addr := range []testAddr{"peer-a", "peer-b"} {
+		ss, err := server.Accept(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receive(t, ss)
+		verify.Values(t, "Peer", ss.Peer(), net.Addr(addr))
+
+		if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewEnd(0, []byte{})}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case p := <-conn.out:
+			verify.Values(t, "sent to", p.addr, net.Addr(addr))
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+	}
+}
+
+func TestEndpointEmptyDTID(t *testing.T) {
+	client, server := newEndpoints(t)
+
+	// A Session that has not learned the TID of the peer sends Continue with
+	// the empty DTID, which is badly formatted.
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+
+	abort := receive(t, cs)
+	verify.Values(t, "type", abort.Transaction.MessageTypeString(), "Abort")
+	verify.Values(t, "cause", abort.Transaction.AbortCause(), "BadlyFormattedTransactionPortion")
+	verify.Values(t, "server sessions", server.Sessions(), 0)
+}
+
+func TestEndpointShortTID(t *testing.T) {
+	tcap.DisableLogging()
+	peer, conn := transport.Pipe()
+	server := tcap.NewEndpoint(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go server.Serve(ctx)
+
+	read := func() *tcap.TCAP {
+		t.Helper()
+		buf := make([]byte, 0xff)
+		n, err := peer.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := tcap.Parse(buf[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	// Begin with the OTID of 2 octets.
+	if _, err := peer.Write([]byte{0x62, 0x04, 0x48, 0x02, 0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "RemoteTID", ss.RemoteTID(), []byte{0x01, 0x02})
+	if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewEnd(0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "DTID of End", read().Transaction.DestTransactionID.Value, []byte{0x01, 0x02})
+
+	// Continue with the DTID unknown and the OTID of 1 octet.
+	if _, err := peer.Write([]byte{0x65, 0x09, 0x48, 0x01, 0x05, 0x49, 0x04, 0xde, 0xad, 0xbe, 0xef}); err != nil {
+		t.Fatal(err)
+	}
+	abort := read()
+	verify.Values(t, "cause", abort.Transaction.AbortCause(), "UnrecognizedTransactionID")
+	verify.Values(t, "DTID of P-Abort", abort.Transaction.DestTransactionID.Value, []byte{0x05})
+}
+
+func TestEndpointNullInvokeID(t *testing.T) {
+	tcap.DisableLogging()
+	peer, conn := transport.Pipe()
+	server := tcap.NewEndpoint(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go server.Serve(ctx)
+
+	// Begin with a Reject of which the Invoke ID is NULL.
+	b := []byte{0x62, 0x0f, 0x48, 0x04, 0x00, 0x00, 0x00, 0x01, 0x6c, 0x07, 0xa4, 0x05, 0x05, 0x00, 0x80, 0x01, 0x00}
+	if _, err := peer.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := receive(t, ss)
+	verify.Values(t, "component", m.Components.Component[0].Type.Code(), tcap.Reject)
+
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		t.Fatal("Close did not return")
+	}
+}
+
+func TestEndpointStore(t *testing.T) {
+	client, server := newEndpoints(t)
+	store := tcap.NewMemoryStore()
+	client.Store = store
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(&tcap.TCAP{Transaction: tcap.NewBegin(0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Load(cs.LocalTID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "saved state", saved.State, tcap.StateInitiationSent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ss)
+	if err := ss.Abort(); err != nil {
+		t.Fatal(err)
+	}
+
+	abort := receive(t, cs)
+	verify.Values(t, "U-Abort", abort.Transaction.PAbortCause == nil, true)
+	if _, err := store.Load(cs.LocalTID()); err == nil {
+		t.Error("state is not deleted after Abort")
+	}
+}
+
+// codecConn is packetConn that saves the addresses of the peers.
+type codecConn struct {
+	packetConn
+}
+
+func (c *codecConn) MarshalAddr(addr net.Addr) ([]byte, error) {
+	return []byte(addr.String()), nil
+}
+
+func (c *codecConn) UnmarshalAddr(b []byte) (net.Addr, error) {
+	return testAddr(b), nil
+}
+
+func TestEndpointRestore(t *testing.T) {
+	tcap.DisableLogging()
+	store := tcap.NewMemoryStore()
+	registry := tcap.NewOperationRegistry()
+	registry.RegisterOperation(&tcap.Operation{Code: 3, Class: tcap.OperationClass1, Timeout: 50 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sent := func(conn *codecConn) packet {
+		t.Helper()
+		select {
+		case p := <-conn.out:
+			return p
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		return packet{}
+	}
+
+	// The first Endpoint accepts the dialogue and sends an Invoke, then fails.
+	conn1 := &codecConn{packetConn{in: make(chan packet, 4), out: make(chan packet, 4)}}
+	server1 := tcap.NewEndpoint(conn1)
+	server1.Store, server1.Registry = store, registry
+	go server1.Serve(ctx)
+
+	b, err := tcap.NewBeginInvoke(0x01020304, 0, 59, []byte{}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn1.in <- packet{b, testAddr("peer-a")}
+	ss, err := server1.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ss)
+	continueInvoke := &tcap.TCAP{
+		Transaction: tcap.NewContinue(0, 0, []byte{}),
+		Components:  tcap.NewComponents(tcap.NewInvoke(1, -1, 3, true, nil)),
+	}
+	if err := ss.Send(continueInvoke); err != nil {
+		t.Fatal(err)
+	}
+	sent(conn1)
+	tid := ss.LocalTID()
+	server1.Close()
+
+	// The second Endpoint takes over the dialogue from the Store.
+	conn2 := &codecConn{packetConn{in: make(chan packet, 4), out: make(chan packet, 4)}}
+	server2 := tcap.NewEndpoint(conn2)
+	server2.Store, server2.Registry = store, registry
+	expired := make(chan *tcap.InvokeState, 1)
+	server2.InvokeTimeout = func(s *tcap.Session, inv *tcap.InvokeState) {
+		expired <- inv
+	}
+	sessions, err := server2.Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server2.Serve(ctx)
+
+	verify.Values(t, "restored", len(sessions), 1)
+	rs := sessions[0]
+	verify.Values(t, "LocalTID", rs.LocalTID(), tid)
+	verify.Values(t, "RemoteTID", rs.RemoteTID(), []byte{0x01, 0x02, 0x03, 0x04})
+	verify.Values(t, "State", rs.State().State, tcap.StateActive)
+	verify.Values(t, "Peer", rs.Peer(), net.Addr(testAddr("peer-a")))
+
+	select {
+	case inv := <-expired:
+		verify.Values(t, "expired Invoke", inv.InvokeID, uint8(1))
+	case <-ctx.Done():
+		t.Fatal("invocation timer is not restarted")
+	}
+
+	if err := rs.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "sent to", sent(conn2).addr, net.Addr(testAddr("peer-a")))
+
+	// The peer continues the dialogue with the second Endpoint.
+	b, err = tcap.NewEndReturnResult(tid, 1, 3, true, nil).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn2.in <- packet{b, testAddr("peer-a")}
+	verify.Values(t, "received", receive(t, rs).Transaction.MessageTypeString(), "End")
+	if _, err := store.Load(tid); err == nil {
+		t.Error("state is not deleted after End")
+	}
+}
+
+func TestEndpointClose(t *testing.T) {
+	client, _ := newEndpoints(t)
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+
+	if _, err := client.NewSession(); !errors.Is(err, tcap.ErrEndpointClosed) {
+		t.Errorf("unexpected error from NewSession: %v", err)
+	}
+	if _, err := cs.Receive(context.Background()); err == nil {
+		t.Error("Receive succeeded after Close")
+	}
+}
@@ -15,3 +15,67 @@ type InvalidCodeError struct {
 func (e *InvalidCodeError) Error() string {
 	return fmt.Sprintf("tcap: got invalid code: %d", e.Code)
 }
+
+// DialogueNotFoundError indicates that the DialogueState is not found in a Store.
+type DialogueNotFoundError struct {
+	LocalTID uint32
+}
+
+// Error returns error message with violating content.
+func (e *DialogueNotFoundError) Error() string {
+	return fmt.Sprintf("tcap: dialogue not found: %#08x", e.LocalTID)
+}
+
+// InvalidTIDError indicates that the Transaction ID is not 1 to 4 octets.
+type InvalidTIDError struct {
+	Length int
+}
+
+// Error returns error message with violating content.
+func (e *InvalidTIDError) Error() string {
+	return fmt.Sprintf("tcap: invalid length of TID: %d", e.Length)
+}
+
+// UnsupportedEncodingError indicates that the value cannot be represented as an IE.
+type UnsupportedEncodingError struct {
+	Tag, Length int
+}
+
+// Error returns error message with violating content.
+func (e *UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("tcap: cannot be encoded as IE: tag=%d, length=%d", e.Tag, e.Length)
+}
+
+// ComponentProblemError indicates that the Component received should be rejected
+// with the problem.
+//
+// Err is the cause of the problem if any, e.g., the error in decoding the Parameter.
+type ComponentProblemError struct {
+	InvokeID    uint8
+	ProblemType int
+	ProblemCode uint8
+	Err         error
+}
+
+// Error returns error message with violating content.
+func (e *ComponentProblemError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("tcap: component problem: invoke ID=%d, type=%d, code=%d: %v", e.InvokeID, e.ProblemType, e.ProblemCode, e.Err)
+	}
+	return fmt.Sprintf("tcap: component problem: invoke ID=%d, type=%d, code=%d", e.InvokeID, e.ProblemType, e.ProblemCode)
+}
+
+// Unwrap returns the cause of the problem.
+func (e *ComponentProblemError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidJSONError indicates that the field in JSON representation is invalid.
+type InvalidJSONError struct {
+	Field, Value string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidJSONError) Error() string {
+	return fmt.Sprintf("tcap: invalid %s in JSON: %q", e.Field, e.Value)
+}
@@ -7,4 +7,5 @@ require (
 	github.com/pascaldekloe/goe v0.1.1
 	github.com/wmnsk/go-m3ua v0.1.11
 	github.com/wmnsk/go-sccp v0.0.5
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,327 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap_test
+
+import (
+	"context"
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+var testcases = []struct {
+	description string
+	opCode      uint8
+	arg         gsmmap.Parameter
+	res         gsmmap.Parameter
+}{
+	{
+		description: "updateLocation",
+		opCode:      gsmmap.UpdateLocation,
+		arg: &gsmmap.UpdateLocationArg{
+			IMSI:      "001010123456789",
+			MSCNumber: gsmmap.NewAddressString("819012345678"),
+			VLRNumber: gsmmap.NewAddressString("819012345679"),
+		},
+		res: &gsmmap.UpdateLocationRes{
+			HLRNumber: gsmmap.NewAddressString("819000000001"),
+		},
+	}, {
+		description: "cancelLocation",
+		opCode:      gsmmap.CancelLocation,
+		arg: &gsmmap.CancelLocationArg{
+			IMSI:             "001010123456789",
+			LMSI:             []byte{0xde, 0xad, 0xbe, 0xef},
+			CancellationType: intPtr(gsmmap.SubscriptionWithdraw),
+		},
+		res: &gsmmap.CancelLocationRes{},
+	}, {
+		description: "insertSubscriberData",
+		opCode:      gsmmap.InsertSubscriberData,
+		arg: &gsmmap.InsertSubscriberDataArg{
+			MSISDN:            gsmmap.NewAddressString("819012345678"),
+			Category:          []byte{0x0a},
+			SubscriberStatus:  intPtr(gsmmap.ServiceGranted),
+			TeleserviceList:   [][]byte{{0x11}, {0x21}, {0x22}},
+			NetworkAccessMode: intPtr(gsmmap.PacketAndCircuit),
+		},
+		res: &gsmmap.InsertSubscriberDataRes{
+			TeleserviceList: [][]byte{{0x22}},
+		},
+	}, {
+		description: "sendRoutingInfo",
+		opCode:      gsmmap.SendRoutingInfo,
+		arg: &gsmmap.SendRoutingInfoArg{
+			MSISDN:             gsmmap.NewAddressString("819012345678"),
+			NumberOfForwarding: intPtr(1),
+			InterrogationType:  gsmmap.InterrogationBasicCall,
+			GMSCAddress:        gsmmap.NewAddressString("819000000002"),
+		},
+		res: &gsmmap.SendRoutingInfoRes{
+			IMSI:          "001010123456789",
+			RoamingNumber: gsmmap.NewAddressString("819000009999"),
+		},
+	}, {
+		description: "sendRoutingInfoForSM",
+		opCode:      gsmmap.SendRoutingInfoForSM,
+		arg: &gsmmap.RoutingInfoForSMArg{
+			MSISDN:               gsmmap.NewAddressString("819012345678"),
+			SMRPPRI:              true,
+			ServiceCentreAddress: gsmmap.NewAddressString("819000000003"),
+		},
+		res: &gsmmap.RoutingInfoForSMRes{
+			IMSI:              "001010123456789",
+			NetworkNodeNumber: gsmmap.NewAddressString("819012345678"),
+			AdditionalSGSN:    gsmmap.NewAddressString("819012345600"),
+		},
+	}, {
+		description: "mo-forwardSM",
+		opCode:      gsmmap.MOForwardSM,
+		arg: &gsmmap.MOForwardSMArg{
+			SMRPDA: gsmmap.SMRPDA{ServiceCentreAddress: gsmmap.NewAddressString("819000000003")},
+			SMRPOA: gsmmap.SMRPOA{MSISDN: gsmmap.NewAddressString("819012345678")},
+			SMRPUI: []byte{0x01, 0x02, 0x03},
+			IMSI:   "001010123456789",
+		},
+		res: &gsmmap.ForwardSMRes{},
+	}, {
+		description: "mt-forwardSM",
+		opCode:      gsmmap.MTForwardSM,
+		arg: &gsmmap.MTForwardSMArg{
+			SMRPDA:             gsmmap.SMRPDA{IMSI: "001010123456789"},
+			SMRPOA:             gsmmap.SMRPOA{ServiceCentreAddress: gsmmap.NewAddressString("819000000003")},
+			SMRPUI:             []byte{0x04, 0x05, 0x06},
+			MoreMessagesToSend: true,
+		},
+		res: &gsmmap.ForwardSMRes{SMRPUI: []byte{0x00}},
+	}, {
+		description: "sendAuthenticationInfo",
+		opCode:      gsmmap.SendAuthenticationInfo,
+		arg: &gsmmap.SendAuthenticationInfoArg{
+			IMSI:                     "001010123456789",
+			NumberOfRequestedVectors: 1,
+		},
+		res: &gsmmap.SendAuthenticationInfoRes{
+			Triplets: []*gsmmap.AuthenticationTriplet{
+				{RAND: make([]byte, 16), SRES: []byte{1, 2, 3, 4}, Kc: make([]byte, 8)},
+			},
+		},
+	}, {
+		description: "processUnstructuredSS-Request",
+		opCode:      gsmmap.ProcessUnstructuredSSRequest,
+		arg: &gsmmap.USSDArg{
+			DataCodingScheme: 0x0f,
+			USSDString:       []byte{0xaa, 0x18, 0x0c, 0x36, 0x02},
+			MSISDN:           gsmmap.NewAddressString("819012345678"),
+		},
+		res: &gsmmap.USSDRes{
+			DataCodingScheme: 0x0f,
+			USSDString:       []byte{0xd4, 0x32, 0x9b, 0xfd, 0x06},
+		},
+	}, {
+		description: "anyTimeInterrogation",
+		opCode:      gsmmap.AnyTimeInterrogation,
+		arg: &gsmmap.AnyTimeInterrogationArg{
+			MSISDN:        gsmmap.NewAddressString("819012345678"),
+			RequestedInfo: gsmmap.RequestedInfo{LocationInformation: true, SubscriberState: true},
+			GSMSCFAddress: gsmmap.NewAddressString("819000000004"),
+		},
+		res: &gsmmap.AnyTimeInterrogationRes{
+			SubscriberInfo: gsmmap.SubscriberInfo{
+				LocationInformation: &gsmmap.LocationInformation{
+					AgeOfLocationInformation: intPtr(5),
+					VLRNumber:                gsmmap.NewAddressString("819012345679"),
+					CellGlobalID:             []byte{0x00, 0xf1, 0x10, 0x00, 0x01, 0x00, 0x02},
+				},
+				SubscriberState: &gsmmap.SubscriberState{State: gsmmap.NetDetNotReachable, NotReachableReason: 1},
+			},
+		},
+	}, {
+		description: "provideSubscriberInfo",
+		opCode:      gsmmap.ProvideSubscriberInfo,
+		arg: &gsmmap.ProvideSubscriberInfoArg{
+			IMSI:          "001010123456789",
+			RequestedInfo: gsmmap.RequestedInfo{IMEI: true},
+		},
+		res: &gsmmap.ProvideSubscriberInfoRes{
+			SubscriberInfo: gsmmap.SubscriberInfo{
+				IMEI: "3520990017614823",
+			},
+		},
+	},
+}
+
+func TestParameters(t *testing.T) {
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			for _, p := range []gsmmap.Parameter{c.arg, c.res} {
+				ie, err := gsmmap.MarshalParameter(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got gsmmap.Parameter
+				if p == c.arg {
+					got, err = gsmmap.NewArgument(c.opCode)
+				} else {
+					got, err = gsmmap.NewResult(c.opCode)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := gsmmap.UnmarshalParameter(ie, got); err != nil {
+					t.Fatal(err)
+				}
+				verify.Values(t, "", got, p)
+			}
+		})
+	}
+}
+
+func TestParseArgument(t *testing.T) {
+	// The message is the same as the one sent by examples/client.
+	msg, err := tcap.Parse([]byte{
+		0x62, 0x3c, 0x48, 0x04, 0x11, 0x11, 0x11, 0x11, 0x6b, 0x1e, 0x28, 0x1c, 0x06, 0x07, 0x00, 0x11,
+		0x86, 0x05, 0x01, 0x01, 0x01, 0xa0, 0x11, 0x60, 0x0f, 0x80, 0x02, 0x07, 0x80, 0xa1, 0x09, 0x06,
+		0x07, 0x04, 0x00, 0x00, 0x01, 0x00, 0x02, 0x03, 0x6c, 0x14, 0xa1, 0x12, 0x02, 0x01, 0x00, 0x02,
+		0x01, 0x03, 0x30, 0x0a, 0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := gsmmap.ParseArgument(tcap.LocationCancellationContext, msg.Components.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", arg, &gsmmap.CancelLocationArg{IMSI: "001010123456789"})
+
+	_, err = gsmmap.ParseArgument(tcap.ShortMsgGatewayContext, msg.Components.Component[0])
+	var unsupported *gsmmap.UnsupportedOperationError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("unexpected error for unsupported operation in the context: %v", err)
+	}
+	verify.Values(t, "error", err.Error(), "gsmmap: unsupported operation 3 in context 20")
+
+	r := tcap.DefaultOperationRegistry.ForContext(msg.Dialogue.ApplicationContextOID())
+	if err := r.Validate(msg.Dialogue.ApplicationContextOID(), msg.Components.Component[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := r.Decode(msg.Components.Component[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "registry", decoded, arg)
+}
+
+func TestRegistryContexts(t *testing.T) {
+	acn := func(ctx uint8, ver int) asn1.ObjectIdentifier {
+		return asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, int(ctx), ver}
+	}
+
+	// The operations without parameters are recognized in their contexts.
+	report := tcap.NewInvoke(1, -1, 47, true, nil)
+	if err := gsmmap.Registry.Validate(acn(tcap.ShortMsgGatewayContext, 3), report, nil); err != nil {
+		t.Errorf("reportSM-DeliveryStatus: %v", err)
+	}
+	ussd := tcap.NewInvoke(1, -1, int(gsmmap.ProcessUnstructuredSSRequest), true, nil)
+	if err := gsmmap.Registry.Validate(acn(tcap.NetworkUnstructuredSsContext, 2), ussd, nil); err != nil {
+		t.Errorf("processUnstructuredSS-Request: %v", err)
+	}
+}
+
+func TestEndpointRegistry(t *testing.T) {
+	tcap.DisableLogging()
+	a, b := transport.Pipe()
+	client, server := tcap.NewEndpoint(a), tcap.NewEndpoint(b)
+	server.Registry = gsmmap.Registry
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go client.Serve(ctx)
+	go server.Serve(ctx)
+
+	// sendAuthenticationInfo in version 2 has the bare IMSI, which is not rejected.
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imsi := []byte{0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9}
+	if err := cs.Send(tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.InfoRetrievalContext, 2, 1, int(gsmmap.SendAuthenticationInfo), imsi)); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ss.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "components", len(m.Components.Component), 1)
+}
+
+func TestNewInvoke(t *testing.T) {
+	c, err := gsmmap.NewInvoke(1, gsmmap.SendAuthenticationInfo, &gsmmap.SendAuthenticationInfoArg{
+		IMSI:                     "001010123456789",
+		NumberOfRequestedVectors: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xa1, 0x15, 0x02, 0x01, 0x01, 0x02, 0x01, 0x38, 0x30, 0x0d, 0x80, 0x08, 0x00, 0x01, 0x01, 0x21,
+		0x43, 0x65, 0x87, 0xf9, 0x02, 0x01, 0x02,
+	}
+	verify.Values(t, "", b, want)
+}
+
+func TestNames(t *testing.T) {
+	verify.Values(t, "operation", gsmmap.OperationName(gsmmap.SendRoutingInfoForSM), "sendRoutingInfoForSM")
+	verify.Values(t, "error", gsmmap.ErrorName(gsmmap.AbsentSubscriberSM), "absentSubscriberSM")
+	verify.Values(t, "unknown error", gsmmap.ErrorName(0), "")
+}
+
+func TestLongParameter(t *testing.T) {
+	for _, n := range []int{140, 200} {
+		arg := &gsmmap.MTForwardSMArg{
+			SMRPDA: gsmmap.SMRPDA{IMSI: "001010123456789"},
+			SMRPOA: gsmmap.SMRPOA{ServiceCentreAddress: gsmmap.NewAddressString("819000000001")},
+			SMRPUI: make([]byte, n),
+		}
+		c, err := gsmmap.NewInvoke(1, gsmmap.MTForwardSM, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := (&tcap.TCAP{Transaction: tcap.NewBegin(0x11111111, nil), Components: tcap.NewComponents(c)}).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg, err := tcap.Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := gsmmap.ParseArgument(tcap.ShortMsgMTRelayContext, msg.Components.Component[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, "", got, arg)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package gsmmap provides the typed parameters of the common MAP (Mobile Application Part)
+operations defined in 3GPP TS 29.002, which can be set into and retrieved from
+the Parameter of tcap.Component.
+
+The package is named gsmmap as map is a reserved word in Go.
+The structures follow the ASN.1 definitions of MAP version 3. Only the commonly
+used fields are supported, and the unknown fields are ignored when decoding.
+*/
+package gsmmap
+
+import (
+	"encoding/asn1"
+	"slices"
+	"strconv"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Operation Code definitions.
+const (
+	UpdateLocation               uint8 = 2
+	CancelLocation               uint8 = 3
+	InsertSubscriberData         uint8 = 7
+	SendRoutingInfo              uint8 = 22
+	MTForwardSM                  uint8 = 44
+	SendRoutingInfoForSM         uint8 = 45
+	MOForwardSM                  uint8 = 46
+	SendAuthenticationInfo       uint8 = 56
+	ProcessUnstructuredSSRequest uint8 = 59
+	ProvideSubscriberInfo        uint8 = 70
+	AnyTimeInterrogation         uint8 = 71
+)
+
+// Error Code definitions.
+const (
+	UnknownSubscriber             uint8 = 1
+	UnknownMSC                    uint8 = 3
+	UnidentifiedSubscriber        uint8 = 5
+	AbsentSubscriberSM            uint8 = 6
+	UnknownEquipment              uint8 = 7
+	RoamingNotAllowed             uint8 = 8
+	IllegalSubscriber             uint8 = 9
+	BearerServiceNotProvisioned   uint8 = 10
+	TeleserviceNotProvisioned     uint8 = 11
+	IllegalEquipment              uint8 = 12
+	CallBarred                    uint8 = 13
+	FacilityNotSupported          uint8 = 21
+	AbsentSubscriber              uint8 = 27
+	IncompatibleTerminal          uint8 = 28
+	SubscriberBusyForMTSMS        uint8 = 31
+	SMDeliveryFailure             uint8 = 32
+	MessageWaitingListFull        uint8 = 33
+	SystemFailure                 uint8 = 34
+	DataMissing                   uint8 = 35
+	UnexpectedDataValue           uint8 = 36
+	NumberChanged                 uint8 = 44
+	ATINotAllowed                 uint8 = 49
+	ResourceLimitation            uint8 = 51
+	UnauthorizedRequestingNetwork uint8 = 52
+)
+
+var errorNames = map[uint8]string{
+	UnknownSubscriber:             "unknownSubscriber",
+	UnknownMSC:                    "unknownMSC",
+	UnidentifiedSubscriber:        "unidentifiedSubscriber",
+	AbsentSubscriberSM:            "absentSubscriberSM",
+	UnknownEquipment:              "unknownEquipment",
+	RoamingNotAllowed:             "roamingNotAllowed",
+	IllegalSubscriber:             "illegalSubscriber",
+	BearerServiceNotProvisioned:   "bearerServiceNotProvisioned",
+	TeleserviceNotProvisioned:     "teleserviceNotProvisioned",
+	IllegalEquipment:              "illegalEquipment",
+	CallBarred:                    "callBarred",
+	FacilityNotSupported:          "facilityNotSupported",
+	AbsentSubscriber:              "absentSubscriber",
+	IncompatibleTerminal:          "incompatibleTerminal",
+	SubscriberBusyForMTSMS:        "subscriberBusyForMT-SMS",
+	SMDeliveryFailure:             "sm-DeliveryFailure",
+	MessageWaitingListFull:        "messageWaitingListFull",
+	SystemFailure:                 "systemFailure",
+	DataMissing:                   "dataMissing",
+	UnexpectedDataValue:           "unexpectedDataValue",
+	NumberChanged:                 "numberChanged",
+	ATINotAllowed:                 "atiNotAllowed",
+	ResourceLimitation:            "resourceLimitation",
+	UnauthorizedRequestingNetwork: "unauthorizedRequestingNetwork",
+}
+
+// Parameter is the argument or result of MAP operation.
+//
+// MarshalBinary returns the whole TLV of the parameter, and UnmarshalBinary
+// accepts the same.
+type Parameter = param.Parameter
+
+var operations = map[uint8]param.Operation{
+	UpdateLocation: {
+		Name: "updateLocation",
+		Arg:  func() Parameter { return &UpdateLocationArg{} },
+		Res:  func() Parameter { return &UpdateLocationRes{} },
+	},
+	CancelLocation: {
+		Name: "cancelLocation",
+		Arg:  func() Parameter { return &CancelLocationArg{} },
+		Res:  func() Parameter { return &CancelLocationRes{} },
+	},
+	InsertSubscriberData: {
+		Name: "insertSubscriberData",
+		Arg:  func() Parameter { return &InsertSubscriberDataArg{} },
+		Res:  func() Parameter { return &InsertSubscriberDataRes{} },
+	},
+	SendRoutingInfo: {
+		Name: "sendRoutingInfo",
+		Arg:  func() Parameter { return &SendRoutingInfoArg{} },
+		Res:  func() Parameter { return &SendRoutingInfoRes{} },
+	},
+	MTForwardSM: {
+		Name: "mt-forwardSM",
+		Arg:  func() Parameter { return &MTForwardSMArg{} },
+		Res:  func() Parameter { return &ForwardSMRes{} },
+	},
+	SendRoutingInfoForSM: {
+		Name: "sendRoutingInfoForSM",
+		Arg:  func() Parameter { return &RoutingInfoForSMArg{} },
+		Res:  func() Parameter { return &RoutingInfoForSMRes{} },
+	},
+	MOForwardSM: {
+		Name: "mo-forwardSM",
+		Arg:  func() Parameter { return &MOForwardSMArg{} },
+		Res:  func() Parameter { return &ForwardSMRes{} },
+	},
+	SendAuthenticationInfo: {
+		Name: "sendAuthenticationInfo",
+		Arg:  func() Parameter { return &SendAuthenticationInfoArg{} },
+		Res:  func() Parameter { return &SendAuthenticationInfoRes{} },
+	},
+	ProcessUnstructuredSSRequest: {
+		Name: "processUnstructuredSS-Request",
+		Arg:  func() Parameter { return &USSDArg{} },
+		Res:  func() Parameter { return &USSDRes{} },
+	},
+	ProvideSubscriberInfo: {
+		Name: "provideSubscriberInfo",
+		Arg:  func() Parameter { return &ProvideSubscriberInfoArg{} },
+		Res:  func() Parameter { return &ProvideSubscriberInfoRes{} },
+	},
+	AnyTimeInterrogation: {
+		Name: "anyTimeInterrogation",
+		Arg:  func() Parameter { return &AnyTimeInterrogationArg{} },
+		Res:  func() Parameter { return &AnyTimeInterrogationRes{} },
+	},
+}
+
+// contexts is the list of operations supported in each application context.
+var contexts = map[uint8][]uint8{
+	tcap.NetworkLocUpContext:          {UpdateLocation, InsertSubscriberData},
+	tcap.LocationCancellationContext:  {CancelLocation},
+	tcap.LocationInfoRetrievalContext: {SendRoutingInfo},
+	tcap.InfoRetrievalContext:         {SendAuthenticationInfo},
+	tcap.SubscriberDataMngtContext:    {InsertSubscriberData},
+	tcap.NetworkUnstructuredSsContext: {ProcessUnstructuredSSRequest},
+	tcap.ShortMsgGatewayContext:       {SendRoutingInfoForSM},
+	tcap.ShortMsgRelayContext:         {MOForwardSM},
+	tcap.ShortMsgMTRelayContext:       {MTForwardSM},
+	tcap.SubscriberInfoEnquiryContext: {ProvideSubscriberInfo},
+	tcap.AnyTimeInfoEnquiryContext:    {AnyTimeInterrogation},
+}
+
+// versions is the versions of the application contexts whose ASN.1 matches the
+// parameters of this package. The others are in version 3.
+var versions = map[uint8]int{
+	tcap.NetworkUnstructuredSsContext: 2,
+}
+
+// others is the operations in the application contexts above that this package
+// has no parameters for. They are registered in Registry only by name, not to
+// be rejected as unrecognized.
+var others = map[uint8]map[uint8]string{
+	tcap.NetworkLocUpContext:          {38: "forwardCheckSS-Indication", 50: "activateTraceMode", 57: "restoreData"},
+	tcap.SubscriberDataMngtContext:    {8: "deleteSubscriberData"},
+	tcap.NetworkUnstructuredSsContext: {60: "unstructuredSS-Request", 61: "unstructuredSS-Notify"},
+	tcap.ShortMsgGatewayContext:       {47: "reportSM-DeliveryStatus", 63: "informServiceCentre"},
+}
+
+var table = &param.Table{
+	Protocol:   protocol,
+	Operations: operations,
+	Errors:     errorNames,
+}
+
+// Registry holds the MAP operations and application contexts supported by this
+// package, which is included in tcap.DefaultOperationRegistry on init.
+//
+// The application contexts are registered only in the version whose ASN.1
+// matches the parameters, which is 3 except networkUnstructuredSsContext in 2.
+// Set it in Registry of tcap.Endpoint to validate and decode the Components
+// received in those dialogues.
+var Registry = table.Registry()
+
+func init() {
+	for ctx, ops := range contexts {
+		ver, ok := versions[ctx]
+		if !ok {
+			ver = 3
+		}
+		ops = slices.Clone(ops)
+		for code, name := range others[ctx] {
+			Registry.RegisterOperation(&tcap.Operation{Code: code, Name: name})
+			ops = append(ops, code)
+		}
+		Registry.RegisterContext(asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, int(ctx), ver}, ops...)
+	}
+	tcap.DefaultOperationRegistry.Include(Registry)
+}
+
+// OperationName returns the name of operation in string.
+func OperationName(opCode uint8) string {
+	return table.OperationName(opCode)
+}
+
+// ErrorName returns the name of error in string.
+func ErrorName(errCode uint8) string {
+	return table.ErrorName(errCode)
+}
+
+// Operations returns the Operation Codes supported by this package in the application context.
+func Operations(ctx uint8) []uint8 {
+	return contexts[ctx]
+}
+
+// NewArgument returns an empty argument of the operation.
+func NewArgument(opCode uint8) (Parameter, error) {
+	return table.NewArgument(opCode)
+}
+
+// NewResult returns an empty result of the operation.
+func NewResult(opCode uint8) (Parameter, error) {
+	return table.NewResult(opCode)
+}
+
+// MarshalParameter returns the Parameter as an IE to be set in tcap.Component.
+func MarshalParameter(p Parameter) (*tcap.IE, error) {
+	return param.Marshal(p)
+}
+
+// UnmarshalParameter sets the values retrieved from the IE in tcap.Component in p.
+func UnmarshalParameter(ie *tcap.IE, p Parameter) error {
+	return table.Unmarshal(ie, p)
+}
+
+// SetParameter sets the Parameter in the Component and updates its length.
+func SetParameter(c *tcap.Component, p Parameter) error {
+	return param.Set(c, p)
+}
+
+// NewInvoke returns a new Invoke Component with the argument of the operation.
+func NewInvoke(invID int, opCode uint8, arg Parameter) (*tcap.Component, error) {
+	return param.NewInvoke(invID, opCode, arg)
+}
+
+// NewReturnResult returns a new ReturnResultLast Component with the result of the operation.
+func NewReturnResult(invID int, opCode uint8, res Parameter) (*tcap.Component, error) {
+	return param.NewReturnResult(invID, opCode, res)
+}
+
+// ParseArgument parses the Parameter of Invoke Component as the argument of
+// the operation in the application context given.
+func ParseArgument(ctx uint8, c *tcap.Component) (Parameter, error) {
+	if !slices.Contains(contexts[ctx], c.OpCode()) {
+		return nil, protocol.Unsupported(strconv.Itoa(int(ctx)), c.OpCode())
+	}
+	return table.ParseArgument(c)
+}
+
+// ParseResult parses the Parameter of ReturnResult Component as the result of
+// the operation in the application context given.
+//
+// An empty result is returned if the Component has no Parameter.
+func ParseResult(ctx uint8, c *tcap.Component) (Parameter, error) {
+	if !slices.Contains(contexts[ctx], c.OpCode()) {
+		return nil, protocol.Unsupported(strconv.Itoa(int(ctx)), c.OpCode())
+	}
+	return table.ParseResult(c.OpCode(), c)
+}
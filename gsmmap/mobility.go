@@ -0,0 +1,542 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Cancellation Type definitions.
+const (
+	UpdateProcedure int = iota
+	SubscriptionWithdraw
+	InitialAttachProcedure
+)
+
+// Subscriber Status definitions.
+const (
+	ServiceGranted int = iota
+	OperatorDeterminedBarring
+)
+
+// Network Access Mode definitions.
+const (
+	PacketAndCircuit int = iota
+	OnlyCircuit
+	OnlyPacket
+)
+
+// UpdateLocationArg represents UpdateLocationArg.
+type UpdateLocationArg struct {
+	IMSI                        string
+	MSCNumber                   *AddressString
+	VLRNumber                   *AddressString
+	LMSI                        []byte
+	InformPreviousNetworkEntity bool
+	SkipSubscriberDataUpdate    bool
+	RestorationIndicator        bool
+}
+
+// MarshalBinary returns the byte sequence generated from an UpdateLocationArg.
+func (u *UpdateLocationArg) MarshalBinary() ([]byte, error) {
+	if u.MSCNumber == nil {
+		return nil, protocol.Missing("msc-Number")
+	}
+	if u.VLRNumber == nil {
+		return nil, protocol.Missing("vlr-Number")
+	}
+
+	v, err := appendTBCD(nil, ber.Universal, ber.TagOctetString, u.IMSI)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = appendAddress(v, ber.ContextSpecific, 1, u.MSCNumber); err != nil {
+		return nil, err
+	}
+	if v, err = appendAddress(v, ber.Universal, ber.TagOctetString, u.VLRNumber); err != nil {
+		return nil, err
+	}
+	if u.LMSI != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 10, u.LMSI)
+	}
+	if u.InformPreviousNetworkEntity {
+		v = appendNull(v, 11)
+	}
+	if u.SkipSubscriberDataUpdate {
+		v = appendNull(v, 15)
+	}
+	if u.RestorationIndicator {
+		v = appendNull(v, 16)
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an UpdateLocationArg.
+func (u *UpdateLocationArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "UpdateLocationArg")
+	if err != nil {
+		return err
+	}
+
+	octets := 0
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.Universal, ber.TagOctetString):
+			// imsi and vlr-Number are both untagged OCTET STRING.
+			if octets == 0 {
+				u.IMSI = DecodeTBCD(e.Value)
+			} else if u.VLRNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+			octets++
+		case e.Is(ber.ContextSpecific, 1):
+			if u.MSCNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 10):
+			u.LMSI = e.Value
+		case e.Is(ber.ContextSpecific, 11):
+			u.InformPreviousNetworkEntity = true
+		case e.Is(ber.ContextSpecific, 15):
+			u.SkipSubscriberDataUpdate = true
+		case e.Is(ber.ContextSpecific, 16):
+			u.RestorationIndicator = true
+		}
+	}
+
+	if u.IMSI == "" {
+		return protocol.Missing("imsi")
+	}
+	return nil
+}
+
+// UpdateLocationRes represents UpdateLocationRes.
+type UpdateLocationRes struct {
+	HLRNumber *AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from an UpdateLocationRes.
+func (u *UpdateLocationRes) MarshalBinary() ([]byte, error) {
+	if u.HLRNumber == nil {
+		return nil, protocol.Missing("hlr-Number")
+	}
+
+	v, err := appendAddress(nil, ber.Universal, ber.TagOctetString, u.HLRNumber)
+	if err != nil {
+		return nil, err
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an UpdateLocationRes.
+func (u *UpdateLocationRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "UpdateLocationRes")
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		if e.Is(ber.Universal, ber.TagOctetString) {
+			if u.HLRNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if u.HLRNumber == nil {
+		return protocol.Missing("hlr-Number")
+	}
+	return nil
+}
+
+// CancelLocationArg represents CancelLocationArg.
+//
+// The identity is encoded as imsi-WithLMSI if LMSI is set, otherwise as imsi.
+type CancelLocationArg struct {
+	IMSI             string
+	LMSI             []byte
+	CancellationType *int
+}
+
+// MarshalBinary returns the byte sequence generated from a CancelLocationArg.
+func (c *CancelLocationArg) MarshalBinary() ([]byte, error) {
+	v, err := appendTBCD(nil, ber.Universal, ber.TagOctetString, c.IMSI)
+	if err != nil {
+		return nil, err
+	}
+	if c.LMSI != nil {
+		v = ber.Append(v, ber.Universal, false, ber.TagOctetString, c.LMSI)
+		v = param.Sequence(-1, v)
+	}
+	if c.CancellationType != nil {
+		v = appendInteger(v, ber.Universal, ber.TagEnumerated, *c.CancellationType)
+	}
+	return param.Sequence(3, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CancelLocationArg.
+func (c *CancelLocationArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "CancelLocationArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.Universal, ber.TagOctetString):
+			c.IMSI = DecodeTBCD(e.Value)
+		case e.Is(ber.Universal, ber.TagSequence):
+			ids, err := ber.ParseElements(e.Value)
+			if err != nil {
+				return err
+			}
+			if len(ids) != 2 {
+				return protocol.Invalid("imsi-WithLMSI")
+			}
+			c.IMSI = DecodeTBCD(ids[0].Value)
+			c.LMSI = ids[1].Value
+		case e.Is(ber.Universal, ber.TagEnumerated):
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			c.CancellationType = &n
+		}
+	}
+
+	if c.IMSI == "" {
+		return protocol.Missing("identity")
+	}
+	return nil
+}
+
+// CancelLocationRes represents CancelLocationRes, which has no values but extensions.
+type CancelLocationRes struct{}
+
+// MarshalBinary returns the byte sequence generated from a CancelLocationRes.
+func (c *CancelLocationRes) MarshalBinary() ([]byte, error) {
+	return param.Sequence(-1, nil), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CancelLocationRes.
+func (c *CancelLocationRes) UnmarshalBinary(b []byte) error {
+	_, err := protocol.ParseSequence(b, "CancelLocationRes")
+	return err
+}
+
+// InsertSubscriberDataArg represents InsertSubscriberDataArg.
+//
+// BearerServiceList and TeleserviceList are the lists of Ext-BearerServiceCode
+// and Ext-TeleserviceCode respectively.
+type InsertSubscriberDataArg struct {
+	IMSI                    string
+	MSISDN                  *AddressString
+	Category                []byte
+	SubscriberStatus        *int
+	BearerServiceList       [][]byte
+	TeleserviceList         [][]byte
+	ChargingCharacteristics []byte
+	NetworkAccessMode       *int
+}
+
+// MarshalBinary returns the byte sequence generated from an InsertSubscriberDataArg.
+func (i *InsertSubscriberDataArg) MarshalBinary() ([]byte, error) {
+	var v []byte
+	var err error
+	if i.IMSI != "" {
+		if v, err = appendTBCD(v, ber.ContextSpecific, 0, i.IMSI); err != nil {
+			return nil, err
+		}
+	}
+	if i.MSISDN != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 1, i.MSISDN); err != nil {
+			return nil, err
+		}
+	}
+	if i.Category != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 2, i.Category)
+	}
+	if i.SubscriberStatus != nil {
+		v = appendInteger(v, ber.ContextSpecific, 3, *i.SubscriberStatus)
+	}
+	if i.BearerServiceList != nil {
+		v = ber.Append(v, ber.ContextSpecific, true, 4, octetsList(i.BearerServiceList))
+	}
+	if i.TeleserviceList != nil {
+		v = ber.Append(v, ber.ContextSpecific, true, 6, octetsList(i.TeleserviceList))
+	}
+	if i.ChargingCharacteristics != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 18, i.ChargingCharacteristics)
+	}
+	if i.NetworkAccessMode != nil {
+		v = appendInteger(v, ber.ContextSpecific, 24, *i.NetworkAccessMode)
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InsertSubscriberDataArg.
+func (i *InsertSubscriberDataArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "InsertSubscriberDataArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			i.IMSI = DecodeTBCD(e.Value)
+		case 1:
+			if i.MSISDN, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 2:
+			i.Category = e.Value
+		case 3:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			i.SubscriberStatus = &n
+		case 4:
+			if i.BearerServiceList, err = parseOctetsList(e.Value); err != nil {
+				return err
+			}
+		case 6:
+			if i.TeleserviceList, err = parseOctetsList(e.Value); err != nil {
+				return err
+			}
+		case 18:
+			i.ChargingCharacteristics = e.Value
+		case 24:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			i.NetworkAccessMode = &n
+		}
+	}
+	return nil
+}
+
+// InsertSubscriberDataRes represents InsertSubscriberDataRes.
+//
+// The lists contain the services that are not supported by the VLR.
+type InsertSubscriberDataRes struct {
+	TeleserviceList   [][]byte
+	BearerServiceList [][]byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InsertSubscriberDataRes.
+func (i *InsertSubscriberDataRes) MarshalBinary() ([]byte, error) {
+	var v []byte
+	if i.TeleserviceList != nil {
+		v = ber.Append(v, ber.ContextSpecific, true, 1, octetsList(i.TeleserviceList))
+	}
+	if i.BearerServiceList != nil {
+		v = ber.Append(v, ber.ContextSpecific, true, 2, octetsList(i.BearerServiceList))
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InsertSubscriberDataRes.
+func (i *InsertSubscriberDataRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "InsertSubscriberDataRes")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 1):
+			if i.TeleserviceList, err = parseOctetsList(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 2):
+			if i.BearerServiceList, err = parseOctetsList(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SendAuthenticationInfoArg represents SendAuthenticationInfoArg.
+//
+// RAND and AUTS are the Re-synchronisationInfo and should be set together.
+type SendAuthenticationInfoArg struct {
+	IMSI                       string
+	NumberOfRequestedVectors   int
+	SegmentationProhibited     bool
+	ImmediateResponsePreferred bool
+	RAND                       []byte
+	AUTS                       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a SendAuthenticationInfoArg.
+func (s *SendAuthenticationInfoArg) MarshalBinary() ([]byte, error) {
+	v, err := appendTBCD(nil, ber.ContextSpecific, 0, s.IMSI)
+	if err != nil {
+		return nil, err
+	}
+	v = appendInteger(v, ber.Universal, ber.TagInteger, s.NumberOfRequestedVectors)
+	if s.SegmentationProhibited {
+		v = ber.Append(v, ber.Universal, false, ber.TagNull, nil)
+	}
+	if s.ImmediateResponsePreferred {
+		v = appendNull(v, 1)
+	}
+	if s.RAND != nil || s.AUTS != nil {
+		resync := ber.Append(nil, ber.Universal, false, ber.TagOctetString, s.RAND)
+		resync = ber.Append(resync, ber.Universal, false, ber.TagOctetString, s.AUTS)
+		v = ber.Append(v, ber.Universal, true, ber.TagSequence, resync)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendAuthenticationInfoArg.
+func (s *SendAuthenticationInfoArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "SendAuthenticationInfoArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 0):
+			s.IMSI = DecodeTBCD(e.Value)
+		case e.Is(ber.Universal, ber.TagInteger):
+			if s.NumberOfRequestedVectors, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+		case e.Is(ber.Universal, ber.TagNull):
+			s.SegmentationProhibited = true
+		case e.Is(ber.ContextSpecific, 1):
+			s.ImmediateResponsePreferred = true
+		case e.Is(ber.Universal, ber.TagSequence):
+			resync, err := ber.ParseElements(e.Value)
+			if err != nil {
+				return err
+			}
+			if len(resync) != 2 {
+				return protocol.Invalid("re-synchronisationInfo")
+			}
+			s.RAND, s.AUTS = resync[0].Value, resync[1].Value
+		}
+	}
+
+	if s.IMSI == "" {
+		return protocol.Missing("imsi")
+	}
+	return nil
+}
+
+// AuthenticationTriplet represents AuthenticationTriplet for GSM.
+type AuthenticationTriplet struct {
+	RAND []byte
+	SRES []byte
+	Kc   []byte
+}
+
+// AuthenticationQuintuplet represents AuthenticationQuintuplet for UMTS.
+type AuthenticationQuintuplet struct {
+	RAND []byte
+	XRES []byte
+	CK   []byte
+	IK   []byte
+	AUTN []byte
+}
+
+// SendAuthenticationInfoRes represents SendAuthenticationInfoRes.
+//
+// Either of Triplets or Quintuplets is set as the authenticationSetList.
+type SendAuthenticationInfoRes struct {
+	Triplets    []*AuthenticationTriplet
+	Quintuplets []*AuthenticationQuintuplet
+}
+
+// MarshalBinary returns the byte sequence generated from a SendAuthenticationInfoRes.
+func (s *SendAuthenticationInfoRes) MarshalBinary() ([]byte, error) {
+	var v []byte
+	switch {
+	case s.Triplets != nil:
+		var list []byte
+		for _, t := range s.Triplets {
+			list = ber.Append(list, ber.Universal, true, ber.TagSequence, octetsSequence(t.RAND, t.SRES, t.Kc))
+		}
+		v = ber.Append(v, ber.ContextSpecific, true, 0, list)
+	case s.Quintuplets != nil:
+		var list []byte
+		for _, q := range s.Quintuplets {
+			list = ber.Append(list, ber.Universal, true, ber.TagSequence, octetsSequence(q.RAND, q.XRES, q.CK, q.IK, q.AUTN))
+		}
+		v = ber.Append(v, ber.ContextSpecific, true, 1, list)
+	}
+	return param.Sequence(3, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendAuthenticationInfoRes.
+func (s *SendAuthenticationInfoRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "SendAuthenticationInfoRes")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific || e.Tag > 1 {
+			continue
+		}
+		vectors, err := ber.ParseElements(e.Value)
+		if err != nil {
+			return err
+		}
+		for _, vec := range vectors {
+			o, err := parseOctetsList(vec.Value)
+			if err != nil {
+				return err
+			}
+			if e.Tag == 0 {
+				if len(o) < 3 {
+					return protocol.Invalid("AuthenticationTriplet")
+				}
+				s.Triplets = append(s.Triplets, &AuthenticationTriplet{RAND: o[0], SRES: o[1], Kc: o[2]})
+				continue
+			}
+			if len(o) < 5 {
+				return protocol.Invalid("AuthenticationQuintuplet")
+			}
+			s.Quintuplets = append(s.Quintuplets, &AuthenticationQuintuplet{RAND: o[0], XRES: o[1], CK: o[2], IK: o[3], AUTN: o[4]})
+		}
+	}
+	return nil
+}
+
+func octetsSequence(octets ...[]byte) []byte {
+	var v []byte
+	for _, o := range octets {
+		v = ber.Append(v, ber.Universal, false, ber.TagOctetString, o)
+	}
+	return v
+}
+
+func octetsList(list [][]byte) []byte {
+	return octetsSequence(list...)
+}
+
+func parseOctetsList(b []byte) ([][]byte, error) {
+	elems, err := ber.ParseElements(b)
+	if err != nil {
+		return nil, err
+	}
+	list := make([][]byte, len(elems))
+	for i, e := range elems {
+		list[i] = e.Value
+	}
+	return list, nil
+}
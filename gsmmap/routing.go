@@ -0,0 +1,421 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Interrogation Type definitions.
+const (
+	InterrogationBasicCall int = iota
+	InterrogationForwarding
+)
+
+// SendRoutingInfoArg represents SendRoutingInfoArg.
+type SendRoutingInfoArg struct {
+	MSISDN                    *AddressString
+	NumberOfForwarding        *int
+	InterrogationType         int
+	ORInterrogation           bool
+	ORCapability              *int
+	GMSCAddress               *AddressString
+	CallReferenceNumber       []byte
+	SuppressionOfAnnouncement bool
+}
+
+// MarshalBinary returns the byte sequence generated from a SendRoutingInfoArg.
+func (s *SendRoutingInfoArg) MarshalBinary() ([]byte, error) {
+	if s.MSISDN == nil {
+		return nil, protocol.Missing("msisdn")
+	}
+	if s.GMSCAddress == nil {
+		return nil, protocol.Missing("gmsc-OrGsmSCF-Address")
+	}
+
+	v, err := appendAddress(nil, ber.ContextSpecific, 0, s.MSISDN)
+	if err != nil {
+		return nil, err
+	}
+	if s.NumberOfForwarding != nil {
+		v = appendInteger(v, ber.ContextSpecific, 2, *s.NumberOfForwarding)
+	}
+	v = appendInteger(v, ber.ContextSpecific, 3, s.InterrogationType)
+	if s.ORInterrogation {
+		v = appendNull(v, 4)
+	}
+	if s.ORCapability != nil {
+		v = appendInteger(v, ber.ContextSpecific, 5, *s.ORCapability)
+	}
+	if v, err = appendAddress(v, ber.ContextSpecific, 6, s.GMSCAddress); err != nil {
+		return nil, err
+	}
+	if s.CallReferenceNumber != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 7, s.CallReferenceNumber)
+	}
+	if s.SuppressionOfAnnouncement {
+		v = appendNull(v, 12)
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendRoutingInfoArg.
+func (s *SendRoutingInfoArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "SendRoutingInfoArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if s.MSISDN, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 2:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			s.NumberOfForwarding = &n
+		case 3:
+			if s.InterrogationType, err = param.DecodeInteger(e); err != nil {
+				return err
+			}
+		case 4:
+			s.ORInterrogation = true
+		case 5:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			s.ORCapability = &n
+		case 6:
+			if s.GMSCAddress, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 7:
+			s.CallReferenceNumber = e.Value
+		case 12:
+			s.SuppressionOfAnnouncement = true
+		}
+	}
+
+	if s.MSISDN == nil {
+		return protocol.Missing("msisdn")
+	}
+	return nil
+}
+
+// ForwardingData represents ForwardingData.
+type ForwardingData struct {
+	ForwardedToNumber *AddressString
+	ForwardingOptions []byte
+}
+
+func (f *ForwardingData) marshal() ([]byte, error) {
+	var v []byte
+	var err error
+	if f.ForwardedToNumber != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 5, f.ForwardedToNumber); err != nil {
+			return nil, err
+		}
+	}
+	if f.ForwardingOptions != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 6, f.ForwardingOptions)
+	}
+	return v, nil
+}
+
+func (f *ForwardingData) unmarshal(b []byte) error {
+	elems, err := ber.ParseElements(b)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 5):
+			if f.ForwardedToNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 6):
+			f.ForwardingOptions = e.Value
+		}
+	}
+	return nil
+}
+
+// SendRoutingInfoRes represents SendRoutingInfoRes.
+//
+// Either of RoamingNumber or ForwardingData is set as the routingInfo.
+type SendRoutingInfoRes struct {
+	IMSI           string
+	RoamingNumber  *AddressString
+	ForwardingData *ForwardingData
+	VMSCAddress    *AddressString
+	MSISDN         *AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from a SendRoutingInfoRes.
+func (s *SendRoutingInfoRes) MarshalBinary() ([]byte, error) {
+	var v []byte
+	var err error
+	if s.IMSI != "" {
+		if v, err = appendTBCD(v, ber.ContextSpecific, 9, s.IMSI); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case s.RoamingNumber != nil:
+		if v, err = appendAddress(v, ber.Universal, ber.TagOctetString, s.RoamingNumber); err != nil {
+			return nil, err
+		}
+	case s.ForwardingData != nil:
+		fd, err := s.ForwardingData.marshal()
+		if err != nil {
+			return nil, err
+		}
+		v = ber.Append(v, ber.Universal, true, ber.TagSequence, fd)
+	}
+	if s.VMSCAddress != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 2, s.VMSCAddress); err != nil {
+			return nil, err
+		}
+	}
+	if s.MSISDN != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 12, s.MSISDN); err != nil {
+			return nil, err
+		}
+	}
+
+	return param.Sequence(3, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendRoutingInfoRes.
+func (s *SendRoutingInfoRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "SendRoutingInfoRes")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 9):
+			s.IMSI = DecodeTBCD(e.Value)
+		case e.Is(ber.Universal, ber.TagOctetString):
+			if s.RoamingNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.Universal, ber.TagSequence):
+			s.ForwardingData = &ForwardingData{}
+			if err := s.ForwardingData.unmarshal(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 2):
+			if s.VMSCAddress, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 12):
+			if s.MSISDN, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RoutingInfoForSMArg represents RoutingInfoForSM-Arg.
+type RoutingInfoForSMArg struct {
+	MSISDN               *AddressString
+	SMRPPRI              bool
+	ServiceCentreAddress *AddressString
+	GPRSSupportIndicator bool
+	SMRPMTI              *int
+	IMSI                 string
+}
+
+// MarshalBinary returns the byte sequence generated from a RoutingInfoForSMArg.
+func (r *RoutingInfoForSMArg) MarshalBinary() ([]byte, error) {
+	if r.MSISDN == nil {
+		return nil, protocol.Missing("msisdn")
+	}
+	if r.ServiceCentreAddress == nil {
+		return nil, protocol.Missing("serviceCentreAddress")
+	}
+
+	v, err := appendAddress(nil, ber.ContextSpecific, 0, r.MSISDN)
+	if err != nil {
+		return nil, err
+	}
+	v = ber.Append(v, ber.ContextSpecific, false, 1, ber.EncodeBoolean(r.SMRPPRI))
+	if v, err = appendAddress(v, ber.ContextSpecific, 2, r.ServiceCentreAddress); err != nil {
+		return nil, err
+	}
+	if r.GPRSSupportIndicator {
+		v = appendNull(v, 7)
+	}
+	if r.SMRPMTI != nil {
+		v = appendInteger(v, ber.ContextSpecific, 8, *r.SMRPMTI)
+	}
+	if r.IMSI != "" {
+		if v, err = appendTBCD(v, ber.ContextSpecific, 12, r.IMSI); err != nil {
+			return nil, err
+		}
+	}
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RoutingInfoForSMArg.
+func (r *RoutingInfoForSMArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "RoutingInfoForSM-Arg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			if r.MSISDN, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 1:
+			if r.SMRPPRI, err = ber.DecodeBoolean(e.Value); err != nil {
+				return err
+			}
+		case 2:
+			if r.ServiceCentreAddress, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case 7:
+			r.GPRSSupportIndicator = true
+		case 8:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			r.SMRPMTI = &n
+		case 12:
+			r.IMSI = DecodeTBCD(e.Value)
+		}
+	}
+
+	if r.MSISDN == nil {
+		return protocol.Missing("msisdn")
+	}
+	return nil
+}
+
+// RoutingInfoForSMRes represents RoutingInfoForSM-Res.
+//
+// NetworkNodeNumber, LMSI, GPRSNodeIndicator and the additional numbers are
+// in the locationInfoWithLMSI.
+type RoutingInfoForSMRes struct {
+	IMSI              string
+	NetworkNodeNumber *AddressString
+	LMSI              []byte
+	GPRSNodeIndicator bool
+	AdditionalMSC     *AddressString
+	AdditionalSGSN    *AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from a RoutingInfoForSMRes.
+func (r *RoutingInfoForSMRes) MarshalBinary() ([]byte, error) {
+	if r.NetworkNodeNumber == nil {
+		return nil, protocol.Missing("networkNode-Number")
+	}
+
+	v, err := appendTBCD(nil, ber.Universal, ber.TagOctetString, r.IMSI)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := appendAddress(nil, ber.ContextSpecific, 1, r.NetworkNodeNumber)
+	if err != nil {
+		return nil, err
+	}
+	if r.LMSI != nil {
+		loc = ber.Append(loc, ber.Universal, false, ber.TagOctetString, r.LMSI)
+	}
+	if r.GPRSNodeIndicator {
+		loc = appendNull(loc, 5)
+	}
+	switch {
+	case r.AdditionalMSC != nil:
+		num, err := appendAddress(nil, ber.ContextSpecific, 0, r.AdditionalMSC)
+		if err != nil {
+			return nil, err
+		}
+		loc = ber.Append(loc, ber.ContextSpecific, true, 6, num)
+	case r.AdditionalSGSN != nil:
+		num, err := appendAddress(nil, ber.ContextSpecific, 1, r.AdditionalSGSN)
+		if err != nil {
+			return nil, err
+		}
+		loc = ber.Append(loc, ber.ContextSpecific, true, 6, num)
+	}
+	v = ber.Append(v, ber.ContextSpecific, true, 0, loc)
+
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RoutingInfoForSMRes.
+func (r *RoutingInfoForSMRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "RoutingInfoForSM-Res")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.Universal, ber.TagOctetString):
+			r.IMSI = DecodeTBCD(e.Value)
+		case e.Is(ber.ContextSpecific, 0):
+			locs, err := ber.ParseElements(e.Value)
+			if err != nil {
+				return err
+			}
+			for _, l := range locs {
+				switch {
+				case l.Is(ber.ContextSpecific, 1):
+					if r.NetworkNodeNumber, err = ParseAddressString(l.Value); err != nil {
+						return err
+					}
+				case l.Is(ber.Universal, ber.TagOctetString):
+					r.LMSI = l.Value
+				case l.Is(ber.ContextSpecific, 5):
+					r.GPRSNodeIndicator = true
+				case l.Is(ber.ContextSpecific, 6):
+					num, err := ber.ParseElement(l.Value)
+					if err != nil {
+						return err
+					}
+					a, err := ParseAddressString(num.Value)
+					if err != nil {
+						return err
+					}
+					if num.Tag == 0 {
+						r.AdditionalMSC = a
+					} else {
+						r.AdditionalSGSN = a
+					}
+				}
+			}
+		}
+	}
+
+	if r.NetworkNodeNumber == nil {
+		return protocol.Missing("networkNode-Number")
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// SMRPDA represents SM-RP-DA, the destination address of short message.
+//
+// Only one of the fields should be set. If none of them is set, noSM-RP-DA is used.
+type SMRPDA struct {
+	IMSI                 string
+	LMSI                 []byte
+	ServiceCentreAddress *AddressString
+}
+
+func (s *SMRPDA) appendTo(b []byte) ([]byte, error) {
+	switch {
+	case s.IMSI != "":
+		return appendTBCD(b, ber.ContextSpecific, 0, s.IMSI)
+	case s.LMSI != nil:
+		return ber.Append(b, ber.ContextSpecific, false, 1, s.LMSI), nil
+	case s.ServiceCentreAddress != nil:
+		return appendAddress(b, ber.ContextSpecific, 4, s.ServiceCentreAddress)
+	}
+	return appendNull(b, 5), nil
+}
+
+func (s *SMRPDA) unmarshal(e *ber.Element) error {
+	var err error
+	switch e.Tag {
+	case 0:
+		s.IMSI = DecodeTBCD(e.Value)
+	case 1:
+		s.LMSI = e.Value
+	case 4:
+		s.ServiceCentreAddress, err = ParseAddressString(e.Value)
+	}
+	return err
+}
+
+// SMRPOA represents SM-RP-OA, the originating address of short message.
+//
+// Only one of the fields should be set. If none of them is set, noSM-RP-OA is used.
+type SMRPOA struct {
+	MSISDN               *AddressString
+	ServiceCentreAddress *AddressString
+}
+
+func (s *SMRPOA) appendTo(b []byte) ([]byte, error) {
+	switch {
+	case s.MSISDN != nil:
+		return appendAddress(b, ber.ContextSpecific, 2, s.MSISDN)
+	case s.ServiceCentreAddress != nil:
+		return appendAddress(b, ber.ContextSpecific, 4, s.ServiceCentreAddress)
+	}
+	return appendNull(b, 5), nil
+}
+
+func (s *SMRPOA) unmarshal(e *ber.Element) error {
+	var err error
+	switch e.Tag {
+	case 2:
+		s.MSISDN, err = ParseAddressString(e.Value)
+	case 4:
+		s.ServiceCentreAddress, err = ParseAddressString(e.Value)
+	}
+	return err
+}
+
+// MOForwardSMArg represents MO-ForwardSM-Arg.
+//
+// SMRPUI is the TPDU of the short message as defined in 3GPP TS 23.040.
+type MOForwardSMArg struct {
+	SMRPDA SMRPDA
+	SMRPOA SMRPOA
+	SMRPUI []byte
+	IMSI   string
+}
+
+// MarshalBinary returns the byte sequence generated from a MOForwardSMArg.
+func (m *MOForwardSMArg) MarshalBinary() ([]byte, error) {
+	v, err := marshalForwardSM(&m.SMRPDA, &m.SMRPOA, m.SMRPUI)
+	if err != nil {
+		return nil, err
+	}
+	if m.IMSI != "" {
+		if v, err = appendTBCD(v, ber.Universal, ber.TagOctetString, m.IMSI); err != nil {
+			return nil, err
+		}
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a MOForwardSMArg.
+func (m *MOForwardSMArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "MO-ForwardSM-Arg")
+	if err != nil {
+		return err
+	}
+
+	rest, err := unmarshalForwardSM(elems, &m.SMRPDA, &m.SMRPOA, &m.SMRPUI)
+	if err != nil {
+		return err
+	}
+	for _, e := range rest {
+		if e.Is(ber.Universal, ber.TagOctetString) {
+			m.IMSI = DecodeTBCD(e.Value)
+		}
+	}
+	return nil
+}
+
+// MTForwardSMArg represents MT-ForwardSM-Arg.
+//
+// SMRPUI is the TPDU of the short message as defined in 3GPP TS 23.040.
+type MTForwardSMArg struct {
+	SMRPDA             SMRPDA
+	SMRPOA             SMRPOA
+	SMRPUI             []byte
+	MoreMessagesToSend bool
+}
+
+// MarshalBinary returns the byte sequence generated from a MTForwardSMArg.
+func (m *MTForwardSMArg) MarshalBinary() ([]byte, error) {
+	v, err := marshalForwardSM(&m.SMRPDA, &m.SMRPOA, m.SMRPUI)
+	if err != nil {
+		return nil, err
+	}
+	if m.MoreMessagesToSend {
+		v = ber.Append(v, ber.Universal, false, ber.TagNull, nil)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a MTForwardSMArg.
+func (m *MTForwardSMArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "MT-ForwardSM-Arg")
+	if err != nil {
+		return err
+	}
+
+	rest, err := unmarshalForwardSM(elems, &m.SMRPDA, &m.SMRPOA, &m.SMRPUI)
+	if err != nil {
+		return err
+	}
+	for _, e := range rest {
+		if e.Is(ber.Universal, ber.TagNull) {
+			m.MoreMessagesToSend = true
+		}
+	}
+	return nil
+}
+
+// ForwardSMRes represents MO-ForwardSM-Res and MT-ForwardSM-Res.
+type ForwardSMRes struct {
+	SMRPUI []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ForwardSMRes.
+func (f *ForwardSMRes) MarshalBinary() ([]byte, error) {
+	var v []byte
+	if f.SMRPUI != nil {
+		v = ber.Append(v, ber.Universal, false, ber.TagOctetString, f.SMRPUI)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ForwardSMRes.
+func (f *ForwardSMRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ForwardSM-Res")
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		if e.Is(ber.Universal, ber.TagOctetString) {
+			f.SMRPUI = e.Value
+		}
+	}
+	return nil
+}
+
+func marshalForwardSM(da *SMRPDA, oa *SMRPOA, ui []byte) ([]byte, error) {
+	if ui == nil {
+		return nil, protocol.Missing("sm-RP-UI")
+	}
+
+	v, err := da.appendTo(nil)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = oa.appendTo(v); err != nil {
+		return nil, err
+	}
+	return ber.Append(v, ber.Universal, false, ber.TagOctetString, ui), nil
+}
+
+// unmarshalForwardSM decodes the first three elements common in ForwardSM arguments,
+// and returns the rest of elements.
+func unmarshalForwardSM(elems []*ber.Element, da *SMRPDA, oa *SMRPOA, ui *[]byte) ([]*ber.Element, error) {
+	if len(elems) < 3 {
+		return nil, protocol.Missing("sm-RP-UI")
+	}
+	if err := da.unmarshal(elems[0]); err != nil {
+		return nil, err
+	}
+	if err := oa.unmarshal(elems[1]); err != nil {
+		return nil, err
+	}
+	*ui = elems[2].Value
+	return elems[3:], nil
+}
@@ -0,0 +1,435 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Subscriber State definitions.
+const (
+	AssumedIdle int = iota
+	CamelBusy
+	NetDetNotReachable
+	NotProvidedFromVLR
+)
+
+// Requested Domain definitions.
+const (
+	CSDomain int = iota
+	PSDomain
+)
+
+// RequestedInfo represents RequestedInfo.
+type RequestedInfo struct {
+	LocationInformation bool
+	SubscriberState     bool
+	CurrentLocation     bool
+	RequestedDomain     *int
+	MSClassmark         bool
+	IMEI                bool
+}
+
+func (r *RequestedInfo) marshal() []byte {
+	var v []byte
+	if r.LocationInformation {
+		v = appendNull(v, 0)
+	}
+	if r.SubscriberState {
+		v = appendNull(v, 1)
+	}
+	if r.CurrentLocation {
+		v = appendNull(v, 3)
+	}
+	if r.RequestedDomain != nil {
+		v = appendInteger(v, ber.ContextSpecific, 4, *r.RequestedDomain)
+	}
+	if r.MSClassmark {
+		v = appendNull(v, 5)
+	}
+	if r.IMEI {
+		v = appendNull(v, 6)
+	}
+	return v
+}
+
+func (r *RequestedInfo) unmarshal(b []byte) error {
+	elems, err := ber.ParseElements(b)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			r.LocationInformation = true
+		case 1:
+			r.SubscriberState = true
+		case 3:
+			r.CurrentLocation = true
+		case 4:
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			r.RequestedDomain = &n
+		case 5:
+			r.MSClassmark = true
+		case 6:
+			r.IMEI = true
+		}
+	}
+	return nil
+}
+
+// LocationInformation represents LocationInformation.
+//
+// Either of CellGlobalID or LAI is set as the cellGlobalIdOrServiceAreaIdOrLAI,
+// in the fixed length format.
+type LocationInformation struct {
+	AgeOfLocationInformation *int
+	GeographicalInformation  []byte
+	VLRNumber                *AddressString
+	LocationNumber           []byte
+	CellGlobalID             []byte
+	LAI                      []byte
+	MSCNumber                *AddressString
+	CurrentLocationRetrieved bool
+}
+
+func (l *LocationInformation) marshal() ([]byte, error) {
+	var v []byte
+	var err error
+	if l.AgeOfLocationInformation != nil {
+		v = appendInteger(v, ber.Universal, ber.TagInteger, *l.AgeOfLocationInformation)
+	}
+	if l.GeographicalInformation != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 0, l.GeographicalInformation)
+	}
+	if l.VLRNumber != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 1, l.VLRNumber); err != nil {
+			return nil, err
+		}
+	}
+	if l.LocationNumber != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 2, l.LocationNumber)
+	}
+	switch {
+	case l.CellGlobalID != nil:
+		v = ber.Append(v, ber.ContextSpecific, true, 3, ber.Append(nil, ber.ContextSpecific, false, 0, l.CellGlobalID))
+	case l.LAI != nil:
+		v = ber.Append(v, ber.ContextSpecific, true, 3, ber.Append(nil, ber.ContextSpecific, false, 1, l.LAI))
+	}
+	if l.MSCNumber != nil {
+		if v, err = appendAddress(v, ber.ContextSpecific, 6, l.MSCNumber); err != nil {
+			return nil, err
+		}
+	}
+	if l.CurrentLocationRetrieved {
+		v = appendNull(v, 8)
+	}
+	return v, nil
+}
+
+func (l *LocationInformation) unmarshal(b []byte) error {
+	elems, err := ber.ParseElements(b)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.Universal, ber.TagInteger):
+			n, err := param.DecodeInteger(e)
+			if err != nil {
+				return err
+			}
+			l.AgeOfLocationInformation = &n
+		case e.Is(ber.ContextSpecific, 0):
+			l.GeographicalInformation = e.Value
+		case e.Is(ber.ContextSpecific, 1):
+			if l.VLRNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 2):
+			l.LocationNumber = e.Value
+		case e.Is(ber.ContextSpecific, 3):
+			id, err := ber.ParseElement(e.Value)
+			if err != nil {
+				return err
+			}
+			if id.Tag == 0 {
+				l.CellGlobalID = id.Value
+			} else {
+				l.LAI = id.Value
+			}
+		case e.Is(ber.ContextSpecific, 6):
+			if l.MSCNumber, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 8):
+			l.CurrentLocationRetrieved = true
+		}
+	}
+	return nil
+}
+
+// SubscriberState represents SubscriberState.
+//
+// NotReachableReason is used only when the State is NetDetNotReachable.
+type SubscriberState struct {
+	State              int
+	NotReachableReason int
+}
+
+// SubscriberInfo represents SubscriberInfo.
+type SubscriberInfo struct {
+	LocationInformation *LocationInformation
+	SubscriberState     *SubscriberState
+	IMEI                string
+}
+
+func (s *SubscriberInfo) marshal() ([]byte, error) {
+	var v []byte
+	var err error
+	if s.LocationInformation != nil {
+		loc, err := s.LocationInformation.marshal()
+		if err != nil {
+			return nil, err
+		}
+		v = ber.Append(v, ber.ContextSpecific, true, 0, loc)
+	}
+	if st := s.SubscriberState; st != nil {
+		var state []byte
+		switch st.State {
+		case AssumedIdle:
+			state = appendNull(nil, 0)
+		case CamelBusy:
+			state = appendNull(nil, 1)
+		case NetDetNotReachable:
+			state = appendInteger(nil, ber.Universal, ber.TagEnumerated, st.NotReachableReason)
+		case NotProvidedFromVLR:
+			state = appendNull(nil, 2)
+		}
+		v = ber.Append(v, ber.ContextSpecific, true, 1, state)
+	}
+	if s.IMEI != "" {
+		if v, err = appendTBCD(v, ber.ContextSpecific, 5, s.IMEI); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (s *SubscriberInfo) unmarshal(b []byte) error {
+	elems, err := ber.ParseElements(b)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 0):
+			s.LocationInformation = &LocationInformation{}
+			if err := s.LocationInformation.unmarshal(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 1):
+			st, err := ber.ParseElement(e.Value)
+			if err != nil {
+				return err
+			}
+			s.SubscriberState = &SubscriberState{}
+			switch {
+			case st.Is(ber.ContextSpecific, 0):
+				s.SubscriberState.State = AssumedIdle
+			case st.Is(ber.ContextSpecific, 1):
+				s.SubscriberState.State = CamelBusy
+			case st.Is(ber.Universal, ber.TagEnumerated):
+				s.SubscriberState.State = NetDetNotReachable
+				if s.SubscriberState.NotReachableReason, err = param.DecodeInteger(st); err != nil {
+					return err
+				}
+			case st.Is(ber.ContextSpecific, 2):
+				s.SubscriberState.State = NotProvidedFromVLR
+			}
+		case e.Is(ber.ContextSpecific, 5):
+			s.IMEI = DecodeTBCD(e.Value)
+		}
+	}
+	return nil
+}
+
+// AnyTimeInterrogationArg represents AnyTimeInterrogationArg.
+//
+// Either of IMSI or MSISDN is set as the subscriberIdentity.
+type AnyTimeInterrogationArg struct {
+	IMSI          string
+	MSISDN        *AddressString
+	RequestedInfo RequestedInfo
+	GSMSCFAddress *AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from an AnyTimeInterrogationArg.
+func (a *AnyTimeInterrogationArg) MarshalBinary() ([]byte, error) {
+	if a.GSMSCFAddress == nil {
+		return nil, protocol.Missing("gsmSCF-Address")
+	}
+
+	var id []byte
+	var err error
+	switch {
+	case a.IMSI != "":
+		id, err = appendTBCD(nil, ber.ContextSpecific, 0, a.IMSI)
+	case a.MSISDN != nil:
+		id, err = appendAddress(nil, ber.ContextSpecific, 1, a.MSISDN)
+	default:
+		return nil, protocol.Missing("subscriberIdentity")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := ber.Append(nil, ber.ContextSpecific, true, 0, id)
+	v = ber.Append(v, ber.ContextSpecific, true, 1, a.RequestedInfo.marshal())
+	if v, err = appendAddress(v, ber.ContextSpecific, 3, a.GSMSCFAddress); err != nil {
+		return nil, err
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AnyTimeInterrogationArg.
+func (a *AnyTimeInterrogationArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "AnyTimeInterrogationArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 0):
+			id, err := ber.ParseElement(e.Value)
+			if err != nil {
+				return err
+			}
+			if id.Tag == 0 {
+				a.IMSI = DecodeTBCD(id.Value)
+			} else if a.MSISDN, err = ParseAddressString(id.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 1):
+			if err := a.RequestedInfo.unmarshal(e.Value); err != nil {
+				return err
+			}
+		case e.Is(ber.ContextSpecific, 3):
+			if a.GSMSCFAddress, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if a.IMSI == "" && a.MSISDN == nil {
+		return protocol.Missing("subscriberIdentity")
+	}
+	return nil
+}
+
+// AnyTimeInterrogationRes represents AnyTimeInterrogationRes.
+type AnyTimeInterrogationRes struct {
+	SubscriberInfo SubscriberInfo
+}
+
+// MarshalBinary returns the byte sequence generated from an AnyTimeInterrogationRes.
+func (a *AnyTimeInterrogationRes) MarshalBinary() ([]byte, error) {
+	info, err := a.SubscriberInfo.marshal()
+	if err != nil {
+		return nil, err
+	}
+	return param.Sequence(-1, ber.Append(nil, ber.Universal, true, ber.TagSequence, info)), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AnyTimeInterrogationRes.
+func (a *AnyTimeInterrogationRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSubscriberInfoRes(b, "AnyTimeInterrogationRes", &a.SubscriberInfo)
+}
+
+// ProvideSubscriberInfoArg represents ProvideSubscriberInfoArg.
+type ProvideSubscriberInfoArg struct {
+	IMSI          string
+	LMSI          []byte
+	RequestedInfo RequestedInfo
+}
+
+// MarshalBinary returns the byte sequence generated from a ProvideSubscriberInfoArg.
+func (p *ProvideSubscriberInfoArg) MarshalBinary() ([]byte, error) {
+	v, err := appendTBCD(nil, ber.ContextSpecific, 0, p.IMSI)
+	if err != nil {
+		return nil, err
+	}
+	if p.LMSI != nil {
+		v = ber.Append(v, ber.ContextSpecific, false, 1, p.LMSI)
+	}
+	v = ber.Append(v, ber.ContextSpecific, true, 2, p.RequestedInfo.marshal())
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ProvideSubscriberInfoArg.
+func (p *ProvideSubscriberInfoArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ProvideSubscriberInfoArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		switch {
+		case e.Is(ber.ContextSpecific, 0):
+			p.IMSI = DecodeTBCD(e.Value)
+		case e.Is(ber.ContextSpecific, 1):
+			p.LMSI = e.Value
+		case e.Is(ber.ContextSpecific, 2):
+			if err := p.RequestedInfo.unmarshal(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.IMSI == "" {
+		return protocol.Missing("imsi")
+	}
+	return nil
+}
+
+// ProvideSubscriberInfoRes represents ProvideSubscriberInfoRes.
+type ProvideSubscriberInfoRes struct {
+	SubscriberInfo SubscriberInfo
+}
+
+// MarshalBinary returns the byte sequence generated from a ProvideSubscriberInfoRes.
+func (p *ProvideSubscriberInfoRes) MarshalBinary() ([]byte, error) {
+	info, err := p.SubscriberInfo.marshal()
+	if err != nil {
+		return nil, err
+	}
+	return param.Sequence(-1, ber.Append(nil, ber.Universal, true, ber.TagSequence, info)), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ProvideSubscriberInfoRes.
+func (p *ProvideSubscriberInfoRes) UnmarshalBinary(b []byte) error {
+	return unmarshalSubscriberInfoRes(b, "ProvideSubscriberInfoRes", &p.SubscriberInfo)
+}
+
+func unmarshalSubscriberInfoRes(b []byte, name string, info *SubscriberInfo) error {
+	elems, err := protocol.ParseSequence(b, name)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		if e.Is(ber.Universal, ber.TagSequence) {
+			return info.unmarshal(e.Value)
+		}
+	}
+	return protocol.Missing("subscriberInfo")
+}
@@ -0,0 +1,152 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Nature of Address Indicator definitions.
+const (
+	NAIUnknown uint8 = iota
+	NAIInternationalNumber
+	NAINationalSignificantNumber
+	NAINetworkSpecificNumber
+	NAISubscriberNumber
+	_
+	NAIAbbreviatedNumber
+)
+
+// Numbering Plan Indicator definitions.
+const (
+	NPIUnknown          uint8 = 0
+	NPIISDNTelephony    uint8 = 1
+	NPIData             uint8 = 3
+	NPITelex            uint8 = 4
+	NPILandMobile       uint8 = 6
+	NPINational         uint8 = 8
+	NPIPrivate          uint8 = 9
+	NPIReservedForCAMEL uint8 = 15
+)
+
+// AddressString represents AddressString and ISDN-AddressString in MAP.
+type AddressString struct {
+	NatureOfAddress uint8
+	NumberingPlan   uint8
+	Digits          string
+}
+
+// NewAddressString creates a new AddressString of an international number in ISDN/Telephony numbering plan.
+func NewAddressString(digits string) *AddressString {
+	return &AddressString{
+		NatureOfAddress: NAIInternationalNumber,
+		NumberingPlan:   NPIISDNTelephony,
+		Digits:          digits,
+	}
+}
+
+// ParseAddressString parses given contents octets as an AddressString.
+func ParseAddressString(b []byte) (*AddressString, error) {
+	a := &AddressString{}
+	if err := a.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// MarshalBinary returns the contents octets of AddressString.
+func (a *AddressString) MarshalBinary() ([]byte, error) {
+	digits, err := EncodeTBCD(a.Digits)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{0x80 | (a.NatureOfAddress&0x07)<<4 | a.NumberingPlan&0x0f}, digits...), nil
+}
+
+// UnmarshalBinary sets the values retrieved from the contents octets of AddressString.
+func (a *AddressString) UnmarshalBinary(b []byte) error {
+	if len(b) < 1 {
+		return protocol.Invalid("AddressString")
+	}
+	a.NatureOfAddress = (b[0] >> 4) & 0x07
+	a.NumberingPlan = b[0] & 0x0f
+	a.Digits = DecodeTBCD(b[1:])
+	return nil
+}
+
+// String returns AddressString in human readable string.
+func (a *AddressString) String() string {
+	return fmt.Sprintf("{NatureOfAddress: %d, NumberingPlan: %d, Digits: %s}",
+		a.NatureOfAddress,
+		a.NumberingPlan,
+		a.Digits,
+	)
+}
+
+const tbcdDigits = "0123456789*#abc"
+
+// EncodeTBCD encodes the string of digits into TBCD-STRING.
+//
+// The digits can contain '0'-'9', '*', '#', 'a', 'b' and 'c'. If the number
+// of digits is odd, the last octet is filled with 0xf.
+func EncodeTBCD(s string) ([]byte, error) {
+	b := make([]byte, (len(s)+1)/2)
+	for i, r := range strings.ToLower(s) {
+		d := strings.IndexRune(tbcdDigits, r)
+		if d < 0 {
+			return nil, fmt.Errorf("gsmmap: invalid TBCD digit: %q", r)
+		}
+		if i%2 == 0 {
+			b[i/2] = uint8(d)
+		} else {
+			b[i/2] |= uint8(d) << 4
+		}
+	}
+	if len(s)%2 == 1 {
+		b[len(b)-1] |= 0xf0
+	}
+	return b, nil
+}
+
+// DecodeTBCD decodes the TBCD-STRING into the string of digits.
+func DecodeTBCD(b []byte) string {
+	var sb strings.Builder
+	for _, o := range b {
+		for _, d := range []uint8{o & 0x0f, o >> 4} {
+			if d >= 0x0f {
+				return sb.String()
+			}
+			sb.WriteByte(tbcdDigits[d])
+		}
+	}
+	return sb.String()
+}
+
+func appendTBCD(b []byte, class, tag int, digits string) ([]byte, error) {
+	v, err := EncodeTBCD(digits)
+	if err != nil {
+		return nil, err
+	}
+	return ber.Append(b, class, false, tag, v), nil
+}
+
+func appendAddress(b []byte, class, tag int, a *AddressString) ([]byte, error) {
+	v, err := a.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return ber.Append(b, class, false, tag, v), nil
+}
+
+func appendInteger(b []byte, class, tag int, v int) []byte {
+	return ber.Append(b, class, false, tag, ber.EncodeInteger(int64(v)))
+}
+
+func appendNull(b []byte, tag int) []byte {
+	return ber.Append(b, ber.ContextSpecific, false, tag, nil)
+}
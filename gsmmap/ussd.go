@@ -0,0 +1,94 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// USSDArg represents USSD-Arg used in processUnstructuredSS-Request.
+//
+// USSDString is encoded as indicated by DataCodingScheme (see 3GPP TS 23.038).
+type USSDArg struct {
+	DataCodingScheme uint8
+	USSDString       []byte
+	AlertingPattern  []byte
+	MSISDN           *AddressString
+}
+
+// MarshalBinary returns the byte sequence generated from a USSDArg.
+func (u *USSDArg) MarshalBinary() ([]byte, error) {
+	v := ber.Append(nil, ber.Universal, false, ber.TagOctetString, []byte{u.DataCodingScheme})
+	v = ber.Append(v, ber.Universal, false, ber.TagOctetString, u.USSDString)
+	if u.AlertingPattern != nil {
+		v = ber.Append(v, ber.Universal, false, ber.TagOctetString, u.AlertingPattern)
+	}
+	if u.MSISDN != nil {
+		var err error
+		if v, err = appendAddress(v, ber.ContextSpecific, 0, u.MSISDN); err != nil {
+			return nil, err
+		}
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a USSDArg.
+func (u *USSDArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "USSD-Arg")
+	if err != nil {
+		return err
+	}
+	if len(elems) < 2 {
+		return protocol.Missing("ussd-String")
+	}
+	if len(elems[0].Value) != 1 {
+		return protocol.Invalid("ussd-DataCodingScheme")
+	}
+	u.DataCodingScheme = elems[0].Value[0]
+	u.USSDString = elems[1].Value
+
+	for _, e := range elems[2:] {
+		switch {
+		case e.Is(ber.Universal, ber.TagOctetString):
+			u.AlertingPattern = e.Value
+		case e.Is(ber.ContextSpecific, 0):
+			if u.MSISDN, err = ParseAddressString(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// USSDRes represents USSD-Res.
+type USSDRes struct {
+	DataCodingScheme uint8
+	USSDString       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a USSDRes.
+func (u *USSDRes) MarshalBinary() ([]byte, error) {
+	v := ber.Append(nil, ber.Universal, false, ber.TagOctetString, []byte{u.DataCodingScheme})
+	v = ber.Append(v, ber.Universal, false, ber.TagOctetString, u.USSDString)
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a USSDRes.
+func (u *USSDRes) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "USSD-Res")
+	if err != nil {
+		return err
+	}
+	if len(elems) < 2 {
+		return protocol.Missing("ussd-String")
+	}
+	if len(elems[0].Value) != 1 {
+		return protocol.Invalid("ussd-DataCodingScheme")
+	}
+	u.DataCodingScheme = elems[0].Value[0]
+	u.USSDString = elems[1].Value
+	return nil
+}
@@ -7,6 +7,8 @@ package tcap
 import (
 	"fmt"
 	"io"
+
+	"github.com/wmnsk/go-tcap/ber"
 )
 
 // Tag is a Tag in TCAP IE
@@ -107,23 +109,23 @@ func NewIE(tag Tag, value []byte) *IE {
 
 // MarshalBinary returns the byte sequence generated from a IE instance.
 func (i *IE) MarshalBinary() ([]byte, error) {
-	b := make([]byte, i.MarshalLen())
-	if err := i.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return marshalBinary(i)
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (i *IE) MarshalTo(b []byte) error {
-	if len(b) < 2 {
-		return io.ErrUnexpectedEOF
-	}
+	return marshalTo(i, b)
+}
 
-	b[0] = uint8(i.Tag)
-	b[1] = i.Length
-	copy(b[2:i.MarshalLen()], i.Value)
-	return nil
+// AppendBinary appends the byte sequence generated from a IE instance to b.
+func (i *IE) AppendBinary(b []byte) ([]byte, error) {
+	return i.appendTo(b, MarshalOptions{})
+}
+
+func (i *IE) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	b, off := beginTLV(b, i.Tag)
+	b = append(b, i.Value...)
+	return endTLV(b, off, i.Tag, i.Length, o)
 }
 
 // ParseMultiIEs parses multiple (unspecified number of) IEs to []*IE at a time.
@@ -156,18 +158,64 @@ func ParseIE(b []byte) (*IE, error) {
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an IE.
 func (i *IE) UnmarshalBinary(b []byte) error {
+	_, err := i.unmarshal(b)
+	return err
+}
+
+// unmarshal sets the values of the IE at the beginning of b, and returns the
+// number of bytes consumed.
+func (i *IE) unmarshal(b []byte) (int, error) {
 	l := len(b)
-	if l < 3 {
-		return io.ErrUnexpectedEOF
+	n, offset, err := parseLength(b)
+	if err != nil {
+		return 0, err
 	}
-
 	i.Tag = Tag(b[0])
 	i.Length = b[1]
-	if l < 2+int(i.Length) {
-		return io.ErrUnexpectedEOF
+	if l < offset+n {
+		return 0, io.ErrUnexpectedEOF
 	}
-	i.Value = b[2 : 2+int(i.Length)]
-	return nil
+	i.Value = b[offset : offset+n]
+	return offset + n, nil
+}
+
+// parseIEAt parses the IE at offset in b and returns the offset after it.
+func parseIEAt(b []byte, offset int) (*IE, int, error) {
+	if offset > len(b) {
+		return nil, offset, io.ErrUnexpectedEOF
+	}
+	i := &IE{}
+	n, err := i.unmarshal(b[offset:])
+	if err != nil {
+		return nil, offset, err
+	}
+	return i, offset + n, nil
+}
+
+// parseLength returns the length of the contents of the TLV at the beginning
+// of b, in the short or long form, and the offset of the contents.
+func parseLength(b []byte) (int, int, error) {
+	if len(b) < 2 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	n := int(b[1])
+	if n <= 0x7f {
+		return n, 2, nil
+	}
+
+	// the indefinite form is not supported.
+	k := n & 0x7f
+	if k == 0 || k > 4 {
+		return 0, 0, &UnsupportedEncodingError{Tag: Tag(b[0]).Code(), Length: n}
+	}
+	if len(b) < 2+k {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	n = 0
+	for _, o := range b[2 : 2+k] {
+		n = n<<8 | int(o)
+	}
+	return n, 2 + k, nil
 }
 
 // ParseAsBer parses given byte sequence as multiple IEs.
@@ -196,8 +244,8 @@ func ParseAsBER(b []byte) ([]*IE, error) {
 			continue
 		}
 
-		if i.IE[0].MarshalLen() < i.MarshalLen()-2 {
-			var l = 2
+		if i.IE[0].MarshalLen() < len(i.Value) {
+			var l = i.MarshalLen() - len(i.Value)
 			for _, ie := range i.IE {
 				l += ie.MarshalLen()
 			}
@@ -227,10 +275,11 @@ func (i *IE) ParseRecursive(b []byte) error {
 
 	i.Tag = Tag(b[0])
 	i.Length = b[1]
-	if int(i.Length)+2 > len(b) {
+	n, offset, err := parseLength(b)
+	if err != nil || offset+n > len(b) {
 		return nil
 	}
-	i.Value = b[2 : 2+int(i.Length)]
+	i.Value = b[offset : offset+n]
 
 	if i.Tag.Form() == 1 {
 		x, err := ParseAsBER(i.Value)
@@ -245,12 +294,56 @@ func (i *IE) ParseRecursive(b []byte) error {
 
 // MarshalLen returns the serial length of IE.
 func (i *IE) MarshalLen() int {
-	return 2 + len(i.Value)
+	return tlvLen(len(i.Value))
 }
 
 // SetLength sets the length in Length field.
 func (i *IE) SetLength() {
-	i.Length = uint8(len(i.Value))
+	i.Length = lengthOctet(len(i.Value))
+}
+
+// NewIEFromValue creates a new IE from the BER value, keeping the class and tag of v.
+//
+// It returns error if v has the tag number larger than 30, as the Tag of IE
+// cannot represent it in one octet.
+func NewIEFromValue(v *ber.Value) (*IE, error) {
+	contents := v.ContentsBytes()
+	if v.Tag > 30 {
+		return nil, &UnsupportedEncodingError{Tag: v.Tag, Length: len(contents)}
+	}
+
+	i := &IE{
+		Tag:   NewTag(v.Class, form(v.Constructed), v.Tag),
+		Value: contents,
+	}
+	if v.Constructed {
+		ies, err := ParseAsBER(contents)
+		if err == nil {
+			i.IE = ies
+		}
+	}
+	i.SetLength()
+	return i, nil
+}
+
+// BERValue returns the IE as a BER value, parsing its Value recursively if the IE is constructed.
+func (i *IE) BERValue() (*ber.Value, error) {
+	if i.Tag.Form() == Primitive {
+		return ber.NewPrimitive(i.Tag.Class(), i.Tag.Code(), i.Value), nil
+	}
+
+	elems, err := ber.ParseValues(i.Value)
+	if err != nil {
+		return nil, err
+	}
+	return ber.NewConstructed(i.Tag.Class(), i.Tag.Code(), elems...), nil
+}
+
+func form(constructed bool) int {
+	if constructed {
+		return Constructor
+	}
+	return Primitive
 }
 
 // String returns IE in human readable string.
@@ -0,0 +1,118 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package inap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// FurnishChargingInformationArg represents FurnishChargingInformationArg.
+//
+// The contents of FCIBillingChargingCharacteristics are network specific and
+// kept as they are.
+type FurnishChargingInformationArg struct {
+	FCIBillingChargingCharacteristics []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a FurnishChargingInformationArg.
+func (f *FurnishChargingInformationArg) MarshalBinary() ([]byte, error) {
+	if len(f.FCIBillingChargingCharacteristics) == 0 {
+		return nil, protocol.Missing("FCIBillingChargingCharacteristics")
+	}
+	return param.OctetString(f.FCIBillingChargingCharacteristics), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a FurnishChargingInformationArg.
+func (f *FurnishChargingInformationArg) UnmarshalBinary(b []byte) error {
+	v, err := protocol.ParseOctetString(b, "FurnishChargingInformationArg")
+	if err != nil {
+		return err
+	}
+	f.FCIBillingChargingCharacteristics = v
+	return nil
+}
+
+// ApplyChargingArg represents ApplyChargingArg.
+//
+// The contents of AChBillingChargingCharacteristics are network specific and
+// kept as they are.
+type ApplyChargingArg struct {
+	AChBillingChargingCharacteristics []byte
+	SendCalculationToSCPIndication    bool
+	PartyToCharge                     *LegID
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingArg.
+func (a *ApplyChargingArg) MarshalBinary() ([]byte, error) {
+	if len(a.AChBillingChargingCharacteristics) == 0 {
+		return nil, protocol.Missing("aChBillingChargingCharacteristics")
+	}
+
+	v := appendOctets(nil, 0, a.AChBillingChargingCharacteristics)
+	if a.SendCalculationToSCPIndication {
+		v = appendBoolean(v, 1, true)
+	}
+	if a.PartyToCharge != nil {
+		v = append(v, param.Sequence(2, a.PartyToCharge.marshal())...)
+	}
+	return param.Sequence(-1, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingArg.
+func (a *ApplyChargingArg) UnmarshalBinary(b []byte) error {
+	elems, err := protocol.ParseSequence(b, "ApplyChargingArg")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if e.Class != ber.ContextSpecific {
+			continue
+		}
+		switch e.Tag {
+		case 0:
+			a.AChBillingChargingCharacteristics = e.Value
+		case 1:
+			if a.SendCalculationToSCPIndication, err = ber.DecodeBoolean(e.Value); err != nil {
+				return err
+			}
+		case 2:
+			if a.PartyToCharge, err = parseLegID(e.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(a.AChBillingChargingCharacteristics) == 0 {
+		return protocol.Missing("aChBillingChargingCharacteristics")
+	}
+	return nil
+}
+
+// ApplyChargingReportArg represents ApplyChargingReportArg.
+//
+// The contents of CallResult are network specific and kept as they are.
+type ApplyChargingReportArg struct {
+	CallResult []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) MarshalBinary() ([]byte, error) {
+	if len(a.CallResult) == 0 {
+		return nil, protocol.Missing("CallResult")
+	}
+	return param.OctetString(a.CallResult), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) UnmarshalBinary(b []byte) error {
+	v, err := protocol.ParseOctetString(b, "ApplyChargingReportArg")
+	if err != nil {
+		return err
+	}
+	a.CallResult = v
+	return nil
+}
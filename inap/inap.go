@@ -0,0 +1,213 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package inap provides the typed parameters of ITU-T INAP (Intelligent Network Application Protocol)
+CS-1 and CS-2 operations, which can be set into and retrieved from the Parameter of tcap.Component.
+
+The operation codes and error codes are untyped constants, so that they can be used
+with tcap.NewInvoke and tcap.NewReturnError as they are. The application contexts
+are registered to the tcap package on init; use tcap.NewAARQWithOID with the OIDs
+defined in this package to build the Dialogue Portion.
+*/
+package inap
+
+import (
+	"encoding/asn1"
+
+	"slices"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/internal/param"
+)
+
+// Operation Code definitions.
+const (
+	InitialDP                  = 0
+	Connect                    = 20
+	ReleaseCall                = 22
+	RequestReportBCSMEvent     = 23
+	EventReportBCSM            = 24
+	CollectInformation         = 27
+	Continue                   = 31
+	ResetTimer                 = 33
+	FurnishChargingInformation = 34
+	ApplyCharging              = 35
+	ApplyChargingReport        = 36
+	CallInformationReport      = 44
+	CallInformationRequest     = 45
+	ActivityTest               = 55
+)
+
+// Error Code definitions.
+const (
+	ErrCancelled                   = 0
+	ErrCancelFailed                = 1
+	ErrETCFailed                   = 3
+	ErrImproperCallerResponse      = 4
+	ErrMissingCustomerRecord       = 6
+	ErrMissingParameter            = 7
+	ErrParameterOutOfRange         = 8
+	ErrRequestedInfoError          = 10
+	ErrSystemFailure               = 11
+	ErrTaskRefused                 = 12
+	ErrUnavailableResource         = 13
+	ErrUnexpectedComponentSequence = 14
+	ErrUnexpectedDataValue         = 15
+	ErrUnexpectedParameter         = 16
+	ErrUnknownLegID                = 17
+	ErrUnknownResource             = 18
+)
+
+// Application Context definitions.
+var (
+	CS1SSPToSCP              = asn1.ObjectIdentifier{0, 4, 0, 1, 1, 0, 3, 0}
+	CS1AssistHandoffSSPToSCP = asn1.ObjectIdentifier{0, 4, 0, 1, 1, 0, 3, 1}
+	CS1IPToSCP               = asn1.ObjectIdentifier{0, 4, 0, 1, 1, 0, 3, 2}
+	CS1SCPToSSP              = asn1.ObjectIdentifier{0, 4, 0, 1, 1, 0, 3, 3}
+	CS2SSFToSCFGeneric       = asn1.ObjectIdentifier{0, 0, 17, 1228, 3, 4}
+	CS2SSFToSCFAssistHandoff = asn1.ObjectIdentifier{0, 0, 17, 1228, 3, 6}
+	CS2SCFToSSFGeneric       = asn1.ObjectIdentifier{0, 0, 17, 1228, 3, 8}
+	CS2SRFToSCF              = asn1.ObjectIdentifier{0, 0, 17, 1228, 3, 14}
+)
+
+// Parameter is the argument of INAP operation.
+//
+// MarshalBinary returns the whole TLV of the parameter, and UnmarshalBinary
+// accepts the same.
+type Parameter = param.Parameter
+
+var operations = map[uint8]param.Operation{
+	InitialDP:                  {Name: "initialDP", Arg: func() Parameter { return &InitialDPArg{} }},
+	Connect:                    {Name: "connect", Arg: func() Parameter { return &ConnectArg{} }},
+	ReleaseCall:                {Name: "releaseCall", Arg: func() Parameter { return &ReleaseCallArg{} }},
+	RequestReportBCSMEvent:     {Name: "requestReportBCSMEvent", Arg: func() Parameter { return &RequestReportBCSMEventArg{} }},
+	EventReportBCSM:            {Name: "eventReportBCSM", Arg: func() Parameter { return &EventReportBCSMArg{} }},
+	CollectInformation:         {Name: "collectInformation"},
+	Continue:                   {Name: "continue"},
+	ResetTimer:                 {Name: "resetTimer", Arg: func() Parameter { return &ResetTimerArg{} }},
+	FurnishChargingInformation: {Name: "furnishChargingInformation", Arg: func() Parameter { return &FurnishChargingInformationArg{} }},
+	ApplyCharging:              {Name: "applyCharging", Arg: func() Parameter { return &ApplyChargingArg{} }},
+	ApplyChargingReport:        {Name: "applyChargingReport", Arg: func() Parameter { return &ApplyChargingReportArg{} }},
+	CallInformationReport:      {Name: "callInformationReport"},
+	CallInformationRequest:     {Name: "callInformationRequest"},
+	ActivityTest:               {Name: "activityTest"},
+}
+
+var errorNames = map[uint8]string{
+	ErrCancelled:                   "cancelled",
+	ErrCancelFailed:                "cancelFailed",
+	ErrETCFailed:                   "eTCFailed",
+	ErrImproperCallerResponse:      "improperCallerResponse",
+	ErrMissingCustomerRecord:       "missingCustomerRecord",
+	ErrMissingParameter:            "missingParameter",
+	ErrParameterOutOfRange:         "parameterOutOfRange",
+	ErrRequestedInfoError:          "requestedInfoError",
+	ErrSystemFailure:               "systemFailure",
+	ErrTaskRefused:                 "taskRefused",
+	ErrUnavailableResource:         "unavailableResource",
+	ErrUnexpectedComponentSequence: "unexpectedComponentSequence",
+	ErrUnexpectedDataValue:         "unexpectedDataValue",
+	ErrUnexpectedParameter:         "unexpectedParameter",
+	ErrUnknownLegID:                "unknownLegID",
+	ErrUnknownResource:             "unknownResource",
+}
+
+type context struct {
+	name string
+	ops  []uint8
+}
+
+var (
+	ssfOps = []uint8{
+		InitialDP, Connect, ReleaseCall, RequestReportBCSMEvent, EventReportBCSM,
+		CollectInformation, Continue, ResetTimer, FurnishChargingInformation,
+		ApplyCharging, ApplyChargingReport, CallInformationReport, CallInformationRequest,
+		ActivityTest,
+	}
+	assistOps = []uint8{ResetTimer, ActivityTest}
+)
+
+var contexts = map[string]context{
+	CS1SSPToSCP.String():              {"Core-INAP-CS1-SSP-to-SCP-AC", ssfOps},
+	CS1AssistHandoffSSPToSCP.String(): {"Core-INAP-CS1-Assist-Handoff-SSP-to-SCP-AC", assistOps},
+	CS1IPToSCP.String():               {"Core-INAP-CS1-IP-to-SCP-AC", assistOps},
+	CS1SCPToSSP.String():              {"Core-INAP-CS1-SCP-to-SSP-AC", ssfOps},
+	CS2SSFToSCFGeneric.String():       {"ssf-scfGenericAC", ssfOps},
+	CS2SSFToSCFAssistHandoff.String(): {"ssf-scfAssistHandoffAC", assistOps},
+	CS2SCFToSSFGeneric.String():       {"scf-ssfGenericAC", ssfOps},
+	CS2SRFToSCF.String():              {"srf-scfAC", assistOps},
+}
+
+func init() {
+	for _, oid := range []asn1.ObjectIdentifier{
+		CS1SSPToSCP, CS1AssistHandoffSSPToSCP, CS1IPToSCP, CS1SCPToSSP,
+		CS2SSFToSCFGeneric, CS2SSFToSCFAssistHandoff, CS2SCFToSSFGeneric, CS2SRFToSCF,
+	} {
+		tcap.RegisterApplicationContext(oid, contexts[oid.String()].name)
+		Registry.RegisterContext(oid, contexts[oid.String()].ops...)
+	}
+	tcap.DefaultOperationRegistry.Include(Registry)
+}
+
+var table = &param.Table{
+	Protocol:   protocol,
+	Operations: operations,
+	Errors:     errorNames,
+}
+
+// Registry holds the INAP operations and application contexts supported by this
+// package, which is included in tcap.DefaultOperationRegistry on init.
+var Registry = table.Registry()
+
+// OperationName returns the name of operation in string.
+func OperationName(opCode uint8) string {
+	return table.OperationName(opCode)
+}
+
+// ErrorName returns the name of error in string.
+func ErrorName(errCode uint8) string {
+	return table.ErrorName(errCode)
+}
+
+// Operations returns the Operation Codes supported by this package in the application context.
+func Operations(acn asn1.ObjectIdentifier) []uint8 {
+	return contexts[acn.String()].ops
+}
+
+// NewArgument returns an empty argument of the operation.
+//
+// It returns nil without error for the operations that have no argument or whose
+// argument is not supported by this package.
+func NewArgument(opCode uint8) (Parameter, error) {
+	return table.NewArgument(opCode)
+}
+
+// MarshalParameter returns the Parameter as an IE to be set in tcap.Component.
+func MarshalParameter(p Parameter) (*tcap.IE, error) {
+	return param.Marshal(p)
+}
+
+// UnmarshalParameter sets the values retrieved from the IE in tcap.Component in p.
+func UnmarshalParameter(ie *tcap.IE, p Parameter) error {
+	return table.Unmarshal(ie, p)
+}
+
+// NewInvoke returns a new Invoke Component with the argument of the operation.
+//
+// arg can be nil for the operations without argument.
+func NewInvoke(invID int, opCode uint8, arg Parameter) (*tcap.Component, error) {
+	return param.NewInvoke(invID, opCode, arg)
+}
+
+// ParseArgument parses the Parameter of Invoke Component as the argument of
+// the operation in the application context given.
+//
+// It returns nil without error for the operations that have no argument.
+func ParseArgument(acn asn1.ObjectIdentifier, c *tcap.Component) (Parameter, error) {
+	if !slices.Contains(Operations(acn), c.OpCode()) {
+		return nil, protocol.Unsupported(acn.String(), c.OpCode())
+	}
+	return table.ParseArgument(c)
+}
@@ -0,0 +1,175 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package inap_test
+
+import (
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/inap"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+var testcases = []struct {
+	description string
+	opCode      uint8
+	arg         inap.Parameter
+}{
+	{
+		description: "initialDP",
+		opCode:      inap.InitialDP,
+		arg: &inap.InitialDPArg{
+			ServiceKey:            10,
+			CalledPartyNumber:     []byte{0x83, 0x90, 0x21, 0x43, 0x65},
+			CallingPartyNumber:    []byte{0x03, 0x13, 0x21, 0x43, 0x65},
+			CallingPartysCategory: []byte{0x0a},
+			TriggerType:           intPtr(1),
+			BearerCapability:      []byte{0x80, 0x90, 0xa3},
+			EventTypeBCSM:         intPtr(inap.AnalysedInformation),
+		},
+	}, {
+		description: "connect",
+		opCode:      inap.Connect,
+		arg: &inap.ConnectArg{
+			DestinationRoutingAddress: [][]byte{{0x83, 0x10, 0x21, 0x43}},
+			CorrelationID:             []byte{0x12, 0x34},
+			ScfID:                     []byte{0x01},
+		},
+	}, {
+		description: "releaseCall",
+		opCode:      inap.ReleaseCall,
+		arg:         inap.NewReleaseCallArg(31),
+	}, {
+		description: "requestReportBCSMEvent",
+		opCode:      inap.RequestReportBCSMEvent,
+		arg: &inap.RequestReportBCSMEventArg{
+			BCSMEvents: []*inap.BCSMEvent{
+				{EventTypeBCSM: inap.OAnswer, MonitorMode: inap.MonitorModeNotifyAndContinue, LegID: inap.NewSendingSideID(inap.LegType2)},
+				{EventTypeBCSM: inap.CollectedInfo, MonitorMode: inap.MonitorModeInterrupted, NumberOfDigits: intPtr(4)},
+			},
+			BCSMEventCorrelationID: []byte{0x01},
+		},
+	}, {
+		description: "eventReportBCSM",
+		opCode:      inap.EventReportBCSM,
+		arg: &inap.EventReportBCSMArg{
+			EventTypeBCSM: inap.OAnswer,
+			LegID:         inap.NewReceivingSideID(inap.LegType2),
+			MiscCallInfo:  []byte{0x80, 0x01, 0x01},
+		},
+	}, {
+		description: "resetTimer",
+		opCode:      inap.ResetTimer,
+		arg:         &inap.ResetTimerArg{TimerValue: 120},
+	}, {
+		description: "furnishChargingInformation",
+		opCode:      inap.FurnishChargingInformation,
+		arg:         &inap.FurnishChargingInformationArg{FCIBillingChargingCharacteristics: []byte{0xa0, 0x03, 0x80, 0x01, 0x01}},
+	}, {
+		description: "applyCharging",
+		opCode:      inap.ApplyCharging,
+		arg: &inap.ApplyChargingArg{
+			AChBillingChargingCharacteristics: []byte{0xa0, 0x04, 0x80, 0x02, 0x02, 0x58},
+			SendCalculationToSCPIndication:    true,
+			PartyToCharge:                     inap.NewSendingSideID(inap.LegType1),
+		},
+	}, {
+		description: "applyChargingReport",
+		opCode:      inap.ApplyChargingReport,
+		arg:         &inap.ApplyChargingReportArg{CallResult: []byte{0xa0, 0x03, 0x80, 0x01, 0x3c}},
+	},
+}
+
+func TestParameters(t *testing.T) {
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			ie, err := inap.MarshalParameter(c.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := inap.NewArgument(c.opCode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := inap.UnmarshalParameter(ie, got); err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", got, c.arg)
+		})
+	}
+}
+
+func TestParseArgument(t *testing.T) {
+	c, err := inap.NewInvoke(1, inap.Connect, &inap.ConnectArg{
+		DestinationRoutingAddress: [][]byte{{0x83, 0x10, 0x21, 0x43}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	comps, err := tcap.ParseComponents(append([]byte{0x6c, uint8(len(b))}, b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := inap.ParseArgument(inap.CS1SCPToSSP, comps.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", arg, &inap.ConnectArg{DestinationRoutingAddress: [][]byte{{0x83, 0x10, 0x21, 0x43}}})
+
+	if _, err := inap.ParseArgument(inap.CS2SRFToSCF, comps.Component[0]); err == nil {
+		t.Error("expected error for unsupported operation in the context")
+	}
+}
+
+func TestReturnError(t *testing.T) {
+	c := tcap.NewReturnError(1, inap.ErrMissingParameter, true, nil)
+	verify.Values(t, "", inap.ErrorName(c.ErrorCode.Value[0]), "missingParameter")
+}
+
+func TestApplicationContext(t *testing.T) {
+	d := tcap.NewDialogue(1, 1, tcap.NewAARQWithOID(1, inap.CS1SSPToSCP), nil)
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := tcap.ParseDialogue(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", parsed.Context(), "Core-INAP-CS1-SSP-to-SCP-AC")
+}
+
+func TestLongParameter(t *testing.T) {
+	arg := &inap.InitialDPArg{ServiceKey: 100, CalledPartyNumber: make([]byte, 200)}
+	c, err := inap.NewInvoke(1, inap.InitialDP, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := (&tcap.TCAP{Transaction: tcap.NewBegin(0x11111111, nil), Components: tcap.NewComponents(c)}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := inap.ParseArgument(inap.CS1SSPToSCP, msg.Components.Component[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
@@ -0,0 +1,93 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package inap
+
+import (
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Leg Type definitions.
+const (
+	LegType1 uint8 = 1
+	LegType2 uint8 = 2
+)
+
+// Monitor Mode definitions.
+const (
+	MonitorModeInterrupted int = iota
+	MonitorModeNotifyAndContinue
+	MonitorModeTransparent
+)
+
+// Event Type BCSM definitions.
+const (
+	OrigAttemptAuthorized int = 1
+	CollectedInfo         int = 2
+	AnalysedInformation   int = 3
+	RouteSelectFailure    int = 4
+	OCalledPartyBusy      int = 5
+	ONoAnswer             int = 6
+	OAnswer               int = 7
+	OMidCall              int = 8
+	ODisconnect           int = 9
+	OAbandon              int = 10
+	TermAttemptAuthorized int = 12
+	TBusy                 int = 13
+	TNoAnswer             int = 14
+	TAnswer               int = 15
+	TMidCall              int = 16
+	TDisconnect           int = 17
+	TAbandon              int = 18
+)
+
+// LegID represents LegID, which is either sendingSideID or receivingSideID.
+type LegID struct {
+	Receiving bool
+	LegType   uint8
+}
+
+// NewSendingSideID creates a new LegID of sendingSideID.
+func NewSendingSideID(legType uint8) *LegID {
+	return &LegID{LegType: legType}
+}
+
+// NewReceivingSideID creates a new LegID of receivingSideID.
+func NewReceivingSideID(legType uint8) *LegID {
+	return &LegID{Receiving: true, LegType: legType}
+}
+
+func (l *LegID) marshal() []byte {
+	tag := 0
+	if l.Receiving {
+		tag = 1
+	}
+	return ber.Append(nil, ber.ContextSpecific, false, tag, []byte{l.LegType})
+}
+
+func parseLegID(b []byte) (*LegID, error) {
+	e, err := ber.ParseElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if e.Class != ber.ContextSpecific || e.Tag > 1 || len(e.Value) != 1 {
+		return nil, protocol.Invalid("LegID")
+	}
+	return &LegID{Receiving: e.Tag == 1, LegType: e.Value[0]}, nil
+}
+
+func appendInteger(b []byte, tag int, v int) []byte {
+	return ber.Append(b, ber.ContextSpecific, false, tag, ber.EncodeInteger(int64(v)))
+}
+
+func appendOctets(b []byte, tag int, v []byte) []byte {
+	if v == nil {
+		return b
+	}
+	return ber.Append(b, ber.ContextSpecific, false, tag, v)
+}
+
+func appendBoolean(b []byte, tag int, v bool) []byte {
+	return ber.Append(b, ber.ContextSpecific, false, tag, ber.EncodeBoolean(v))
+}
@@ -0,0 +1,64 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+import "github.com/wmnsk/go-tcap/ber"
+
+// Sequence returns the SEQUENCE of v, or the constructed element with the
+// context-specific tag if tag is not negative.
+func Sequence(tag int, v []byte) []byte {
+	if tag < 0 {
+		return ber.Append(nil, ber.Universal, true, ber.TagSequence, v)
+	}
+	return ber.Append(nil, ber.ContextSpecific, true, tag, v)
+}
+
+// OctetString returns the OCTET STRING of v.
+func OctetString(v []byte) []byte {
+	return ber.Append(nil, ber.Universal, false, ber.TagOctetString, v)
+}
+
+// ParseSequence parses b as a constructed element and returns its children.
+//
+// The tag of the outermost element is not checked, as it differs between
+// the versions of the protocol.
+func (p Protocol) ParseSequence(b []byte, name string) ([]*ber.Element, error) {
+	e, err := ber.ParseElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Constructed {
+		return nil, p.Invalid(name)
+	}
+	return ber.ParseElements(e.Value)
+}
+
+// ParseOctetString parses b as a primitive element and returns its contents.
+func (p Protocol) ParseOctetString(b []byte, name string) ([]byte, error) {
+	e, err := ber.ParseElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if e.Constructed {
+		return nil, p.Invalid(name)
+	}
+	return e.Value, nil
+}
+
+// DecodeInteger decodes the contents of the INTEGER element.
+func DecodeInteger(e *ber.Element) (int, error) {
+	v, err := ber.DecodeInteger(e.Value)
+	return int(v), err
+}
+
+// DecodeIntegerPtr is the same as DecodeInteger, but returns the pointer to
+// the value to be set in an OPTIONAL field.
+func DecodeIntegerPtr(e *ber.Element) (*int, error) {
+	v, err := DecodeInteger(e)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,65 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+import "fmt"
+
+// Protocol is the name of the package of a user protocol, e.g., "gsmmap",
+// which is the prefix of the error messages.
+type Protocol string
+
+// Invalid returns *InvalidParameterError of the parameter.
+func (p Protocol) Invalid(name string) error {
+	return &InvalidParameterError{Protocol: string(p), Name: name}
+}
+
+// Missing returns *MissingParameterError of the parameter.
+func (p Protocol) Missing(name string) error {
+	return &MissingParameterError{Protocol: string(p), Name: name}
+}
+
+// Unsupported returns *UnsupportedOperationError of the operation in the
+// application context, which is empty if not applicable.
+func (p Protocol) Unsupported(ctx string, opCode uint8) error {
+	return &UnsupportedOperationError{Protocol: string(p), Context: ctx, OpCode: opCode}
+}
+
+// InvalidParameterError indicates that the parameter is malformed.
+type InvalidParameterError struct {
+	Protocol string
+	Name     string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidParameterError) Error() string {
+	return fmt.Sprintf("%s: invalid parameter: %s", e.Protocol, e.Name)
+}
+
+// MissingParameterError indicates that a mandatory parameter is missing.
+type MissingParameterError struct {
+	Protocol string
+	Name     string
+}
+
+// Error returns error message with violating content.
+func (e *MissingParameterError) Error() string {
+	return fmt.Sprintf("%s: missing mandatory parameter: %s", e.Protocol, e.Name)
+}
+
+// UnsupportedOperationError indicates that the operation is not supported, in
+// the application context if Context is not empty.
+type UnsupportedOperationError struct {
+	Protocol string
+	Context  string
+	OpCode   uint8
+}
+
+// Error returns error message with violating content.
+func (e *UnsupportedOperationError) Error() string {
+	if e.Context == "" {
+		return fmt.Sprintf("%s: unsupported operation %d", e.Protocol, e.OpCode)
+	}
+	return fmt.Sprintf("%s: unsupported operation %d in context %s", e.Protocol, e.OpCode, e.Context)
+}
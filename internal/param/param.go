@@ -0,0 +1,196 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package param provides the helpers shared by the packages of the typed
+// parameters of user protocols, such as gsmmap and camel, which keep only the
+// tables of their operations and errors.
+package param
+
+import (
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Parameter is the argument or result of an operation.
+type Parameter interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(b []byte) error
+}
+
+// Operation is an entry of Table. Arg and Res return an empty argument and
+// result, and are nil if the operation has none or it is not supported.
+type Operation struct {
+	Name string
+	Arg  func() Parameter
+	Res  func() Parameter
+}
+
+// Table holds the operations and errors of a user protocol.
+//
+// Protocol is the prefix of the errors returned. Contents is true if the
+// Parameter is the contents of the Parameter Set as in ANSI TCAP, rather than
+// the whole TLV.
+type Table struct {
+	Protocol   Protocol
+	Operations map[uint8]Operation
+	Errors     map[uint8]string
+	Contents   bool
+}
+
+// Registry returns a new tcap.OperationRegistry with the operations and errors
+// in the Table. The application contexts are to be registered by the package.
+func (t *Table) Registry() *tcap.OperationRegistry {
+	r := tcap.NewOperationRegistry()
+	for code, op := range t.Operations {
+		r.RegisterOperation(&tcap.Operation{
+			Code:     code,
+			Name:     op.Name,
+			Argument: t.decoder(op.Arg),
+			Result:   t.decoder(op.Res),
+		})
+	}
+	for code, name := range t.Errors {
+		r.RegisterError(&tcap.ErrorDefinition{Code: code, Name: name})
+	}
+	return r
+}
+
+// decoder returns the tcap.ParameterDecoder that decodes the Parameter into the
+// one returned by newParam, or nil if newParam is nil.
+func (t *Table) decoder(newParam func() Parameter) tcap.ParameterDecoder {
+	if newParam == nil {
+		return nil
+	}
+	return func(v *ber.Value) (interface{}, error) {
+		b := v.ContentsBytes()
+		if !t.Contents {
+			b, _ = v.MarshalBinary()
+		}
+		p := newParam()
+		if err := p.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// OperationName returns the name of operation, or empty string if unknown.
+func (t *Table) OperationName(opCode uint8) string {
+	return t.Operations[opCode].Name
+}
+
+// ErrorName returns the name of error, or empty string if unknown.
+func (t *Table) ErrorName(errCode uint8) string {
+	return t.Errors[errCode]
+}
+
+// NewArgument returns an empty argument of the operation, or nil without error
+// if the operation has no argument.
+func (t *Table) NewArgument(opCode uint8) (Parameter, error) {
+	op, ok := t.Operations[opCode]
+	if !ok {
+		return nil, t.Protocol.Unsupported("", opCode)
+	}
+	if op.Arg == nil {
+		return nil, nil
+	}
+	return op.Arg(), nil
+}
+
+// NewResult returns an empty result of the operation, or nil without error if
+// the operation has no result.
+func (t *Table) NewResult(opCode uint8) (Parameter, error) {
+	op, ok := t.Operations[opCode]
+	if !ok {
+		return nil, t.Protocol.Unsupported("", opCode)
+	}
+	if op.Res == nil {
+		return nil, nil
+	}
+	return op.Res(), nil
+}
+
+// Marshal returns the Parameter as an IE to be set in tcap.Component.
+//
+// MarshalBinary of p should return the whole TLV of the parameter.
+func Marshal(p Parameter) (*tcap.IE, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return tcap.ParseIERecursive(b)
+}
+
+// Unmarshal sets the values retrieved from the IE in tcap.Component in p.
+func (t *Table) Unmarshal(ie *tcap.IE, p Parameter) error {
+	if ie == nil {
+		return t.Protocol.Missing("Parameter")
+	}
+	return p.UnmarshalBinary(ber.Append(nil, ie.Tag.Class(), ie.Tag.Form() == tcap.Constructor, ie.Tag.Code(), ie.Value))
+}
+
+// Set sets the Parameter in the Component and updates its length.
+func Set(c *tcap.Component, p Parameter) error {
+	ie, err := Marshal(p)
+	if err != nil {
+		return err
+	}
+	c.Parameter = ie
+	c.SetLength()
+	return nil
+}
+
+// NewInvoke returns a new Invoke Component with the argument, which can be nil.
+func NewInvoke(invID int, opCode uint8, arg Parameter) (*tcap.Component, error) {
+	c := tcap.NewInvoke(invID, -1, int(opCode), true, nil)
+	if arg == nil {
+		return c, nil
+	}
+	if err := Set(c, arg); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewReturnResult returns a new ReturnResultLast Component with the result,
+// which can be nil.
+func NewReturnResult(invID int, opCode uint8, res Parameter) (*tcap.Component, error) {
+	c := tcap.NewReturnResult(invID, int(opCode), true, true, nil)
+	if res == nil {
+		return c, nil
+	}
+	if err := Set(c, res); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseArgument parses the Parameter of Invoke Component as the argument of
+// the operation. It returns nil without error if the operation has no argument.
+func (t *Table) ParseArgument(c *tcap.Component) (Parameter, error) {
+	p, err := t.NewArgument(c.OpCode())
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if err := t.Unmarshal(c.Parameter, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseResult parses the Parameter of ReturnResult Component as the result of
+// the operation. An empty result is returned if the Component has no Parameter.
+func (t *Table) ParseResult(opCode uint8, c *tcap.Component) (Parameter, error) {
+	p, err := t.NewResult(opCode)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if c.Parameter == nil {
+		return p, nil
+	}
+	if err := t.Unmarshal(c.Parameter, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
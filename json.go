@@ -0,0 +1,671 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// The JSON representation of TCAP messages is a semantic one rather than a dump
+// of the structs, e.g.,
+//
+//	{
+//	  "transaction": {"type": "begin", "otid": "11111111"},
+//	  "dialogue": {
+//	    "oid": "0.0.17.773.1.1.1",
+//	    "pdu": {
+//	      "type": "AARQ",
+//	      "protocolVersion": 1,
+//	      "applicationContext": {"oid": "0.4.0.0.1.0.2.3", "name": "locationCancellationContext"}
+//	    }
+//	  },
+//	  "components": [{
+//	    "type": "invoke",
+//	    "invokeID": 0,
+//	    "opCode": {"local": 3, "name": "cancelLocation"},
+//	    "parameter": {"class": "universal", "tag": 16, "constructed": true, "elements": [...]}
+//	  }]
+//	}
+//
+// The names are informational only; the codes and OIDs are used when the message
+// is rebuilt from JSON. The lengths are computed on UnmarshalJSON.
+
+type transactionJSON struct {
+	Type        string     `json:"type"`
+	OTID        string     `json:"otid,omitempty"`
+	DTID        string     `json:"dtid,omitempty"`
+	PAbortCause *causeJSON `json:"pAbortCause,omitempty"`
+	Payload     string     `json:"payload,omitempty"`
+}
+
+type codeJSON struct {
+	Local  *int64 `json:"local,omitempty"`
+	Global string `json:"global,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type causeJSON struct {
+	Code int    `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+type dialogueJSON struct {
+	OID     string       `json:"oid"`
+	PDU     *DialoguePDU `json:"pdu,omitempty"`
+	Payload string       `json:"payload,omitempty"`
+}
+
+type dialoguePDUJSON struct {
+	Type                   string          `json:"type"`
+	ProtocolVersion        *int            `json:"protocolVersion,omitempty"`
+	ApplicationContext     *contextJSON    `json:"applicationContext,omitempty"`
+	Result                 *int64          `json:"result,omitempty"`
+	ResultSourceDiagnostic *diagnosticJSON `json:"resultSourceDiagnostic,omitempty"`
+	AbortSource            *int            `json:"abortSource,omitempty"`
+	UserInformation        *IE             `json:"userInformation,omitempty"`
+}
+
+type contextJSON struct {
+	OID  string `json:"oid"`
+	Name string `json:"name,omitempty"`
+}
+
+type diagnosticJSON struct {
+	Source string `json:"source"`
+	Reason int64  `json:"reason"`
+}
+
+type componentJSON struct {
+	Type      string       `json:"type"`
+	InvokeID  *int64       `json:"invokeID,omitempty"`
+	LinkedID  *int64       `json:"linkedID,omitempty"`
+	OpCode    *codeJSON    `json:"opCode,omitempty"`
+	ErrorCode *codeJSON    `json:"errorCode,omitempty"`
+	Problem   *problemJSON `json:"problem,omitempty"`
+	Parameter *IE          `json:"parameter,omitempty"`
+}
+
+type problemJSON struct {
+	Type string `json:"type"`
+	Code int    `json:"code"`
+}
+
+type tcapJSON struct {
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Dialogue    *Dialogue    `json:"dialogue,omitempty"`
+	Components  *Components  `json:"components,omitempty"`
+}
+
+var (
+	messageTypeNames = map[int]string{
+		Unidirectional: "unidirectional",
+		Begin:          "begin",
+		End:            "end",
+		Continue:       "continue",
+		Abort:          "abort",
+	}
+	componentTypeNames = map[int]string{
+		Invoke:              "invoke",
+		ReturnResultLast:    "returnResultLast",
+		ReturnError:         "returnError",
+		Reject:              "reject",
+		ReturnResultNotLast: "returnResultNotLast",
+	}
+	dialogueTypeNames = map[int]string{
+		AARQ: "AARQ",
+		AARE: "AARE",
+		ABRT: "ABRT",
+	}
+	problemTypeNames = map[int]string{
+		GeneralProblem:      "general",
+		InvokeProblem:       "invoke",
+		ReturnResultProblem: "returnResult",
+		ReturnErrorProblem:  "returnError",
+	}
+	diagnosticSourceNames = map[int]string{
+		DialogueServiceUser:     "user",
+		DialogueServiceProvider: "provider",
+	}
+)
+
+// MessageTypeName returns the name of Message Type used in JSON, e.g. "begin",
+// or empty if the code is unknown.
+func MessageTypeName(code int) string {
+	return messageTypeNames[code]
+}
+
+// MessageTypeCode returns the Message Type named by MessageTypeName.
+func MessageTypeCode(name string) (int, bool) {
+	return lookupName(messageTypeNames, name)
+}
+
+// ComponentTypeName returns the name of Component Type used in JSON, e.g.
+// "invoke", or empty if the code is unknown.
+func ComponentTypeName(code int) string {
+	return componentTypeNames[code]
+}
+
+// ComponentTypeCode returns the Component Type named by ComponentTypeName.
+func ComponentTypeCode(name string) (int, bool) {
+	return lookupName(componentTypeNames, name)
+}
+
+// DialogueTypeName returns the name of Dialogue PDU Type used in JSON, e.g.
+// "AARQ", or empty if the code is unknown.
+func DialogueTypeName(code int) string {
+	return dialogueTypeNames[code]
+}
+
+// DialogueTypeCode returns the Dialogue PDU Type named by DialogueTypeName.
+func DialogueTypeCode(name string) (int, bool) {
+	return lookupName(dialogueTypeNames, name)
+}
+
+// ProblemTypeName returns the name of the tag of Problem Code used in JSON,
+// e.g. "general", or empty if the code is unknown.
+func ProblemTypeName(code int) string {
+	return problemTypeNames[code]
+}
+
+// ProblemTypeCode returns the tag of Problem Code named by ProblemTypeName.
+func ProblemTypeCode(name string) (int, bool) {
+	return lookupName(problemTypeNames, name)
+}
+
+// DiagnosticSourceName returns the name of the source of Result Source
+// Diagnostic used in JSON, "user" or "provider", or empty if the code is unknown.
+func DiagnosticSourceName(code int) string {
+	return diagnosticSourceNames[code]
+}
+
+// DiagnosticSourceCode returns the source named by DiagnosticSourceName.
+func DiagnosticSourceCode(name string) (int, bool) {
+	return lookupName(diagnosticSourceNames, name)
+}
+
+// MarshalJSON returns the JSON representation of TCAP.
+//
+// The Payload of Transaction and Dialogue are omitted, as they are represented
+// by Dialogue and Components.
+func (t *TCAP) MarshalJSON() ([]byte, error) {
+	j := &tcapJSON{Components: t.Components}
+	if t.Transaction != nil {
+		tx := *t.Transaction
+		tx.Payload = nil
+		j.Transaction = &tx
+	}
+	if t.Dialogue != nil {
+		d := *t.Dialogue
+		d.Payload = nil
+		j.Dialogue = &d
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in TCAP.
+func (t *TCAP) UnmarshalJSON(b []byte) error {
+	j := &tcapJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	if j.Transaction == nil {
+		return &InvalidJSONError{Field: "transaction", Value: "missing"}
+	}
+
+	j.Transaction.Payload = []byte{}
+	if j.Dialogue != nil {
+		j.Dialogue.Payload = []byte{}
+	}
+	*t = TCAP{Transaction: j.Transaction, Dialogue: j.Dialogue, Components: j.Components}
+	t.SetLength()
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of Transaction.
+func (t *Transaction) MarshalJSON() ([]byte, error) {
+	j := &transactionJSON{
+		Type:    messageTypeNames[t.Type.Code()],
+		OTID:    t.OTID(),
+		DTID:    t.DTID(),
+		Payload: hex.EncodeToString(t.Payload),
+	}
+	if j.Type == "" {
+		return nil, &InvalidCodeError{Code: t.Type.Code()}
+	}
+	if t.Type.Code() == Abort && t.PAbortCause != nil && len(t.PAbortCause.Value) > 0 {
+		j.PAbortCause = &causeJSON{Code: int(t.PAbortCause.Value[0]), Name: t.AbortCause()}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in Transaction.
+func (t *Transaction) UnmarshalJSON(b []byte) error {
+	j := &transactionJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	mtype, ok := lookupName(messageTypeNames, j.Type)
+	if !ok {
+		return &InvalidJSONError{Field: "type", Value: j.Type}
+	}
+	payload, err := hex.DecodeString(j.Payload)
+	if err != nil {
+		return &InvalidJSONError{Field: "payload", Value: j.Payload}
+	}
+
+	*t = Transaction{Type: NewApplicationWideConstructorTag(mtype), Payload: payload}
+	if j.OTID != "" {
+		if t.OrigTransactionID, err = tidFromJSON(8, j.OTID); err != nil {
+			return &InvalidJSONError{Field: "otid", Value: j.OTID}
+		}
+	}
+	if j.DTID != "" {
+		if t.DestTransactionID, err = tidFromJSON(9, j.DTID); err != nil {
+			return &InvalidJSONError{Field: "dtid", Value: j.DTID}
+		}
+	}
+	if c := j.PAbortCause; c != nil {
+		if c.Code < 0 || c.Code > 0xff {
+			return &InvalidJSONError{Field: "pAbortCause", Value: strconv.Itoa(c.Code)}
+		}
+		t.PAbortCause = NewIE(NewApplicationWidePrimitiveTag(10), []byte{uint8(c.Code)})
+	}
+	t.SetLength()
+	return nil
+}
+
+func tidFromJSON(tag int, s string) (*IE, error) {
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewIE(NewApplicationWidePrimitiveTag(tag), v), nil
+}
+
+// MarshalJSON returns the JSON representation of Dialogue.
+func (d *Dialogue) MarshalJSON() ([]byte, error) {
+	j := &dialogueJSON{PDU: d.DialoguePDU, Payload: hex.EncodeToString(d.Payload)}
+	if field := d.ObjectIdentifier; field != nil {
+		oid, err := ber.DecodeObjectIdentifier(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		j.OID = oid.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in Dialogue.
+func (d *Dialogue) UnmarshalJSON(b []byte) error {
+	j := &dialogueJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	payload, err := hex.DecodeString(j.Payload)
+	if err != nil {
+		return &InvalidJSONError{Field: "payload", Value: j.Payload}
+	}
+	*d = Dialogue{
+		Tag:         NewApplicationWideConstructorTag(11),
+		ExternalTag: NewUniversalConstructorTag(8),
+		DialoguePDU: j.PDU,
+		Payload:     payload,
+	}
+	if j.OID != "" {
+		if d.ObjectIdentifier, err = oidFromJSON(j.OID); err != nil {
+			return &InvalidJSONError{Field: "oid", Value: j.OID}
+		}
+	}
+	if d.DialoguePDU != nil {
+		d.SingleAsn1Type = &IE{Tag: NewContextSpecificConstructorTag(0)}
+	}
+	d.SetLength()
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of DialoguePDU.
+func (d *DialoguePDU) MarshalJSON() ([]byte, error) {
+	j := &dialoguePDUJSON{Type: d.DialogueType(), UserInformation: d.UserInformation}
+	if j.Type == "" {
+		return nil, &InvalidCodeError{Code: d.Type.Code()}
+	}
+
+	if field := d.ProtocolVersion; field != nil && len(field.Value) > 0 {
+		v := int(field.Value[len(field.Value)-1] >> 7)
+		j.ProtocolVersion = &v
+	}
+	if d.ApplicationContextName != nil {
+		oid := d.ApplicationContextOID()
+		if oid == nil {
+			return nil, &InvalidJSONError{Field: "applicationContext", Value: hex.EncodeToString(d.ApplicationContextName.Value)}
+		}
+		j.ApplicationContext = &contextJSON{OID: oid.String(), Name: d.Context()}
+	}
+	if field := d.Result; field != nil {
+		v, err := decodeTaggedInteger(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		j.Result = &v
+	}
+	if field := d.ResultSourceDiagnostic; field != nil {
+		e, err := ber.ParseElement(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		src, ok := diagnosticSourceNames[e.Tag]
+		if !ok {
+			return nil, &InvalidCodeError{Code: e.Tag}
+		}
+		reason, err := decodeTaggedInteger(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		j.ResultSourceDiagnostic = &diagnosticJSON{Source: src, Reason: reason}
+	}
+	if field := d.AbortSource; field != nil && len(field.Value) > 0 {
+		v := int(field.Value[0])
+		j.AbortSource = &v
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in DialoguePDU.
+func (d *DialoguePDU) UnmarshalJSON(b []byte) error {
+	j := &dialoguePDUJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	dtype, ok := lookupName(dialogueTypeNames, j.Type)
+	if !ok {
+		return &InvalidJSONError{Field: "type", Value: j.Type}
+	}
+
+	*d = DialoguePDU{Type: NewApplicationWideConstructorTag(dtype), UserInformation: j.UserInformation}
+	if v := j.ProtocolVersion; v != nil {
+		d.ProtocolVersion = NewIE(NewContextSpecificPrimitiveTag(0), []byte{0x07, uint8(*v << 7)})
+	}
+	if c := j.ApplicationContext; c != nil {
+		oid, err := parseOID(c.OID)
+		if err != nil {
+			return &InvalidJSONError{Field: "applicationContext", Value: c.OID}
+		}
+		if d.ApplicationContextName = NewApplicationContextNameOID(oid); d.ApplicationContextName == nil {
+			return &InvalidJSONError{Field: "applicationContext", Value: c.OID}
+		}
+	}
+	if v := j.Result; v != nil {
+		d.Result = NewIE(NewContextSpecificConstructorTag(2), ber.Append(nil, ber.Universal, false, ber.TagInteger, ber.EncodeInteger(*v)))
+	}
+	if diag := j.ResultSourceDiagnostic; diag != nil {
+		src, ok := lookupName(diagnosticSourceNames, diag.Source)
+		if !ok {
+			return &InvalidJSONError{Field: "resultSourceDiagnostic", Value: diag.Source}
+		}
+		reason := ber.Append(nil, ber.Universal, false, ber.TagInteger, ber.EncodeInteger(diag.Reason))
+		d.ResultSourceDiagnostic = NewIE(NewContextSpecificConstructorTag(3), ber.Append(nil, ber.ContextSpecific, true, src, reason))
+	}
+	if v := j.AbortSource; v != nil {
+		d.AbortSource = NewIE(NewContextSpecificPrimitiveTag(0), []byte{uint8(*v)})
+	}
+	d.SetLength()
+	return nil
+}
+
+// decodeTaggedInteger decodes the INTEGER wrapped by an explicit tag.
+func decodeTaggedInteger(b []byte) (int64, error) {
+	e, err := ber.ParseElement(b)
+	if err != nil {
+		return 0, err
+	}
+	return ber.DecodeInteger(e.Value)
+}
+
+// MarshalJSON returns the JSON representation of Components, which is the array of Component.
+func (c *Components) MarshalJSON() ([]byte, error) {
+	comps := c.Component
+	if comps == nil {
+		comps = []*Component{}
+	}
+	return json.Marshal(comps)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in Components.
+func (c *Components) UnmarshalJSON(b []byte) error {
+	var comps []*Component
+	if err := json.Unmarshal(b, &comps); err != nil {
+		return err
+	}
+
+	*c = Components{Tag: NewApplicationWideConstructorTag(12), Component: comps}
+	c.SetLength()
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of Component.
+func (c *Component) MarshalJSON() ([]byte, error) {
+	j := &componentJSON{Type: c.ComponentTypeString(), Parameter: c.Parameter}
+	if j.Type == "" {
+		return nil, &InvalidCodeError{Code: c.Type.Code()}
+	}
+
+	var err error
+	if c.InvokeID != nil {
+		if j.InvokeID, err = integerJSON(c.InvokeID); err != nil {
+			return nil, err
+		}
+	}
+	if c.LinkedID != nil {
+		if j.LinkedID, err = integerJSON(c.LinkedID); err != nil {
+			return nil, err
+		}
+	}
+	if c.OperationCode != nil {
+		if j.OpCode, err = opCodeJSON(c.OperationCode, c.OperationName()); err != nil {
+			return nil, err
+		}
+	}
+	if c.ErrorCode != nil {
+		if j.ErrorCode, err = opCodeJSON(c.ErrorCode, c.ErrorName()); err != nil {
+			return nil, err
+		}
+	}
+	if field := c.ProblemCode; field != nil && len(field.Value) > 0 {
+		j.Problem = &problemJSON{Type: problemTypeNames[field.Tag.Code()], Code: int(field.Value[0])}
+		if j.Problem.Type == "" {
+			return nil, &InvalidCodeError{Code: field.Tag.Code()}
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in Component.
+func (c *Component) UnmarshalJSON(b []byte) error {
+	j := &componentJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	ctype, ok := lookupName(componentTypeNames, j.Type)
+	if !ok {
+		return &InvalidJSONError{Field: "type", Value: j.Type}
+	}
+
+	*c = Component{Type: NewContextSpecificConstructorTag(ctype), Parameter: j.Parameter}
+	if v := j.InvokeID; v != nil {
+		c.InvokeID = NewIE(NewUniversalPrimitiveTag(ber.TagInteger), ber.EncodeInteger(*v))
+	} else {
+		c.InvokeID = NewIE(NewUniversalPrimitiveTag(ber.TagNull), nil)
+	}
+	if v := j.LinkedID; v != nil {
+		c.LinkedID = NewIE(NewContextSpecificPrimitiveTag(0), ber.EncodeInteger(*v))
+	}
+
+	var err error
+	if j.OpCode != nil {
+		if c.OperationCode, err = opCodeFromJSON(j.OpCode); err != nil {
+			return err
+		}
+	}
+	if j.ErrorCode != nil {
+		if c.ErrorCode, err = opCodeFromJSON(j.ErrorCode); err != nil {
+			return err
+		}
+	}
+	if p := j.Problem; p != nil {
+		ptype, ok := lookupName(problemTypeNames, p.Type)
+		if !ok || p.Code < 0 || p.Code > 0xff {
+			return &InvalidJSONError{Field: "problem", Value: p.Type + " " + strconv.Itoa(p.Code)}
+		}
+		c.ProblemCode = NewIE(NewContextSpecificPrimitiveTag(ptype), []byte{uint8(p.Code)})
+	}
+
+	switch ctype {
+	case ReturnResultLast, ReturnResultNotLast:
+		if c.OperationCode != nil || c.Parameter != nil {
+			c.ResultRetres = &IE{Tag: NewUniversalConstructorTag(ber.TagSequence)}
+		}
+	}
+	c.SetLength()
+	return nil
+}
+
+func integerJSON(i *IE) (*int64, error) {
+	if i.Tag == NewUniversalPrimitiveTag(ber.TagNull) {
+		return nil, nil
+	}
+	v, err := ber.DecodeInteger(i.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func opCodeJSON(i *IE, name string) (*codeJSON, error) {
+	if i.Tag.Code() == ber.TagObjectIdentifier {
+		oid, err := ber.DecodeObjectIdentifier(i.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &codeJSON{Global: oid.String(), Name: name}, nil
+	}
+
+	v, err := ber.DecodeInteger(i.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &codeJSON{Local: &v, Name: name}, nil
+}
+
+func opCodeFromJSON(j *codeJSON) (*IE, error) {
+	switch {
+	case j.Local != nil:
+		return NewIE(NewUniversalPrimitiveTag(ber.TagInteger), ber.EncodeInteger(*j.Local)), nil
+	case j.Global != "":
+		i, err := oidFromJSON(j.Global)
+		if err != nil {
+			return nil, &InvalidJSONError{Field: "global", Value: j.Global}
+		}
+		return i, nil
+	}
+	return nil, &InvalidJSONError{Field: "code", Value: "missing local or global"}
+}
+
+// MarshalJSON returns the JSON representation of IE, which is the same as the one
+// of ber.Value. The constructed IE whose Value cannot be parsed as BER has its
+// Value in hex instead of elements.
+func (i *IE) MarshalJSON() ([]byte, error) {
+	v, err := i.BERValue()
+	if err != nil {
+		return json.Marshal(&ber.JSONValue{
+			Class:       ber.ClassName(i.Tag.Class()),
+			Tag:         i.Tag.Code(),
+			Constructed: true,
+			Value:       hex.EncodeToString(i.Value),
+		})
+	}
+	return v.MarshalJSON()
+}
+
+// UnmarshalJSON sets the values retrieved from the JSON representation in IE.
+func (i *IE) UnmarshalJSON(b []byte) error {
+	j := &ber.JSONValue{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	// keep the Value of constructed IE as it is, as it may not be valid BER.
+	if j.Constructed && j.Value != "" && len(j.Elements) == 0 {
+		class, err := ber.ParseClassName(j.Class)
+		if err != nil {
+			return err
+		}
+		v, err := hex.DecodeString(j.Value)
+		if err != nil {
+			return &InvalidJSONError{Field: "value", Value: j.Value}
+		}
+		if j.Tag > 30 {
+			return &UnsupportedEncodingError{Tag: j.Tag, Length: len(v)}
+		}
+		*i = *NewIE(NewTag(class, Constructor, j.Tag), v)
+		i.IE, _ = ParseAsBER(v)
+		return nil
+	}
+
+	v, err := j.BERValue()
+	if err != nil {
+		return err
+	}
+	ie, err := NewIEFromValue(v)
+	if err != nil {
+		return err
+	}
+	*i = *ie
+	return nil
+}
+
+func oidFromJSON(s string) (*IE, error) {
+	oid, err := parseOID(s)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ber.EncodeObjectIdentifier(oid)
+	if err != nil {
+		return nil, err
+	}
+	return NewIE(NewUniversalPrimitiveTag(ber.TagObjectIdentifier), v), nil
+}
+
+// parseOID parses the OID in dot notation, such as "0.4.0.0.1.0.2.3".
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for n, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return nil, &InvalidJSONError{Field: "oid", Value: s}
+		}
+		oid[n] = v
+	}
+	return oid, nil
+}
+
+func lookupName(names map[int]string, name string) (int, bool) {
+	for code, n := range names {
+		if n == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
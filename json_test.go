@@ -0,0 +1,176 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"encoding/asn1"
+	"encoding/json"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+func withDialogue(t *tcap.TCAP, pdu *tcap.DialoguePDU) *tcap.TCAP {
+	t.Dialogue = tcap.NewDialogue(tcap.DialogueAsID, 1, pdu, []byte{})
+	t.SetLength()
+	return t
+}
+
+func withComponents(t *tcap.TCAP, comps ...*tcap.Component) *tcap.TCAP {
+	t.Components = tcap.NewComponents(comps...)
+	t.SetLength()
+	return t
+}
+
+var jsonCases = []struct {
+	description string
+	msg         *tcap.TCAP
+}{
+	{
+		"Begin - AARQ - Invoke",
+		tcap.NewBeginInvokeWithDialogue(
+			0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3,
+			[]byte{0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9},
+		),
+	}, {
+		"End - AARE - ReturnResultLast",
+		tcap.NewEndReturnResultWithDialogue(
+			0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, true,
+			[]byte{0x04, 0x01, 0xff},
+		),
+	}, {
+		"Continue - ReturnError, Reject",
+		withComponents(
+			&tcap.TCAP{Transaction: tcap.NewContinue(0x22222222, 0x11111111, []byte{})},
+			tcap.NewReturnError(1, 34, true, []byte{0x0a, 0x01, 0x02}),
+			&tcap.Component{
+				Type:        tcap.NewContextSpecificConstructorTag(tcap.Reject),
+				InvokeID:    tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagInteger), []byte{0x02}),
+				ProblemCode: tcap.NewIE(tcap.NewContextSpecificPrimitiveTag(tcap.InvokeProblem), []byte{tcap.InvokeProblemMistypedParameter}),
+			},
+		),
+	}, {
+		"Begin - Invoke with global opCode and tagged parameter",
+		withComponents(
+			&tcap.TCAP{Transaction: tcap.NewBegin(0xdeadbeef, []byte{})},
+			withParameter(
+				tcap.NewInvoke(-1, 0, 0, false, nil),
+				ber.NewConstructed(ber.ContextSpecific, 3, ber.NewInteger(-100), ber.NewOctetString([]byte("go-tcap"))),
+			),
+		),
+	}, {
+		"Abort - ABRT",
+		withDialogue(
+			&tcap.TCAP{Transaction: tcap.NewAbort(0x11111111, tcap.ResourceLimitation, []byte{})},
+			tcap.NewABRT(uint8(tcap.AbortDialogueServiceProvider)),
+		),
+	}, {
+		"Begin - AARQ with OID and user information",
+		withDialogue(
+			&tcap.TCAP{Transaction: tcap.NewBegin(1, []byte{})},
+			tcap.NewAARQWithOID(1, asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 50, 2}, tcap.NewIE(
+				tcap.NewContextSpecificConstructorTag(30), []byte{0x28, 0x03, 0x02, 0x01, 0x05},
+			)),
+		),
+	},
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, c := range jsonCases {
+		t.Run(c.description, func(t *testing.T) {
+			want, err := c.msg.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := tcap.Parse(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, m := range []*tcap.TCAP{c.msg, parsed} {
+				j, err := json.Marshal(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := &tcap.TCAP{}
+				if err := json.Unmarshal(j, got); err != nil {
+					t.Fatalf("%s: %s", j, err)
+				}
+				b, err := got.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				verify.Values(t, string(j), b, want)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(jsonCases[0].msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"transaction":{"type":"begin","otid":"11111111"},` +
+		`"dialogue":{"oid":"0.0.17.773.1.1.1","pdu":{"type":"AARQ","protocolVersion":1,` +
+		`"applicationContext":{"oid":"0.4.0.0.1.0.2.3","name":"locationCancellationContext"}}},` +
+		`"components":[{"type":"invoke","invokeID":0,"opCode":{"local":3},` +
+		`"parameter":{"class":"universal","tag":16,"constructed":true,` +
+		`"elements":[{"class":"universal","tag":4,"value":"00010121436587f9"}]}}]}`
+	verify.Values(t, "JSON", string(b), want)
+}
+
+func TestUnmarshalJSONFixture(t *testing.T) {
+	fixture := `{
+		"transaction": {"type": "abort", "dtid": "01020304", "pAbortCause": {"code": 1}},
+		"components": []
+	}`
+
+	got := &tcap.TCAP{}
+	if err := json.Unmarshal([]byte(fixture), got); err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "DTID", got.DTID(), uint32(0x01020304))
+	verify.Values(t, "AbortCause", got.Transaction.AbortCause(), "UnrecognizedTransactionID")
+
+	for _, s := range []string{
+		`{}`,
+		`{"transaction": {"type": "start"}}`,
+		`{"transaction": {"type": "begin", "otid": "xyz"}}`,
+		`{"transaction": {"type": "begin"}, "components": [{"type": "invoke", "invokeID": 1, "opCode": {}}]}`,
+		`{"transaction": {"type": "begin"}, "dialogue": {"oid": "0.0.17.773.1.1.1", "pdu": {"type": "AARQ", "applicationContext": {"oid": "a.b"}}}}`,
+	} {
+		if err := json.Unmarshal([]byte(s), &tcap.TCAP{}); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
+
+func TestTypeNames(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		code   int
+		toName func(int) string
+		toCode func(string) (int, bool)
+	}{
+		{"begin", tcap.Begin, tcap.MessageTypeName, tcap.MessageTypeCode},
+		{"returnResultLast", tcap.ReturnResultLast, tcap.ComponentTypeName, tcap.ComponentTypeCode},
+		{"AARE", tcap.AARE, tcap.DialogueTypeName, tcap.DialogueTypeCode},
+		{"invoke", tcap.InvokeProblem, tcap.ProblemTypeName, tcap.ProblemTypeCode},
+		{"provider", tcap.DialogueServiceProvider, tcap.DiagnosticSourceName, tcap.DiagnosticSourceCode},
+	} {
+		verify.Values(t, "name", c.toName(c.code), c.name)
+		code, ok := c.toCode(c.name)
+		verify.Values(t, c.name, code, c.code)
+		verify.Values(t, c.name+" found", ok, true)
+		if _, ok := c.toCode("unknown"); ok {
+			t.Errorf("%s: unknown name is found", c.name)
+		}
+	}
+}
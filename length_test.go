@@ -0,0 +1,212 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+func newBeginWithDialogue() *tcap.TCAP {
+	return tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})
+}
+
+// newLongInvoke returns an Invoke whose Parameter is longer than the short form.
+func newLongInvoke(n int) *tcap.Component {
+	c := tcap.NewInvoke(1, -1, 71, true, nil)
+	c.Parameter = tcap.NewIE(tcap.NewUniversalConstructorTag(0x10), ber.Append(nil, ber.Universal, false, ber.TagOctetString, make([]byte, n)))
+	return c
+}
+
+func TestMarshalLengths(t *testing.T) {
+	want := mustMarshal(t, newBeginWithDialogue())
+
+	m := newBeginWithDialogue()
+	m.Transaction.Length = 0
+	m.Dialogue.Length = 0x7f
+	m.Dialogue.ExternalLength = 1
+	m.Dialogue.DialoguePDU.Length = 2
+	m.Components.Length = 0xff
+	m.Components.Component[0].Length = 3
+	m.Components.Component[0].Parameter.Length = 4
+	got := mustMarshal(t, m)
+	verify.Values(t, "marshalled with wrong lengths", got, want)
+
+	// the fields added after SetLength are counted.
+	m.Components.Component = append(m.Components.Component, tcap.NewInvoke(1, 0, 5, true, nil))
+	got = mustMarshal(t, m)
+	verify.Values(t, "length", len(got), m.MarshalLen())
+	parsed, err := tcap.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "components", len(parsed.Components.Component), 2)
+}
+
+func TestMarshalRaw(t *testing.T) {
+	m := newBeginWithDialogue()
+	m.Transaction.Length = 0x10
+	m.Components.Component[0].InvokeID.Length = 0x7f
+
+	b, err := tcap.MarshalOptions{Raw: true}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := len(b) - 10 // the length of InvokeID
+	verify.Values(t, "Transaction length", b[1], uint8(0x10))
+	verify.Values(t, "InvokeID length", b[i], uint8(0x7f))
+	verify.Values(t, "other lengths", b[2:i], mustMarshal(t, m)[2:i])
+
+	// the long form is followed by the length in the number of octets given.
+	m.Transaction.Length = 0x82
+	m.Components.Component[0].InvokeID.Length = 1
+	b, err = tcap.MarshalOptions{Raw: true}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "non-minimal long form", b[:4], []byte{0x62, 0x82, 0x00, uint8(len(b) - 4)})
+	parsed, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "OTID parsed", parsed.OTID(), uint32(0x11111111))
+	verify.Values(t, "AppContextName parsed", parsed.AppContextName(), "locationCancellationContext")
+}
+
+func TestMarshalLongForm(t *testing.T) {
+	m := &tcap.TCAP{
+		Transaction: tcap.NewBegin(0x11111111, nil),
+		Dialogue:    tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQ(1, tcap.LocationCancellationContext, 3), nil),
+		Components:  tcap.NewComponents(newLongInvoke(300)),
+	}
+	m.SetLength()
+	verify.Values(t, "Transaction Length", m.Transaction.Length, uint8(0x82))
+	verify.Values(t, "Components Length", m.Components.Length, uint8(0x82))
+
+	b := mustMarshal(t, m)
+	verify.Values(t, "length", len(b), m.MarshalLen())
+	verify.Values(t, "Transaction header", b[:4], []byte{0x62, 0x82, uint8((len(b) - 4) >> 8), uint8(len(b) - 4)})
+
+	parsed, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "OTID", parsed.OTID(), uint32(0x11111111))
+	verify.Values(t, "Parameter", parsed.Components.Component[0].Parameter.Value, m.Components.Component[0].Parameter.Value)
+
+	parsed.Transaction.Payload = nil
+	parsed.Dialogue.Payload = nil
+	verify.Values(t, "marshalled again", mustMarshal(t, parsed), b)
+
+	// 128 to 255 octets in one length octet.
+	m.Components = tcap.NewComponents(newLongInvoke(130))
+	b = mustMarshal(t, m)
+	verify.Values(t, "Components header", b[len(b)-m.Components.MarshalLen():][:3], []byte{0x6c, 0x81, uint8(m.Components.MarshalLen() - 3)})
+}
+
+func TestMarshalShortForm(t *testing.T) {
+	m := tcap.NewBeginInvoke(0x11111111, 0, 3, nil)
+	m.Components = tcap.NewComponents(newLongInvoke(130))
+
+	_, err := tcap.MarshalOptions{ShortForm: true}.Marshal(m)
+	var uerr *tcap.UnsupportedEncodingError
+	if !errors.As(err, &uerr) {
+		t.Fatalf("got %v, want UnsupportedEncodingError", err)
+	}
+	verify.Values(t, "error", uerr, &tcap.UnsupportedEncodingError{Tag: 0x10, Length: 133})
+
+	a := tcap.NewANSIQuery(0x11111111, true, tcap.NewANSIInvoke(1, -1, 9, 1, true, true, make([]byte, 130)))
+	if _, err := (tcap.MarshalOptions{ShortForm: true}).Marshal(a); !errors.As(err, &uerr) {
+		t.Errorf("got %v, want UnsupportedEncodingError for ANSI", err)
+	}
+}
+
+func TestMarshalANSILongForm(t *testing.T) {
+	a := tcap.NewANSIQuery(0x11111111, true, tcap.NewANSIInvoke(1, -1, 9, 1, true, true, make([]byte, 200)))
+	b := mustMarshal(t, a)
+	verify.Values(t, "length", len(b), a.MarshalLen())
+	verify.Values(t, "Package header", b[:4], []byte{0xe2, 0x81, uint8(len(b) - 3), 0xc7})
+
+	parsed, err := tcap.ParseANSI(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Parameter Set", parsed.Components.Component[0].Parameter.Value, make([]byte, 200))
+	verify.Values(t, "marshalled again", mustMarshal(t, parsed), b)
+
+	a.SetLength()
+	verify.Values(t, "Length", a.Length, uint8(0x81))
+}
+
+func TestNewIEFromLongValue(t *testing.T) {
+	v := ber.NewPrimitive(ber.Universal, ber.TagOctetString, make([]byte, 300))
+	ie, err := tcap.NewIEFromValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "marshalled", mustMarshal(t, ie), append([]byte{0x04, 0x82, 0x01, 0x2c}, make([]byte, 300)...))
+
+	c := tcap.NewInvoke(1, -1, 71, true, nil)
+	if err := c.SetParameter(ber.NewConstructed(ber.Universal, ber.TagSequence, v)); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := tcap.ParseComponents(mustMarshal(t, tcap.NewComponents(c)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.Component[0].ParameterValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Parameter", got.Elements[0].Contents, make([]byte, 300))
+
+	if _, err := tcap.NewIEFromValue(ber.NewPrimitive(ber.ContextSpecific, 31, nil)); err == nil {
+		t.Error("no error for the tag number larger than 30")
+	}
+}
+
+func TestMarshalParsed(t *testing.T) {
+	b := []byte{
+		// End, DTID
+		0x64, 0x20, 0x49, 0x04, 0x11, 0x11, 0x11, 0x11,
+		// Components
+		0x6c, 0x18,
+		// ReturnResultLast
+		0xa2, 0x0b, 0x02, 0x01, 0x01, 0x30, 0x06, 0x02, 0x01, 0x03, 0x04, 0x01, 0xff,
+		// Invoke
+		0xa1, 0x09, 0x02, 0x01, 0x02, 0x02, 0x01, 0x05, 0x04, 0x01, 0xee,
+	}
+	m, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "length", m.MarshalLen(), len(b))
+	verify.Values(t, "marshalled", mustMarshal(t, m), b)
+
+	// the lengths follow the changes.
+	m.Components.Component[0].Parameter = tcap.NewIE(0x04, []byte{0xfe, 0xfe})
+	b = mustMarshal(t, m)
+	verify.Values(t, "lengths", []byte{b[1], b[9], b[11], b[16]}, []byte{0x21, 0x19, 0x0c, 0x07})
+}
+
+func TestSetLengthOverflow(t *testing.T) {
+	m := tcap.NewBeginInvoke(0x11111111, 0, 3, nil)
+	for i := range 20 {
+		m.Components.Component = append(m.Components.Component, tcap.NewInvoke(i, -1, 71, true, make([]byte, 8)))
+	}
+	m.SetLength()
+	verify.Values(t, "Transaction Length", m.Transaction.Length, uint8(0x82))
+
+	b := mustMarshal(t, m)
+	parsed, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "components", len(parsed.Components.Component), 21)
+}
@@ -5,65 +5,151 @@
 package tcap
 
 import (
-	"io"
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
-	"sync"
+	"strings"
+	"sync/atomic"
 )
 
-var (
-	logger = log.New(os.Stderr, "", log.LstdFlags)
-	logMu  sync.Mutex
-)
-
-// SetLogger replaces the standard logger with arbitrary *log.Logger.
-//
 // This package prints just informational logs from goroutines working background
 // that might help developers test the program but can be ignored safely. More
 // important ones that needs any action by caller would be returned as errors.
+//
+// The logs are structured with the attributes below where they apply.
+//
+//	otid, dtid: the TIDs in hex
+//	acn:        the Application Context Name in dotted OID
+//	invoke_id:  the Invoke ID of the Component
+//	opcode:     the Operation Code of the Component
+//	peer:       the address of the peer, if the connection has RemoteAddr
+//	error:      the error occurred
+//
+// The messages sent and received by Endpoint are logged at debug level.
+
+// defaultLogger is the logger used when no logger is given.
+var defaultLogger atomic.Pointer[slog.Logger]
+
+func init() {
+	defaultLogger.Store(newStdLogger(nil))
+}
+
+// DefaultLogger returns the *slog.Logger used by the package when no logger is
+// given, e.g., in NewInvoke and Endpoint without Logger.
+func DefaultLogger() *slog.Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefaultLogger replaces the default logger with l.
+// If l is nil, logging from the package is disabled.
+func SetDefaultLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(slog.DiscardHandler)
+	}
+	defaultLogger.Store(l)
+}
+
+// SetLogger replaces the standard logger with arbitrary *log.Logger.
+//
+// The logs at info level and above are printed to l with the attributes in
+// "key=value" form.
+//
+// Deprecated: use SetDefaultLogger, or Endpoint.Logger for each Endpoint.
 func SetLogger(l *log.Logger) {
 	if l == nil {
 		log.Println("Don't pass nil to SetLogger: use DisableLogging instead.")
 	}
 
-	setLogger(l)
+	defaultLogger.Store(newStdLogger(l))
 }
 
 // EnableLogging enables the logging from the package.
 // If l is nil, it uses default logger provided by the package.
 // Logging is enabled by default.
 //
-// See also: SetLogger.
+// See also: SetLogger, SetDefaultLogger.
 func EnableLogging(l *log.Logger) {
-	logMu.Lock()
-	defer logMu.Unlock()
-
-	setLogger(l)
+	defaultLogger.Store(newStdLogger(l))
 }
 
 // DisableLogging disables the logging from the package.
 // Logging is enabled by default.
+//
+// The Endpoints with Logger set keep logging with it.
 func DisableLogging() {
-	logMu.Lock()
-	defer logMu.Unlock()
-
-	logger.SetOutput(io.Discard)
+	SetDefaultLogger(nil)
 }
 
-func setLogger(l *log.Logger) {
+// newStdLogger returns a *slog.Logger printing to l, or the one that prints to
+// os.Stderr if l is nil.
+func newStdLogger(l *log.Logger) *slog.Logger {
 	if l == nil {
 		l = log.New(os.Stderr, "", log.LstdFlags)
 	}
+	return slog.New(&stdHandler{l: l})
+}
+
+// stdHandler is a slog.Handler printing the records at info level and above to
+// *log.Logger in the form of "LEVEL message key=value ...".
+type stdHandler struct {
+	l      *log.Logger
+	attrs  string
+	prefix string
+}
+
+func (h *stdHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
 
-	logMu.Lock()
-	defer logMu.Unlock()
+func (h *stdHandler) Handle(_ context.Context, r slog.Record) error {
+	b := &strings.Builder{}
+	b.WriteString(r.Level.String())
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(b, h.prefix, a)
+		return true
+	})
+	return h.l.Output(0, b.String())
+}
 
-	logger = l
+func (h *stdHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	b := &strings.Builder{}
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		appendAttr(b, h.prefix, a)
+	}
+	return &stdHandler{l: h.l, attrs: b.String(), prefix: h.prefix}
 }
 
-func logf(format string, v ...interface{}) {
-	logMu.Lock()
-	defer logMu.Unlock()
+func (h *stdHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &stdHandler{l: h.l, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
 
-	logger.Printf(format, v...)
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " =\"") {
+		v = fmt.Sprintf("%q", v)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, v)
 }
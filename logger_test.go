@@ -0,0 +1,54 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+func TestLogger(t *testing.T) {
+	defer tcap.DisableLogging()
+	invalid := asn1.ObjectIdentifier{3}
+	want := "WARN failed to encode ApplicationContextName acn=3 error=\"ber: invalid object identifier: 3\"\n"
+
+	buf := &bytes.Buffer{}
+	tcap.SetLogger(log.New(buf, "", 0))
+	tcap.NewApplicationContextNameOID(invalid)
+	verify.Values(t, "SetLogger", buf.String(), want)
+
+	buf.Reset()
+	tcap.DisableLogging()
+	tcap.NewApplicationContextNameOID(invalid)
+	verify.Values(t, "DisableLogging", buf.String(), "")
+
+	tcap.EnableLogging(log.New(buf, "", 0))
+	tcap.NewApplicationContextNameOID(invalid)
+	verify.Values(t, "EnableLogging", buf.String(), want)
+
+	// the debug logs are not printed with *log.Logger.
+	buf.Reset()
+	tcap.DefaultLogger().With(slog.Group("g", "a", 1)).WithGroup("h").Debug("debug")
+	tcap.DefaultLogger().With(slog.Group("g", "a", 1)).WithGroup("h").Info("info", "b", "x y")
+	verify.Values(t, "groups", buf.String(), "INFO info g.a=1 h.b=\"x y\"\n")
+
+	buf.Reset()
+	tcap.SetDefaultLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+	tcap.NewApplicationContextNameOID(invalid)
+	verify.Values(t, "SetDefaultLogger", buf.String(), "level=WARN msg=\"failed to encode ApplicationContextName\" acn=3 error=\"ber: invalid object identifier: 3\"\n")
+}
@@ -0,0 +1,212 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package malform produces deliberately malformed TCAP messages, to test the
+robustness of the peers.
+
+A Mutation breaks a well-formed message given as *tcap.TCAP, either in the
+structure before it is marshalled, e.g., with a Transaction ID missing, or in
+the byte sequence after it, e.g., with a wrong length. Apply returns the byte
+sequence of the message malformed by the mutations, leaving the message as it
+is.
+
+	b, err := malform.Apply(msg, malform.Length(malform.Components, 3))
+
+Catalogue returns the named mutations to iterate over. The ones that need a
+part the message does not have return ErrNotApplicable, to be skipped.
+
+	for _, m := range malform.Catalogue() {
+		b, err := malform.Apply(msg, m)
+		if errors.Is(err, malform.ErrNotApplicable) {
+			continue
+		}
+		...
+	}
+
+To set the Length fields of the message as they are, marshal it with
+tcap.MarshalOptions{Raw: true} instead. Only ITU-T TCAP is supported.
+*/
+package malform
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// ErrNotApplicable is returned when the message does not have the part that a
+// Mutation breaks.
+var ErrNotApplicable = errors.New("malform: not applicable to the message")
+
+// Mutation is a deliberate malformation of a TCAP message.
+//
+// Modify changes the message before it is marshalled, with the lengths
+// computed after it, and Corrupt changes the byte sequence after it. Either
+// of them can be nil.
+type Mutation struct {
+	Name        string
+	Description string
+
+	Modify  func(t *tcap.TCAP) error
+	Corrupt func(b []byte) ([]byte, error)
+}
+
+// Apply returns the byte sequence of t malformed by the mutations.
+//
+// The Modify of all the mutations are called in order on a copy of t, and then
+// the Corrupt of them on the byte sequence marshalled.
+func Apply(t *tcap.TCAP, mutations ...Mutation) ([]byte, error) {
+	c, err := clone(t)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range mutations {
+		if m.Modify == nil {
+			continue
+		}
+		if err := m.Modify(c); err != nil {
+			return nil, fmt.Errorf("malform: %s: %w", m.Name, err)
+		}
+	}
+
+	b, err := c.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("malform: %w", err)
+	}
+
+	for _, m := range mutations {
+		if m.Corrupt == nil {
+			continue
+		}
+		if b, err = m.Corrupt(b); err != nil {
+			return nil, fmt.Errorf("malform: %s: %w", m.Name, err)
+		}
+	}
+	return b, nil
+}
+
+// clone returns a copy of t that can be modified, by marshalling and parsing.
+func clone(t *tcap.TCAP) (*tcap.TCAP, error) {
+	b, err := t.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("malform: %w", err)
+	}
+	c, err := tcap.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("malform: %w", err)
+	}
+
+	// the portions are marshalled from the fields, not from the rest.
+	if c.Dialogue != nil {
+		c.Dialogue.Payload = nil
+	}
+	if c.Dialogue != nil || c.Components != nil {
+		c.Transaction.Payload = nil
+	}
+	return c, nil
+}
+
+// Part is a part of TCAP message.
+type Part int
+
+// Part definitions.
+const (
+	Transaction Part = iota
+	Dialogue
+	DialoguePDU
+	Components
+	// Component is the first Component.
+	Component
+)
+
+// String returns the name of Part.
+func (p Part) String() string {
+	switch p {
+	case Transaction:
+		return "transaction"
+	case Dialogue:
+		return "dialogue"
+	case DialoguePDU:
+		return "dialoguePDU"
+	case Components:
+		return "components"
+	case Component:
+		return "component"
+	}
+	return fmt.Sprintf("Part(%d)", int(p))
+}
+
+// element is the position of a TLV in the byte sequence.
+type element struct {
+	start, contents, end int
+}
+
+// locate returns the position of the part in b.
+func locate(b []byte, p Part) (element, error) {
+	ts, err := next(b, 0)
+	if err != nil {
+		return element{}, err
+	}
+
+	var e element
+	switch p {
+	case Transaction:
+		return ts, nil
+	case Dialogue, DialoguePDU:
+		if e, err = child(b, ts, 0x6b); err != nil {
+			return element{}, err
+		}
+		if p == Dialogue {
+			return e, nil
+		}
+		if e, err = child(b, e, 0x28); err != nil {
+			return element{}, err
+		}
+		if e, err = child(b, e, 0xa0); err != nil {
+			return element{}, err
+		}
+		return next(b[:e.end], e.contents)
+	case Components, Component:
+		if e, err = child(b, ts, 0x6c); err != nil {
+			return element{}, err
+		}
+		if p == Components {
+			return e, nil
+		}
+		return next(b[:e.end], e.contents)
+	}
+	return element{}, fmt.Errorf("unknown part: %v", p)
+}
+
+// next returns the position of the TLV at offset in b.
+func next(b []byte, offset int) (element, error) {
+	if offset >= len(b) {
+		return element{}, ErrNotApplicable
+	}
+	e, n, err := ber.Next(b[offset:])
+	if err != nil {
+		return element{}, err
+	}
+	return element{start: offset, contents: offset + n - len(e.Value), end: offset + n}, nil
+}
+
+// child returns the position of the first TLV with the tag in the contents of
+// parent.
+func child(b []byte, parent element, tag uint8) (element, error) {
+	for offset := parent.contents; offset < parent.end; {
+		e, err := next(b[:parent.end], offset)
+		if err != nil {
+			return element{}, err
+		}
+		if b[offset] == tag {
+			return e, nil
+		}
+		offset = e.end
+	}
+	return element{}, ErrNotApplicable
+}
@@ -0,0 +1,171 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package malform_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/malform"
+)
+
+func newBegin() *tcap.TCAP {
+	m := tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})
+	m.Components.Component = append(m.Components.Component, tcap.NewInvoke(1, -1, 71, true, []byte{0x04, 0x01, 0xee}))
+	return m
+}
+
+func newEnd() *tcap.TCAP {
+	return tcap.NewEndReturnResult(0x22222222, 1, 3, true, []byte{0x04, 0x01, 0xff})
+}
+
+func mustMarshal(t *testing.T, m *tcap.TCAP) []byte {
+	t.Helper()
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func mustApply(t *testing.T, m *tcap.TCAP, mutations ...malform.Mutation) []byte {
+	t.Helper()
+	b, err := malform.Apply(m, mutations...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCatalogue(t *testing.T) {
+	names := map[string]bool{}
+	for _, mu := range malform.Catalogue() {
+		if names[mu.Name] {
+			t.Errorf("duplicate name %q", mu.Name)
+		}
+		names[mu.Name] = true
+		if mu.Description == "" {
+			t.Errorf("%s: no description", mu.Name)
+		}
+
+		applied := 0
+		for _, m := range []*tcap.TCAP{newBegin(), newEnd()} {
+			orig := mustMarshal(t, m)
+			b, err := malform.Apply(m, mu)
+			if errors.Is(err, malform.ErrNotApplicable) {
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: %v", mu.Name, err)
+				continue
+			}
+			applied++
+			if bytes.Equal(b, orig) {
+				t.Errorf("%s: not malformed: %x", mu.Name, b)
+			}
+			verify.Values(t, mu.Name+": original", mustMarshal(t, m), orig)
+		}
+		if applied == 0 {
+			t.Errorf("%s: not applicable to any message", mu.Name)
+		}
+	}
+}
+
+func TestNotApplicable(t *testing.T) {
+	m := tcap.NewBeginInvoke(0x11111111, 0, 3, nil)
+	for _, mu := range []malform.Mutation{
+		malform.Length(malform.Dialogue, 1),
+		malform.Truncate(malform.DialoguePDU),
+		malform.MissingTID(malform.DTID),
+		malform.ComponentsBeforeDialogue(),
+		malform.ReverseComponents(),
+	} {
+		if _, err := malform.Apply(m, mu); !errors.Is(err, malform.ErrNotApplicable) {
+			t.Errorf("%s: got %v, want ErrNotApplicable", mu.Name, err)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	m := newEnd()
+	orig := mustMarshal(t, m)
+
+	b := mustApply(t, m, malform.Length(malform.Components, 3))
+	verify.Values(t, "Components length", b[9], orig[9]+3)
+	verify.Values(t, "the rest", append(b[:9:9], b[10:]...), append(orig[:9:9], orig[10:]...))
+
+	b = mustApply(t, m, malform.Retag(malform.Component, 0xa7), malform.Length(malform.Transaction, -1))
+	verify.Values(t, "Transaction length", b[1], orig[1]-1)
+	verify.Values(t, "Component tag", b[10], uint8(0xa7))
+
+	// the long form is adjusted with the carry.
+	m = tcap.NewBeginInvoke(0x11111111, 0, 3, make([]byte, 0xfd))
+	orig = mustMarshal(t, m)
+	verify.Values(t, "Transaction header", orig[:4], []byte{0x62, 0x82, 0x01, 0x14})
+	b = mustApply(t, m, malform.Length(malform.Transaction, -0x15))
+	verify.Values(t, "long form", b[:4], []byte{0x62, 0x82, 0x00, 0xff})
+}
+
+func TestTruncate(t *testing.T) {
+	m := newBegin()
+	orig := mustMarshal(t, m)
+	b := mustApply(t, m, malform.Truncate(malform.Component))
+	verify.Values(t, "prefix", b, orig[:len(b)])
+	if len(b) >= len(orig)-m.Components.Component[1].MarshalLen() {
+		t.Errorf("not truncated in the first Component: %d of %d", len(b), len(orig))
+	}
+}
+
+func TestTID(t *testing.T) {
+	m := tcap.NewContinueInvoke(0x11111111, 0x22222222, 0, 3, nil)
+
+	b := mustApply(t, m, malform.MissingTID(malform.OTID))
+	verify.Values(t, "OTID missing", b[2:8], []byte{0x49, 0x04, 0x22, 0x22, 0x22, 0x22})
+	if parsed, err := tcap.Parse(b); err == nil && parsed.Transaction.OrigTransactionID.Tag != 0x49 {
+		t.Errorf("OTID parsed: %v", parsed.Transaction.OrigTransactionID)
+	}
+
+	b = mustApply(t, m, malform.DuplicateTID(malform.DTID))
+	verify.Values(t, "DTID repeated", b[2:14], []byte{
+		0x48, 0x04, 0x11, 0x11, 0x11, 0x11,
+		0x49, 0x04, 0x22, 0x22, 0x22, 0x22,
+	})
+	verify.Values(t, "repeated", b[14:20], []byte{0x49, 0x04, 0x22, 0x22, 0x22, 0x22})
+	verify.Values(t, "Components", b[20], uint8(0x6c))
+	verify.Values(t, "length", int(b[1]), len(b)-2)
+}
+
+func TestComponentsBeforeDialogue(t *testing.T) {
+	m := newBegin()
+	b := mustApply(t, m, malform.ComponentsBeforeDialogue())
+	verify.Values(t, "length", len(b), len(mustMarshal(t, m)))
+	verify.Values(t, "Components", b[8], uint8(0x6c))
+	verify.Values(t, "Dialogue", b[8+m.Components.MarshalLen()], uint8(0x6b))
+}
+
+func TestModify(t *testing.T) {
+	m := newBegin()
+	b := mustApply(t, m,
+		malform.OversizedInvokeID(2),
+		malform.DialogueOID([]byte{0, 17, 134, 5, 1, 15, 1}),
+		malform.ReverseComponents(),
+	)
+	parsed, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "dialogue OID", parsed.Dialogue.ObjectIdentifier.Value, []byte{0, 17, 134, 5, 1, 15, 1})
+	comps := parsed.Components.Component
+	verify.Values(t, "reversed", comps[0].OperationCode.Value, []byte{71})
+	verify.Values(t, "the other", comps[0].InvokeID.Value, []byte{0x01})
+	verify.Values(t, "Invoke ID", comps[1].InvokeID.Value, []byte{0x01, 0x00})
+
+	if _, err := malform.Apply(m, malform.OversizedInvokeID(0)); err == nil {
+		t.Error("Invoke ID of 0 octets applied")
+	}
+}
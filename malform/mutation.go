@@ -0,0 +1,328 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package malform
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Length adds delta to the length of the part, in the short or long form,
+// leaving the contents as they are.
+//
+// The form is kept as it is, so a short form length of 127 plus 1 becomes 0x80,
+// the indefinite form, and a short form length of 0 minus 1 becomes 0xff, the
+// reserved one, rather than the same length in the long form.
+func Length(p Part, delta int) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("%s/length%+d", p, delta),
+		Description: fmt.Sprintf("the length of %s is %+d octets from the contents", p, delta),
+		Corrupt: func(b []byte) ([]byte, error) {
+			e, err := locate(b, p)
+			if err != nil {
+				return nil, err
+			}
+
+			// the length octets following the first one in the long form.
+			first := e.start + 1
+			if e.contents > first+1 {
+				first++
+			}
+			carry := delta
+			for i := e.contents - 1; i >= first; i-- {
+				v := int(b[i]) + carry
+				b[i] = uint8(v)
+				carry = v >> 8
+			}
+			return b, nil
+		},
+	}
+}
+
+// Truncate cuts the message in the middle of the contents of the part.
+func Truncate(p Part) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("%s/truncated", p),
+		Description: fmt.Sprintf("the message ends in the middle of %s", p),
+		Corrupt: func(b []byte) ([]byte, error) {
+			e, err := locate(b, p)
+			if err != nil {
+				return nil, err
+			}
+			if e.end == e.contents {
+				return b[:e.start+1], nil
+			}
+			return b[:e.contents+(e.end-e.contents)/2], nil
+		},
+	}
+}
+
+// Retag replaces the tag of the part with tag, e.g., to make an unknown message
+// type or component type.
+func Retag(p Part, tag tcap.Tag) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("%s/tag-%#02x", p, uint8(tag)),
+		Description: fmt.Sprintf("the tag of %s is %#02x", p, uint8(tag)),
+		Corrupt: func(b []byte) ([]byte, error) {
+			e, err := locate(b, p)
+			if err != nil {
+				return nil, err
+			}
+			b[e.start] = uint8(tag)
+			return b, nil
+		},
+	}
+}
+
+// TID is either of the Transaction IDs.
+type TID int
+
+// TID definitions.
+const (
+	OTID TID = iota
+	DTID
+)
+
+// String returns the name of TID.
+func (id TID) String() string {
+	if id == OTID {
+		return "otid"
+	}
+	return "dtid"
+}
+
+func (id TID) field(t *tcap.TCAP) **tcap.IE {
+	if id == OTID {
+		return &t.Transaction.OrigTransactionID
+	}
+	return &t.Transaction.DestTransactionID
+}
+
+// MissingTID removes the Transaction ID.
+func MissingTID(id TID) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("transaction/missing-%s", id),
+		Description: fmt.Sprintf("the %s is missing", id),
+		Modify: func(t *tcap.TCAP) error {
+			field := id.field(t)
+			if *field == nil {
+				return ErrNotApplicable
+			}
+			*field = nil
+			return nil
+		},
+	}
+}
+
+// DuplicateTID repeats the Transaction ID.
+func DuplicateTID(id TID) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("transaction/duplicate-%s", id),
+		Description: fmt.Sprintf("the %s is repeated", id),
+		Modify: func(t *tcap.TCAP) error {
+			field := *id.field(t)
+			if field == nil {
+				return ErrNotApplicable
+			}
+			return insert(t, field)
+		},
+	}
+}
+
+// UnknownPortion puts an element with the tag unknown in the Transaction
+// Portion before the Dialogue and the Components.
+func UnknownPortion(tag tcap.Tag) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("transaction/unknown-%#02x", uint8(tag)),
+		Description: fmt.Sprintf("an unknown element %#02x is in the Transaction Portion", uint8(tag)),
+		Modify: func(t *tcap.TCAP) error {
+			return insert(t, tcap.NewIE(tag, []byte{0}))
+		},
+	}
+}
+
+// insert puts the IE after the Transaction IDs.
+func insert(t *tcap.TCAP, ie *tcap.IE) error {
+	portions, err := inline(t)
+	if err != nil {
+		return err
+	}
+	b, err := ie.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	t.Transaction.Payload = append(b, portions...)
+	return nil
+}
+
+// inline moves the Dialogue and the Components into the Payload of the
+// Transaction, to put any bytes around them, and returns them.
+func inline(t *tcap.TCAP) ([]byte, error) {
+	var b []byte
+	if t.Dialogue == nil && t.Components == nil {
+		b = t.Transaction.Payload
+	}
+	var err error
+	if d := t.Dialogue; d != nil {
+		if b, err = d.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	if c := t.Components; c != nil {
+		if b, err = c.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	t.Dialogue, t.Components = nil, nil
+	return b, nil
+}
+
+// ComponentsBeforeDialogue swaps the Dialogue Portion and the Component
+// Portion.
+func ComponentsBeforeDialogue() Mutation {
+	return Mutation{
+		Name:        "dialogue/after-components",
+		Description: "the Dialogue Portion follows the Component Portion",
+		Modify: func(t *tcap.TCAP) error {
+			if t.Dialogue == nil || t.Components == nil {
+				return ErrNotApplicable
+			}
+			b, err := t.Components.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if b, err = t.Dialogue.AppendBinary(b); err != nil {
+				return err
+			}
+			t.Dialogue, t.Components = nil, nil
+			t.Transaction.Payload = b
+			return nil
+		},
+	}
+}
+
+// DialogueOID replaces the contents of the OBJECT IDENTIFIER of the Dialogue
+// Portion, which is Dialogue-As-ID or Unidialogue-As-ID.
+func DialogueOID(v []byte) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("dialogue/oid-%x", v),
+		Description: fmt.Sprintf("the dialogue OID is %x", v),
+		Modify: func(t *tcap.TCAP) error {
+			if t.Dialogue == nil {
+				return ErrNotApplicable
+			}
+			t.Dialogue.ObjectIdentifier = tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagObjectIdentifier), v)
+			return nil
+		},
+	}
+}
+
+// ApplicationContextOID replaces the contents of the OBJECT IDENTIFIER of the
+// Application Context Name in AARQ or AARE.
+func ApplicationContextOID(v []byte) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("dialogue/acn-%x", v),
+		Description: fmt.Sprintf("the Application Context Name is %x", v),
+		Modify: func(t *tcap.TCAP) error {
+			if t.Dialogue == nil || t.Dialogue.DialoguePDU == nil || t.Dialogue.DialoguePDU.ApplicationContextName == nil {
+				return ErrNotApplicable
+			}
+			oid := ber.Append(nil, ber.Universal, false, ber.TagObjectIdentifier, v)
+			t.Dialogue.DialoguePDU.ApplicationContextName = tcap.NewIE(tcap.NewContextSpecificConstructorTag(1), oid)
+			return nil
+		},
+	}
+}
+
+// UnknownComponent appends a Component with the type unknown, which has only
+// an Invoke ID.
+func UnknownComponent(tag tcap.Tag) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("components/unknown-%#02x", uint8(tag)),
+		Description: fmt.Sprintf("an unknown Component %#02x follows the others", uint8(tag)),
+		Modify: func(t *tcap.TCAP) error {
+			if t.Components == nil {
+				return ErrNotApplicable
+			}
+			t.Components.Component = append(t.Components.Component, &tcap.Component{
+				Type:     tag,
+				InvokeID: tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagInteger), []byte{0x7f}),
+			})
+			return nil
+		},
+	}
+}
+
+// ReverseComponents reverses the order of the Components, e.g., to have the
+// results before the invoke.
+func ReverseComponents() Mutation {
+	return Mutation{
+		Name:        "components/reversed",
+		Description: "the Components are in the reverse order",
+		Modify: func(t *tcap.TCAP) error {
+			if t.Components == nil || len(t.Components.Component) < 2 {
+				return ErrNotApplicable
+			}
+			slices.Reverse(t.Components.Component)
+			return nil
+		},
+	}
+}
+
+// OversizedInvokeID replaces the Invoke ID of the first Component with the one
+// of n octets, out of the range of -128 to 127 if n is larger than 1.
+//
+// It fails if n is not positive.
+func OversizedInvokeID(n int) Mutation {
+	return Mutation{
+		Name:        fmt.Sprintf("component/invoke-id-%d-octets", n),
+		Description: fmt.Sprintf("the Invoke ID is %d octets", n),
+		Modify: func(t *tcap.TCAP) error {
+			if t.Components == nil || len(t.Components.Component) == 0 {
+				return ErrNotApplicable
+			}
+			if n <= 0 {
+				return fmt.Errorf("invalid length of Invoke ID: %d", n)
+			}
+			v := make([]byte, n)
+			v[0] = 0x01
+			t.Components.Component[0].InvokeID = tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagInteger), v)
+			return nil
+		},
+	}
+}
+
+// Catalogue returns the mutations of the common malformations.
+func Catalogue() []Mutation {
+	var ms []Mutation
+	for _, p := range []Part{Transaction, Dialogue, DialoguePDU, Components, Component} {
+		ms = append(ms, Length(p, -1), Length(p, 1), Truncate(p))
+	}
+	return append(ms,
+		Retag(Transaction, tcap.NewApplicationWideConstructorTag(15)),
+		MissingTID(OTID),
+		MissingTID(DTID),
+		DuplicateTID(OTID),
+		DuplicateTID(DTID),
+		UnknownPortion(tcap.NewApplicationWidePrimitiveTag(15)),
+
+		Retag(DialoguePDU, tcap.NewApplicationWideConstructorTag(15)),
+		DialogueOID([]byte{0, 17, 134, 5, 1, tcap.UnidialogueAsID, 1}),
+		DialogueOID([]byte{0, 17, 134, 5, 1, 15, 1}),
+		DialogueOID(nil),
+		ApplicationContextOID([]byte{0, 4, 0, 0, 1, 0, 127, 127}),
+		ApplicationContextOID(nil),
+		ComponentsBeforeDialogue(),
+
+		UnknownComponent(tcap.NewContextSpecificConstructorTag(5)),
+		ReverseComponents(),
+		Retag(Component, tcap.NewContextSpecificConstructorTag(6)),
+		OversizedInvokeID(2),
+		OversizedInvokeID(5),
+	)
+}
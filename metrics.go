@@ -0,0 +1,29 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import "time"
+
+// Metrics receives the events of the transaction and component sublayers in
+// Endpoint to be measured. The metrics package provides an implementation that
+// exports them in the OpenMetrics text format.
+//
+// The methods are called from multiple goroutines, and should not block.
+type Metrics interface {
+	// Message is called with each message sent or received, which must not
+	// be modified.
+	Message(t *TCAP, sent bool)
+
+	// DialogueStarted is called when a Session with a transaction is started.
+	DialogueStarted()
+
+	// DialogueEnded is called when the Session ends, with the time since it
+	// started.
+	DialogueEnded(d time.Duration)
+
+	// InvokeTimeout is called with each Invoke whose invocation timer has
+	// expired.
+	InvokeTimeout(inv *InvokeState)
+}
@@ -0,0 +1,276 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package metrics provides Collector, an implementation of tcap.Metrics that
+exports the metrics in the OpenMetrics text format, to be scraped by Prometheus
+or read by anything else without running an external service.
+
+	c := metrics.NewCollector()
+	ep := tcap.NewEndpoint(conn)
+	ep.Metrics = c
+	http.Handle("/metrics", c)
+
+The metrics below are exported. The direction label is "in" for the messages
+received and "out" for the ones sent.
+
+	tcap_messages_total{direction,type}            messages by the message type
+	tcap_dialogues_total{direction,acn}            dialogues begun by the Application Context Name
+	tcap_dialogues_active                          dialogues in progress
+	tcap_dialogue_duration_seconds                 histogram of the duration of the dialogues
+	tcap_p_aborts_total{direction,cause}           P-Aborts by the cause, e.g., "UnrecognizedTransactionID"
+	tcap_components_total{direction,type,opcode}   components by the component type and the operation code
+	tcap_rejects_total{direction,problem,code}     Rejects by the problem type and the problem code
+	tcap_invoke_timeouts_total{opcode}             Invokes whose invocation timer has expired
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// ContentType is the content type of the OpenMetrics text format.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of the buckets of the dialogue duration
+// histogram in seconds, used if none is given to NewCollector.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Collector collects the metrics of the Endpoints it is set to.
+//
+// It is safe for concurrent use, and can be shared by multiple Endpoints.
+type Collector struct {
+	mu         sync.Mutex
+	messages   map[labels]uint64
+	dialogues  map[labels]uint64
+	active     int64
+	pAborts    map[labels]uint64
+	components map[labels]uint64
+	rejects    map[labels]uint64
+	timeouts   map[labels]uint64
+
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// labels are the values of the labels of a sample in the order of the names.
+type labels [3]string
+
+// NewCollector creates a new Collector with the buckets of the dialogue
+// duration histogram in seconds. DefaultBuckets is used if none is given.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Collector{
+		messages:   map[labels]uint64{},
+		dialogues:  map[labels]uint64{},
+		pAborts:    map[labels]uint64{},
+		components: map[labels]uint64{},
+		rejects:    map[labels]uint64{},
+		timeouts:   map[labels]uint64{},
+		buckets:    buckets,
+		counts:     make([]uint64, len(buckets)),
+	}
+}
+
+// Message counts the message sent or received.
+func (c *Collector) Message(t *tcap.TCAP, sent bool) {
+	if t.Transaction == nil {
+		return
+	}
+	dir := "in"
+	if sent {
+		dir = "out"
+	}
+	ts := t.Transaction
+	mtype := tcap.MessageTypeName(ts.Type.Code())
+	if mtype == "" {
+		mtype = strconv.Itoa(ts.Type.Code())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages[labels{dir, mtype}]++
+	switch ts.Type.Code() {
+	case tcap.Begin, tcap.Unidirectional:
+		acn := ""
+		if t.Dialogue != nil {
+			if oid := t.Dialogue.ApplicationContextOID(); oid != nil {
+				acn = oid.String()
+			}
+		}
+		c.dialogues[labels{dir, acn}]++
+	case tcap.Abort:
+		if ts.PAbortCause != nil && len(ts.PAbortCause.Value) > 0 {
+			cause := ts.AbortCause()
+			if cause == "" {
+				cause = strconv.Itoa(int(ts.PAbortCause.Value[0]))
+			}
+			c.pAborts[labels{dir, cause}]++
+		}
+	}
+
+	if t.Components == nil {
+		return
+	}
+	for _, comp := range t.Components.Component {
+		ctype := comp.ComponentTypeString()
+		if ctype == "" {
+			ctype = strconv.Itoa(comp.Type.Code())
+		}
+		c.components[labels{dir, ctype, opCode(comp.OperationCode)}]++
+
+		if comp.Type.Code() == tcap.Reject && comp.ProblemCode != nil && len(comp.ProblemCode.Value) > 0 {
+			ptype := tcap.ProblemTypeName(comp.ProblemCode.Tag.Code())
+			if ptype == "" {
+				ptype = strconv.Itoa(comp.ProblemCode.Tag.Code())
+			}
+			c.rejects[labels{dir, ptype, strconv.Itoa(int(comp.ProblemCode.Value[0]))}]++
+		}
+	}
+}
+
+// opCode returns the local operation code in decimal, "global" for the global
+// one, or empty if ie is nil.
+func opCode(ie *tcap.IE) string {
+	if ie == nil {
+		return ""
+	}
+	if ie.Tag.Code() != ber.TagInteger {
+		return "global"
+	}
+	v, err := ber.DecodeInteger(ie.Value)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// DialogueStarted counts the dialogue in progress.
+func (c *Collector) DialogueStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active++
+}
+
+// DialogueEnded observes the duration of the dialogue ended.
+func (c *Collector) DialogueEnded(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active--
+	sec := d.Seconds()
+	for i, b := range c.buckets {
+		if sec <= b {
+			c.counts[i]++
+		}
+	}
+	c.count++
+	c.sum += sec
+}
+
+// InvokeTimeout counts the Invoke whose invocation timer has expired.
+func (c *Collector) InvokeTimeout(inv *tcap.InvokeState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeouts[labels{strconv.Itoa(int(inv.OpCode))}]++
+}
+
+// WriteTo writes the metrics to w in the OpenMetrics text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	c.mu.Lock()
+	writeCounter(bw, "tcap_messages", "TCAP messages sent and received.", []string{"direction", "type"}, c.messages)
+	writeCounter(bw, "tcap_dialogues", "Dialogues begun by the Application Context Name.", []string{"direction", "acn"}, c.dialogues)
+	fmt.Fprintf(bw, "# TYPE tcap_dialogues_active gauge\n# HELP tcap_dialogues_active Dialogues in progress.\ntcap_dialogues_active %d\n", c.active)
+
+	fmt.Fprintf(bw, "# TYPE tcap_dialogue_duration_seconds histogram\n# HELP tcap_dialogue_duration_seconds Duration of the dialogues.\n")
+	for i, b := range c.buckets {
+		fmt.Fprintf(bw, "tcap_dialogue_duration_seconds_bucket{le=\"%s\"} %d\n", formatFloat(b), c.counts[i])
+	}
+	fmt.Fprintf(bw, "tcap_dialogue_duration_seconds_bucket{le=\"+Inf\"} %d\n", c.count)
+	fmt.Fprintf(bw, "tcap_dialogue_duration_seconds_count %d\ntcap_dialogue_duration_seconds_sum %s\n", c.count, formatFloat(c.sum))
+
+	writeCounter(bw, "tcap_p_aborts", "P-Aborts sent and received by the cause.", []string{"direction", "cause"}, c.pAborts)
+	writeCounter(bw, "tcap_components", "Components sent and received by the type and the operation code.", []string{"direction", "type", "opcode"}, c.components)
+	writeCounter(bw, "tcap_rejects", "Rejects sent and received by the problem type and code.", []string{"direction", "problem", "code"}, c.rejects)
+	writeCounter(bw, "tcap_invoke_timeouts", "Invokes whose invocation timer has expired by the operation code.", []string{"opcode"}, c.timeouts)
+	c.mu.Unlock()
+
+	bw.WriteString("# EOF\n")
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP writes the metrics in response to the request.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	c.WriteTo(w)
+}
+
+func writeCounter(w io.Writer, name, help string, names []string, samples map[labels]uint64) {
+	fmt.Fprintf(w, "# TYPE %s counter\n# HELP %s %s\n", name, name, help)
+
+	keys := make([]labels, 0, len(samples))
+	for k := range samples {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+
+	for _, k := range keys {
+		pairs := make([]string, len(names))
+		for i, n := range names {
+			pairs[i] = n + "=\"" + escaper.Replace(k[i]) + "\""
+		}
+		fmt.Fprintf(w, "%s_total{%s} %d\n", name, strings.Join(pairs, ","), samples[k])
+	}
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
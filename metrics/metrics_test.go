@@ -0,0 +1,176 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/metrics"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+const want = `# TYPE tcap_messages counter
+# HELP tcap_messages TCAP messages sent and received.
+tcap_messages_total{direction="in",type="abort"} 1
+tcap_messages_total{direction="in",type="continue"} 1
+tcap_messages_total{direction="in",type="end"} 1
+tcap_messages_total{direction="out",type="begin"} 1
+tcap_messages_total{direction="out",type="continue"} 2
+# TYPE tcap_dialogues counter
+# HELP tcap_dialogues Dialogues begun by the Application Context Name.
+tcap_dialogues_total{direction="out",acn="0.4.0.0.1.0.2.3"} 1
+# TYPE tcap_dialogues_active gauge
+# HELP tcap_dialogues_active Dialogues in progress.
+tcap_dialogues_active 0
+# TYPE tcap_dialogue_duration_seconds histogram
+# HELP tcap_dialogue_duration_seconds Duration of the dialogues.
+tcap_dialogue_duration_seconds_bucket{le="0"} 0
+tcap_dialogue_duration_seconds_bucket{le="10"} 2
+tcap_dialogue_duration_seconds_bucket{le="+Inf"} 2
+tcap_dialogue_duration_seconds_count 2
+# TYPE tcap_p_aborts counter
+# HELP tcap_p_aborts P-Aborts sent and received by the cause.
+tcap_p_aborts_total{direction="in",cause="BadlyFormattedTransactionPortion"} 1
+# TYPE tcap_components counter
+# HELP tcap_components Components sent and received by the type and the operation code.
+tcap_components_total{direction="in",type="reject",opcode=""} 1
+tcap_components_total{direction="in",type="returnResultLast",opcode="3"} 1
+tcap_components_total{direction="out",type="invoke",opcode="3"} 2
+# TYPE tcap_rejects counter
+# HELP tcap_rejects Rejects sent and received by the problem type and code.
+tcap_rejects_total{direction="in",problem="invoke",code="1"} 1
+# TYPE tcap_invoke_timeouts counter
+# HELP tcap_invoke_timeouts Invokes whose invocation timer has expired by the operation code.
+tcap_invoke_timeouts_total{opcode="3"} 1
+# EOF
+`
+
+func TestCollector(t *testing.T) {
+	tcap.DisableLogging()
+	c := metrics.NewCollector(10, 0)
+
+	a, b := transport.Pipe()
+	client, server := tcap.NewEndpoint(a), tcap.NewEndpoint(b)
+	client.Metrics = c
+	client.Registry = tcap.NewOperationRegistry()
+	client.Registry.RegisterOperation(&tcap.Operation{Code: 3, Class: tcap.OperationClass1, Timeout: time.Millisecond})
+	expired := make(chan *tcap.InvokeState, 1)
+	client.InvokeTimeout = func(_ *tcap.Session, inv *tcap.InvokeState) { expired <- inv }
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go client.Serve(ctx)
+	go server.Serve(ctx)
+
+	cs, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(tcap.NewBeginInvokeWithDialogue(0, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{})); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case inv := <-expired:
+		verify.Values(t, "expired", inv.OpCode, uint8(3))
+	case <-ctx.Done():
+		t.Fatal("invocation timer not expired")
+	}
+
+	// the peer rejects the second Invoke and ends with the result of the first.
+	if err := ss.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Send(&tcap.TCAP{
+		Transaction: tcap.NewContinue(0, 0, []byte{}),
+		Components:  tcap.NewComponents(tcap.NewInvoke(1, -1, 3, true, nil)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.Send(&tcap.TCAP{
+		Transaction: tcap.NewEnd(0, []byte{}),
+		Components: tcap.NewComponents(
+			tcap.NewReject(1, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil),
+			tcap.NewReturnResult(0, 3, true, true, nil),
+		),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the peer does not know the transaction.
+	unknown, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unknown.Send(&tcap.TCAP{Transaction: tcap.NewContinue(0, 0, []byte{})}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unknown.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for client.Sessions() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := c.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "written", n, int64(buf.Len()))
+
+	// the sum of the durations varies.
+	var lines []string
+	for _, l := range strings.SplitAfter(buf.String(), "\n") {
+		if !strings.HasPrefix(l, "tcap_dialogue_duration_seconds_sum ") {
+			lines = append(lines, l)
+		}
+	}
+	verify.Values(t, "metrics", strings.Join(lines, ""), want)
+}
+
+func TestServeHTTP(t *testing.T) {
+	c := metrics.NewCollector()
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	verify.Values(t, "content type", w.Header().Get("Content-Type"), metrics.ContentType)
+	body := w.Body.String()
+	for _, s := range []string{
+		"tcap_dialogues_active 0\n",
+		"tcap_dialogue_duration_seconds_bucket{le=\"0.005\"} 0\n",
+		"tcap_dialogue_duration_seconds_bucket{le=\"+Inf\"} 0\n",
+		"tcap_dialogue_duration_seconds_sum 0\n",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("missing %q in:\n%s", s, body)
+		}
+	}
+	if !strings.HasSuffix(body, "\n# EOF\n") {
+		t.Errorf("no EOF in:\n%s", body)
+	}
+}
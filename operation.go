@@ -0,0 +1,425 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// Operation Class definitions, as in ITU-T Q.771.
+const (
+	// OperationClass1 reports both success and failure.
+	OperationClass1 int = iota + 1
+	// OperationClass2 reports failure only.
+	OperationClass2
+	// OperationClass3 reports success only.
+	OperationClass3
+	// OperationClass4 reports neither success nor failure.
+	OperationClass4
+)
+
+// ParameterDecoder decodes the Parameter of Component into a typed value.
+type ParameterDecoder func(v *ber.Value) (interface{}, error)
+
+// NewParameterDecoder returns the ParameterDecoder that decodes the Parameter
+// into a new value of the same type as prototype with ber.UnmarshalValueWithParams.
+//
+// The value returned by the decoder is a pointer to the type of prototype.
+func NewParameterDecoder(prototype interface{}, params string) ParameterDecoder {
+	t := reflect.TypeOf(prototype)
+	return func(v *ber.Value) (interface{}, error) {
+		x := reflect.New(t)
+		if err := ber.UnmarshalValueWithParams(v, x.Interface(), params); err != nil {
+			return nil, err
+		}
+		return x.Interface(), nil
+	}
+}
+
+// Operation is the definition of an operation in the style of ROS (ITU-T X.880).
+//
+// Timeout is the default value of the invocation timer. The timer is not
+// started if it is zero. Errors and Linked are the codes of the errors that
+// the operation may return and the operations that may be linked to it; any
+// is allowed if they are nil.
+type Operation struct {
+	Code     uint8
+	Name     string
+	Class    int
+	Timeout  time.Duration
+	Argument ParameterDecoder
+	Result   ParameterDecoder
+	Errors   []uint8
+	Linked   []uint8
+}
+
+// ErrorDefinition is the definition of an error in the style of ROS (ITU-T X.880).
+type ErrorDefinition struct {
+	Code      uint8
+	Name      string
+	Parameter ParameterDecoder
+}
+
+// OperationRegistry holds the operations and errors of a user protocol and
+// the application contexts that contain them.
+//
+// It is safe for concurrent use.
+type OperationRegistry struct {
+	mu         sync.RWMutex
+	operations map[uint8]*Operation
+	errors     map[uint8]*ErrorDefinition
+	contexts   map[string][]uint8
+	included   map[string]*OperationRegistry
+
+	// ambiguousOps and ambiguousErrors are the codes that the registries
+	// included have with different names.
+	ambiguousOps    map[uint8]bool
+	ambiguousErrors map[uint8]bool
+}
+
+// DefaultOperationRegistry is the OperationRegistry used by String() of Component
+// and Update() of DialogueState.
+//
+// As the codes of operations are unique only within a user protocol, register
+// the operations of the protocol in use. The packages of the user protocols,
+// such as gsmmap and camel, include theirs with Include on init.
+var DefaultOperationRegistry = NewOperationRegistry()
+
+// NewOperationRegistry creates a new empty OperationRegistry.
+func NewOperationRegistry() *OperationRegistry {
+	return &OperationRegistry{
+		operations: map[uint8]*Operation{},
+		errors:     map[uint8]*ErrorDefinition{},
+		contexts:   map[string][]uint8{},
+		included:   map[string]*OperationRegistry{},
+
+		ambiguousOps:    map[uint8]bool{},
+		ambiguousErrors: map[uint8]bool{},
+	}
+}
+
+// RegisterOperation registers the operations, replacing the existing ones with the same code.
+func (r *OperationRegistry) RegisterOperation(ops ...*Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, op := range ops {
+		r.operations[op.Code] = op
+		delete(r.ambiguousOps, op.Code)
+	}
+}
+
+// RegisterError registers the errors, replacing the existing ones with the same code.
+func (r *OperationRegistry) RegisterError(errs ...*ErrorDefinition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range errs {
+		r.errors[e.Code] = e
+		delete(r.ambiguousErrors, e.Code)
+	}
+}
+
+// RegisterContext registers the codes of operations contained in the application context.
+func (r *OperationRegistry) RegisterContext(acn asn1.ObjectIdentifier, opCodes ...uint8) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.contexts[acn.String()] = append([]uint8(nil), opCodes...)
+}
+
+// Include adds the operations, errors and application contexts of sub to r.
+//
+// The operations in the application contexts of sub are looked up in sub, as
+// the codes are unique only within a user protocol, e.g., 22 is sendRoutingInfo
+// in MAP and releaseCall in CAP. The other lookups find the ones of sub only if
+// r does not have the ones with the same code, and OperationName and ErrorName
+// of r return empty string for the codes with different names.
+func (r *OperationRegistry) Include(sub *OperationRegistry) {
+	sub.mu.RLock()
+	defer sub.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for code, op := range sub.operations {
+		if cur, ok := r.operations[code]; !ok {
+			r.operations[code] = op
+		} else if cur.Name != op.Name {
+			r.ambiguousOps[code] = true
+		}
+	}
+	for code, e := range sub.errors {
+		if cur, ok := r.errors[code]; !ok {
+			r.errors[code] = e
+		} else if cur.Name != e.Name {
+			r.ambiguousErrors[code] = true
+		}
+	}
+	for acn, codes := range sub.contexts {
+		r.contexts[acn] = codes
+		r.included[acn] = sub
+	}
+}
+
+// ForContext returns the OperationRegistry included in r for the application
+// context, or r itself if there is none.
+func (r *OperationRegistry) ForContext(acn asn1.ObjectIdentifier) *OperationRegistry {
+	if acn == nil {
+		return r
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if sub, ok := r.included[acn.String()]; ok {
+		return sub
+	}
+	return r
+}
+
+// Operation returns the operation with the code, or nil if not registered.
+func (r *OperationRegistry) Operation(opCode uint8) *Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.operations[opCode]
+}
+
+// Error returns the error with the code, or nil if not registered.
+func (r *OperationRegistry) Error(errCode uint8) *ErrorDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.errors[errCode]
+}
+
+// Operations returns the operations contained in the application context, sorted by code.
+func (r *OperationRegistry) Operations(acn asn1.ObjectIdentifier) []*Operation {
+	r = r.ForContext(acn)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ops []*Operation
+	for _, code := range r.contexts[acn.String()] {
+		if op, ok := r.operations[code]; ok {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Code < ops[j].Code })
+	return ops
+}
+
+// OperationName returns the name of operation, or empty string if not registered.
+//
+// It also returns empty string if the registries included have the code with
+// different names. Look it up in the one returned by ForContext instead.
+func (r *OperationRegistry) OperationName(opCode uint8) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if op, ok := r.operations[opCode]; ok && !r.ambiguousOps[opCode] {
+		return op.Name
+	}
+	return ""
+}
+
+// ErrorName returns the name of error, or empty string if not registered.
+//
+// It also returns empty string if the registries included have the code with
+// different names. Look it up in the one returned by ForContext instead.
+func (r *OperationRegistry) ErrorName(errCode uint8) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if e, ok := r.errors[errCode]; ok && !r.ambiguousErrors[errCode] {
+		return e.Name
+	}
+	return ""
+}
+
+// hasContext reports whether the application context is registered.
+func (r *OperationRegistry) hasContext(acn asn1.ObjectIdentifier) bool {
+	if acn == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.contexts[acn.String()]
+	return ok
+}
+
+// contains reports whether the application context contains the operation.
+// Any operation is allowed if acn is nil or not registered.
+func (r *OperationRegistry) contains(acn asn1.ObjectIdentifier, opCode uint8) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if acn == nil {
+		return true
+	}
+	codes, ok := r.contexts[acn.String()]
+	if !ok {
+		return true
+	}
+	for _, c := range codes {
+		if c == opCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the Component received against the definitions of the operations.
+//
+// acn is the application context of the dialogue, which can be nil. inv is the
+// outstanding Invoke that the Component responds to, or the one that the Invoke
+// is linked to. It returns *ComponentProblemError with the problem to be sent
+// back in a Reject Component. The operations are looked up in the registry
+// returned by ForContext.
+func (r *OperationRegistry) Validate(acn asn1.ObjectIdentifier, c *Component, inv *InvokeState) error {
+	r = r.ForContext(acn)
+	problem := func(problemType int, code uint8) error {
+		return &ComponentProblemError{InvokeID: c.InvID(), ProblemType: problemType, ProblemCode: code}
+	}
+
+	switch c.Type.Code() {
+	case Invoke:
+		if c.OperationCode == nil || len(c.OperationCode.Value) == 0 {
+			return problem(GeneralProblem, MistypedComponent)
+		}
+		op := r.Operation(c.OpCode())
+		if op == nil || !r.contains(acn, op.Code) {
+			return problem(InvokeProblem, InvokeProblemUnrecognizedOperation)
+		}
+		if c.LinkedID == nil {
+			return nil
+		}
+		if inv == nil {
+			return problem(InvokeProblem, InvokeProblemUnrecognizedLinkedID)
+		}
+		if linked := r.Operation(inv.OpCode); linked != nil && linked.Linked != nil && !containsCode(linked.Linked, op.Code) {
+			return problem(InvokeProblem, InvokeProblemUnexpectedLinkedOperation)
+		}
+	case ReturnResultLast, ReturnResultNotLast:
+		if c.OperationCode != nil && len(c.OperationCode.Value) == 0 {
+			return problem(GeneralProblem, MistypedComponent)
+		}
+		if inv == nil {
+			return problem(ReturnResultProblem, ResultProblemUnrecognizedInvokeID)
+		}
+		if op := r.Operation(inv.OpCode); op != nil && (op.Class == OperationClass2 || op.Class == OperationClass4) {
+			return problem(ReturnResultProblem, ResultProblemReturnResultUnexpected)
+		}
+	case ReturnError:
+		if inv == nil {
+			return problem(ReturnErrorProblem, ErrorProblemUnrecognizedInvokeID)
+		}
+		if c.ErrorCode == nil || len(c.ErrorCode.Value) == 0 {
+			return problem(GeneralProblem, MistypedComponent)
+		}
+		op := r.Operation(inv.OpCode)
+		if op == nil {
+			return nil
+		}
+		if op.Class == OperationClass3 || op.Class == OperationClass4 {
+			return problem(ReturnErrorProblem, ErrorProblemReturnErrorUnexpected)
+		}
+		if r.Error(c.OpCode()) == nil {
+			return problem(ReturnErrorProblem, ErrorProblemUnrecognizedError)
+		}
+		if op.Errors != nil && !containsCode(op.Errors, c.OpCode()) {
+			return problem(ReturnErrorProblem, ErrorProblemUnexpectedError)
+		}
+	}
+	return nil
+}
+
+// Decode decodes the Parameter of the Component with the decoder of the
+// argument, result or error parameter registered.
+//
+// The code of operation is taken from the Component, or from inv if the
+// Component does not have it, e.g., ReturnResult without Parameter. It returns
+// nil without error if the Component has no Parameter or the decoder is not
+// registered, *ComponentProblemError with mistyped component if the code is
+// empty, and with mistyped parameter if decoding fails.
+func (r *OperationRegistry) Decode(c *Component, inv *InvokeState) (interface{}, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+
+	mistyped := &ComponentProblemError{InvokeID: c.InvID(), ProblemType: GeneralProblem, ProblemCode: MistypedComponent}
+	var (
+		dec         ParameterDecoder
+		problemType int
+		problemCode uint8
+	)
+	switch c.Type.Code() {
+	case Invoke:
+		if c.OperationCode == nil {
+			return nil, nil
+		}
+		if len(c.OperationCode.Value) == 0 {
+			return nil, mistyped
+		}
+		if op := r.Operation(c.OpCode()); op != nil {
+			dec = op.Argument
+		}
+		problemType, problemCode = InvokeProblem, InvokeProblemMistypedParameter
+	case ReturnResultLast, ReturnResultNotLast:
+		code, ok := uint8(0), false
+		if c.OperationCode != nil {
+			if len(c.OperationCode.Value) == 0 {
+				return nil, mistyped
+			}
+			code, ok = c.OpCode(), true
+		} else if inv != nil {
+			code, ok = inv.OpCode, true
+		}
+		if op := r.Operation(code); ok && op != nil {
+			dec = op.Result
+		}
+		problemType, problemCode = ReturnResultProblem, ResultProblemMistypedParameter
+	case ReturnError:
+		if c.ErrorCode == nil {
+			return nil, nil
+		}
+		if len(c.ErrorCode.Value) == 0 {
+			return nil, mistyped
+		}
+		if e := r.Error(c.OpCode()); e != nil {
+			dec = e.Parameter
+		}
+		problemType, problemCode = ReturnErrorProblem, ErrorProblemMistypedParameter
+	}
+	if dec == nil {
+		return nil, nil
+	}
+
+	v, err := c.ParameterValue()
+	if err == nil {
+		var p interface{}
+		if p, err = dec(v); err == nil {
+			return p, nil
+		}
+	}
+	return nil, &ComponentProblemError{InvokeID: c.InvID(), ProblemType: problemType, ProblemCode: problemCode, Err: err}
+}
+
+func containsCode(codes []uint8, code uint8) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
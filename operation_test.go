@@ -0,0 +1,181 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"encoding/asn1"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+type cancelLocationArg struct {
+	IMSI []byte
+}
+
+var testACN = asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 2, 3}
+
+func newTestRegistry() *tcap.OperationRegistry {
+	r := tcap.NewOperationRegistry()
+	r.RegisterOperation(
+		&tcap.Operation{
+			Code: 3, Name: "cancelLocation", Class: tcap.OperationClass1, Timeout: 30 * time.Second,
+			Argument: tcap.NewParameterDecoder(cancelLocationArg{}, ""),
+			Errors:   []uint8{34},
+			Linked:   []uint8{59},
+		},
+		&tcap.Operation{Code: 59, Name: "processUnstructuredSS-Request", Class: tcap.OperationClass1},
+		&tcap.Operation{Code: 67, Name: "purgeMS", Class: tcap.OperationClass3},
+	)
+	r.RegisterError(
+		&tcap.ErrorDefinition{Code: 34, Name: "systemFailure"},
+		&tcap.ErrorDefinition{Code: 35, Name: "dataMissing"},
+	)
+	r.RegisterContext(testACN, 3)
+	return r
+}
+
+// emptyCode empties the Operation Code or the Error Code of the Component.
+func emptyCode(c *tcap.Component) *tcap.Component {
+	ie := c.OperationCode
+	if c.Type.Code() == tcap.ReturnError {
+		ie = c.ErrorCode
+	}
+	ie.Length, ie.Value = 0, nil
+	return c
+}
+
+func TestOperationRegistryValidate(t *testing.T) {
+	r := newTestRegistry()
+	cancelLocation := &tcap.InvokeState{InvokeID: 1, OpCode: 3}
+	purgeMS := &tcap.InvokeState{InvokeID: 1, OpCode: 67}
+
+	cases := []struct {
+		description string
+		acn         asn1.ObjectIdentifier
+		component   *tcap.Component
+		inv         *tcap.InvokeState
+		problemType int
+		problemCode uint8
+	}{
+		{"Invoke", testACN, tcap.NewInvoke(1, -1, 3, true, nil), nil, -1, 0},
+		{"Invoke/unknown", nil, tcap.NewInvoke(1, -1, 99, true, nil), nil, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation},
+		{"Invoke/empty code", nil, emptyCode(tcap.NewInvoke(1, -1, 3, true, nil)), nil, tcap.GeneralProblem, tcap.MistypedComponent},
+		{"Invoke/not in context", testACN, tcap.NewInvoke(1, -1, 67, true, nil), nil, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation},
+		{"Invoke/linked", nil, tcap.NewInvoke(2, 1, 59, true, nil), cancelLocation, -1, 0},
+		{"Invoke/unknown linked ID", nil, tcap.NewInvoke(2, 1, 59, true, nil), nil, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedLinkedID},
+		{"Invoke/unexpected linked", nil, tcap.NewInvoke(2, 1, 67, true, nil), cancelLocation, tcap.InvokeProblem, tcap.InvokeProblemUnexpectedLinkedOperation},
+		{"ReturnResult", nil, tcap.NewReturnResult(1, 3, true, true, nil), cancelLocation, -1, 0},
+		{"ReturnResult/unknown invoke", nil, tcap.NewReturnResult(1, 3, true, true, nil), nil, tcap.ReturnResultProblem, tcap.ResultProblemUnrecognizedInvokeID},
+		{"ReturnResult/empty code", nil, emptyCode(tcap.NewReturnResult(1, 3, true, true, nil)), cancelLocation, tcap.GeneralProblem, tcap.MistypedComponent},
+		{"ReturnError", nil, tcap.NewReturnError(1, 34, true, nil), cancelLocation, -1, 0},
+		{"ReturnError/empty code", nil, emptyCode(tcap.NewReturnError(1, 34, true, nil)), cancelLocation, tcap.GeneralProblem, tcap.MistypedComponent},
+		{"ReturnError/class 3", nil, tcap.NewReturnError(1, 34, true, nil), purgeMS, tcap.ReturnErrorProblem, tcap.ErrorProblemReturnErrorUnexpected},
+		{"ReturnError/unrecognized", nil, tcap.NewReturnError(1, 99, true, nil), cancelLocation, tcap.ReturnErrorProblem, tcap.ErrorProblemUnrecognizedError},
+		{"ReturnError/unexpected", nil, tcap.NewReturnError(1, 35, true, nil), cancelLocation, tcap.ReturnErrorProblem, tcap.ErrorProblemUnexpectedError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := r.Validate(c.acn, c.component, c.inv)
+			if c.problemType < 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var p *tcap.ComponentProblemError
+			if !errors.As(err, &p) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			verify.Values(t, "problem", []int{p.ProblemType, int(p.ProblemCode)}, []int{c.problemType, int(c.problemCode)})
+		})
+	}
+}
+
+func TestOperationRegistryDecode(t *testing.T) {
+	r := newTestRegistry()
+
+	p, err := r.Decode(tcap.NewInvoke(1, -1, 3, true, []byte{0x04, 0x02, 0x21, 0x43}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "argument", p, &cancelLocationArg{IMSI: []byte{0x21, 0x43}})
+
+	_, err = r.Decode(tcap.NewInvoke(1, -1, 3, true, []byte{0x02, 0x01, 0x01}), nil)
+	var problem *tcap.ComponentProblemError
+	if !errors.As(err, &problem) || problem.ProblemCode != tcap.InvokeProblemMistypedParameter {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = r.Decode(emptyCode(tcap.NewInvoke(1, -1, 3, true, []byte{0x04, 0x02, 0x21, 0x43})), nil)
+	if !errors.As(err, &problem) || problem.ProblemCode != tcap.MistypedComponent {
+		t.Errorf("unexpected error with empty Operation Code: %v", err)
+	}
+
+	verify.Values(t, "operations", len(r.Operations(testACN)), 1)
+	verify.Values(t, "name", r.OperationName(59), "processUnstructuredSS-Request")
+	verify.Values(t, "error", r.ErrorName(34), "systemFailure")
+}
+
+func TestOperationRegistryInclude(t *testing.T) {
+	capACN := asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, 50, 1}
+	capRegistry := tcap.NewOperationRegistry()
+	capRegistry.RegisterOperation(&tcap.Operation{Code: 3, Name: "capOperation"}, &tcap.Operation{Code: 22, Name: "releaseCall"})
+	capRegistry.RegisterContext(capACN, 3, 22)
+
+	r := newTestRegistry()
+	r.Include(capRegistry)
+	verify.Values(t, "ambiguous name", r.OperationName(3), "")
+	verify.Values(t, "included", r.OperationName(22), "releaseCall")
+	verify.Values(t, "in context", r.ForContext(capACN).OperationName(3), "capOperation")
+	verify.Values(t, "out of context", r.ForContext(testACN).Operation(3).Name, "cancelLocation")
+
+	r.RegisterOperation(&tcap.Operation{Code: 3, Name: "cancelLocation"})
+	verify.Values(t, "registered again", r.OperationName(3), "cancelLocation")
+
+	if err := r.Validate(capACN, tcap.NewInvoke(1, -1, 22, true, nil), nil); err != nil {
+		t.Error(err)
+	}
+	if err := r.Validate(capACN, tcap.NewInvoke(1, -1, 59, true, nil), nil); err == nil {
+		t.Error("operation out of the context validated")
+	}
+}
+
+func TestDialogueStateTimer(t *testing.T) {
+	r := newTestRegistry()
+	s := tcap.NewDialogueState(0x11111111, nil, nil)
+
+	s.UpdateWithRegistry(tcap.NewBeginInvoke(0x11111111, 1, 3, nil), true, r)
+	inv := s.Invoke(1)
+	if inv == nil || inv.Class != tcap.OperationClass1 || inv.Deadline.IsZero() {
+		t.Fatalf("unexpected invoke: %v", inv)
+	}
+
+	if expired := s.ExpiredInvokes(time.Now()); len(expired) != 0 {
+		t.Errorf("unexpected expiry: %v", expired)
+	}
+	if expired := s.ExpiredInvokes(time.Now().Add(time.Minute)); len(expired) != 1 || len(s.Invokes) != 0 {
+		t.Errorf("invoke not expired: %v", s)
+	}
+}
+
+func TestComponentString(t *testing.T) {
+	saved := tcap.DefaultOperationRegistry
+	tcap.DefaultOperationRegistry = newTestRegistry()
+	t.Cleanup(func() { tcap.DefaultOperationRegistry = saved })
+
+	if s := tcap.NewInvoke(1, -1, 3, true, nil).String(); !strings.Contains(s, "(cancelLocation)") {
+		t.Errorf("operation name not found: %s", s)
+	}
+	if s := tcap.NewReturnError(1, 34, true, nil).String(); !strings.Contains(s, "(systemFailure)") {
+		t.Errorf("error name not found: %s", s)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package pcap reads and writes TCAP messages carried on SIGTRAN in pcap and
+pcapng capture files.
+
+Reader retrieves TCAP from the frames of SCTP/M3UA/SCCP with the SCCP addresses
+and the timestamps, and Writer encapsulates TCAP into the frames of the same stack
+(or the exported PDU link type of Wireshark), so that the files can be opened
+with Wireshark as they are.
+
+Only SCCP UDT and XUDT in M3UA DATA on unfragmented SCTP DATA chunks are handled.
+*/
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-tcap"
+)
+
+// LinkType is the link-layer header type of the frames.
+type LinkType uint16
+
+// LinkType definitions.
+const (
+	LinkTypeNull        LinkType = 0
+	LinkTypeEthernet    LinkType = 1
+	LinkTypeRaw         LinkType = 101
+	LinkTypeLinuxSLL    LinkType = 113
+	LinkTypeIPv4        LinkType = 228
+	LinkTypeIPv6        LinkType = 229
+	LinkTypeExportedPDU LinkType = 252
+	LinkTypeLinuxSLL2   LinkType = 276
+)
+
+// SCCP Message Type definitions for Packet.
+const (
+	SCCPUDT  = "UDT"
+	SCCPXUDT = "XUDT"
+)
+
+// errNotTCAP indicates that the frame does not carry TCAP, which is skipped by Reader.
+var errNotTCAP = errors.New("pcap: not a TCAP frame")
+
+// FrameError indicates that the frame in the capture is malformed. Frame is
+// the number of the frame counted from 1.
+//
+// It is returned by Reader.Next without Packet, and the reading can be
+// continued after that.
+type FrameError struct {
+	Frame int
+	Time  time.Time
+	Err   error
+}
+
+// Error returns error message with the number of the frame.
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("pcap: malformed frame %d: %s", e.Frame, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// Packet is a TCAP message in a frame with the information of the lower layers.
+//
+// Src and Dst are the addresses and ports of IP/SCTP, which are not available
+// with LinkTypeExportedPDU. OPC and DPC are the point codes in M3UA.
+//
+// Payload is the TCAP message as it is. TCAP or ANSI is set by Reader depending
+// on the variant of the message, and Writer uses Payload, or the one of them
+// marshalled if Payload is empty.
+type Packet struct {
+	Time                time.Time
+	Src, Dst            netip.AddrPort
+	OPC, DPC            uint32
+	SCCPType            string
+	CalledPartyAddress  *params.PartyAddress
+	CallingPartyAddress *params.PartyAddress
+	Payload             []byte
+	TCAP                *tcap.TCAP
+	ANSI                *tcap.ANSITCAP
+}
+
+// Message returns the decoded TCAP message, either *tcap.TCAP or *tcap.ANSITCAP.
+// It returns nil if neither is set.
+func (p *Packet) Message() tcap.Message {
+	switch {
+	case p.TCAP != nil:
+		return p.TCAP
+	case p.ANSI != nil:
+		return p.ANSI
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-sccp/utils"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/pcap"
+)
+
+func partyAddress(called bool, ssn uint8, digits string) *params.PartyAddress {
+	ai := params.NewAddressIndicator(false, true, false, params.GTITTNPESNAI)
+	gt := params.NewGlobalTitle(
+		params.GTITTNPESNAI, params.TranslationType(0), params.NPISDNTelephony,
+		params.ESBCDEven, params.NAIInternationalNumber, utils.MustBCDEncode(digits),
+	)
+	if called {
+		return params.NewCalledPartyAddress(ai, 0, ssn, gt)
+	}
+	return params.NewCallingPartyAddress(ai, 0, ssn, gt)
+}
+
+func testPackets(t *testing.T) []*pcap.Packet {
+	t.Helper()
+	begin, err := tcap.NewBeginInvokeWithDialogue(
+		0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3,
+		[]byte{0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := tcap.NewEndReturnResult(0x11111111, 0, 3, true, nil).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, err := tcap.NewANSIQuery(0x01020304, true,
+		tcap.NewANSIInvoke(1, -1, 0x89, 15, false, true, []byte{0x81, 0x01, 0x00}),
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	return []*pcap.Packet{
+		{
+			Time: ts, OPC: 1, DPC: 2, SCCPType: pcap.SCCPUDT,
+			CalledPartyAddress:  partyAddress(true, 6, "1234567890"),
+			CallingPartyAddress: partyAddress(false, 7, "9876543210"),
+			Payload:             begin,
+		}, {
+			Time: ts.Add(time.Second), OPC: 2, DPC: 1, SCCPType: pcap.SCCPXUDT,
+			CalledPartyAddress:  partyAddress(true, 7, "9876543210"),
+			CallingPartyAddress: partyAddress(false, 6, "1234567890"),
+			Payload:             end,
+		}, {
+			Time: ts.Add(2 * time.Second), OPC: 3, DPC: 4, SCCPType: pcap.SCCPUDT,
+			CalledPartyAddress:  partyAddress(true, 6, "1234567890"),
+			CallingPartyAddress: partyAddress(false, 7, "9876543210"),
+			Payload:             query,
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		description string
+		newWriter   func(io.Writer, pcap.LinkType) (*pcap.Writer, error)
+		linkType    pcap.LinkType
+		src, dst    netip.AddrPort
+	}{
+		{"pcap/Ethernet/IPv4", pcap.NewWriter, pcap.LinkTypeEthernet, netip.MustParseAddrPort("192.0.2.1:2905"), netip.MustParseAddrPort("192.0.2.2:2906")},
+		{"pcap/Raw/IPv6", pcap.NewWriter, pcap.LinkTypeRaw, netip.MustParseAddrPort("[2001:db8::1]:2905"), netip.MustParseAddrPort("[2001:db8::2]:2905")},
+		{"pcapng/Ethernet/IPv6", pcap.NewNgWriter, pcap.LinkTypeEthernet, netip.MustParseAddrPort("[2001:db8::1]:2905"), netip.MustParseAddrPort("[2001:db8::2]:2905")},
+		{"pcapng/IPv4", pcap.NewNgWriter, pcap.LinkTypeIPv4, netip.MustParseAddrPort("192.0.2.1:2905"), netip.MustParseAddrPort("192.0.2.2:2905")},
+		{"pcap/ExportedPDU", pcap.NewWriter, pcap.LinkTypeExportedPDU, netip.AddrPort{}, netip.AddrPort{}},
+		{"pcapng/ExportedPDU", pcap.NewNgWriter, pcap.LinkTypeExportedPDU, netip.AddrPort{}, netip.AddrPort{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := c.newWriter(buf, c.linkType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := testPackets(t)
+			for _, p := range want {
+				p.Src, p.Dst = c.src, c.dst
+				if err := w.WritePacket(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r, err := pcap.NewReader(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, p := range want {
+				got, err := r.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				verify.Values(t, "Time", got.Time.UTC(), p.Time)
+				verify.Values(t, "Src", got.Src, p.Src)
+				verify.Values(t, "Dst", got.Dst, p.Dst)
+				verify.Values(t, "OPC", got.OPC, p.OPC)
+				verify.Values(t, "DPC", got.DPC, p.DPC)
+				verify.Values(t, "SCCPType", got.SCCPType, p.SCCPType)
+				verify.Values(t, "CalledPartyAddress", got.CalledPartyAddress.Address(), p.CalledPartyAddress.Address())
+				verify.Values(t, "CallingPartyAddress", got.CallingPartyAddress.SubsystemNumber, p.CallingPartyAddress.SubsystemNumber)
+				verify.Values(t, "Payload", got.Payload, p.Payload)
+
+				if i < 2 && got.TCAP == nil || i == 2 && got.ANSI == nil {
+					t.Errorf("message %d is not decoded: %v", i, got.Message())
+				}
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWritePacketFromMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := pcap.NewWriter(buf, pcap.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := tcap.NewBeginInvoke(0xdeadbeef, 1, 2, nil)
+	if err := w.WritePacket(&pcap.Packet{TCAP: msg}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pcap.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Src", got.Src, pcap.DefaultSrc)
+	verify.Values(t, "Dst", got.Dst, pcap.DefaultDst)
+	verify.Values(t, "OTID", got.TCAP.OTID(), uint32(0xdeadbeef))
+
+	if err := w.WritePacket(&pcap.Packet{}); err == nil {
+		t.Error("expected error for Packet without message")
+	}
+}
+
+func TestReaderSkipsOtherFrames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := pcap.NewWriter(buf, pcap.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ARP frame
+	arp := append(make([]byte, 12), 0x08, 0x06, 0x00, 0x01)
+	rec := make([]byte, 16)
+	binary.LittleEndian.PutUint32(rec[8:12], uint32(len(arp)))
+	binary.LittleEndian.PutUint32(rec[12:16], uint32(len(arp)))
+	buf.Write(append(rec, arp...))
+
+	if err := w.WritePacket(testPackets(t)[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pcap.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "OTID", got.TCAP.OTID(), uint32(0x11111111))
+}
+
+func TestReaderMalformedFrames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := pcap.NewWriter(buf, pcap.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IPv4 frame truncated in the header
+	ip := append(make([]byte, 12), 0x08, 0x00, 0x45, 0x00)
+	rec := make([]byte, 16)
+	binary.LittleEndian.PutUint32(rec[8:12], uint32(len(ip)))
+	binary.LittleEndian.PutUint32(rec[12:16], uint32(len(ip)))
+	buf.Write(append(rec, ip...))
+
+	if err := w.WritePacket(testPackets(t)[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// frame with the captured length too large to be allocated
+	binary.LittleEndian.PutUint32(rec[8:12], 0xffffffff)
+	buf.Write(rec)
+
+	r, err := pcap.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ferr *pcap.FrameError
+	if _, err := r.Next(); !errors.As(err, &ferr) {
+		t.Fatalf("got %v, want FrameError", err)
+	}
+	verify.Values(t, "Frame", ferr.Frame, 1)
+
+	got, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "OTID", got.TCAP.OTID(), uint32(0x11111111))
+
+	if _, err := r.Next(); err == nil || errors.As(err, &ferr) {
+		t.Errorf("got %v, want error for invalid captured length", err)
+	}
+}
+
+func TestUnsupportedLinkType(t *testing.T) {
+	if _, err := pcap.NewWriter(io.Discard, pcap.LinkTypeLinuxSLL); err == nil {
+		t.Error("expected error for unsupported link type")
+	}
+	if _, err := pcap.NewReader(bytes.NewReader([]byte{1, 2, 3, 4})); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,495 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"time"
+
+	"github.com/wmnsk/go-m3ua/messages"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-sccp"
+	"github.com/wmnsk/go-tcap"
+)
+
+const (
+	ipProtoSCTP   = 132
+	sctpChunkData = 0
+	ppidM3UA      = 3
+
+	magicPcap       = 0xa1b2c3d4
+	magicPcapNano   = 0xa1b23c4d
+	magicPcapng     = 0x0a0d0d0a
+	magicByteOrder  = 0x1a2b3c4d
+	blockTypeIDB    = 1
+	blockTypeSPB    = 3
+	blockTypeEPB    = 6
+	optionTSResol   = 9
+	exportedPDUName = 12
+
+	// maxCapLen is the maximum length of a captured frame, which is the
+	// maximum snapshot length of libpcap.
+	maxCapLen = 262144
+	// maxBlockLen is the maximum length of a pcapng block.
+	maxBlockLen = 16 << 20
+)
+
+// Reader reads TCAP messages from a pcap or pcapng capture.
+type Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+
+	// pcap
+	linkType LinkType
+	nano     bool
+
+	// pcapng
+	ng         bool
+	interfaces []pcapngInterface
+
+	pending []*Packet
+	frames  int
+}
+
+type pcapngInterface struct {
+	linkType LinkType
+	tsResol  time.Duration
+}
+
+// frame is a captured frame. err is set if the block of the frame is read but
+// malformed.
+type frame struct {
+	linkType LinkType
+	time     time.Time
+	data     []byte
+	err      error
+}
+
+// NewReader creates a new Reader, detecting the format of the capture from its header.
+func NewReader(r io.Reader) (*Reader, error) {
+	c := &Reader{r: bufio.NewReader(r)}
+
+	magic, err := c.r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case binary.BigEndian.Uint32(magic) == magicPcapng:
+		c.ng = true
+		return c, nil
+	case binary.LittleEndian.Uint32(magic) == magicPcap:
+		c.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic) == magicPcap:
+		c.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(magic) == magicPcapNano:
+		c.order, c.nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(magic) == magicPcapNano:
+		c.order, c.nano = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("pcap: unknown capture file format: magic=%x", magic)
+	}
+
+	hdr := make([]byte, 24)
+	if _, err := io.ReadFull(c.r, hdr); err != nil {
+		return nil, err
+	}
+	c.linkType = LinkType(c.order.Uint32(hdr[20:24]))
+	return c, nil
+}
+
+// Next returns the next TCAP message in the capture. The frames that do not
+// carry TCAP are skipped. It returns io.EOF at the end of the capture.
+//
+// If the TCAP message cannot be decoded, Next returns the Packet without TCAP
+// and ANSI together with the error. If the frame is malformed, it returns
+// *FrameError without Packet. The reading can be continued after them.
+func (c *Reader) Next() (*Packet, error) {
+	for len(c.pending) == 0 {
+		f, err := c.nextFrame()
+		if err != nil {
+			return nil, err
+		}
+		c.frames++
+		if f.err != nil {
+			return nil, &FrameError{Frame: c.frames, Err: f.err}
+		}
+		if c.pending, err = decapsulate(f); err != nil && !errors.Is(err, errNotTCAP) {
+			return nil, &FrameError{Frame: c.frames, Time: f.time, Err: err}
+		}
+	}
+
+	p := c.pending[0]
+	c.pending = c.pending[1:]
+
+	msg, err := tcap.ParseWithVariant(p.Payload, tcap.VariantAuto)
+	if err != nil {
+		return p, err
+	}
+	switch m := msg.(type) {
+	case *tcap.TCAP:
+		p.TCAP = m
+	case *tcap.ANSITCAP:
+		p.ANSI = m
+	}
+	return p, nil
+}
+
+func (c *Reader) nextFrame() (*frame, error) {
+	if c.ng {
+		return c.nextBlock()
+	}
+
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(c.r, hdr); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	caplen := c.order.Uint32(hdr[8:12])
+	if caplen > maxCapLen {
+		return nil, fmt.Errorf("pcap: invalid captured length: %d", caplen)
+	}
+	data := make([]byte, caplen)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, err
+	}
+
+	sub := time.Duration(c.order.Uint32(hdr[4:8])) * time.Microsecond
+	if c.nano {
+		sub = time.Duration(c.order.Uint32(hdr[4:8]))
+	}
+	return &frame{
+		linkType: c.linkType,
+		time:     time.Unix(int64(c.order.Uint32(hdr[0:4])), int64(sub)),
+		data:     data,
+	}, nil
+}
+
+func (c *Reader) nextBlock() (*frame, error) {
+	for {
+		hdr := make([]byte, 8)
+		if _, err := io.ReadFull(c.r, hdr); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		btype := binary.BigEndian.Uint32(hdr[0:4])
+		if btype == magicPcapng {
+			// the byte order is determined by Section Header Block.
+			bom, err := c.r.Peek(4)
+			if err != nil {
+				return nil, err
+			}
+			c.order = binary.BigEndian
+			if binary.LittleEndian.Uint32(bom) == magicByteOrder {
+				c.order = binary.LittleEndian
+			}
+			c.interfaces = nil
+		} else {
+			btype = c.order.Uint32(hdr[0:4])
+		}
+
+		blen := int(c.order.Uint32(hdr[4:8]))
+		if blen < 12 || blen > maxBlockLen {
+			return nil, fmt.Errorf("pcap: invalid pcapng block length: %d", blen)
+		}
+		body := make([]byte, blen-8)
+		if _, err := io.ReadFull(c.r, body); err != nil {
+			return nil, err
+		}
+		body = body[:len(body)-4]
+
+		switch btype {
+		case blockTypeIDB:
+			if len(body) < 8 {
+				return &frame{err: fmt.Errorf("too short Interface Description Block: %d", len(body))}, nil
+			}
+			c.interfaces = append(c.interfaces, pcapngInterface{
+				linkType: LinkType(c.order.Uint16(body[0:2])),
+				tsResol:  c.parseTSResol(body[8:]),
+			})
+		case blockTypeEPB:
+			if len(body) < 20 {
+				return &frame{err: fmt.Errorf("too short Enhanced Packet Block: %d", len(body))}, nil
+			}
+			id := int(c.order.Uint32(body[0:4]))
+			if id >= len(c.interfaces) {
+				return &frame{err: fmt.Errorf("unknown interface ID: %d", id)}, nil
+			}
+			iface := c.interfaces[id]
+			ts := uint64(c.order.Uint32(body[4:8]))<<32 | uint64(c.order.Uint32(body[8:12]))
+			caplen := int(c.order.Uint32(body[12:16]))
+			if len(body[20:]) < caplen {
+				return &frame{err: fmt.Errorf("captured length exceeds the block: %d", caplen)}, nil
+			}
+			return &frame{
+				linkType: iface.linkType,
+				time:     time.Unix(0, 0).Add(time.Duration(ts) * iface.tsResol),
+				data:     body[20 : 20+caplen],
+			}, nil
+		case blockTypeSPB:
+			if len(body) < 4 || len(c.interfaces) == 0 {
+				return &frame{err: fmt.Errorf("invalid Simple Packet Block")}, nil
+			}
+			return &frame{linkType: c.interfaces[0].linkType, data: body[4:]}, nil
+		}
+	}
+}
+
+// parseTSResol returns the resolution of timestamp in the options of Interface
+// Description Block. Only the resolutions of power of 10 are supported.
+func (c *Reader) parseTSResol(opts []byte) time.Duration {
+	for len(opts) >= 4 {
+		code, l := c.order.Uint16(opts[0:2]), int(c.order.Uint16(opts[2:4]))
+		if code == 0 || len(opts) < 4+l {
+			break
+		}
+		if code == optionTSResol && l == 1 && opts[4]&0x80 == 0 {
+			d := time.Second
+			for i := 0; i < int(opts[4]) && d > 1; i++ {
+				d /= 10
+			}
+			return d
+		}
+		opts = opts[4+(l+3)&^3:]
+	}
+	return time.Microsecond
+}
+
+// decapsulate retrieves the TCAP payloads from the frame, peeling off
+// Ethernet/IP/SCTP/M3UA/SCCP.
+func decapsulate(f *frame) ([]*Packet, error) {
+	if f.linkType == LinkTypeExportedPDU {
+		p, err := decapsulateExportedPDU(f.data)
+		if err != nil {
+			return nil, err
+		}
+		p.Time = f.time
+		return []*Packet{p}, nil
+	}
+
+	b, err := stripLinkLayer(f.linkType, f.data)
+	if err != nil {
+		return nil, err
+	}
+	src, dst, b, err := stripIP(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 12 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	src = netip.AddrPortFrom(src.Addr(), binary.BigEndian.Uint16(b[0:2]))
+	dst = netip.AddrPortFrom(dst.Addr(), binary.BigEndian.Uint16(b[2:4]))
+
+	var packets []*Packet
+	for _, chunk := range sctpUserData(b) {
+		p := &Packet{Time: f.time, Src: src, Dst: dst}
+		if err := stripM3UA(chunk, p); err != nil {
+			continue
+		}
+		packets = append(packets, p)
+	}
+	if len(packets) == 0 {
+		return nil, errNotTCAP
+	}
+	return packets, nil
+}
+
+// decapsulateExportedPDU retrieves the TCAP from the exported PDU of the
+// protocol m3ua, sccp or tcap.
+func decapsulateExportedPDU(b []byte) (*Packet, error) {
+	var proto string
+	for {
+		if len(b) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		tag, l := binary.BigEndian.Uint16(b[0:2]), int(binary.BigEndian.Uint16(b[2:4]))
+		if len(b) < 4+l {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := b[4 : 4+l]
+		b = b[4+l:]
+		if tag == 0 {
+			break
+		}
+		if tag == exportedPDUName {
+			proto = string(trimNull(v))
+		}
+	}
+
+	p := &Packet{}
+	switch proto {
+	case "m3ua":
+		return p, stripM3UA(b, p)
+	case "sccp":
+		return p, stripSCCP(b, p)
+	case "tcap":
+		p.Payload = b
+		return p, nil
+	}
+	return nil, errNotTCAP
+}
+
+func trimNull(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+func stripLinkLayer(linkType LinkType, b []byte) ([]byte, error) {
+	var etherType uint16
+	switch linkType {
+	case LinkTypeEthernet:
+		if len(b) < 14 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		etherType, b = binary.BigEndian.Uint16(b[12:14]), b[14:]
+		for (etherType == 0x8100 || etherType == 0x88a8) && len(b) >= 4 {
+			etherType, b = binary.BigEndian.Uint16(b[2:4]), b[4:]
+		}
+	case LinkTypeLinuxSLL:
+		if len(b) < 16 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		etherType, b = binary.BigEndian.Uint16(b[14:16]), b[16:]
+	case LinkTypeLinuxSLL2:
+		if len(b) < 20 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		etherType, b = binary.BigEndian.Uint16(b[0:2]), b[20:]
+	case LinkTypeNull:
+		if len(b) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return b[4:], nil
+	case LinkTypeRaw, LinkTypeIPv4, LinkTypeIPv6:
+		return b, nil
+	default:
+		return nil, fmt.Errorf("pcap: unsupported link type: %d", linkType)
+	}
+
+	if etherType != 0x0800 && etherType != 0x86dd {
+		return nil, errNotTCAP
+	}
+	return b, nil
+}
+
+// stripIP returns the addresses and the SCTP packet in IPv4 or IPv6 packet.
+func stripIP(b []byte) (src, dst netip.AddrPort, sctp []byte, err error) {
+	if len(b) < 1 {
+		return src, dst, nil, io.ErrUnexpectedEOF
+	}
+
+	switch b[0] >> 4 {
+	case 4:
+		ihl := int(b[0]&0x0f) * 4
+		if len(b) < 20 || len(b) < ihl {
+			return src, dst, nil, io.ErrUnexpectedEOF
+		}
+		// fragments are not reassembled.
+		if b[9] != ipProtoSCTP || binary.BigEndian.Uint16(b[6:8])&0x3fff != 0 {
+			return src, dst, nil, errNotTCAP
+		}
+		total := int(binary.BigEndian.Uint16(b[2:4]))
+		if total < ihl || total > len(b) {
+			total = len(b)
+		}
+		src = netip.AddrPortFrom(netip.AddrFrom4([4]byte(b[12:16])), 0)
+		dst = netip.AddrPortFrom(netip.AddrFrom4([4]byte(b[16:20])), 0)
+		return src, dst, b[ihl:total], nil
+	case 6:
+		if len(b) < 40 {
+			return src, dst, nil, io.ErrUnexpectedEOF
+		}
+		if b[6] != ipProtoSCTP {
+			return src, dst, nil, errNotTCAP
+		}
+		src = netip.AddrPortFrom(netip.AddrFrom16([16]byte(b[8:24])), 0)
+		dst = netip.AddrPortFrom(netip.AddrFrom16([16]byte(b[24:40])), 0)
+		return src, dst, b[40:], nil
+	}
+	return src, dst, nil, errNotTCAP
+}
+
+// sctpUserData returns the user data in the unfragmented DATA chunks carrying M3UA.
+func sctpUserData(b []byte) [][]byte {
+	if len(b) < 12 {
+		return nil
+	}
+
+	var data [][]byte
+	for b = b[12:]; len(b) >= 4; {
+		ctype, flags, l := b[0], b[1], int(binary.BigEndian.Uint16(b[2:4]))
+		if l < 4 || l > len(b) {
+			break
+		}
+		if ctype == sctpChunkData && flags&0x03 == 0x03 && l >= 16 {
+			if ppid := binary.BigEndian.Uint32(b[12:16]); ppid == ppidM3UA || ppid == 0 {
+				data = append(data, b[16:l])
+			}
+		}
+
+		padded := (l + 3) &^ 3
+		if padded > len(b) {
+			break
+		}
+		b = b[padded:]
+	}
+	return data
+}
+
+// stripM3UA sets the point codes and the contents of SCCP in M3UA DATA in p.
+func stripM3UA(b []byte, p *Packet) error {
+	msg, err := messages.Parse(b)
+	if err != nil {
+		return err
+	}
+	data, ok := msg.(*messages.Data)
+	if !ok || data.ProtocolData == nil {
+		return errNotTCAP
+	}
+	pd, err := data.ProtocolData.ProtocolData()
+	if err != nil {
+		return err
+	}
+	if pd.ServiceIndicator != m3params.ServiceIndSCCP {
+		return errNotTCAP
+	}
+
+	p.OPC, p.DPC = pd.OriginatingPointCode, pd.DestinationPointCode
+	return stripSCCP(pd.Data, p)
+}
+
+// stripSCCP sets the addresses and the TCAP in SCCP UDT or XUDT in p.
+func stripSCCP(b []byte, p *Packet) error {
+	msg, err := sccp.ParseMessage(b)
+	if err != nil {
+		return err
+	}
+
+	switch m := msg.(type) {
+	case *sccp.UDT:
+		p.SCCPType = SCCPUDT
+		p.CalledPartyAddress, p.CallingPartyAddress = m.CalledPartyAddress, m.CallingPartyAddress
+		p.Payload = m.Data.Value()
+	case *sccp.XUDT:
+		p.SCCPType = SCCPXUDT
+		p.CalledPartyAddress, p.CallingPartyAddress = m.CalledPartyAddress, m.CallingPartyAddress
+		p.Payload = m.Data.Value()
+	default:
+		return errNotTCAP
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/netip"
+
+	"github.com/wmnsk/go-m3ua/messages"
+	m3params "github.com/wmnsk/go-m3ua/messages/params"
+	"github.com/wmnsk/go-sccp"
+	"github.com/wmnsk/go-sccp/params"
+)
+
+// Default addresses used by Writer when they are not set in Packet.
+var (
+	DefaultSrc = netip.MustParseAddrPort("127.0.0.1:2905")
+	DefaultDst = netip.MustParseAddrPort("127.0.0.2:2905")
+)
+
+const snapLen = 0xffff
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Writer writes TCAP messages into a pcap or pcapng capture.
+//
+// The messages are encapsulated in SCCP UDT (or XUDT), M3UA DATA, SCTP, IP and
+// Ethernet depending on the LinkType. With LinkTypeExportedPDU, M3UA DATA is
+// written as the exported PDU of the protocol "m3ua".
+type Writer struct {
+	w        io.Writer
+	linkType LinkType
+	ng       bool
+
+	tsn uint32
+	ssn uint16
+}
+
+// NewWriter creates a new Writer that writes pcap, writing the file header.
+//
+// linkType should be one of LinkTypeEthernet, LinkTypeRaw, LinkTypeIPv4,
+// LinkTypeIPv6 and LinkTypeExportedPDU.
+func NewWriter(w io.Writer, linkType LinkType) (*Writer, error) {
+	if err := checkLinkType(linkType); err != nil {
+		return nil, err
+	}
+
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], magicPcap)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2)
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], snapLen)
+	binary.LittleEndian.PutUint32(hdr[20:24], uint32(linkType))
+	if _, err := w.Write(hdr); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, linkType: linkType}, nil
+}
+
+// NewNgWriter creates a new Writer that writes pcapng, writing the Section
+// Header Block and the Interface Description Block.
+func NewNgWriter(w io.Writer, linkType LinkType) (*Writer, error) {
+	if err := checkLinkType(linkType); err != nil {
+		return nil, err
+	}
+
+	shb := make([]byte, 28)
+	binary.LittleEndian.PutUint32(shb[0:4], magicPcapng)
+	binary.LittleEndian.PutUint32(shb[4:8], 28)
+	binary.LittleEndian.PutUint32(shb[8:12], magicByteOrder)
+	binary.LittleEndian.PutUint16(shb[12:14], 1)
+	binary.LittleEndian.PutUint64(shb[16:24], 0xffffffffffffffff)
+	binary.LittleEndian.PutUint32(shb[24:28], 28)
+
+	idb := make([]byte, 20)
+	binary.LittleEndian.PutUint32(idb[0:4], blockTypeIDB)
+	binary.LittleEndian.PutUint32(idb[4:8], 20)
+	binary.LittleEndian.PutUint16(idb[8:10], uint16(linkType))
+	binary.LittleEndian.PutUint32(idb[12:16], snapLen)
+	binary.LittleEndian.PutUint32(idb[16:20], 20)
+
+	if _, err := w.Write(append(shb, idb...)); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, linkType: linkType, ng: true}, nil
+}
+
+func checkLinkType(linkType LinkType) error {
+	switch linkType {
+	case LinkTypeEthernet, LinkTypeRaw, LinkTypeIPv4, LinkTypeIPv6, LinkTypeExportedPDU:
+		return nil
+	}
+	return fmt.Errorf("pcap: unsupported link type for writing: %d", linkType)
+}
+
+// WritePacket writes the TCAP message in p as a frame.
+//
+// The zero values in p are replaced with the defaults: DefaultSrc and DefaultDst
+// for the addresses, UDT for SCCPType, and the addresses routed on SSN 0 for
+// the SCCP addresses.
+func (w *Writer) WritePacket(p *Packet) error {
+	payload, err := p.payload()
+	if err != nil {
+		return err
+	}
+
+	m3, err := w.m3ua(p, payload)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch w.linkType {
+	case LinkTypeExportedPDU:
+		data = append([]byte{0, exportedPDUName, 0, 4, 'm', '3', 'u', 'a', 0, 0, 0, 0}, m3...)
+	default:
+		src, dst := p.Src, p.Dst
+		if !src.IsValid() {
+			src = DefaultSrc
+		}
+		if !dst.IsValid() {
+			dst = DefaultDst
+		}
+		if src.Addr().Is4() != dst.Addr().Is4() {
+			return fmt.Errorf("pcap: mixed IP versions: %s, %s", src, dst)
+		}
+		if w.linkType == LinkTypeIPv4 && !src.Addr().Is4() || w.linkType == LinkTypeIPv6 && src.Addr().Is4() {
+			return fmt.Errorf("pcap: IP version does not match the link type: %s", src)
+		}
+
+		data = ipPacket(src.Addr(), dst.Addr(), w.sctpPacket(src.Port(), dst.Port(), m3))
+		if w.linkType == LinkTypeEthernet {
+			etherType := []byte{0x08, 0x00}
+			if !src.Addr().Is4() {
+				etherType = []byte{0x86, 0xdd}
+			}
+			data = append(append(make([]byte, 12), etherType...), data...)
+		}
+	}
+
+	return w.writeFrame(p, data)
+}
+
+func (p *Packet) payload() ([]byte, error) {
+	if len(p.Payload) > 0 {
+		return p.Payload, nil
+	}
+	if m := p.Message(); m != nil {
+		return m.MarshalBinary()
+	}
+	return nil, fmt.Errorf("pcap: no TCAP message in Packet")
+}
+
+// m3ua returns M3UA DATA carrying the payload in SCCP UDT or XUDT.
+func (w *Writer) m3ua(p *Packet, payload []byte) ([]byte, error) {
+	cdpa, cgpa := p.CalledPartyAddress, p.CallingPartyAddress
+	ai := params.NewAddressIndicator(false, true, true, params.GTINoGT)
+	if cdpa == nil {
+		cdpa = params.NewCalledPartyAddress(ai, 0, 0, nil)
+	}
+	if cgpa == nil {
+		cgpa = params.NewCallingPartyAddress(ai, 0, 0, nil)
+	}
+
+	var (
+		sccpb []byte
+		err   error
+	)
+	switch p.SCCPType {
+	case "", SCCPUDT:
+		sccpb, err = sccp.NewUDT(1, false, cdpa, cgpa, payload).MarshalBinary()
+	case SCCPXUDT:
+		sccpb, err = sccp.NewXUDT(1, false, 15, cdpa, cgpa, payload).MarshalBinary()
+	default:
+		return nil, fmt.Errorf("pcap: unsupported SCCP message type: %s", p.SCCPType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pd := m3params.NewProtocolData(p.OPC, p.DPC, m3params.ServiceIndSCCP, 0, 0, 0, sccpb)
+	return messages.NewData(nil, nil, pd, nil).MarshalBinary()
+}
+
+// sctpPacket returns the SCTP packet with a DATA chunk carrying M3UA.
+func (w *Writer) sctpPacket(srcPort, dstPort uint16, m3 []byte) []byte {
+	chunkLen := 16 + len(m3)
+	b := make([]byte, 12+(chunkLen+3)&^3)
+	binary.BigEndian.PutUint16(b[0:2], srcPort)
+	binary.BigEndian.PutUint16(b[2:4], dstPort)
+	binary.BigEndian.PutUint32(b[4:8], 1)
+
+	c := b[12:]
+	c[0], c[1] = sctpChunkData, 0x03
+	binary.BigEndian.PutUint16(c[2:4], uint16(chunkLen))
+	binary.BigEndian.PutUint32(c[4:8], w.tsn)
+	binary.BigEndian.PutUint16(c[10:12], w.ssn)
+	binary.BigEndian.PutUint32(c[12:16], ppidM3UA)
+	copy(c[16:], m3)
+	w.tsn++
+	w.ssn++
+
+	binary.LittleEndian.PutUint32(b[8:12], crc32.Checksum(b, castagnoli))
+	return b
+}
+
+// ipPacket returns the IPv4 or IPv6 packet carrying SCTP.
+func ipPacket(src, dst netip.Addr, sctp []byte) []byte {
+	if src.Is4() {
+		b := make([]byte, 20, 20+len(sctp))
+		b[0] = 0x45
+		binary.BigEndian.PutUint16(b[2:4], uint16(20+len(sctp)))
+		b[6] = 0x40 // don't fragment
+		b[8], b[9] = 64, ipProtoSCTP
+		s, d := src.As4(), dst.As4()
+		copy(b[12:16], s[:])
+		copy(b[16:20], d[:])
+
+		var sum uint32
+		for i := 0; i < 20; i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i:]))
+		}
+		for sum > 0xffff {
+			sum = sum&0xffff + sum>>16
+		}
+		binary.BigEndian.PutUint16(b[10:12], ^uint16(sum))
+		return append(b, sctp...)
+	}
+
+	b := make([]byte, 40, 40+len(sctp))
+	b[0] = 0x60
+	binary.BigEndian.PutUint16(b[4:6], uint16(len(sctp)))
+	b[6], b[7] = ipProtoSCTP, 64
+	s, d := src.As16(), dst.As16()
+	copy(b[8:24], s[:])
+	copy(b[24:40], d[:])
+	return append(b, sctp...)
+}
+
+func (w *Writer) writeFrame(p *Packet, data []byte) error {
+	if !w.ng {
+		hdr := make([]byte, 16)
+		binary.LittleEndian.PutUint32(hdr[0:4], uint32(p.Time.Unix()))
+		binary.LittleEndian.PutUint32(hdr[4:8], uint32(p.Time.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(hdr[8:12], uint32(len(data)))
+		binary.LittleEndian.PutUint32(hdr[12:16], uint32(len(data)))
+		_, err := w.w.Write(append(hdr, data...))
+		return err
+	}
+
+	blen := 32 + (len(data)+3)&^3
+	b := make([]byte, blen)
+	ts := uint64(p.Time.UnixMicro())
+	binary.LittleEndian.PutUint32(b[0:4], blockTypeEPB)
+	binary.LittleEndian.PutUint32(b[4:8], uint32(blen))
+	binary.LittleEndian.PutUint32(b[12:16], uint32(ts>>32))
+	binary.LittleEndian.PutUint32(b[16:20], uint32(ts))
+	binary.LittleEndian.PutUint32(b[20:24], uint32(len(data)))
+	binary.LittleEndian.PutUint32(b[24:28], uint32(len(data)))
+	copy(b[28:], data)
+	binary.LittleEndian.PutUint32(b[blen-4:], uint32(blen))
+	_, err := w.w.Write(b)
+	return err
+}
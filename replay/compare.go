@@ -0,0 +1,151 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wmnsk/go-tcap"
+)
+
+// Diff is a difference of a message from the peer against the recorded one.
+type Diff struct {
+	// Message is the index of the message in the dialogue.
+	Message int
+	// Path is the path of the field in the JSON representation.
+	Path string
+	// Got and Want are the values in JSON, or empty if the field is missing.
+	Got, Want string
+}
+
+// String returns the Diff in a line.
+func (d *Diff) String() string {
+	got, want := d.Got, d.Want
+	if got == "" {
+		got = "<missing>"
+	}
+	if want == "" {
+		want = "<missing>"
+	}
+	return fmt.Sprintf("message %d: %s: got %s, want %s", d.Message, d.Path, got, want)
+}
+
+// Compare compares the messages in their JSON representation of the tcap
+// package, and returns the differences found in the fields not masked.
+//
+// The paths are the keys of the objects and the indexes of the arrays joined
+// with dots, such as "transaction.otid" and "components.0.opCode.local". In
+// the masks, "*" matches any key or index, and a mask matches the fields under
+// the path as well, e.g., "components.*.parameter" masks the parameters of all
+// the components.
+func Compare(n int, got, want *tcap.TCAP, masks ...string) ([]*Diff, error) {
+	g, err := tree(got)
+	if err != nil {
+		return nil, err
+	}
+	w, err := tree(want)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms [][]string
+	for _, m := range masks {
+		ms = append(ms, strings.Split(m, "."))
+	}
+	var diffs []*Diff
+	walk(nil, g, w, func(path []string, g, w any) {
+		for _, m := range ms {
+			if matchMask(m, path) {
+				return
+			}
+		}
+		diffs = append(diffs, &Diff{Message: n, Path: strings.Join(path, "."), Got: text(g), Want: text(w)})
+	})
+	return diffs, nil
+}
+
+func tree(t *tcap.TCAP) (any, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// walk calls f with the path of each leaf or missing field that differs.
+func walk(path []string, g, w any, f func(path []string, g, w any)) {
+	switch wv := w.(type) {
+	case map[string]any:
+		gv, ok := g.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(wv)+len(gv))
+		for k := range wv {
+			keys = append(keys, k)
+		}
+		for k := range gv {
+			if _, ok := wv[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walk(append(path, k), gv[k], wv[k], f)
+		}
+		return
+	case []any:
+		gv, ok := g.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(wv) || i < len(gv); i++ {
+			var ge, we any
+			if i < len(gv) {
+				ge = gv[i]
+			}
+			if i < len(wv) {
+				we = wv[i]
+			}
+			walk(append(path, strconv.Itoa(i)), ge, we, f)
+		}
+		return
+	}
+
+	if text(g) != text(w) {
+		f(append([]string(nil), path...), g, w)
+	}
+}
+
+func matchMask(mask, path []string) bool {
+	if len(mask) > len(path) {
+		return false
+	}
+	for i, m := range mask {
+		if m != "*" && m != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func text(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,297 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package replay
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+// Player replays the dialogues on a connection as one side of them.
+//
+// The dialogues can be played concurrently. The messages from the peer are
+// routed to the dialogue by the DTID, which is the TID of the Side as recorded,
+// and Begin and Unidirectional to the dialogues waiting for them in order, as
+// tcap.Endpoint does. The messages of no dialogue in play are discarded.
+type Player struct {
+	Side Side
+
+	// Masks are the paths of the fields not to compare in the messages from
+	// the peer, e.g., "components.*.parameter". See Compare for the paths.
+	Masks []string
+
+	// Timeout is the time to wait for each message from the peer.
+	// Zero means no timeout.
+	Timeout time.Duration
+
+	conn   transport.Conn
+	once   sync.Once
+	mu     sync.Mutex
+	routes map[string]chan packet
+	begins chan packet
+	done   chan struct{}
+	err    error
+}
+
+// packet is a message received with the address of the peer, which is nil
+// if the connection does not tell it.
+type packet struct {
+	t    *tcap.TCAP
+	addr net.Addr
+}
+
+// NewPlayer creates a new Player that plays the side of the dialogues on conn.
+func NewPlayer(conn transport.Conn, side Side) *Player {
+	return &Player{
+		Side:    side,
+		Timeout: 5 * time.Second,
+		conn:    conn,
+		routes:  map[string]chan packet{},
+		begins:  make(chan packet, 64),
+		done:    make(chan struct{}),
+	}
+}
+
+// Play plays the dialogue, and returns the differences of the messages from
+// the peer against the recorded ones.
+//
+// The messages of the Side are sent with the TID of the peer and the invoke
+// IDs of the invokes from the peer replaced with the ones received in the
+// dialogue, while the TID and the invoke IDs of the Side are kept as recorded.
+// The playing stops when the peer ends or aborts the dialogue earlier than
+// recorded, which is reported as a difference.
+func (p *Player) Play(ctx context.Context, d *Dialogue) ([]*Diff, error) {
+	p.once.Do(func() {
+		go p.read()
+	})
+
+	recv, err := p.route(d)
+	if err != nil {
+		return nil, err
+	}
+	defer p.unroute(recv)
+
+	var (
+		diffs   []*Diff
+		peerTID []byte
+		peer    net.Addr
+		invIDs  = map[string][]byte{}
+	)
+	for i, m := range d.Messages {
+		want, err := m.TCAP()
+		if err != nil {
+			return diffs, fmt.Errorf("replay: message %d: %w", i, err)
+		}
+
+		if (p.Side == Initiator) == m.FromInitiator {
+			if peerTID != nil {
+				rewrite(&want.Transaction.DestTransactionID, peerTID)
+			}
+			if want.Components != nil {
+				for _, c := range want.Components.Component {
+					id := &c.LinkedID
+					if c.Type.Code() != tcap.Invoke {
+						id = &c.InvokeID
+					}
+					if *id == nil {
+						continue
+					}
+					if v, ok := invIDs[hex.EncodeToString((*id).Value)]; ok {
+						rewrite(id, v)
+					}
+				}
+			}
+
+			b, err := want.MarshalBinary()
+			if err != nil {
+				return diffs, fmt.Errorf("replay: message %d: %w", i, err)
+			}
+			if err := p.write(b, peer); err != nil {
+				return diffs, err
+			}
+			continue
+		}
+
+		ch := recv
+		if i == 0 {
+			ch = p.begins
+		}
+		pkt, err := p.receive(ctx, ch)
+		if err != nil {
+			return diffs, fmt.Errorf("replay: message %d: %w", i, err)
+		}
+		if pkt.addr != nil {
+			peer = pkt.addr
+		}
+		got := pkt.t
+
+		// the allocations of the peer are learned and normalized to the
+		// recorded ones before comparing.
+		if gid, wid := got.Transaction.OrigTransactionID, want.Transaction.OrigTransactionID; gid != nil && wid != nil {
+			peerTID = gid.Value
+			gid.Value = wid.Value
+		}
+		if got.Components != nil && want.Components != nil {
+			for j, gc := range got.Components.Component {
+				if j >= len(want.Components.Component) {
+					break
+				}
+				wc := want.Components.Component[j]
+				if gc.Type.Code() != tcap.Invoke || wc.Type.Code() != tcap.Invoke || gc.InvokeID == nil || wc.InvokeID == nil {
+					continue
+				}
+				invIDs[hex.EncodeToString(wc.InvokeID.Value)] = gc.InvokeID.Value
+				gc.InvokeID.Value = wc.InvokeID.Value
+			}
+		}
+
+		ds, err := Compare(i, got, want, p.Masks...)
+		if err != nil {
+			return diffs, err
+		}
+		diffs = append(diffs, ds...)
+
+		switch got.Transaction.Type.Code() {
+		case tcap.End, tcap.Abort:
+			if i < len(d.Messages)-1 {
+				return diffs, nil
+			}
+		}
+	}
+	return diffs, nil
+}
+
+// rewrite replaces the value of the IE. The lengths of the enclosing parts
+// are computed when the message is marshalled.
+func rewrite(ie **tcap.IE, v []byte) {
+	*ie = tcap.NewIE((*ie).Tag, append([]byte(nil), v...))
+}
+
+// ErrClosed is returned when the connection of Player is closed.
+var ErrClosed = errors.New("replay: connection closed")
+
+// write sends b to the peer at addr if the connection has WriteTo, e.g.,
+// transport.M3UA accepted without the remote address.
+func (p *Player) write(b []byte, addr net.Addr) error {
+	var err error
+	if pc, ok := p.conn.(packetConn); ok && addr != nil {
+		_, err = pc.WriteTo(b, addr)
+	} else {
+		_, err = p.conn.Write(b)
+	}
+	return err
+}
+
+// route registers the dialogue to receive the messages whose DTID is the TID
+// of the Side, and returns the channel to receive them.
+func (p *Player) route(d *Dialogue) (chan packet, error) {
+	key := ""
+	for _, m := range d.Messages {
+		if (p.Side == Initiator) != m.FromInitiator {
+			continue
+		}
+		t, err := m.TCAP()
+		if err != nil {
+			return nil, err
+		}
+		if t.Transaction != nil && t.Transaction.OrigTransactionID != nil {
+			key = tidKey(t.Transaction.OrigTransactionID)
+			break
+		}
+	}
+
+	ch := make(chan packet, 16)
+	if key == "" {
+		return ch, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.routes[key]; ok {
+		return nil, fmt.Errorf("replay: TID %s is in play", key)
+	}
+	p.routes[key] = ch
+	return ch, nil
+}
+
+func (p *Player) unroute(ch chan packet) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, c := range p.routes {
+		if c == ch {
+			delete(p.routes, key)
+		}
+	}
+}
+
+func (p *Player) read() {
+	defer close(p.done)
+	pc, _ := p.conn.(packetConn)
+	buf := make([]byte, 0xffff)
+	for {
+		var (
+			pkt packet
+			n   int
+			err error
+		)
+		if pc != nil {
+			n, pkt.addr, err = pc.ReadFrom(buf)
+		} else {
+			n, err = p.conn.Read(buf)
+		}
+		if err != nil {
+			p.err = err
+			return
+		}
+
+		t, err := (&Message{Payload: append([]byte(nil), buf[:n]...)}).TCAP()
+		if err != nil || t.Transaction == nil {
+			continue
+		}
+		pkt.t = t
+
+		ch := p.begins
+		if dtid := t.Transaction.DestTransactionID; dtid != nil {
+			p.mu.Lock()
+			ch = p.routes[tidKey(dtid)]
+			p.mu.Unlock()
+		}
+		if ch == nil {
+			continue
+		}
+		// the message is discarded rather than blocking the others if the
+		// dialogue does not receive it.
+		select {
+		case ch <- pkt:
+		default:
+		}
+	}
+}
+
+func (p *Player) receive(ctx context.Context, ch chan packet) (packet, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	select {
+	case pkt := <-ch:
+		return pkt, nil
+	case <-p.done:
+		return packet{}, fmt.Errorf("%w: %w", ErrClosed, p.err)
+	case <-ctx.Done():
+		return packet{}, ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package replay
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+// Recorder is a transport.Conn that records the messages read from and written
+// to the connection it wraps, in the format read by ReadJSON.
+//
+// The messages that cannot be parsed are passed through without being recorded.
+type Recorder struct {
+	conn transport.Conn
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder creates a new Recorder that writes the messages on conn to w.
+func NewRecorder(conn transport.Conn, w io.Writer) *Recorder {
+	return &Recorder{conn: conn, enc: json.NewEncoder(w)}
+}
+
+// Read reads a message from the connection and records it.
+func (r *Recorder) Read(b []byte) (int, error) {
+	n, err := r.conn.Read(b)
+	if err == nil {
+		r.record(b[:n])
+	}
+	return n, err
+}
+
+// ReadFrom is the same as Read, but also returns the address of the peer if
+// the connection has ReadFrom, e.g., transport.M3UA.
+func (r *Recorder) ReadFrom(b []byte) (int, net.Addr, error) {
+	pc, ok := r.conn.(packetConn)
+	if !ok {
+		n, err := r.Read(b)
+		return n, nil, err
+	}
+	n, addr, err := pc.ReadFrom(b)
+	if err == nil {
+		r.record(b[:n])
+	}
+	return n, addr, err
+}
+
+// WriteTo is the same as Write, but sends the message to addr if the
+// connection has WriteTo.
+func (r *Recorder) WriteTo(b []byte, addr net.Addr) (int, error) {
+	pc, ok := r.conn.(packetConn)
+	if !ok || addr == nil {
+		return r.Write(b)
+	}
+	n, err := pc.WriteTo(b, addr)
+	if err == nil {
+		r.record(b)
+	}
+	return n, err
+}
+
+// Write writes a message to the connection and records it.
+func (r *Recorder) Write(b []byte) (int, error) {
+	n, err := r.conn.Write(b)
+	if err == nil {
+		r.record(b)
+	}
+	return n, err
+}
+
+// Close closes the connection.
+func (r *Recorder) Close() error {
+	return r.conn.Close()
+}
+
+func (r *Recorder) record(b []byte) {
+	t, err := (&Message{Payload: b}).TCAP()
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(&record{Time: time.Now(), Message: t})
+}
+
+// packetConn is the connection that reads and writes the messages with the
+// address of the peer, such as transport.M3UA.
+type packetConn interface {
+	ReadFrom(b []byte) (int, net.Addr, error)
+	WriteTo(b []byte, addr net.Addr) (int, error)
+}
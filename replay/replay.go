@@ -0,0 +1,288 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package replay records TCAP dialogues and replays them against a system under
+test, acting as either side of them.
+
+A Recording is built from the messages in the order they are captured, from a
+pcap/pcapng file, from JSON, or with Recorder that records the messages passing
+through a connection. The messages are grouped into the dialogues by the
+transaction IDs.
+
+Player replays a Dialogue on a connection as the initiator or the responder.
+The messages of its side are sent with the transaction IDs and the invoke IDs
+rewritten to the ones allocated by the peer, and the messages from the peer are
+compared with the recorded ones. The differences are reported with the paths
+in the JSON representation of the tcap package, such as "components.0.opCode",
+and can be ignored with the masks of the paths.
+
+Only ITU-T TCAP is supported.
+*/
+package replay
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/pcap"
+)
+
+// Side is the side of the dialogue.
+type Side int
+
+// Side definitions.
+const (
+	Initiator Side = iota
+	Responder
+)
+
+// String returns the name of Side.
+func (s Side) String() string {
+	if s == Initiator {
+		return "initiator"
+	}
+	return "responder"
+}
+
+// Message is a message in the recorded dialogue.
+type Message struct {
+	Time          time.Time
+	FromInitiator bool
+	Payload       []byte
+}
+
+// TCAP returns a new TCAP parsed from the Payload, with the Payload of
+// Transaction and Dialogue and the contents of the results kept as parsed
+// cleared so that it can be marshalled again.
+func (m *Message) TCAP() (*tcap.TCAP, error) {
+	t, err := tcap.Parse(m.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if t.Dialogue != nil || t.Components != nil {
+		t.Transaction.Payload = nil
+	}
+	if t.Dialogue != nil && t.Components != nil {
+		t.Dialogue.Payload = nil
+	}
+	if t.Components != nil {
+		for _, c := range t.Components.Component {
+			if c.ResultRetres != nil && c.OperationCode != nil {
+				c.ResultRetres.Value = nil
+			}
+		}
+	}
+	return t, nil
+}
+
+// Dialogue is a recorded dialogue.
+type Dialogue struct {
+	Messages []*Message
+}
+
+// Recording is the dialogues recorded.
+type Recording struct {
+	Dialogues []*Dialogue
+
+	// the dialogues in progress by the TIDs of the initiator and the responder.
+	initiators map[string]*Dialogue
+	responders map[string]*Dialogue
+}
+
+// NewRecording creates a new empty Recording.
+func NewRecording() *Recording {
+	return &Recording{initiators: map[string]*Dialogue{}, responders: map[string]*Dialogue{}}
+}
+
+// ErrUnknownDialogue indicates that the message does not belong to any dialogue
+// begun in the Recording.
+var ErrUnknownDialogue = errors.New("replay: message of unknown dialogue")
+
+// Add adds the TCAP message in b to the Recording.
+//
+// The message is added to the dialogue that it belongs to, or a new dialogue
+// if it is Begin or Unidirectional. ErrUnknownDialogue is returned if the
+// dialogue has not begun in the Recording.
+func (r *Recording) Add(b []byte, at time.Time) error {
+	t, err := tcap.Parse(b)
+	if err != nil {
+		return err
+	}
+	if t.Transaction == nil {
+		return fmt.Errorf("replay: no Transaction Portion")
+	}
+
+	m := &Message{Time: at, Payload: append([]byte(nil), b...)}
+	otid, dtid := tidKey(t.Transaction.OrigTransactionID), tidKey(t.Transaction.DestTransactionID)
+
+	var d *Dialogue
+	switch t.Transaction.Type.Code() {
+	case tcap.Unidirectional:
+		m.FromInitiator = true
+		r.Dialogues = append(r.Dialogues, &Dialogue{Messages: []*Message{m}})
+		return nil
+	case tcap.Begin:
+		m.FromInitiator = true
+		d = &Dialogue{}
+		r.Dialogues = append(r.Dialogues, d)
+		r.initiators[otid] = d
+	default:
+		var ok bool
+		if d, ok = r.initiators[dtid]; ok {
+			if otid != "" {
+				r.responders[otid] = d
+			}
+		} else if d, ok = r.responders[dtid]; ok {
+			m.FromInitiator = true
+		} else {
+			return ErrUnknownDialogue
+		}
+	}
+	d.Messages = append(d.Messages, m)
+
+	switch t.Transaction.Type.Code() {
+	case tcap.End, tcap.Abort:
+		for k, v := range r.initiators {
+			if v == d {
+				delete(r.initiators, k)
+			}
+		}
+		for k, v := range r.responders {
+			if v == d {
+				delete(r.responders, k)
+			}
+		}
+	}
+	return nil
+}
+
+func tidKey(ie *tcap.IE) string {
+	if ie == nil {
+		return ""
+	}
+	return hex.EncodeToString(ie.Value)
+}
+
+// ReadPcap reads the Recording from the pcap or pcapng capture.
+//
+// The messages of the dialogues begun before the capture, the ANSI TCAP
+// messages and the malformed frames are skipped.
+func ReadPcap(rd io.Reader) (*Recording, error) {
+	c, err := pcap.NewReader(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRecording()
+	for {
+		pkt, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return r, nil
+		}
+		var ferr *pcap.FrameError
+		if errors.As(err, &ferr) {
+			continue
+		}
+		if pkt == nil {
+			return nil, err
+		}
+		if pkt.TCAP == nil {
+			continue
+		}
+		if err := r.Add(pkt.Payload, pkt.Time); err != nil && !errors.Is(err, ErrUnknownDialogue) {
+			return nil, err
+		}
+	}
+}
+
+// record is the JSON representation of a message in the Recording.
+type record struct {
+	Time    time.Time  `json:"time,omitzero"`
+	Message *tcap.TCAP `json:"message"`
+}
+
+// ReadJSON reads the Recording from the JSON array of the messages, or the
+// sequence of them such as the one written by Recorder. Each message is an
+// object with "time" and "message" in the JSON representation of the tcap
+// package.
+//
+//	{"time": "2024-01-01T00:00:00Z", "message": {"transaction": {"type": "begin", ...}, ...}}
+//
+// The messages of the dialogues begun before the first message are skipped.
+func ReadJSON(rd io.Reader) (*Recording, error) {
+	br := bufio.NewReader(rd)
+	var records []*record
+	if isJSONArray(br) {
+		if err := json.NewDecoder(br).Decode(&records); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(br)
+		for {
+			rec := &record{}
+			if err := dec.Decode(rec); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			records = append(records, rec)
+		}
+	}
+
+	r := NewRecording()
+	for i, rec := range records {
+		if rec.Message == nil {
+			return nil, fmt.Errorf("replay: message %d: no message", i)
+		}
+		b, err := rec.Message.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("replay: message %d: %w", i, err)
+		}
+		if err := r.Add(b, rec.Time); err != nil && !errors.Is(err, ErrUnknownDialogue) {
+			return nil, fmt.Errorf("replay: message %d: %w", i, err)
+		}
+	}
+	return r, nil
+}
+
+func isJSONArray(br *bufio.Reader) bool {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		_ = br.UnreadByte()
+		return c == '['
+	}
+}
+
+// WriteJSON writes the messages in the Recording to w, one message in a line,
+// dialogue by dialogue.
+func (r *Recording) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, d := range r.Dialogues {
+		for _, m := range d.Messages {
+			t, err := m.TCAP()
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(&record{Time: m.Time, Message: t}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
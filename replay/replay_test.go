@@ -0,0 +1,482 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/pcap"
+	"github.com/wmnsk/go-tcap/replay"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func marshal(t *testing.T, tx *tcap.Transaction, d *tcap.Dialogue, comps ...*tcap.Component) []byte {
+	t.Helper()
+	m := &tcap.TCAP{Transaction: tx, Dialogue: d}
+	if len(comps) > 0 {
+		m.Components = tcap.NewComponents(comps...)
+	}
+	m.SetLength()
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// dialogue is a recorded dialogue in which the responder invokes an operation
+// linked to the one invoked by the initiator.
+func dialogue(t *testing.T, otid, dtid uint32) [][]byte {
+	t.Helper()
+	return [][]byte{
+		marshal(t, tcap.NewBegin(otid, []byte{}),
+			tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQ(1, tcap.LocationCancellationContext, 3), []byte{}),
+			tcap.NewInvoke(1, -1, 2, true, nil)),
+		marshal(t, tcap.NewContinue(dtid, otid, []byte{}), nil, tcap.NewInvoke(5, 1, 3, true, nil)),
+		marshal(t, tcap.NewContinue(otid, dtid, []byte{}), nil, tcap.NewReturnResult(5, 3, true, true, nil)),
+		marshal(t, tcap.NewEnd(otid, []byte{}), nil, tcap.NewReturnResult(1, 2, true, true, nil)),
+	}
+}
+
+func record(t *testing.T, msgs ...[]byte) *replay.Recording {
+	t.Helper()
+	r := replay.NewRecording()
+	for _, m := range msgs {
+		if err := r.Add(m, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func sides(d *replay.Dialogue) []bool {
+	var s []bool
+	for _, m := range d.Messages {
+		s = append(s, m.FromInitiator)
+	}
+	return s
+}
+
+func TestRecording(t *testing.T) {
+	a, b := dialogue(t, 0x11111111, 0x22222222), dialogue(t, 0x33333333, 0x44444444)
+	r := record(t, a[0], b[0], a[1], b[1], b[2], a[2], a[3], b[3])
+
+	verify.Values(t, "dialogues", len(r.Dialogues), 2)
+	for i, d := range r.Dialogues {
+		verify.Values(t, "sides", sides(d), []bool{true, false, true, false})
+		verify.Values(t, "first", d.Messages[0].Payload, [][]byte{a[0], b[0]}[i])
+	}
+
+	// the TIDs are released on End.
+	if err := r.Add(a[2], time.Time{}); !errors.Is(err, replay.ErrUnknownDialogue) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	r := record(t, dialogue(t, 0x11111111, 0x22222222)...)
+	buf := &bytes.Buffer{}
+	if err := r.WriteJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	array := append(append([]byte("[\n"), bytes.Join(lines, []byte(",\n"))...), ']')
+
+	cases := []struct {
+		description string
+		in          []byte
+		dialogues   int
+	}{
+		{"lines", buf.Bytes(), 1},
+		{"array", array, 1},
+		{"without Begin", bytes.Join(lines[1:], []byte("\n")), 0},
+	}
+	for _, c := range cases {
+		got, err := replay.ReadJSON(bytes.NewReader(c.in))
+		if err != nil {
+			t.Fatalf("%s: %v", c.description, err)
+		}
+		verify.Values(t, c.description, len(got.Dialogues), c.dialogues)
+		if c.dialogues > 0 {
+			verify.Values(t, c.description, got.Dialogues[0].Messages, r.Dialogues[0].Messages)
+		}
+	}
+
+	if _, err := replay.ReadJSON(bytes.NewReader([]byte(`{"time": "2024-01-01T00:00:00Z"}`))); err == nil {
+		t.Error("no error with no message")
+	}
+}
+
+func TestReadPcap(t *testing.T) {
+	msgs := dialogue(t, 0x11111111, 0x22222222)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	buf := &bytes.Buffer{}
+	w, err := pcap.NewWriter(buf, pcap.LinkTypeIPv4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range msgs {
+		if err := w.WritePacket(&pcap.Packet{Time: ts.Add(time.Duration(i) * time.Second), Payload: m}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := replay.ReadPcap(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "dialogues", len(r.Dialogues), 1)
+	d := r.Dialogues[0]
+	verify.Values(t, "sides", sides(d), []bool{true, false, true, false})
+	verify.Values(t, "payload", d.Messages[3].Payload, msgs[3])
+	verify.Values(t, "time", d.Messages[3].Time.Equal(ts.Add(3*time.Second)), true)
+}
+
+// peer plays a side of the dialogue with the Endpoint, which allocates the
+// TIDs at random, and the invoke ID given to its invoke.
+type peer struct {
+	ep     *tcap.Endpoint
+	invID  int
+	opCode int
+}
+
+func newPeer(t *testing.T, conn transport.Conn, invID, opCode int) *peer {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	p := &peer{ep: tcap.NewEndpoint(conn), invID: invID, opCode: opCode}
+	go p.ep.Serve(ctx)
+	return p
+}
+
+func invokeID(m *tcap.TCAP) int {
+	c := m.Components.Component[0]
+	if c.Type.Code() == tcap.Invoke && c.LinkedID != nil {
+		return int(c.LinkedID.Value[0])
+	}
+	return int(c.InvID())
+}
+
+// respond plays the responder, and returns the invoke IDs it received.
+func (p *peer) respond(ctx context.Context) ([]int, error) {
+	s, err := p.ep.Accept(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Receive(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.Send(&tcap.TCAP{
+		Transaction: tcap.NewContinue(0, 0, []byte{}),
+		Components:  tcap.NewComponents(tcap.NewInvoke(p.invID, 1, p.opCode, true, nil)),
+	}); err != nil {
+		return nil, err
+	}
+	m, err := s.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(&tcap.TCAP{
+		Transaction: tcap.NewEnd(0, []byte{}),
+		Components:  tcap.NewComponents(tcap.NewReturnResult(1, 2, true, true, nil)),
+	}); err != nil {
+		return nil, err
+	}
+	return []int{invokeID(m)}, nil
+}
+
+// initiate plays the initiator, and returns the invoke IDs it received.
+func (p *peer) initiate(ctx context.Context) ([]int, error) {
+	s, err := p.ep.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(&tcap.TCAP{
+		Transaction: tcap.NewBegin(0, []byte{}),
+		Dialogue:    tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQ(1, tcap.LocationCancellationContext, 3), []byte{}),
+		Components:  tcap.NewComponents(tcap.NewInvoke(p.invID, -1, 2, true, nil)),
+	}); err != nil {
+		return nil, err
+	}
+	m, err := s.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int{invokeID(m)}
+	if err := s.Send(&tcap.TCAP{
+		Transaction: tcap.NewContinue(0, 0, []byte{}),
+		Components:  tcap.NewComponents(tcap.NewReturnResult(int(m.Components.Component[0].InvID()), p.opCode, true, true, nil)),
+	}); err != nil {
+		return nil, err
+	}
+	if m, err = s.Receive(ctx); err != nil {
+		return nil, err
+	}
+	return append(ids, invokeID(m)), nil
+}
+
+func TestPlay(t *testing.T) {
+	cases := []struct {
+		description string
+		side        replay.Side
+		invID       int
+		opCode      int
+		masks       []string
+		wantIDs     []int
+		wantDiffs   []string
+	}{
+		{
+			"initiator", replay.Initiator, 9, 3, nil,
+			[]int{9}, nil,
+		}, {
+			"initiator with differences", replay.Initiator, 9, 4, nil,
+			[]int{9}, []string{"message 1: components.0.opCode.local: got 4, want 3"},
+		}, {
+			"responder", replay.Responder, 7, 3, nil,
+			[]int{7, 7}, nil,
+		}, {
+			"responder with differences", replay.Responder, 7, 4, nil,
+			[]int{7, 7}, []string{"message 2: components.0.opCode.local: got 4, want 3"},
+		}, {
+			"responder with masks", replay.Responder, 7, 4, []string{"components.*.opCode"},
+			[]int{7, 7}, nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r := record(t, dialogue(t, 0x11111111, 0x22222222)...)
+			conn, peerConn := transport.Pipe()
+			p := newPeer(t, peerConn, c.invID, c.opCode)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			type result struct {
+				ids []int
+				err error
+			}
+			done := make(chan result, 1)
+			go func() {
+				var res result
+				if c.side == replay.Initiator {
+					res.ids, res.err = p.respond(ctx)
+				} else {
+					res.ids, res.err = p.initiate(ctx)
+				}
+				done <- res
+			}()
+
+			player := replay.NewPlayer(conn, c.side)
+			player.Masks = c.masks
+			diffs, err := player.Play(ctx, r.Dialogues[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := <-done
+			if res.err != nil {
+				t.Fatal(res.err)
+			}
+
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			verify.Values(t, "diffs", got, c.wantDiffs)
+			verify.Values(t, "invoke IDs", res.ids, c.wantIDs)
+		})
+	}
+}
+
+func TestPlayConcurrently(t *testing.T) {
+	tids, opCodes := []uint32{0x11111111, 0x33333333}, []int{2, 3}
+	var msgs [][]byte
+	for i, tid := range tids {
+		msgs = append(msgs,
+			marshal(t, tcap.NewBegin(tid, []byte{}), nil, tcap.NewInvoke(1, -1, opCodes[i], true, nil)),
+			marshal(t, tcap.NewEnd(tid, []byte{}), nil, tcap.NewReturnResult(1, opCodes[i], true, true, nil)),
+		)
+	}
+	r := record(t, msgs...)
+	conn, peerConn := transport.Pipe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the peer answers the dialogues in the reverse order of the Begins.
+	go func() {
+		var ends [][]byte
+		buf := make([]byte, 1024)
+		for range tids {
+			n, err := peerConn.Read(buf)
+			if err != nil {
+				return
+			}
+			m, err := tcap.Parse(buf[:n])
+			if err != nil {
+				return
+			}
+			for i, tid := range tids {
+				if m.OTID() == tid {
+					ends = append([][]byte{msgs[i*2+1]}, ends...)
+				}
+			}
+		}
+		for _, b := range ends {
+			if _, err := peerConn.Write(b); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := replay.NewPlayer(conn, replay.Initiator)
+	errs := make(chan error, len(tids))
+	for _, d := range r.Dialogues {
+		go func() {
+			diffs, err := player.Play(ctx, d)
+			if err == nil && len(diffs) > 0 {
+				err = errors.New(diffs[0].String())
+			}
+			errs <- err
+		}()
+	}
+	for range tids {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPlayEndedEarly(t *testing.T) {
+	r := record(t, dialogue(t, 0x11111111, 0x22222222)...)
+	conn, peerConn := transport.Pipe()
+	ep := tcap.NewEndpoint(peerConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go ep.Serve(ctx)
+	go func() {
+		s, err := ep.Accept(ctx)
+		if err != nil {
+			return
+		}
+		_ = s.Send(&tcap.TCAP{Transaction: tcap.NewAbort(0, tcap.UnrecognizedMessageType, []byte{})})
+	}()
+
+	diffs, err := replay.NewPlayer(conn, replay.Initiator).Play(ctx, r.Dialogues[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.Path)
+	}
+	verify.Values(t, "paths", got, []string{"components", "transaction.otid", "transaction.pAbortCause", "transaction.type"})
+}
+
+func TestPlayTimeout(t *testing.T) {
+	r := record(t, dialogue(t, 0x11111111, 0x22222222)...)
+	conn, _ := transport.Pipe()
+	p := replay.NewPlayer(conn, replay.Initiator)
+	p.Timeout = 10 * time.Millisecond
+	if _, err := p.Play(context.Background(), r.Dialogues[0]); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	conn.Close()
+	if _, err := p.Play(context.Background(), r.Dialogues[0]); err == nil {
+		t.Error("no error on closed connection")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	msgs := dialogue(t, 0x11111111, 0x22222222)
+	parse := func(b []byte) *tcap.TCAP {
+		m, err := (&replay.Message{Payload: b}).TCAP()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	cases := []struct {
+		masks []string
+		want  []string
+	}{
+		{nil, []string{
+			`message 0: components.0.invokeID: got 5, want 1`,
+			`message 0: components.0.linkedID: got 1, want <missing>`,
+			`message 0: components.0.opCode.local: got 3, want 2`,
+			`message 0: dialogue: got <missing>, want {"oid":"0.0.17.773.1.1.1","pdu":{"applicationContext":{"name":"locationCancellationContext","oid":"0.4.0.0.1.0.2.3"},"protocolVersion":1,"type":"AARQ"}}`,
+			`message 0: transaction.dtid: got "11111111", want <missing>`,
+			`message 0: transaction.otid: got "22222222", want "11111111"`,
+			`message 0: transaction.type: got "continue", want "begin"`,
+		}},
+		{[]string{"components.*", "dialogue", "transaction"}, nil},
+		{[]string{"components.0.opCode", "components.*.linkedID", "transaction", "dialogue.pdu"}, []string{
+			`message 0: components.0.invokeID: got 5, want 1`,
+			`message 0: dialogue: got <missing>, want {"oid":"0.0.17.773.1.1.1","pdu":{"applicationContext":{"name":"locationCancellationContext","oid":"0.4.0.0.1.0.2.3"},"protocolVersion":1,"type":"AARQ"}}`,
+		}},
+	}
+	for _, c := range cases {
+		diffs, err := replay.Compare(0, parse(msgs[1]), parse(msgs[0]), c.masks...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range diffs {
+			got = append(got, d.String())
+		}
+		verify.Values(t, "diffs", got, c.want)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	msgs := dialogue(t, 0x11111111, 0x22222222)
+	conn, peerConn := transport.Pipe()
+	buf := &bytes.Buffer{}
+	rec := replay.NewRecorder(conn, buf)
+
+	for i, m := range msgs {
+		if i%2 == 0 {
+			if _, err := rec.Write(m); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := peerConn.Read(make([]byte, 0xffff)); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if _, err := peerConn.Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.Read(make([]byte, 0xffff)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not recorded.
+	if _, err := rec.Write([]byte{0xff}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := replay.ReadJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "dialogues", len(r.Dialogues), 1)
+	for i, m := range r.Dialogues[0].Messages {
+		verify.Values(t, "payload", m.Payload, msgs[i])
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package scenario
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+)
+
+// TCAP compiles the Message into TCAP.
+func (m *Message) TCAP() (*tcap.TCAP, error) {
+	tx, err := m.transaction()
+	if err != nil {
+		return nil, err
+	}
+	t := &tcap.TCAP{Transaction: tx}
+
+	if m.Dialogue != nil {
+		pdu, err := m.Dialogue.pdu()
+		if err != nil {
+			return nil, err
+		}
+		oid := tcap.DialogueAsID
+		if tx.Type.Code() == tcap.Unidirectional {
+			oid = tcap.UnidialogueAsID
+		}
+		t.Dialogue = tcap.NewDialogue(oid, 1, pdu, []byte{})
+	}
+
+	if len(m.Components) > 0 {
+		comps := make([]*tcap.Component, len(m.Components))
+		for i, c := range m.Components {
+			if comps[i], err = c.component(); err != nil {
+				return nil, fmt.Errorf("component %d: %w", i, err)
+			}
+		}
+		t.Components = tcap.NewComponents(comps...)
+	}
+
+	t.SetLength()
+	return t, nil
+}
+
+func (m *Message) transaction() (*tcap.Transaction, error) {
+	mtype, ok := tcap.MessageTypeCode(m.Type)
+	if !ok {
+		return nil, &InvalidFieldError{Field: "type", Value: m.Type}
+	}
+	tx := &tcap.Transaction{Type: tcap.NewApplicationWideConstructorTag(mtype), Payload: []byte{}}
+
+	var err error
+	switch mtype {
+	case tcap.Begin, tcap.Continue:
+		if tx.OrigTransactionID, err = tid(8, "otid", m.OTID); err != nil {
+			return nil, err
+		}
+	default:
+		if m.OTID != "" {
+			return nil, &InvalidFieldError{Field: "otid", Value: m.OTID}
+		}
+	}
+	switch mtype {
+	case tcap.End, tcap.Continue, tcap.Abort:
+		if tx.DestTransactionID, err = tid(9, "dtid", m.DTID); err != nil {
+			return nil, err
+		}
+	default:
+		if m.DTID != "" {
+			return nil, &InvalidFieldError{Field: "dtid", Value: m.DTID}
+		}
+	}
+
+	if c := m.AbortCause; c != nil {
+		if mtype != tcap.Abort || *c < 0 || *c > 0xff {
+			return nil, &InvalidFieldError{Field: "abortCause", Value: strconv.Itoa(*c)}
+		}
+		tx.PAbortCause = tcap.NewIE(tcap.NewApplicationWidePrimitiveTag(10), []byte{uint8(*c)})
+	}
+
+	tx.SetLength()
+	return tx, nil
+}
+
+func tid(tag int, field, s string) (*tcap.IE, error) {
+	if s == "" {
+		return nil, &MissingFieldError{Field: field}
+	}
+	v, err := hex.DecodeString(s)
+	if err != nil || len(v) < 1 || len(v) > 4 {
+		return nil, &InvalidFieldError{Field: field, Value: s}
+	}
+	return tcap.NewIE(tcap.NewApplicationWidePrimitiveTag(tag), v), nil
+}
+
+func (d *Dialogue) pdu() (*tcap.DialoguePDU, error) {
+	dtype, ok := tcap.DialogueTypeCode(d.Type)
+	if !ok {
+		return nil, &InvalidFieldError{Field: "dialogue type", Value: d.Type}
+	}
+
+	var userinfo []*tcap.IE
+	if len(d.UserInformation) > 0 {
+		var b []byte
+		for _, v := range d.UserInformation {
+			bv, err := v.BERValue()
+			if err != nil {
+				return nil, err
+			}
+			b = bv.AppendTo(b)
+		}
+		userinfo = append(userinfo, &tcap.IE{Value: b})
+	}
+
+	if dtype == tcap.ABRT {
+		src := tcap.AbortDialogueServiceUser
+		if d.AbortSource != nil {
+			src = *d.AbortSource
+		}
+		return tcap.NewABRT(uint8(src), userinfo...), nil
+	}
+
+	pver := 1
+	if d.ProtocolVersion != nil {
+		pver = *d.ProtocolVersion
+	}
+	oid, err := d.contextOID()
+	if err != nil {
+		return nil, err
+	}
+
+	var pdu *tcap.DialoguePDU
+	if dtype == tcap.AARQ {
+		pdu = tcap.NewAARQWithOID(pver, oid, userinfo...)
+	} else {
+		result := int(tcap.Accepted)
+		if d.Result != nil {
+			result = *d.Result
+		}
+		diag := &Diagnostic{Source: "user", Reason: int(tcap.Null)}
+		if d.Diagnostic != nil {
+			diag = d.Diagnostic
+		}
+		src, ok := tcap.DiagnosticSourceCode(diag.Source)
+		if !ok {
+			return nil, &InvalidFieldError{Field: "diagnostic source", Value: diag.Source}
+		}
+		pdu = tcap.NewAAREWithOID(pver, oid, uint8(result), src, uint8(diag.Reason), userinfo...)
+	}
+
+	if pdu.ApplicationContextName == nil {
+		return nil, &InvalidFieldError{Field: "context", Value: d.Context}
+	}
+	return pdu, nil
+}
+
+// contextOID returns the OID of application context given by the name or the OID.
+func (d *Dialogue) contextOID() (asn1.ObjectIdentifier, error) {
+	if d.Context == "" {
+		return nil, &MissingFieldError{Field: "context"}
+	}
+	if oid, err := parseOID(d.Context); err == nil {
+		return oid, nil
+	}
+	if oid := tcap.LookupApplicationContextByName(d.Context); oid != nil {
+		return oid, nil
+	}
+
+	for ctx := 1; ctx <= 0x7f; ctx++ {
+		if tcap.NewAARQ(1, uint8(ctx), 1).Context() != d.Context {
+			continue
+		}
+		if d.Version <= 0 {
+			return nil, &MissingFieldError{Field: "version"}
+		}
+		return asn1.ObjectIdentifier{0, 4, 0, 0, 1, 0, ctx, d.Version}, nil
+	}
+	return nil, &InvalidFieldError{Field: "context", Value: d.Context}
+}
+
+func (c *Component) component() (*tcap.Component, error) {
+	ctype, ok := tcap.ComponentTypeCode(c.Type)
+	if !ok {
+		return nil, &InvalidFieldError{Field: "component type", Value: c.Type}
+	}
+	comp := &tcap.Component{Type: tcap.NewContextSpecificConstructorTag(ctype)}
+
+	switch {
+	case c.InvokeID != nil:
+		comp.InvokeID = tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagInteger), ber.EncodeInteger(int64(*c.InvokeID)))
+	case ctype == tcap.Reject:
+		comp.InvokeID = tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagNull), nil)
+	default:
+		return nil, &MissingFieldError{Field: "invokeID"}
+	}
+	if c.LinkedID != nil {
+		comp.LinkedID = tcap.NewIE(tcap.NewContextSpecificPrimitiveTag(0), ber.EncodeInteger(int64(*c.LinkedID)))
+	}
+
+	var err error
+	if c.OpCode != nil {
+		if comp.OperationCode, err = c.OpCode.ie(); err != nil {
+			return nil, err
+		}
+	} else if ctype == tcap.Invoke {
+		return nil, &MissingFieldError{Field: "opCode"}
+	}
+	if c.ErrorCode != nil {
+		if comp.ErrorCode, err = c.ErrorCode.ie(); err != nil {
+			return nil, err
+		}
+	} else if ctype == tcap.ReturnError {
+		return nil, &MissingFieldError{Field: "errorCode"}
+	}
+	if p := c.Problem; p != nil {
+		ptype, ok := tcap.ProblemTypeCode(p.Type)
+		if !ok || p.Code < 0 || p.Code > 0xff {
+			return nil, &InvalidFieldError{Field: "problem", Value: fmt.Sprintf("%s %d", p.Type, p.Code)}
+		}
+		comp.ProblemCode = tcap.NewIE(tcap.NewContextSpecificPrimitiveTag(ptype), []byte{uint8(p.Code)})
+	} else if ctype == tcap.Reject {
+		return nil, &MissingFieldError{Field: "problem"}
+	}
+
+	switch {
+	case c.Parameter != nil && !c.MAP.IsZero():
+		return nil, &InvalidFieldError{Field: "map", Value: "given with parameter"}
+	case c.Parameter != nil:
+		v, err := c.Parameter.BERValue()
+		if err != nil {
+			return nil, err
+		}
+		if comp.Parameter, err = tcap.NewIEFromValue(v); err != nil {
+			return nil, err
+		}
+	case !c.MAP.IsZero():
+		if comp.Parameter, err = c.mapParameter(ctype); err != nil {
+			return nil, err
+		}
+	}
+
+	switch ctype {
+	case tcap.ReturnResultLast, tcap.ReturnResultNotLast:
+		if comp.OperationCode != nil || comp.Parameter != nil {
+			comp.ResultRetres = &tcap.IE{Tag: tcap.NewUniversalConstructorTag(ber.TagSequence)}
+		}
+	}
+
+	comp.SetLength()
+	return comp, nil
+}
+
+// mapParameter returns the Parameter encoded from the typed MAP value.
+func (c *Component) mapParameter(ctype int) (*tcap.IE, error) {
+	if ctype != tcap.Invoke && ctype != tcap.ReturnResultLast && ctype != tcap.ReturnResultNotLast {
+		return nil, &InvalidFieldError{Field: "map", Value: c.Type}
+	}
+	if c.OpCode == nil || c.OpCode.Global != nil {
+		return nil, &MissingFieldError{Field: "local opCode"}
+	}
+
+	p, err := newMAPParameter(ctype, uint8(c.OpCode.Local))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.MAP.Decode(p); err != nil {
+		return nil, err
+	}
+	return gsmmap.MarshalParameter(p)
+}
+
+func newMAPParameter(ctype int, opCode uint8) (gsmmap.Parameter, error) {
+	switch ctype {
+	case tcap.Invoke:
+		return gsmmap.NewArgument(opCode)
+	case tcap.ReturnResultLast, tcap.ReturnResultNotLast:
+		return gsmmap.NewResult(opCode)
+	}
+	return nil, &InvalidFieldError{Field: "map", Value: strconv.Itoa(ctype)}
+}
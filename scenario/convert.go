@@ -0,0 +1,253 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package scenario
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"gopkg.in/yaml.v3"
+)
+
+// NewMessage creates a new Message from TCAP.
+//
+// If typedMAP is true, the parameters of the MAP operations supported by the
+// gsmmap package are written as the typed values, as long as they are encoded
+// back into the same octets. Otherwise they are written as BER trees.
+func NewMessage(t *tcap.TCAP, typedMAP bool) (*Message, error) {
+	tx := t.Transaction
+	if tx == nil {
+		return nil, &MissingFieldError{Field: "transaction"}
+	}
+	m := &Message{
+		Type: tcap.MessageTypeName(tx.Type.Code()),
+		OTID: tx.OTID(),
+		DTID: tx.DTID(),
+	}
+	if m.Type == "" {
+		return nil, &InvalidFieldError{Field: "type", Value: strconv.Itoa(tx.Type.Code())}
+	}
+	if tx.Type.Code() == tcap.Abort && tx.PAbortCause != nil && len(tx.PAbortCause.Value) > 0 {
+		cause := int(tx.PAbortCause.Value[0])
+		m.AbortCause = &cause
+	}
+
+	if t.Dialogue != nil && t.Dialogue.DialoguePDU != nil {
+		d, err := newDialogue(t.Dialogue.DialoguePDU)
+		if err != nil {
+			return nil, err
+		}
+		m.Dialogue = d
+	}
+
+	if t.Components != nil {
+		for _, c := range t.Components.Component {
+			comp, err := newComponent(c, typedMAP)
+			if err != nil {
+				return nil, err
+			}
+			m.Components = append(m.Components, comp)
+		}
+	}
+	return m, nil
+}
+
+func newDialogue(pdu *tcap.DialoguePDU) (*Dialogue, error) {
+	d := &Dialogue{Type: pdu.DialogueType()}
+	if d.Type == "" {
+		return nil, &InvalidFieldError{Field: "dialogue type", Value: strconv.Itoa(pdu.Type.Code())}
+	}
+
+	if field := pdu.ProtocolVersion; field != nil && len(field.Value) > 0 {
+		if v := int(field.Value[len(field.Value)-1] >> 7); v != 1 {
+			d.ProtocolVersion = &v
+		}
+	}
+	if pdu.ApplicationContextName != nil {
+		oid := pdu.ApplicationContextOID()
+		if oid == nil {
+			return nil, &InvalidFieldError{Field: "context", Value: hex.EncodeToString(pdu.ApplicationContextName.Value)}
+		}
+		d.Context = oid.String()
+		if name := pdu.Context(); name != "" {
+			d.Context = name
+			if tcap.LookupApplicationContextByName(name) == nil {
+				d.Version = oid[len(oid)-1]
+			}
+		}
+	}
+	if field := pdu.Result; field != nil {
+		v, err := taggedInteger(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		d.Result = &v
+	}
+	if field := pdu.ResultSourceDiagnostic; field != nil {
+		e, err := ber.ParseElement(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		reason, err := taggedInteger(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		d.Diagnostic = &Diagnostic{Source: tcap.DiagnosticSourceName(e.Tag), Reason: reason}
+	}
+	if field := pdu.AbortSource; field != nil && len(field.Value) > 0 {
+		v := int(field.Value[0])
+		d.AbortSource = &v
+	}
+	if field := pdu.UserInformation; field != nil {
+		vals, err := ber.ParseValues(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vals {
+			d.UserInformation = append(d.UserInformation, NewValue(v))
+		}
+	}
+	return d, nil
+}
+
+// taggedInteger decodes the INTEGER wrapped by an explicit tag.
+func taggedInteger(b []byte) (int, error) {
+	e, err := ber.ParseElement(b)
+	if err != nil {
+		return 0, err
+	}
+	v, err := ber.DecodeInteger(e.Value)
+	return int(v), err
+}
+
+func newComponent(c *tcap.Component, typedMAP bool) (*Component, error) {
+	comp := &Component{Type: c.ComponentTypeString()}
+	if comp.Type == "" {
+		return nil, &InvalidFieldError{Field: "component type", Value: strconv.Itoa(c.Type.Code())}
+	}
+
+	var err error
+	if field := c.InvokeID; field != nil && field.Tag.Code() == ber.TagInteger {
+		if comp.InvokeID, err = integer(field.Value); err != nil {
+			return nil, err
+		}
+	}
+	if field := c.LinkedID; field != nil {
+		if comp.LinkedID, err = integer(field.Value); err != nil {
+			return nil, err
+		}
+	}
+	if field := c.OperationCode; field != nil {
+		if comp.OpCode, err = newCode(field, c.OperationName()); err != nil {
+			return nil, err
+		}
+	}
+	if field := c.ErrorCode; field != nil {
+		if comp.ErrorCode, err = newCode(field, c.ErrorName()); err != nil {
+			return nil, err
+		}
+	}
+	if field := c.ProblemCode; field != nil && len(field.Value) > 0 {
+		comp.Problem = &Problem{Type: tcap.ProblemTypeName(field.Tag.Code()), Code: int(field.Value[0])}
+	}
+
+	if c.Parameter == nil {
+		return comp, nil
+	}
+	if typedMAP {
+		if n := mapNode(c); n != nil {
+			comp.MAP = *n
+			return comp, nil
+		}
+	}
+	v, err := c.ParameterValue()
+	if err != nil {
+		comp.Parameter = &Value{
+			Class:       ber.ClassName(c.Parameter.Tag.Class()),
+			Tag:         c.Parameter.Tag.Code(),
+			Constructed: true,
+			Value:       hex.EncodeToString(c.Parameter.Value),
+		}
+		return comp, nil
+	}
+	comp.Parameter = NewValue(v)
+	return comp, nil
+}
+
+func integer(b []byte) (*int, error) {
+	v, err := ber.DecodeInteger(b)
+	if err != nil {
+		return nil, err
+	}
+	i := int(v)
+	return &i, nil
+}
+
+func newCode(ie *tcap.IE, name string) (*Code, error) {
+	if ie.Tag.Code() == ber.TagObjectIdentifier {
+		oid, err := ber.DecodeObjectIdentifier(ie.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &Code{Global: oid, Name: name}, nil
+	}
+
+	v, err := ber.DecodeInteger(ie.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &Code{Local: int(v), Name: name}, nil
+}
+
+// mapNode returns the Parameter of the Component as the typed MAP value in
+// YAML node, or nil if it cannot be represented without changing the octets.
+func mapNode(c *tcap.Component) *yaml.Node {
+	if c.OperationCode == nil || c.OperationCode.Tag.Code() != ber.TagInteger {
+		return nil
+	}
+	p, err := newMAPParameter(c.Type.Code(), c.OpCode())
+	if err != nil {
+		return nil
+	}
+	if err := gsmmap.UnmarshalParameter(c.Parameter, p); err != nil {
+		return nil
+	}
+
+	ie, err := gsmmap.MarshalParameter(p)
+	if err != nil || ie.Tag != c.Parameter.Tag || !bytes.Equal(ie.Value, c.Parameter.Value) {
+		return nil
+	}
+
+	n := &yaml.Node{}
+	if err := n.Encode(p); err != nil {
+		return nil
+	}
+	return prune(n)
+}
+
+// prune removes the fields with null or empty value from the mapping node.
+func prune(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return n
+	}
+
+	var content []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], prune(n.Content[i+1])
+		if v.Tag == "!!null" || (v.Kind == yaml.MappingNode || v.Kind == yaml.SequenceNode) && len(v.Content) == 0 {
+			continue
+		}
+		if v.Tag == "!!bool" && v.Value == "false" {
+			continue
+		}
+		content = append(content, k, v)
+	}
+	n.Content = content
+	return n
+}
@@ -0,0 +1,47 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package scenario
+
+import "fmt"
+
+// InvalidFieldError indicates that the value of a field in scenario is invalid.
+type InvalidFieldError struct {
+	Field, Value string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidFieldError) Error() string {
+	return fmt.Sprintf("scenario: invalid %s: %q", e.Field, e.Value)
+}
+
+// MissingFieldError indicates that a mandatory field in scenario is missing.
+type MissingFieldError struct {
+	Field string
+}
+
+// Error returns error message with violating content.
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("scenario: missing mandatory field: %s", e.Field)
+}
+
+// MessageError indicates that the message at Index in scenario cannot be built.
+type MessageError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+// Error returns error message with violating content.
+func (e *MessageError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("scenario: message %d (%s): %v", e.Index, e.Name, e.Err)
+	}
+	return fmt.Sprintf("scenario: message %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
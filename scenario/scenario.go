@@ -0,0 +1,160 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package scenario provides a YAML format describing TCAP messages, which can be
+authored and reviewed without writing Go code.
+
+A scenario is a list of messages, each of which is compiled into *tcap.TCAP.
+The parameters of components are given as BER trees, or as the typed values of
+MAP operations in the gsmmap package, whose keys are the lowercased field names.
+
+	name: cancel location
+	messages:
+	  - name: request
+	    type: begin
+	    otid: "11111111"
+	    dialogue:
+	      type: AARQ
+	      context: locationCancellationContext
+	      version: 3
+	    components:
+	      - type: invoke
+	        invokeID: 1
+	        opCode: 3 # cancelLocation
+	        map:
+	          imsi: "001010123456789"
+	  - name: response
+	    type: end
+	    dtid: "11111111"
+	    components:
+	      - type: returnResultLast
+	        invokeID: 1
+	        opCode: cancelLocation
+	        parameter:
+	          class: universal
+	          tag: 16
+	          constructed: true
+
+NewMessage converts *tcap.TCAP back into the same format.
+*/
+package scenario
+
+import (
+	"bytes"
+
+	"github.com/wmnsk/go-tcap"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a named list of messages.
+type Scenario struct {
+	Name        string     `yaml:"name,omitempty"`
+	Description string     `yaml:"description,omitempty"`
+	Messages    []*Message `yaml:"messages"`
+}
+
+// Message describes a TCAP message.
+//
+// OTID and DTID are in hex. AbortCause is the P-Abort cause of Abort, and the
+// Abort without it is a U-Abort.
+type Message struct {
+	Name       string       `yaml:"name,omitempty"`
+	Type       string       `yaml:"type"`
+	OTID       string       `yaml:"otid,omitempty"`
+	DTID       string       `yaml:"dtid,omitempty"`
+	AbortCause *int         `yaml:"abortCause,omitempty"`
+	Dialogue   *Dialogue    `yaml:"dialogue,omitempty"`
+	Components []*Component `yaml:"components,omitempty"`
+}
+
+// Dialogue describes the DialoguePDU in the Dialogue Portion.
+//
+// Context is the name or the OID of application context. Version is required
+// with the name of MAP application context, such as "shortMsgGatewayContext".
+// ProtocolVersion is 1 if omitted, and UserInformation is the contents of
+// user-information, i.e., the list of EXTERNAL.
+type Dialogue struct {
+	Type            string      `yaml:"type"`
+	ProtocolVersion *int        `yaml:"protocolVersion,omitempty"`
+	Context         string      `yaml:"context,omitempty"`
+	Version         int         `yaml:"version,omitempty"`
+	Result          *int        `yaml:"result,omitempty"`
+	Diagnostic      *Diagnostic `yaml:"diagnostic,omitempty"`
+	AbortSource     *int        `yaml:"abortSource,omitempty"`
+	UserInformation []*Value    `yaml:"userInformation,omitempty"`
+}
+
+// Diagnostic describes the result-source-diagnostic of AARE.
+//
+// Source is either "user" or "provider".
+type Diagnostic struct {
+	Source string `yaml:"source"`
+	Reason int    `yaml:"reason"`
+}
+
+// Component describes a TCAP Component.
+//
+// Type is the name returned by ComponentTypeString of tcap.Component. The
+// Parameter is given either as a BER tree in Parameter, or as the argument or
+// result of MAP operation in MAP.
+type Component struct {
+	Type      string    `yaml:"type"`
+	InvokeID  *int      `yaml:"invokeID,omitempty"`
+	LinkedID  *int      `yaml:"linkedID,omitempty"`
+	OpCode    *Code     `yaml:"opCode,omitempty"`
+	ErrorCode *Code     `yaml:"errorCode,omitempty"`
+	Problem   *Problem  `yaml:"problem,omitempty"`
+	Parameter *Value    `yaml:"parameter,omitempty"`
+	MAP       yaml.Node `yaml:"map,omitempty"`
+}
+
+// Problem describes the problem of Reject.
+//
+// Type is one of "general", "invoke", "returnResult" and "returnError".
+type Problem struct {
+	Type string `yaml:"type"`
+	Code int    `yaml:"code"`
+}
+
+// Parse parses the YAML document as a Scenario.
+//
+// The unknown fields are treated as error to catch the typos.
+func Parse(b []byte) (*Scenario, error) {
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+
+	s := &Scenario{}
+	if err := d.Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MarshalBinary returns the YAML document of Scenario.
+func (s *Scenario) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	e := yaml.NewEncoder(buf)
+	e.SetIndent(2)
+	if err := e.Encode(s); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TCAP compiles the messages in Scenario into TCAP.
+func (s *Scenario) TCAP() ([]*tcap.TCAP, error) {
+	ts := make([]*tcap.TCAP, len(s.Messages))
+	for i, m := range s.Messages {
+		t, err := m.TCAP()
+		if err != nil {
+			return nil, &MessageError{Index: i, Name: m.Name, Err: err}
+		}
+		ts[i] = t
+	}
+	return ts, nil
+}
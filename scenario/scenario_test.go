@@ -0,0 +1,167 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package scenario_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"github.com/wmnsk/go-tcap/scenario"
+)
+
+func marshal(t *testing.T, m interface{ MarshalBinary() ([]byte, error) }) []byte {
+	t.Helper()
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func expectedMessages(t *testing.T) [][]byte {
+	t.Helper()
+	begin := tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 0, nil)
+	inv, err := gsmmap.NewInvoke(0, gsmmap.CancelLocation, &gsmmap.CancelLocationArg{IMSI: "001010123456789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin.Components = tcap.NewComponents(inv)
+	begin.SetLength()
+
+	end := tcap.NewEndReturnResultWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, true, []byte{})
+
+	return [][]byte{
+		marshal(t, begin),
+		marshal(t, end),
+		marshal(t, &tcap.TCAP{Transaction: tcap.NewAbort(0x11111111, tcap.ResourceLimitation, []byte{})}),
+	}
+}
+
+func TestScenarioFile(t *testing.T) {
+	b, err := os.ReadFile("testdata/cancel-location.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := scenario.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "Name", s.Name, "cancel location")
+
+	msgs, err := s.TCAP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := expectedMessages(t)
+	if len(msgs) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(msgs), len(want))
+	}
+	for i, m := range msgs {
+		verify.Values(t, s.Messages[i].Name, marshal(t, m), want[i])
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	for _, typed := range []bool{false, true} {
+		s := &scenario.Scenario{Name: "round trip"}
+		for _, b := range expectedMessages(t) {
+			parsed, err := tcap.Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := scenario.NewMessage(parsed, typed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Messages = append(s.Messages, m)
+		}
+		if got := !s.Messages[0].Components[0].MAP.IsZero(); got != typed {
+			t.Errorf("typed=%v: unexpected MAP value: %v", typed, got)
+		}
+
+		y := marshal(t, s)
+		parsed, err := scenario.Parse(y)
+		if err != nil {
+			t.Fatalf("%s\n%s", err, y)
+		}
+		msgs, err := parsed.TCAP()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range expectedMessages(t) {
+			verify.Values(t, string(y), marshal(t, msgs[i]), want)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	c := tcap.NewInvoke(1, -1, 2, true, nil)
+	if err := c.SetParameter(ber.NewSequence(ber.NewOctetString([]byte{0x00, 0x01}))); err != nil {
+		t.Fatal(err)
+	}
+	m, err := scenario.NewMessage(&tcap.TCAP{
+		Transaction: tcap.NewBegin(0x01020304, []byte{}),
+		Components:  tcap.NewComponents(c),
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify.Values(t, "YAML", string(marshal(t, &scenario.Scenario{Messages: []*scenario.Message{m}})), `messages:
+  - type: begin
+    otid: "01020304"
+    components:
+      - type: invoke
+        invokeID: 1
+        opCode: 2 # updateLocation
+        parameter:
+          class: universal
+          tag: 16
+          constructed: true
+          elements:
+            - class: universal
+              tag: 4
+              value: "0001"
+`)
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		description, yaml, want string
+	}{
+		{"unknown field", "messages:\n  - type: begin\n    otd: '01'\n", "field otd not found"},
+		{"unknown type", "messages:\n  - type: start\n", `invalid type: "start"`},
+		{"missing otid", "messages:\n  - type: begin\n", "missing mandatory field: otid"},
+		{"otid in end", "messages:\n  - type: end\n    otid: '01'\n    dtid: '01'\n", `invalid otid: "01"`},
+		{"missing version", "messages:\n  - type: begin\n    otid: '01'\n    dialogue: {type: AARQ, context: shortMsgGatewayContext}\n", "missing mandatory field: version"},
+		{"unknown context", "messages:\n  - type: begin\n    otid: '01'\n    dialogue: {type: AARQ, context: foo}\n", `invalid context: "foo"`},
+		{"unknown opCode", "messages:\n  - type: begin\n    otid: '01'\n    components: [{type: invoke, invokeID: 1, opCode: foo}]\n", `invalid code: "foo"`},
+		{"missing opCode", "messages:\n  - type: begin\n    otid: '01'\n    components: [{type: invoke, invokeID: 1}]\n", "component 0: scenario: missing mandatory field: opCode"},
+		{"map in returnError", "messages:\n  - type: end\n    dtid: '01'\n    components: [{type: returnError, invokeID: 1, errorCode: 1, map: {}}]\n", "invalid map"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			s, err := scenario.Parse([]byte(c.yaml))
+			if err == nil {
+				_, err = s.TCAP()
+
+				var merr *scenario.MessageError
+				if !errors.As(err, &merr) {
+					t.Errorf("not a MessageError: %v", err)
+				}
+			}
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package scenario
+
+import (
+	"encoding/asn1"
+	"strconv"
+	"strings"
+
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/ber"
+	"github.com/wmnsk/go-tcap/gsmmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Value is a BER value in the form of tree.
+//
+// Class is one of "universal", "application", "context" and "private", and is
+// "universal" if omitted. Primitive Value has its contents octets in hex in Value,
+// and constructed Value has its components in Elements, or its contents octets
+// in hex in Value.
+type Value struct {
+	Class       string   `yaml:"class,omitempty"`
+	Tag         int      `yaml:"tag"`
+	Constructed bool     `yaml:"constructed,omitempty"`
+	Value       string   `yaml:"value,omitempty"`
+	Elements    []*Value `yaml:"elements,omitempty"`
+}
+
+// NewValue creates a new Value from the BER value.
+func NewValue(v *ber.Value) *Value {
+	return fromJSONValue(v.JSON())
+}
+
+func fromJSONValue(j *ber.JSONValue) *Value {
+	v := &Value{Class: j.Class, Tag: j.Tag, Constructed: j.Constructed, Value: j.Value}
+	for _, e := range j.Elements {
+		v.Elements = append(v.Elements, fromJSONValue(e))
+	}
+	return v
+}
+
+// BERValue returns the BER value represented by Value.
+func (v *Value) BERValue() (*ber.Value, error) {
+	return v.jsonValue().BERValue()
+}
+
+func (v *Value) jsonValue() *ber.JSONValue {
+	j := &ber.JSONValue{Class: v.Class, Tag: v.Tag, Constructed: v.Constructed, Value: v.Value}
+	if j.Class == "" {
+		j.Class = ber.ClassName(ber.Universal)
+	}
+	for _, e := range v.Elements {
+		j.Elements = append(j.Elements, e.jsonValue())
+	}
+	return j
+}
+
+// Code is the operation code or error code.
+//
+// In YAML, it is written as an integer for the local value, as an OID in dot
+// notation for the global value, or as the name of operation or error.
+// The names are looked up in tcap.DefaultOperationRegistry and then the MAP
+// operations and errors in the gsmmap package.
+type Code struct {
+	Local  int
+	Global asn1.ObjectIdentifier
+	Name   string
+}
+
+// UnmarshalYAML sets the values retrieved from the YAML node in Code.
+func (c *Code) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return &InvalidFieldError{Field: "code", Value: n.Value}
+	}
+
+	switch {
+	case n.Tag == "!!int":
+		v, err := strconv.ParseInt(n.Value, 0, 64)
+		if err != nil {
+			return &InvalidFieldError{Field: "code", Value: n.Value}
+		}
+		*c = Code{Local: int(v)}
+	case strings.Contains(n.Value, "."):
+		oid, err := parseOID(n.Value)
+		if err != nil {
+			return err
+		}
+		*c = Code{Global: oid}
+	default:
+		code, ok := lookupCode(n.Value)
+		if !ok {
+			return &InvalidFieldError{Field: "code", Value: n.Value}
+		}
+		*c = Code{Local: int(code), Name: n.Value}
+	}
+	return nil
+}
+
+// MarshalYAML returns the YAML node of Code, with the name as a comment.
+func (c *Code) MarshalYAML() (interface{}, error) {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(c.Local), LineComment: c.Name}
+	if c.Global != nil {
+		n.Tag, n.Value = "!!str", c.Global.String()
+	}
+	return n, nil
+}
+
+func (c *Code) ie() (*tcap.IE, error) {
+	if c.Global == nil {
+		return tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagInteger), ber.EncodeInteger(int64(c.Local))), nil
+	}
+
+	v, err := ber.EncodeObjectIdentifier(c.Global)
+	if err != nil {
+		return nil, &InvalidFieldError{Field: "code", Value: c.Global.String()}
+	}
+	return tcap.NewIE(tcap.NewUniversalPrimitiveTag(ber.TagObjectIdentifier), v), nil
+}
+
+func lookupCode(name string) (uint8, bool) {
+	r := tcap.DefaultOperationRegistry
+	for code := 0; code <= 0xff; code++ {
+		if op := r.Operation(uint8(code)); op != nil && op.Name == name {
+			return uint8(code), true
+		}
+		if e := r.Error(uint8(code)); e != nil && e.Name == name {
+			return uint8(code), true
+		}
+	}
+	for code := 0; code <= 0xff; code++ {
+		if gsmmap.OperationName(uint8(code)) == name || gsmmap.ErrorName(uint8(code)) == name {
+			return uint8(code), true
+		}
+	}
+	return 0, false
+}
+
+// parseOID parses the OID in dot notation, such as "0.4.0.0.1.0.2.3".
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return nil, &InvalidFieldError{Field: "OID", Value: s}
+		}
+		oid[i] = v
+	}
+	return oid, nil
+}
@@ -0,0 +1,263 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"time"
+)
+
+// Transaction State definitions, as in the transaction sublayer of ITU-T Q.774.
+const (
+	StateIdle int = iota
+	StateInitiationSent
+	StateInitiationReceived
+	StateActive
+)
+
+// DialogueState is a snapshot of a transaction and its dialogue.
+//
+// It holds everything needed to resume the dialogue in another process:
+// the transaction IDs on both sides, the transaction state, the ACN, the
+// SCCP addresses of the peers and the Invokes that are still waiting for
+// a response. DialogueState is keyed by LocalTID in a Store.
+//
+// RemoteTID is kept in the octets received, as the TID allocated by the peer
+// can be 1 to 4 octets and is sent back as it is.
+type DialogueState struct {
+	LocalTID               uint32         `json:"localTid"`
+	RemoteTID              []byte         `json:"remoteTid,omitempty"`
+	State                  int            `json:"state"`
+	ApplicationContextName []byte         `json:"applicationContextName,omitempty"`
+	LocalAddress           []byte         `json:"localAddress,omitempty"`
+	RemoteAddress          []byte         `json:"remoteAddress,omitempty"`
+	Invokes                []*InvokeState `json:"invokes,omitempty"`
+	UpdatedAt              time.Time      `json:"updatedAt"`
+}
+
+// InvokeState is an outstanding Invoke in a dialogue.
+//
+// Deadline is the absolute time when the invocation timer expires. Zero
+// value means the timer is not running.
+type InvokeState struct {
+	InvokeID uint8     `json:"invokeId"`
+	OpCode   uint8     `json:"opCode"`
+	Class    int       `json:"class,omitempty"`
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+// NewDialogueState creates a new DialogueState in Idle state.
+func NewDialogueState(localTID uint32, localAddr, remoteAddr []byte) *DialogueState {
+	return &DialogueState{
+		LocalTID:      localTID,
+		LocalAddress:  localAddr,
+		RemoteAddress: remoteAddr,
+		State:         StateIdle,
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// Update updates the state with the TCAP message sent or received.
+//
+// It moves the transaction state, learns the remote TID and ACN, and
+// tracks the Invokes sent by this side until the final response is received.
+// The class and the invocation timer of the Invokes are taken from
+// DefaultOperationRegistry.
+func (s *DialogueState) Update(t *TCAP, sent bool) {
+	s.UpdateWithRegistry(t, sent, DefaultOperationRegistry)
+}
+
+// UpdateWithRegistry is the same as Update, but the class and the invocation
+// timer of the Invokes are taken from the OperationRegistry given, for the
+// application context of the dialogue.
+func (s *DialogueState) UpdateWithRegistry(t *TCAP, sent bool, r *OperationRegistry) {
+	s.UpdatedAt = time.Now()
+
+	if ts := t.Transaction; ts != nil {
+		switch ts.Type.Code() {
+		case Begin:
+			if sent {
+				s.State = StateInitiationSent
+			} else {
+				s.State = StateInitiationReceived
+				s.RemoteTID = tidFrom(ts.OrigTransactionID)
+			}
+		case Continue:
+			if !sent {
+				s.RemoteTID = tidFrom(ts.OrigTransactionID)
+			}
+			s.State = StateActive
+		case End, Abort:
+			s.State = StateIdle
+		}
+	}
+
+	if d := t.Dialogue; d != nil {
+		if pdu := d.DialoguePDU; pdu != nil && pdu.ApplicationContextName != nil {
+			s.ApplicationContextName = append([]byte(nil), pdu.ApplicationContextName.Value...)
+		}
+	}
+
+	if c := t.Components; c != nil {
+		r = r.ForContext(s.ApplicationContextOID())
+		for _, comp := range c.Component {
+			switch comp.Type.Code() {
+			case Invoke:
+				if sent && comp.OperationCode != nil && len(comp.OperationCode.Value) > 0 && comp.InvokeID != nil && len(comp.InvokeID.Value) > 0 {
+					class, deadline := 0, time.Time{}
+					if op := r.Operation(comp.OpCode()); op != nil {
+						class = op.Class
+						if op.Timeout > 0 {
+							deadline = s.UpdatedAt.Add(op.Timeout)
+						}
+					}
+					s.AddInvoke(comp.InvID(), comp.OpCode(), class, deadline)
+				}
+			case ReturnResultLast, ReturnError, Reject:
+				if !sent && comp.InvokeID != nil && len(comp.InvokeID.Value) > 0 {
+					s.RemoveInvoke(comp.InvID())
+				}
+			}
+		}
+	}
+
+	if s.State == StateIdle {
+		s.Invokes = nil
+	}
+}
+
+// AddInvoke adds an outstanding Invoke to the state.
+//
+// If the Invoke ID is already in use, the existing one is replaced.
+func (s *DialogueState) AddInvoke(invID, opCode uint8, class int, deadline time.Time) {
+	s.RemoveInvoke(invID)
+	s.Invokes = append(s.Invokes, &InvokeState{
+		InvokeID: invID,
+		OpCode:   opCode,
+		Class:    class,
+		Deadline: deadline,
+	})
+}
+
+// RemoveInvoke removes the outstanding Invoke with the given ID.
+func (s *DialogueState) RemoveInvoke(invID uint8) {
+	for i, inv := range s.Invokes {
+		if inv.InvokeID == invID {
+			s.Invokes = append(s.Invokes[:i], s.Invokes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Invoke returns the outstanding Invoke with the given ID, or nil if not found.
+func (s *DialogueState) Invoke(invID uint8) *InvokeState {
+	for _, inv := range s.Invokes {
+		if inv.InvokeID == invID {
+			return inv
+		}
+	}
+	return nil
+}
+
+// ExpiredInvokes removes the Invokes whose invocation timer has expired and returns them.
+//
+// For the Invokes of class 2 and 4, the expiry means the operation has
+// completed without error.
+func (s *DialogueState) ExpiredInvokes(now time.Time) []*InvokeState {
+	var expired, remaining []*InvokeState
+	for _, inv := range s.Invokes {
+		if !inv.Deadline.IsZero() && !inv.Deadline.After(now) {
+			expired = append(expired, inv)
+		} else {
+			remaining = append(remaining, inv)
+		}
+	}
+	s.Invokes = remaining
+	return expired
+}
+
+// Clone returns a deep copy of the DialogueState.
+func (s *DialogueState) Clone() *DialogueState {
+	c := *s
+	c.RemoteTID = append([]byte(nil), s.RemoteTID...)
+	c.ApplicationContextName = append([]byte(nil), s.ApplicationContextName...)
+	c.LocalAddress = append([]byte(nil), s.LocalAddress...)
+	c.RemoteAddress = append([]byte(nil), s.RemoteAddress...)
+	c.Invokes = nil
+	for _, inv := range s.Invokes {
+		i := *inv
+		c.Invokes = append(c.Invokes, &i)
+	}
+	return &c
+}
+
+// ApplicationContextOID returns the ApplicationContextName as an OID, or nil if
+// it is not known or invalid.
+func (s *DialogueState) ApplicationContextOID() asn1.ObjectIdentifier {
+	if s.ApplicationContextName == nil {
+		return nil
+	}
+	pdu := &DialoguePDU{ApplicationContextName: &IE{Value: s.ApplicationContextName}}
+	return pdu.ApplicationContextOID()
+}
+
+// StateString returns the name of transaction state in string.
+func (s *DialogueState) StateString() string {
+	switch s.State {
+	case StateIdle:
+		return "Idle"
+	case StateInitiationSent:
+		return "InitiationSent"
+	case StateInitiationReceived:
+		return "InitiationReceived"
+	case StateActive:
+		return "Active"
+	}
+	return ""
+}
+
+// Remaining returns the time left until the invocation timer expires.
+//
+// It returns 0 if the timer has already expired or is not running.
+func (i *InvokeState) Remaining(now time.Time) time.Duration {
+	if i.Deadline.IsZero() || !i.Deadline.After(now) {
+		return 0
+	}
+	return i.Deadline.Sub(now)
+}
+
+// String returns DialogueState in human readable string.
+func (s *DialogueState) String() string {
+	return fmt.Sprintf("{LocalTID: %#08x, RemoteTID: %x, State: %s, ApplicationContextName: %x, LocalAddress: %x, RemoteAddress: %x, Invokes: %v, UpdatedAt: %v}",
+		s.LocalTID,
+		s.RemoteTID,
+		s.StateString(),
+		s.ApplicationContextName,
+		s.LocalAddress,
+		s.RemoteAddress,
+		s.Invokes,
+		s.UpdatedAt,
+	)
+}
+
+// String returns InvokeState in human readable string.
+func (i *InvokeState) String() string {
+	return fmt.Sprintf("{InvokeID: %d, OpCode: %d, Class: %d, Deadline: %v}",
+		i.InvokeID,
+		i.OpCode,
+		i.Class,
+		i.Deadline,
+	)
+}
+
+// tidFrom returns the copy of the TID allocated by the peer, which can be 1 to
+// 4 octets.
+func tidFrom(ie *IE) []byte {
+	if ie == nil {
+		return nil
+	}
+	return append([]byte(nil), ie.Value...)
+}
@@ -0,0 +1,209 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store is a persistent storage of DialogueState.
+//
+// A process that handles the dialogues saves the state every time it changes,
+// so that another process can Load or List them and take over the live
+// dialogues after a restart or failover.
+type Store interface {
+	// Save stores the state, replacing the existing one with the same LocalTID.
+	Save(s *DialogueState) error
+	// Load returns the state with the given LocalTID.
+	// It returns *DialogueNotFoundError if there is no such state.
+	Load(localTID uint32) (*DialogueState, error)
+	// Delete removes the state with the given LocalTID.
+	// It does nothing if there is no such state.
+	Delete(localTID uint32) error
+	// List returns all the states stored, sorted by LocalTID.
+	List() ([]*DialogueState, error)
+}
+
+// MemoryStore is a Store that keeps the states in memory.
+//
+// It is safe for concurrent use. It is useful to test the dialogue handling,
+// or to share the states between goroutines in the same process.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	states map[uint32]*DialogueState
+}
+
+// NewMemoryStore creates a new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: map[uint32]*DialogueState{},
+	}
+}
+
+// Save stores the copy of the state.
+func (m *MemoryStore) Save(s *DialogueState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.states[s.LocalTID] = s.Clone()
+	return nil
+}
+
+// Load returns the copy of the state with the given LocalTID.
+func (m *MemoryStore) Load(localTID uint32) (*DialogueState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.states[localTID]
+	if !ok {
+		return nil, &DialogueNotFoundError{LocalTID: localTID}
+	}
+	return s.Clone(), nil
+}
+
+// Delete removes the state with the given LocalTID.
+func (m *MemoryStore) Delete(localTID uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, localTID)
+	return nil
+}
+
+// List returns the copies of all the states stored.
+func (m *MemoryStore) List() ([]*DialogueState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make([]*DialogueState, 0, len(m.states))
+	for _, s := range m.states {
+		states = append(states, s.Clone())
+	}
+	sortStates(states)
+	return states, nil
+}
+
+// FileStore is a Store that keeps each state in a JSON file in a directory.
+//
+// Files are written atomically by renaming a temporary file, so that a standby
+// process reading the same directory never sees a partially written state.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore creates a new FileStore that uses dir, creating it if necessary.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for FileStore: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes the state to the file named after its LocalTID.
+func (f *FileStore) Save(s *DialogueState) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DialogueState: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(s.LocalTID))
+}
+
+// Load reads the state with the given LocalTID from the file.
+func (f *FileStore) Load(localTID uint32) (*DialogueState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := os.ReadFile(f.path(localTID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &DialogueNotFoundError{LocalTID: localTID}
+		}
+		return nil, err
+	}
+
+	s := &DialogueState{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DialogueState: %w", err)
+	}
+	return s, nil
+}
+
+// Delete removes the file of the state with the given LocalTID.
+func (f *FileStore) Delete(localTID uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.path(localTID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List reads all the states in the directory.
+func (f *FileStore) List() ([]*DialogueState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []*DialogueState
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(f.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		s := &DialogueState{}
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal DialogueState in %s: %w", e.Name(), err)
+		}
+		states = append(states, s)
+	}
+	sortStates(states)
+	return states, nil
+}
+
+func (f *FileStore) path(localTID uint32) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%08x.json", localTID))
+}
+
+func sortStates(states []*DialogueState) {
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].LocalTID < states[j].LocalTID
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+func TestDialogueStateUpdate(t *testing.T) {
+	s := tcap.NewDialogueState(0x11111111, []byte{0x01}, []byte{0x02})
+
+	s.Update(tcap.NewBeginInvokeWithDialogue(
+		0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0x00},
+	), true)
+	if s.State != tcap.StateInitiationSent {
+		t.Errorf("unexpected state after Begin: %s", s.StateString())
+	}
+	if s.Invoke(0) == nil {
+		t.Fatal("Invoke not tracked")
+	}
+
+	s.Update(tcap.NewContinueInvoke(0x22222222, 0x11111111, 1, 7, nil), false)
+	if s.State != tcap.StateActive || !bytes.Equal(s.RemoteTID, []byte{0x22, 0x22, 0x22, 0x22}) {
+		t.Errorf("unexpected state after Continue: %v", s)
+	}
+
+	s.Update(tcap.NewEndReturnResult(0x11111111, 0, 3, true, nil), false)
+	if s.State != tcap.StateIdle || len(s.Invokes) != 0 {
+		t.Errorf("unexpected state after End: %v", s)
+	}
+}
+
+func TestStore(t *testing.T) {
+	fileStore, err := tcap.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := []struct {
+		description string
+		store       tcap.Store
+	}{
+		{"MemoryStore", tcap.NewMemoryStore()},
+		{"FileStore", fileStore},
+	}
+
+	deadline := time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+	for _, c := range stores {
+		t.Run(c.description, func(t *testing.T) {
+			want := &tcap.DialogueState{
+				LocalTID:               0x11111111,
+				RemoteTID:              []byte{0x22, 0x22, 0x22, 0x22},
+				State:                  tcap.StateActive,
+				ApplicationContextName: []byte{0x04, 0x00, 0x00, 0x01, 0x00, 0x02, 0x03},
+				LocalAddress:           []byte{0x12, 0x06, 0x00},
+				RemoteAddress:          []byte{0x12, 0x07, 0x00},
+				Invokes: []*tcap.InvokeState{
+					{InvokeID: 1, OpCode: 3, Class: 1, Deadline: deadline},
+				},
+				UpdatedAt: deadline.Add(-30 * time.Second),
+			}
+			if err := c.store.Save(want); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.store.Save(&tcap.DialogueState{LocalTID: 0x00000001}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.store.Load(0x11111111)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", got, want)
+
+			list, err := c.store.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 2 || list[0].LocalTID != 0x00000001 {
+				t.Errorf("unexpected list: %v", list)
+			}
+
+			if err := c.store.Delete(0x11111111); err != nil {
+				t.Fatal(err)
+			}
+			var nf *tcap.DialogueNotFoundError
+			if _, err := c.store.Load(0x11111111); !errors.As(err, &nf) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := want.Invokes[0].Remaining(deadline.Add(-10 * time.Second)); got != 10*time.Second {
+				t.Errorf("unexpected remaining time: %v", got)
+			}
+		})
+	}
+}
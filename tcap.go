@@ -11,7 +11,6 @@ That makes this implementation flexible enough to create arbitrary payload with
 package tcap
 
 import (
-	"encoding/binary"
 	"fmt"
 )
 
@@ -75,37 +74,56 @@ func NewEndReturnResultWithDialogue(dtid uint32, dlgType, ctx, ctxver uint8, inv
 
 // MarshalBinary returns the byte sequence generated from a TCAP instance.
 func (t *TCAP) MarshalBinary() ([]byte, error) {
-	b := make([]byte, t.MarshalLen())
-	if err := t.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return marshalBinary(t)
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *TCAP) MarshalTo(b []byte) error {
-	var offset = 0
-	if portion := t.Transaction; portion != nil {
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+	return marshalTo(t, b)
+}
+
+// AppendBinary appends the byte sequence generated from a TCAP instance to b.
+//
+// It is the fastest way to marshal, with the buffer reused or with Encoder.
+func (t *TCAP) AppendBinary(b []byte) ([]byte, error) {
+	return t.appendTo(b, MarshalOptions{})
+}
+
+// appendTo appends the Dialogue and the Components inside the Transaction
+// Portion. The Payload of the Transaction, or of the Dialogue, is appended only
+// if it is the rest of the message, i.e., no portion follows it.
+func (t *TCAP) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	if ts := t.Transaction; ts != nil {
+		return ts.appendWith(b, o, t.appendPortions)
+	}
+	return t.appendPortions(b, o)
+}
+
+func (t *TCAP) appendPortions(b []byte, o MarshalOptions) ([]byte, error) {
+	if t.Dialogue == nil && t.Components == nil {
+		if t.Transaction != nil {
+			return append(b, t.Transaction.Payload...), nil
 		}
-		offset += portion.MarshalLen()
+		return b, nil
 	}
 
+	var err error
 	if portion := t.Dialogue; portion != nil {
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+		if t.Components == nil {
+			b, err = portion.appendTo(b, o)
+		} else {
+			b, err = portion.appendWith(b, o)
+		}
+		if err != nil {
+			return nil, err
 		}
-		offset += portion.MarshalLen()
 	}
-
 	if portion := t.Components; portion != nil {
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+		if b, err = portion.appendTo(b, o); err != nil {
+			return nil, err
 		}
 	}
-
-	return nil
+	return b, nil
 }
 
 // Parse parses given byte sequence as a TCAP.
@@ -202,44 +220,63 @@ func ParseBER(b []byte) ([]*TCAP, error) {
 
 // MarshalLen returns the serial length of TCAP.
 func (t *TCAP) MarshalLen() int {
-	l := 0
-	if portion := t.Components; portion != nil {
-		l += portion.MarshalLen()
+	if ts := t.Transaction; ts != nil {
+		return tlvLen(ts.fieldsLen() + t.portionsLen())
 	}
+	return t.portionsLen()
+}
+
+// portionsLen returns the serial length of the contents appended by
+// appendPortions.
+func (t *TCAP) portionsLen() int {
+	if t.Dialogue == nil && t.Components == nil {
+		if t.Transaction != nil {
+			return len(t.Transaction.Payload)
+		}
+		return 0
+	}
+
+	l := 0
 	if portion := t.Dialogue; portion != nil {
-		l += portion.MarshalLen()
+		if t.Components == nil {
+			l += portion.MarshalLen()
+		} else {
+			l += portion.portionLen()
+		}
 	}
-	if portion := t.Transaction; portion != nil {
+	if portion := t.Components; portion != nil {
 		l += portion.MarshalLen()
 	}
 	return l
 }
 
 // SetLength sets the length in Length field.
+//
+// It is no longer needed before marshalling, as the lengths are computed from
+// the contents; the Length fields are written only in raw mode with
+// MarshalOptions. For the contents longer than 127 octets, the Length is the
+// first octet of the long form.
 func (t *TCAP) SetLength() {
 	if portion := t.Components; portion != nil {
 		portion.SetLength()
 	}
 	if portion := t.Dialogue; portion != nil {
-		portion.SetLength()
+		if t.Components == nil {
+			portion.SetLength()
+		} else {
+			portion.setLength(0)
+		}
 	}
 	if portion := t.Transaction; portion != nil {
 		portion.SetLength()
-		if c := t.Components; c != nil {
-			portion.Length += uint8(c.MarshalLen())
-		}
-		if d := t.Dialogue; d != nil {
-			portion.Length += uint8(d.MarshalLen())
-		}
+		portion.Length = lengthOctet(portion.fieldsLen() + t.portionsLen())
 	}
 }
 
 // OTID returns the TCAP Originating Transaction ID in Transaction Portion in uint32.
 func (t *TCAP) OTID() uint32 {
 	if ts := t.Transaction; ts != nil {
-		if otid := ts.OrigTransactionID; otid != nil {
-			return binary.BigEndian.Uint32(otid.Value)
-		}
+		return tidValue(ts.OrigTransactionID)
 	}
 
 	return 0
@@ -248,9 +285,7 @@ func (t *TCAP) OTID() uint32 {
 // DTID returns the TCAP Originating Transaction ID in Transaction Portion in uint32.
 func (t *TCAP) DTID() uint32 {
 	if ts := t.Transaction; ts != nil {
-		if dtid := ts.DestTransactionID; dtid != nil {
-			return binary.BigEndian.Uint32(dtid.Value)
-		}
+		return tidValue(ts.DestTransactionID)
 	}
 
 	return 0
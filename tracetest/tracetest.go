@@ -0,0 +1,153 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package tracetest provides Recorder, a tcap.Tracer that keeps the spans in
+memory to be inspected in tests.
+
+	rec := tracetest.NewRecorder()
+	ep := tcap.NewEndpoint(conn)
+	ep.Tracer = rec
+	...
+	for _, s := range rec.Spans() {
+		fmt.Println(s.Name, s.Attributes["tcap.local_tid"], s.Ended)
+	}
+*/
+package tracetest
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-tcap"
+)
+
+// SpanData is a snapshot of a span recorded.
+//
+// ID is numbered from 1 in the order the spans are started, and ParentID is
+// the ID of the parent span, or 0 if the span has no parent.
+type SpanData struct {
+	ID         int
+	ParentID   int
+	Name       string
+	Attributes map[string]string
+	Events     []Event
+	Err        error
+	Start      time.Time
+	End        time.Time
+	Ended      bool
+}
+
+// Event is an event added to a span.
+type Event struct {
+	Name       string
+	Attributes map[string]string
+	Time       time.Time
+}
+
+// Recorder is a tcap.Tracer that records the spans in memory.
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type spanKey struct{}
+
+// Start starts a span recorded by r, as a child of the span in ctx if it is
+// started by r.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...tcap.Attribute) (context.Context, tcap.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &span{r: r, data: SpanData{
+		ID:         len(r.spans) + 1,
+		Name:       name,
+		Attributes: map[string]string{},
+		Start:      time.Now(),
+	}}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok && parent.r == r {
+		s.data.ParentID = parent.data.ID
+	}
+	setAttributes(s.data.Attributes, attrs)
+	r.spans = append(r.spans, s)
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Spans returns the snapshots of the spans recorded in the order started.
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]SpanData, len(r.spans))
+	for i, s := range r.spans {
+		d := s.data
+		d.Attributes = maps.Clone(d.Attributes)
+		d.Events = append([]Event(nil), d.Events...)
+		spans[i] = d
+	}
+	return spans
+}
+
+// Reset discards the spans recorded.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+}
+
+// span is a span recorded, whose data is guarded by the mutex of the Recorder.
+type span struct {
+	r    *Recorder
+	data SpanData
+}
+
+func (s *span) SetAttributes(attrs ...tcap.Attribute) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	setAttributes(s.data.Attributes, attrs)
+}
+
+func (s *span) AddEvent(name string, attrs ...tcap.Attribute) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	e := Event{Name: name, Attributes: map[string]string{}, Time: time.Now()}
+	setAttributes(e.Attributes, attrs)
+	s.data.Events = append(s.data.Events, e)
+}
+
+func (s *span) SetError(err error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	s.data.Err = err
+}
+
+func (s *span) End() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if !s.data.Ended {
+		s.data.End = time.Now()
+		s.data.Ended = true
+	}
+}
+
+func setAttributes(m map[string]string, attrs []tcap.Attribute) {
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+}
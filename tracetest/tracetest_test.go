@@ -0,0 +1,79 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tracetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+	"github.com/wmnsk/go-tcap/tracetest"
+)
+
+func TestRecorder(t *testing.T) {
+	r, other := tracetest.NewRecorder(), tracetest.NewRecorder()
+
+	ctx, root := r.Start(context.Background(), "root", tcap.Attr("a", "1"))
+	_, child := r.Start(ctx, "child")
+	child.SetAttributes(tcap.Attr("b", "2"), tcap.Attr("a", "3"))
+	child.AddEvent("event", tcap.Attr("c", "4"))
+	child.SetError(errors.New("failed"))
+	child.End()
+	child.End()
+
+	// the span of another Recorder is not a parent.
+	octx, _ := other.Start(context.Background(), "other")
+	r.Start(octx, "orphan")
+
+	spans := r.Spans()
+	for i := range spans {
+		if spans[i].Start.IsZero() {
+			t.Errorf("span %d has no start time", spans[i].ID)
+		}
+		if spans[i].Ended == spans[i].End.IsZero() {
+			t.Errorf("span %d: ended %v at %v", spans[i].ID, spans[i].Ended, spans[i].End)
+		}
+		spans[i].Start, spans[i].End = time.Time{}, time.Time{}
+		for j := range spans[i].Events {
+			spans[i].Events[j].Time = time.Time{}
+		}
+	}
+	verify.Values(t, "spans", spans, []tracetest.SpanData{
+		{ID: 1, Name: "root", Attributes: map[string]string{"a": "1"}},
+		{
+			ID: 2, ParentID: 1, Name: "child",
+			Attributes: map[string]string{"a": "3", "b": "2"},
+			Events:     []tracetest.Event{{Name: "event", Attributes: map[string]string{"c": "4"}}},
+			Err:        errors.New("failed"),
+			Ended:      true,
+		},
+		{ID: 3, Name: "orphan", Attributes: map[string]string{}},
+	})
+
+	// the snapshots are not changed by the span.
+	root.SetAttributes(tcap.Attr("a", "5"))
+	verify.Values(t, "snapshot", spans[0].Attributes["a"], "1")
+	verify.Values(t, "updated", r.Spans()[0].Attributes["a"], "5")
+
+	r.Reset()
+	verify.Values(t, "after Reset", len(r.Spans()), 0)
+}
+
+func TestDefaultTracer(t *testing.T) {
+	defer tcap.SetDefaultTracer(nil)
+
+	ctx := context.Background()
+	if got, span := tcap.DefaultTracer().Start(ctx, "noop"); got != ctx || span == nil {
+		t.Errorf("unexpected context or span from the no-op tracer: %v, %v", got, span)
+	}
+
+	r := tracetest.NewRecorder()
+	tcap.SetDefaultTracer(r)
+	tcap.DefaultTracer().Start(ctx, "recorded")
+	verify.Values(t, "recorded", len(r.Spans()), 1)
+}
@@ -0,0 +1,260 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+)
+
+// Tracer starts the spans of the dialogues and the invokes in Endpoint.
+//
+// It is shaped after the tracer of OpenTelemetry so that an adapter to it or any
+// other tracing system is a few lines. The tracetest package provides an
+// in-memory implementation for tests.
+//
+// Each Session is traced as a span named "tcap.dialogue", with an event
+// "tcap.message" for each message sent and received. Each Invoke sent or
+// received is traced as a child span named "tcap.invoke", which ends with the
+// ReturnResultLast, ReturnError or Reject for it, or with the end of the
+// dialogue. The spans have the attributes below where they apply.
+//
+//	tcap.local_tid, tcap.remote_tid: the TIDs in hex
+//	tcap.acn:                        the Application Context Name in dotted OID
+//	sccp.local_address:              the SCCP address of this side
+//	sccp.remote_address:             the SCCP address of the peer
+//	tcap.message_type:               the message type, e.g., "Begin"
+//	tcap.direction:                  "sent" or "received"
+//	tcap.invoke_id, tcap.opcode:     the Invoke ID and the Operation Code
+//
+// The SCCP addresses are known if the connection of the Endpoint has the method
+// PartyAddresses() (local, remote string), as transport.M3UA does. The remote
+// address is also taken from the messages received if the connection has the
+// method ReadFrom.
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx if any, and returns
+	// the context with the span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by Tracer. The methods are called from multiple
+// goroutines, but not concurrently for the same span.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	AddEvent(name string, attrs ...Attribute)
+	SetError(err error)
+	End()
+}
+
+// Attribute is a key-value pair of a span or an event.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr creates a new Attribute.
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type tracerHolder struct{ Tracer }
+
+// defaultTracer is the tracer used when no tracer is given.
+var defaultTracer atomic.Pointer[tracerHolder]
+
+func init() {
+	defaultTracer.Store(&tracerHolder{noopTracer{}})
+}
+
+// DefaultTracer returns the Tracer used by Endpoint without Tracer. It does
+// nothing unless replaced with SetDefaultTracer.
+func DefaultTracer() Tracer {
+	return defaultTracer.Load().Tracer
+}
+
+// SetDefaultTracer replaces the default tracer with t.
+// If t is nil, tracing is disabled.
+func SetDefaultTracer(t Tracer) {
+	if t == nil {
+		t = noopTracer{}
+	}
+	defaultTracer.Store(&tracerHolder{t})
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute)    {}
+func (noopSpan) AddEvent(string, ...Attribute) {}
+func (noopSpan) SetError(error)                {}
+func (noopSpan) End()                          {}
+
+// invokeKey identifies an Invoke in a dialogue: the Invoke IDs of the both
+// sides are independent.
+type invokeKey struct {
+	sent bool
+	id   uint8
+}
+
+type invokeSpan struct {
+	ctx  context.Context
+	span Span
+}
+
+// dialogueTrace is the spans of a Session.
+type dialogueTrace struct {
+	tracer    Tracer
+	ctx       context.Context
+	span      Span
+	remoteTID bool
+	remote    bool
+	acn       bool
+	invokes   map[invokeKey]*invokeSpan
+}
+
+func newDialogueTrace(ctx context.Context, tracer Tracer, tid uint32, local, remote string) *dialogueTrace {
+	attrs := []Attribute{Attr("tcap.local_tid", fmt.Sprintf("%08x", tid))}
+	if local != "" {
+		attrs = append(attrs, Attr("sccp.local_address", local))
+	}
+	if remote != "" {
+		attrs = append(attrs, Attr("sccp.remote_address", remote))
+	}
+
+	d := &dialogueTrace{tracer: tracer, remote: remote != "", invokes: map[invokeKey]*invokeSpan{}}
+	d.ctx, d.span = tracer.Start(ctx, "tcap.dialogue", attrs...)
+	return d
+}
+
+// peer sets the address of the peer learned from the message received, if it
+// is not known yet.
+func (d *dialogueTrace) peer(addr net.Addr) {
+	if d.span == nil || d.remote || addr == nil {
+		return
+	}
+	d.span.SetAttributes(Attr("sccp.remote_address", addr.String()))
+	d.remote = true
+}
+
+// message traces the message sent or received with the state updated by it.
+func (d *dialogueTrace) message(t *TCAP, sent bool, s *DialogueState) {
+	if d.span == nil {
+		return
+	}
+
+	dir := direction(sent)
+	if !d.remoteTID && len(s.RemoteTID) > 0 {
+		d.span.SetAttributes(Attr("tcap.remote_tid", fmt.Sprintf("%x", s.RemoteTID)))
+		d.remoteTID = true
+	}
+	if !d.acn && t.Dialogue != nil {
+		if oid := t.Dialogue.ApplicationContextOID(); oid != nil {
+			d.span.SetAttributes(Attr("tcap.acn", oid.String()))
+			d.acn = true
+		}
+	}
+
+	ts := t.Transaction
+	d.span.AddEvent("tcap.message", Attr("tcap.message_type", ts.MessageTypeString()), Attr("tcap.direction", dir))
+	if ts.Type.Code() == Abort {
+		if cause := ts.AbortCause(); cause != "" {
+			d.span.SetError(fmt.Errorf("tcap: dialogue aborted: %s", cause))
+		} else {
+			d.span.SetError(errors.New("tcap: dialogue aborted"))
+		}
+	}
+
+	if t.Components == nil {
+		return
+	}
+	for _, c := range t.Components.Component {
+		if c.InvokeID == nil || len(c.InvokeID.Value) == 0 {
+			continue
+		}
+		switch c.Type.Code() {
+		case Invoke:
+			attrs := []Attribute{Attr("tcap.invoke_id", strconv.Itoa(int(c.InvID()))), Attr("tcap.direction", dir)}
+			if c.OperationCode != nil && len(c.OperationCode.Value) > 0 {
+				attrs = append(attrs, Attr("tcap.opcode", strconv.Itoa(int(c.OpCode()))))
+			}
+			key := invokeKey{sent, c.InvID()}
+			if old, ok := d.invokes[key]; ok {
+				old.span.End()
+			}
+			ctx, span := d.tracer.Start(d.ctx, "tcap.invoke", attrs...)
+			d.invokes[key] = &invokeSpan{ctx, span}
+		case ReturnResultNotLast:
+			if inv, ok := d.invokes[invokeKey{!sent, c.InvID()}]; ok {
+				inv.span.AddEvent("tcap.result", Attr("tcap.direction", dir))
+			}
+		case ReturnResultLast, ReturnError, Reject:
+			key := invokeKey{!sent, c.InvID()}
+			inv, ok := d.invokes[key]
+			if !ok {
+				continue
+			}
+			switch c.Type.Code() {
+			case ReturnError:
+				if c.ErrorCode != nil && len(c.ErrorCode.Value) > 0 {
+					inv.span.SetError(fmt.Errorf("tcap: return error: %d", c.ErrorCode.Value[0]))
+				} else {
+					inv.span.SetError(errors.New("tcap: return error"))
+				}
+			case Reject:
+				inv.span.SetError(errors.New("tcap: rejected"))
+			}
+			inv.span.End()
+			delete(d.invokes, key)
+		}
+	}
+}
+
+// failed records the error in sending a message.
+func (d *dialogueTrace) failed(err error) {
+	if d.span != nil {
+		d.span.SetError(err)
+	}
+}
+
+// expired ends the spans of the Invokes sent whose timer has expired.
+func (d *dialogueTrace) expired(invokes []*InvokeState) {
+	for _, i := range invokes {
+		key := invokeKey{true, i.InvokeID}
+		if inv, ok := d.invokes[key]; ok {
+			inv.span.SetError(errors.New("tcap: invocation timer expired"))
+			inv.span.End()
+			delete(d.invokes, key)
+		}
+	}
+}
+
+// end ends the spans of the Invokes left and the dialogue.
+func (d *dialogueTrace) end() {
+	if d.span == nil {
+		return
+	}
+	for key, inv := range d.invokes {
+		inv.span.End()
+		delete(d.invokes, key)
+	}
+	d.span.End()
+	d.span = nil
+}
+
+func direction(sent bool) string {
+	if sent {
+		return "sent"
+	}
+	return "received"
+}
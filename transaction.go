@@ -7,6 +7,7 @@ package tcap
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 // Message Type definitions.
@@ -139,67 +140,49 @@ func NewAbort(dtid uint32, cause uint8, payload []byte) *Transaction {
 
 // MarshalBinary returns the byte sequence generated from a Transaction instance.
 func (t *Transaction) MarshalBinary() ([]byte, error) {
-	b := make([]byte, t.MarshalLen())
-	if err := t.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return marshalBinary(t)
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *Transaction) MarshalTo(b []byte) error {
-	b[0] = uint8(t.Type)
-	b[1] = t.Length
+	return marshalTo(t, b)
+}
+
+// AppendBinary appends the byte sequence generated from a Transaction instance to b.
+func (t *Transaction) AppendBinary(b []byte) ([]byte, error) {
+	return t.appendTo(b, MarshalOptions{})
+}
+
+func (t *Transaction) appendTo(b []byte, o MarshalOptions) ([]byte, error) {
+	return t.appendWith(b, o, func(b []byte, _ MarshalOptions) ([]byte, error) {
+		return append(b, t.Payload...), nil
+	})
+}
+
+// appendWith appends the Transaction Portion with the contents appended by
+// payload after the fields.
+func (t *Transaction) appendWith(b []byte, o MarshalOptions, payload func(b []byte, o MarshalOptions) ([]byte, error)) ([]byte, error) {
+	b, off := beginTLV(b, t.Type)
 
-	var offset = 2
+	var err error
 	switch t.Type.Code() {
-	case Unidirectional:
-		break
 	case Begin:
-		if field := t.OrigTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		b, err = appendIE(b, t.OrigTransactionID, o)
 	case End:
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		b, err = appendIE(b, t.DestTransactionID, o)
 	case Continue:
-		if field := t.OrigTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		b, err = appendIEs(b, o, t.OrigTransactionID, t.DestTransactionID)
 	case Abort:
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := t.PAbortCause; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		b, err = appendIEs(b, o, t.DestTransactionID, t.PAbortCause)
 	}
-	copy(b[offset:t.MarshalLen()], t.Payload)
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	if b, err = payload(b, o); err != nil {
+		return nil, err
+	}
+
+	return endTLV(b, off, t.Type, t.Length, o)
 }
 
 // ParseTransaction parses given byte sequence as an Transaction.
@@ -213,53 +196,79 @@ func ParseTransaction(b []byte) (*Transaction, error) {
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an Transaction.
 func (t *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return io.ErrUnexpectedEOF
+	}
 	t.Type = Tag(b[0])
 	t.Length = b[1]
 
-	var err error
-	var offset = 2
+	_, offset, err := parseLength(b)
+	if err != nil {
+		return err
+	}
 	switch t.Type.Code() {
 	case Unidirectional:
 		break
 	case Begin:
-		t.OrigTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
+		if t.OrigTransactionID, offset, err = parseTID(b, offset); err != nil {
 			return err
 		}
-		offset += t.OrigTransactionID.MarshalLen()
 	case End:
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
+		if t.DestTransactionID, offset, err = parseTID(b, offset); err != nil {
 			return err
 		}
-		offset += t.DestTransactionID.MarshalLen()
 	case Continue:
-		t.OrigTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
+		if t.OrigTransactionID, offset, err = parseTID(b, offset); err != nil {
 			return err
 		}
-		offset += t.OrigTransactionID.MarshalLen()
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
+		if t.DestTransactionID, offset, err = parseTID(b, offset); err != nil {
 			return err
 		}
-		offset += t.DestTransactionID.MarshalLen()
 	case Abort:
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
+		if t.DestTransactionID, offset, err = parseTID(b, offset); err != nil {
 			return err
 		}
-		offset += t.DestTransactionID.MarshalLen()
-		t.PAbortCause, err = ParseIE(b[offset : offset+3])
-		if err != nil {
-			return err
+		// P-Abort cause is absent in U-Abort.
+		if len(b) > offset && b[offset] == 0x4a {
+			if len(b) < offset+3 {
+				return io.ErrUnexpectedEOF
+			}
+			t.PAbortCause, offset, err = parseIEAt(b[:offset+3], offset)
+			if err != nil {
+				return err
+			}
 		}
-		offset += t.PAbortCause.MarshalLen()
 	}
 	t.Payload = b[offset:]
 	return nil
 }
 
+// parseTID parses the TID at offset and returns the offset after it.
+//
+// It returns *InvalidTIDError if the TID is not 1 to 4 octets as in ITU-T Q.773.
+func parseTID(b []byte, offset int) (*IE, int, error) {
+	ie, offset, err := parseIEAt(b, offset)
+	if err != nil {
+		return nil, offset, err
+	}
+	if len(ie.Value) < 1 || len(ie.Value) > 4 {
+		return nil, offset, &InvalidTIDError{Length: len(ie.Value)}
+	}
+	return ie, offset, nil
+}
+
+// tidValue returns the TID in uint32, or 0 if it is nil or longer than 4 octets.
+func tidValue(ie *IE) uint32 {
+	if ie == nil || len(ie.Value) > 4 {
+		return 0
+	}
+	var v uint32
+	for _, o := range ie.Value {
+		v = v<<8 | uint32(o)
+	}
+	return v
+}
+
 // SetValsFrom sets the values from IE parsed by ParseBER.
 func (t *Transaction) SetValsFrom(berParsed *IE) error {
 	t.Type = berParsed.Tag
@@ -279,7 +288,12 @@ func (t *Transaction) SetValsFrom(berParsed *IE) error {
 
 // MarshalLen returns the serial length of Transaction.
 func (t *Transaction) MarshalLen() int {
-	l := 2
+	return tlvLen(t.fieldsLen() + len(t.Payload))
+}
+
+// fieldsLen returns the serial length of the fields before the Payload.
+func (t *Transaction) fieldsLen() int {
+	l := 0
 	switch t.Type.Code() {
 	case Unidirectional:
 		break
@@ -306,7 +320,7 @@ func (t *Transaction) MarshalLen() int {
 			l += field.MarshalLen()
 		}
 	}
-	return l + len(t.Payload)
+	return l
 }
 
 // SetLength sets the length in Length field.
@@ -320,7 +334,7 @@ func (t *Transaction) SetLength() {
 	if field := t.PAbortCause; field != nil {
 		field.SetLength()
 	}
-	t.Length = uint8(t.MarshalLen() - 2)
+	t.Length = lengthOctet(t.fieldsLen() + len(t.Payload))
 }
 
 // MessageTypeString returns the name of Message Type in string.
@@ -0,0 +1,265 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/ishidawataru/sctp"
+	"github.com/wmnsk/go-m3ua"
+	"github.com/wmnsk/go-sccp"
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-sccp/utils"
+)
+
+// M3UA is a Conn that carries TCAP in SCCP UDT over M3UA.
+//
+// Write sends the message from the local address to the remote address. As
+// the dialogues with different peers share an association, ReadFrom returns the
+// Calling Party Address of each message as *PartyAddr, and WriteTo sends the
+// message to the address given, with which tcap.Endpoint answers each dialogue
+// to its peer.
+type M3UA struct {
+	conn   *m3ua.Conn
+	local  *params.PartyAddress
+	remote *params.PartyAddress
+
+	rmu sync.Mutex
+	buf []byte
+}
+
+// PartyAddr is the SCCP address of the peer, which implements net.Addr.
+type PartyAddr struct {
+	*params.PartyAddress
+}
+
+// Network returns "sccp".
+func (a *PartyAddr) Network() string {
+	return "sccp"
+}
+
+// String returns the GT or the point code with the SSN, e.g., "gt=819000000000,ssn=8".
+func (a *PartyAddr) String() string {
+	return addressString(a.PartyAddress)
+}
+
+// NewM3UA creates a new M3UA on the established M3UA connection.
+func NewM3UA(conn *m3ua.Conn, local, remote *params.PartyAddress) *M3UA {
+	return &M3UA{conn: conn, local: local, remote: remote, buf: make([]byte, 0xffff)}
+}
+
+// DialM3UA establishes the M3UA association with the peer at addr, e.g.,
+// "127.0.0.1:2905", and returns M3UA on it.
+func DialM3UA(ctx context.Context, addr string, cfg *m3ua.Config, local, remote *params.PartyAddress) (*M3UA, error) {
+	raddr, err := sctp.ResolveSCTPAddr("sctp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("transport: failed to resolve SCTP address: %w", err)
+	}
+	conn, err := m3ua.Dial(ctx, "m3ua", nil, raddr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewM3UA(conn, local, remote), nil
+}
+
+// M3UAListener accepts the M3UA associations from the peers.
+type M3UAListener struct {
+	l     *m3ua.Listener
+	local *params.PartyAddress
+}
+
+// ListenM3UA listens on addr for M3UA associations. The M3UA accepted has the
+// local address given and no remote address, i.e., the messages are sent with
+// WriteTo to the Calling Party Addresses of the messages received.
+func ListenM3UA(addr string, cfg *m3ua.Config, local *params.PartyAddress) (*M3UAListener, error) {
+	laddr, err := sctp.ResolveSCTPAddr("sctp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("transport: failed to resolve SCTP address: %w", err)
+	}
+	l, err := m3ua.Listen("m3ua", laddr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &M3UAListener{l: l, local: local}, nil
+}
+
+// Accept waits for the next association and returns M3UA on it.
+func (l *M3UAListener) Accept(ctx context.Context) (*M3UA, error) {
+	conn, err := l.l.Accept(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewM3UA(conn, l.local, nil), nil
+}
+
+// Close stops listening.
+func (l *M3UAListener) Close() error {
+	return l.l.Close()
+}
+
+// Read reads the TCAP message in the next SCCP UDT or XUDT.
+//
+// The SCCP messages of other types are discarded. If b is too small to hold
+// the message, it returns io.ErrShortBuffer and the message is lost.
+func (m *M3UA) Read(b []byte) (int, error) {
+	n, _, err := m.ReadFrom(b)
+	return n, err
+}
+
+// ReadFrom is the same as Read, but also returns the Calling Party Address of
+// the message as *PartyAddr.
+func (m *M3UA) ReadFrom(b []byte) (int, net.Addr, error) {
+	m.rmu.Lock()
+	defer m.rmu.Unlock()
+
+	for {
+		n, err := m.conn.Read(m.buf)
+		if err != nil {
+			return 0, nil, err
+		}
+		msg, err := sccp.ParseMessage(m.buf[:n])
+		if err != nil {
+			continue
+		}
+
+		var cgpa *params.PartyAddress
+		var data []byte
+		switch s := msg.(type) {
+		case *sccp.UDT:
+			cgpa, data = s.CallingPartyAddress, s.Data.Value()
+		case *sccp.XUDT:
+			cgpa, data = s.CallingPartyAddress, s.Data.Value()
+		default:
+			continue
+		}
+
+		if len(data) > len(b) {
+			return 0, nil, io.ErrShortBuffer
+		}
+		var addr net.Addr
+		if cgpa != nil {
+			addr = &PartyAddr{cgpa}
+		}
+		return copy(b, data), addr, nil
+	}
+}
+
+// Write sends b in SCCP UDT to the remote address.
+func (m *M3UA) Write(b []byte) (int, error) {
+	if m.remote == nil {
+		return 0, fmt.Errorf("transport: no remote SCCP address to send to")
+	}
+	return m.writeTo(b, m.remote)
+}
+
+// WriteTo sends b in SCCP UDT to addr, which must be *PartyAddr.
+func (m *M3UA) WriteTo(b []byte, addr net.Addr) (int, error) {
+	a, ok := addr.(*PartyAddr)
+	if !ok || a.PartyAddress == nil {
+		return 0, fmt.Errorf("transport: invalid SCCP address: %v", addr)
+	}
+	return m.writeTo(b, a.PartyAddress)
+}
+
+// MarshalAddr returns addr, which must be *PartyAddr, in the octets of the
+// SCCP parameter, to be saved in tcap.DialogueState.
+func (m *M3UA) MarshalAddr(addr net.Addr) ([]byte, error) {
+	a, ok := addr.(*PartyAddr)
+	if !ok || a.PartyAddress == nil {
+		return nil, fmt.Errorf("transport: invalid SCCP address: %v", addr)
+	}
+	b := make([]byte, a.MarshalLen())
+	if _, err := a.Write(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UnmarshalAddr returns the *PartyAddr in the octets returned by MarshalAddr.
+func (m *M3UA) UnmarshalAddr(b []byte) (net.Addr, error) {
+	p, _, err := params.ParseCallingPartyAddress(b)
+	if err != nil {
+		return nil, fmt.Errorf("transport: invalid SCCP address: %w", err)
+	}
+	return &PartyAddr{p}, nil
+}
+
+func (m *M3UA) writeTo(b []byte, remote *params.PartyAddress) (int, error) {
+	udt, err := sccp.NewUDT(1, true, calledParty(remote), callingParty(m.local), b).MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := m.conn.Write(udt); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// RemoteAddr returns the address of the peer of the association.
+func (m *M3UA) RemoteAddr() net.Addr {
+	return m.conn.RemoteAddr()
+}
+
+// PartyAddresses returns the local SCCP address and the remote one that Write
+// sends the messages to, e.g., "gt=819000000000,ssn=8". The remote address is
+// empty if it is not given.
+func (m *M3UA) PartyAddresses() (local, remote string) {
+	return addressString(m.local), addressString(m.remote)
+}
+
+// Close closes the M3UA connection.
+func (m *M3UA) Close() error {
+	return m.conn.Close()
+}
+
+// GTAddress creates an SCCP address that routes on the Global Title of E.164
+// digits, with the SSN given.
+func GTAddress(ssn uint8, digits string) (*params.PartyAddress, error) {
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, fmt.Errorf("transport: invalid Global Title: %q", digits)
+	}
+	bcd, err := utils.BCDEncode(digits)
+	if err != nil {
+		return nil, fmt.Errorf("transport: invalid Global Title %q: %w", digits, err)
+	}
+	es := params.ESBCDEven
+	if len(digits)%2 == 1 {
+		es = params.ESBCDOdd
+	}
+
+	gti := params.GTITTNPESNAI
+	return params.NewCallingPartyAddress(
+		params.NewAddressIndicator(false, true, false, gti), 0, ssn,
+		params.NewGlobalTitle(gti, params.TranslationType(0), params.NPISDNTelephony, es, params.NAIInternationalNumber, bcd),
+	), nil
+}
+
+func calledParty(p *params.PartyAddress) *params.PartyAddress {
+	return params.NewCalledPartyAddress(p.Indicator, p.SignalingPointCode, p.SubsystemNumber, p.GlobalTitle)
+}
+
+func callingParty(p *params.PartyAddress) *params.PartyAddress {
+	return params.NewCallingPartyAddress(p.Indicator, p.SignalingPointCode, p.SubsystemNumber, p.GlobalTitle)
+}
+
+// addressString returns the GT or the point code of p with the SSN.
+func addressString(p *params.PartyAddress) string {
+	if p == nil {
+		return ""
+	}
+	addr := fmt.Sprintf("pc=%d", p.SignalingPointCode)
+	if p.GlobalTitle != nil && p.GlobalTitle.AddressInformation != nil {
+		addr = "gt=" + p.GlobalTitle.Address()
+	}
+	if p.HasSSN() {
+		addr += fmt.Sprintf(",ssn=%d", p.SubsystemNumber)
+	}
+	return addr
+}
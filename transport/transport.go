@@ -0,0 +1,90 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package transport provides the message-oriented connections that carry TCAP
+messages, to be used with tcap.Endpoint.
+
+Pipe creates an in-process loopback, which is useful to run both sides in a
+process or in tests, and M3UA carries TCAP in SCCP UDT over SCTP/M3UA.
+
+	client, server := transport.Pipe()
+	go tcap.NewEndpoint(server).Serve(ctx)
+	ep := tcap.NewEndpoint(client)
+*/
+package transport
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClosed is returned when the connection is closed.
+var ErrClosed = errors.New("transport: connection closed")
+
+// Conn is a connection that sends a TCAP message in each Write and returns
+// one in each Read.
+type Conn interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	Close() error
+}
+
+// pipe is one end of the loopback created by Pipe.
+type pipe struct {
+	in   <-chan []byte
+	out  chan<- []byte
+	done chan struct{}
+	once *sync.Once
+}
+
+// Pipe creates a pair of Conns connected in the process.
+//
+// The messages written to one end are read from the other end in order.
+// Closing either end closes both, like a link going down.
+func Pipe() (Conn, Conn) {
+	a, b := make(chan []byte, 256), make(chan []byte, 256)
+	done, once := make(chan struct{}), &sync.Once{}
+	return &pipe{in: a, out: b, done: done, once: once}, &pipe{in: b, out: a, done: done, once: once}
+}
+
+// Read reads the next message into b.
+//
+// If b is too small to hold the message, it returns io.ErrShortBuffer and the
+// message is lost.
+func (p *pipe) Read(b []byte) (int, error) {
+	select {
+	case m := <-p.in:
+		if len(m) > len(b) {
+			return 0, io.ErrShortBuffer
+		}
+		return copy(b, m), nil
+	case <-p.done:
+		return 0, ErrClosed
+	}
+}
+
+// Write sends b as a message to the other end.
+func (p *pipe) Write(b []byte) (int, error) {
+	m := append([]byte(nil), b...)
+	select {
+	case <-p.done:
+		return 0, ErrClosed
+	default:
+	}
+
+	select {
+	case p.out <- m:
+		return len(b), nil
+	case <-p.done:
+		return 0, ErrClosed
+	}
+}
+
+// Close closes both ends of the pipe.
+func (p *pipe) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
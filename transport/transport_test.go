@@ -0,0 +1,105 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package transport_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-sccp/params"
+	"github.com/wmnsk/go-tcap/transport"
+)
+
+func TestPipe(t *testing.T) {
+	a, b := transport.Pipe()
+	msgs := [][]byte{{0x62, 0x00}, {0x65, 0x01, 0x00}, {0x64}}
+	for _, m := range msgs {
+		if _, err := a.Write(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := make([]byte, 16)
+	for _, want := range msgs {
+		n, err := b.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, "message", buf[:n], want)
+	}
+
+	if _, err := b.Write([]byte{0x62, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Read(make([]byte, 1)); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("unexpected error with short buffer: %v", err)
+	}
+
+	b.Close()
+	if _, err := a.Write([]byte{0x62}); !errors.Is(err, transport.ErrClosed) {
+		t.Errorf("unexpected error from Write after Close: %v", err)
+	}
+	if _, err := a.Read(buf); !errors.Is(err, transport.ErrClosed) {
+		t.Errorf("unexpected error from Read after Close: %v", err)
+	}
+}
+
+func TestGTAddress(t *testing.T) {
+	cases := []struct {
+		digits string
+		es     params.EncodingScheme
+		gt     []byte
+	}{
+		{"819012345678", params.ESBCDEven, []byte{0x18, 0x09, 0x21, 0x43, 0x65, 0x87}},
+		{"81901234567", params.ESBCDOdd, []byte{0x18, 0x09, 0x21, 0x43, 0x65, 0x07}},
+	}
+	for _, c := range cases {
+		a, err := transport.GTAddress(6, c.digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, c.digits+" SSN", a.SubsystemNumber, uint8(6))
+		verify.Values(t, c.digits+" ES", a.GlobalTitle.EncodingScheme, c.es)
+		verify.Values(t, c.digits+" GT", a.GlobalTitle.AddressInformation, c.gt)
+	}
+
+	if _, err := transport.GTAddress(6, "81a"); err == nil {
+		t.Error("no error with invalid digits")
+	}
+}
+
+func TestPartyAddresses(t *testing.T) {
+	local, err := transport.GTAddress(8, "819000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := transport.GTAddress(6, "81901234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, r := transport.NewM3UA(nil, local, nil).PartyAddresses()
+	verify.Values(t, "local", l, "gt=819000000000,ssn=8")
+	verify.Values(t, "unknown remote", r, "")
+
+	_, r = transport.NewM3UA(nil, local, remote).PartyAddresses()
+	verify.Values(t, "remote", r, "gt=81901234567,ssn=6")
+
+	addr := &transport.PartyAddr{PartyAddress: remote}
+	verify.Values(t, "PartyAddr", addr.String(), "gt=81901234567,ssn=6")
+
+	m := transport.NewM3UA(nil, local, nil)
+	b, err := m.MarshalAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := m.UnmarshalAddr(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "restored", restored.String(), "gt=81901234567,ssn=6")
+}
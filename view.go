@@ -0,0 +1,206 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"fmt"
+
+	"github.com/wmnsk/go-tcap/ber"
+)
+
+// MessageView is a read-only view of an ITU-T TCAP message that decodes the
+// fields on demand without allocations, for routing and filtering messages at
+// high rates.
+//
+// Nothing is decoded until asked, and the values returned refer to the byte
+// sequence given to NewMessageView, which must not be modified while the view
+// is in use. The lengths in long form are accepted. Use Parse to access the
+// other fields.
+type MessageView struct {
+	b []byte
+}
+
+// NewMessageView creates a new MessageView over b.
+func NewMessageView(b []byte) MessageView {
+	return MessageView{b: b}
+}
+
+// Bytes returns the byte sequence of the message.
+func (v MessageView) Bytes() []byte {
+	return v.b
+}
+
+// MessageType returns the message type, e.g., Begin.
+func (v MessageView) MessageType() (int, error) {
+	t, err := v.transaction()
+	if err != nil {
+		return 0, err
+	}
+	return t.Tag, nil
+}
+
+// OTID returns the Originating Transaction ID, or nil if the message has none.
+func (v MessageView) OTID() ([]byte, error) {
+	e, ok, err := v.portion(0x08)
+	if !ok {
+		return nil, err
+	}
+	return e.Value, nil
+}
+
+// DTID returns the Destination Transaction ID, or nil if the message has none.
+func (v MessageView) DTID() ([]byte, error) {
+	e, ok, err := v.portion(0x09)
+	if !ok {
+		return nil, err
+	}
+	return e.Value, nil
+}
+
+// ApplicationContextName returns the contents of the Application Context Name
+// in the Dialogue Portion, or nil if the message has none.
+//
+// The contents are the same as DialogueState.ApplicationContextName, and can be
+// decoded with ber.DecodeObjectIdentifier.
+func (v MessageView) ApplicationContextName() ([]byte, error) {
+	d, ok, err := v.portion(0x0b)
+	if !ok {
+		return nil, err
+	}
+
+	// EXTERNAL { direct-reference, single-ASN1-type [0] { APDU { ..., [1] { OID } } } }
+	ext, ok, err := find(d.Value, ber.Universal, 8)
+	if !ok {
+		return nil, err
+	}
+	asn1Type, ok, err := find(ext.Value, ber.ContextSpecific, 0)
+	if !ok {
+		return nil, err
+	}
+	apdu, _, err := ber.Next(asn1Type.Value)
+	if err != nil {
+		return nil, err
+	}
+	acn, ok, err := find(apdu.Value, ber.ContextSpecific, 1)
+	if !ok {
+		return nil, err
+	}
+	oid, ok, err := find(acn.Value, ber.Universal, ber.TagObjectIdentifier)
+	if !ok {
+		return nil, err
+	}
+	return oid.Value, nil
+}
+
+// FirstOpCode returns the contents of the Operation Code of the first Invoke or
+// ReturnResult that has one, or nil if no Component has it.
+//
+// The contents are an INTEGER for the local operation codes, and an OBJECT
+// IDENTIFIER for the global ones.
+func (v MessageView) FirstOpCode() ([]byte, error) {
+	c, ok, err := v.portion(0x0c)
+	if !ok {
+		return nil, err
+	}
+
+	for b := c.Value; len(b) > 0; {
+		comp, n, err := ber.Next(b)
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+		if comp.Class != ber.ContextSpecific {
+			continue
+		}
+
+		var op ber.Element
+		switch comp.Tag {
+		case Invoke:
+			op, ok, err = invokeOpCode(comp.Value)
+		case ReturnResultLast, ReturnResultNotLast:
+			op, ok, err = resultOpCode(comp.Value)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return op.Value, nil
+		}
+	}
+	return nil, nil
+}
+
+// invokeOpCode returns the Operation Code in the contents of Invoke, which
+// follows the Invoke ID and the optional Linked ID.
+func invokeOpCode(b []byte) (ber.Element, bool, error) {
+	for i := 0; len(b) > 0; i++ {
+		e, n, err := ber.Next(b)
+		if err != nil {
+			return ber.Element{}, false, err
+		}
+		b = b[n:]
+		if i == 0 || e.Class == ber.ContextSpecific {
+			continue
+		}
+		return e, true, nil
+	}
+	return ber.Element{}, false, nil
+}
+
+// resultOpCode returns the Operation Code in the contents of ReturnResult,
+// which is the first in the SEQUENCE following the Invoke ID.
+func resultOpCode(b []byte) (ber.Element, bool, error) {
+	seq, ok, err := find(b, ber.Universal, ber.TagSequence)
+	if !ok {
+		return ber.Element{}, false, err
+	}
+	if len(seq.Value) == 0 {
+		return ber.Element{}, false, nil
+	}
+	op, _, err := ber.Next(seq.Value)
+	if err != nil {
+		return ber.Element{}, false, err
+	}
+	return op, true, nil
+}
+
+// transaction returns the Transaction Portion.
+func (v MessageView) transaction() (ber.Element, error) {
+	t, _, err := ber.Next(v.b)
+	if err != nil {
+		return ber.Element{}, err
+	}
+	if t.Class != ber.Application || !t.Constructed {
+		return ber.Element{}, fmt.Errorf("tcap: not a Transaction Portion: %#x", v.b[0])
+	}
+	return t, nil
+}
+
+// portion returns the element with the tag of application-wide class in the
+// Transaction Portion.
+func (v MessageView) portion(tag int) (ber.Element, bool, error) {
+	t, err := v.transaction()
+	if err != nil {
+		return ber.Element{}, false, err
+	}
+	return find(t.Value, ber.Application, tag)
+}
+
+// find returns the first element with the class and the tag in b.
+func find(b []byte, class, tag int) (ber.Element, bool, error) {
+	for len(b) > 0 {
+		e, n, err := ber.Next(b)
+		if err != nil {
+			return ber.Element{}, false, err
+		}
+		if e.Class == class && e.Tag == tag {
+			return e, true, nil
+		}
+		b = b[n:]
+	}
+	return ber.Element{}, false, nil
+}
@@ -0,0 +1,205 @@
+// Copyright go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap_test
+
+import (
+	"encoding"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+	"github.com/wmnsk/go-tcap"
+)
+
+func mustMarshal(t testing.TB, m encoding.BinaryMarshaler) []byte {
+	t.Helper()
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestMessageView(t *testing.T) {
+	continueMsg := &tcap.TCAP{
+		Transaction: tcap.NewContinue(0x11111111, 0x22222222, []byte{}),
+		Components: tcap.NewComponents(
+			tcap.NewReject(1, tcap.InvokeProblem, tcap.InvokeProblemUnrecognizedOperation, nil),
+			tcap.NewInvoke(2, 1, 45, true, nil),
+		),
+	}
+	continueMsg.SetLength()
+
+	cases := []struct {
+		description string
+		message     []byte
+		mtype       int
+		otid, dtid  []byte
+		acn, opcode []byte
+	}{
+		{
+			description: "Begin with dialogue",
+			message:     mustMarshal(t, tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})),
+			mtype:       tcap.Begin,
+			otid:        []byte{0x11, 0x11, 0x11, 0x11},
+			acn:         []byte{0x04, 0x00, 0x00, 0x01, 0x00, 0x02, 0x03},
+			opcode:      []byte{0x03},
+		}, {
+			description: "Continue with Reject and Invoke with Linked ID",
+			message:     mustMarshal(t, continueMsg),
+			mtype:       tcap.Continue,
+			otid:        []byte{0x11, 0x11, 0x11, 0x11},
+			dtid:        []byte{0x22, 0x22, 0x22, 0x22},
+			opcode:      []byte{0x2d},
+		}, {
+			description: "End with dialogue and ReturnResultLast",
+			message:     mustMarshal(t, tcap.NewEndReturnResultWithDialogue(0x22222222, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 2, true, []byte{})),
+			mtype:       tcap.End,
+			dtid:        []byte{0x22, 0x22, 0x22, 0x22},
+			acn:         []byte{0x04, 0x00, 0x00, 0x01, 0x00, 0x02, 0x03},
+			opcode:      []byte{0x02},
+		}, {
+			description: "P-Abort",
+			message:     mustMarshal(t, &tcap.TCAP{Transaction: tcap.NewAbort(0x22222222, tcap.UnrecognizedTransactionID, []byte{})}),
+			mtype:       tcap.Abort,
+			dtid:        []byte{0x22, 0x22, 0x22, 0x22},
+		}, {
+			description: "Begin in long form",
+			message:     []byte{0x62, 0x81, 0x06, 0x48, 0x04, 0x01, 0x02, 0x03, 0x04},
+			mtype:       tcap.Begin,
+			otid:        []byte{0x01, 0x02, 0x03, 0x04},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			v := tcap.NewMessageView(c.message)
+
+			mtype, err := v.MessageType()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "message type", mtype, c.mtype)
+
+			for _, f := range []struct {
+				name string
+				get  func() ([]byte, error)
+				want []byte
+			}{
+				{"OTID", v.OTID, c.otid},
+				{"DTID", v.DTID, c.dtid},
+				{"ACN", v.ApplicationContextName, c.acn},
+				{"opcode", v.FirstOpCode, c.opcode},
+			} {
+				got, err := f.get()
+				if err != nil {
+					t.Fatalf("%s: %v", f.name, err)
+				}
+				verify.Values(t, f.name, got, f.want)
+			}
+		})
+	}
+}
+
+func TestMessageViewMalformed(t *testing.T) {
+	for _, b := range [][]byte{
+		nil,
+		{0x62},
+		{0x62, 0x06, 0x48, 0x04, 0x01},
+		{0x30, 0x00},
+	} {
+		v := tcap.NewMessageView(b)
+		if _, err := v.MessageType(); err == nil {
+			t.Errorf("no error with %x", b)
+		}
+		if _, err := v.OTID(); err == nil {
+			t.Errorf("no error from OTID with %x", b)
+		}
+	}
+
+	// the error in the field is found when it is asked.
+	v := tcap.NewMessageView([]byte{0x62, 0x09, 0x48, 0x04, 0x01, 0x02, 0x03, 0x04, 0x6c, 0x05, 0xa1})
+	if _, err := v.OTID(); err != nil {
+		t.Errorf("unexpected error from OTID: %v", err)
+	}
+	if _, err := v.FirstOpCode(); err == nil {
+		t.Error("no error from FirstOpCode with truncated Components")
+	}
+}
+
+func TestMessageViewAllocs(t *testing.T) {
+	v := tcap.NewMessageView(mustMarshal(t, tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x01, 0xff})))
+	allocs := testing.AllocsPerRun(100, func() {
+		v.MessageType()
+		v.OTID()
+		v.DTID()
+		v.ApplicationContextName()
+		v.FirstOpCode()
+	})
+	verify.Values(t, "allocs", allocs, 0.0)
+}
+
+func benchmarkMessage(b *testing.B) []byte {
+	return mustMarshal(b, tcap.NewBeginInvokeWithDialogue(
+		0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 3, 0, 3,
+		[]byte{0x04, 0x08, 0x44, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0xf0, 0x81, 0x01, 0x00},
+	))
+}
+
+func BenchmarkParse(b *testing.B) {
+	msg := benchmarkMessage(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		t, err := tcap.Parse(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = t.OTID()
+	}
+}
+
+func BenchmarkParseBER(b *testing.B) {
+	msg := benchmarkMessage(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		t, err := tcap.ParseBER(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = t[0].OTID()
+	}
+}
+
+func BenchmarkMessageViewOTID(b *testing.B) {
+	msg := benchmarkMessage(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := tcap.NewMessageView(msg).OTID(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMessageView(b *testing.B) {
+	msg := benchmarkMessage(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		v := tcap.NewMessageView(msg)
+		if _, err := v.MessageType(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := v.OTID(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := v.DTID(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := v.ApplicationContextName(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := v.FirstOpCode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}